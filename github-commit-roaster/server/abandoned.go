@@ -0,0 +1,90 @@
+package main
+
+import (
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// AbandonmentStats counts listed repos nobody has pushed to in a while.
+type AbandonmentStats struct {
+	Stale                 int    `json:"stale"` // untouched for StaleAfterDays
+	Dead                  int    `json:"dead"`  // untouched for DeadAfterDays
+	StaleAfterDays        int    `json:"stale_after_days"`
+	DeadAfterDays         int    `json:"dead_after_days"`
+	MostRecentlyAbandoned string `json:"most_recently_abandoned,omitempty"`
+}
+
+// detectAbandonedRepos checks each repo's last push against the thresholds.
+// Empty repos have no PushedAt and are ignored.
+func detectAbandonedRepos(repos []*github.Repository, staleDays, deadDays int, now time.Time) AbandonmentStats {
+	stats := AbandonmentStats{StaleAfterDays: staleDays, DeadAfterDays: deadDays}
+	staleBefore := now.AddDate(0, 0, -staleDays)
+	deadBefore := now.AddDate(0, 0, -deadDays)
+
+	var latest time.Time
+	for _, repo := range repos {
+		if repo.PushedAt == nil {
+			continue
+		}
+		pushed := repo.GetPushedAt().Time
+		if !pushed.Before(staleBefore) {
+			continue
+		}
+		stats.Stale++
+		if pushed.Before(deadBefore) {
+			stats.Dead++
+		}
+		if pushed.After(latest) {
+			latest = pushed
+			stats.MostRecentlyAbandoned = repo.GetName()
+		}
+	}
+	return stats
+}
+
+const (
+	// burstDays is how soon after creation a repo's pushes must stop for it
+	// to count as started in a burst of energy.
+	burstDays = 7
+	// abandonedProjectMonths is how long a burst project must then sit
+	// untouched to count as abandoned.
+	abandonedProjectMonths = 6
+	// maxAbandonedExamples is how many abandoned project names are listed.
+	maxAbandonedExamples = 3
+)
+
+// AbandonedProjectStats counts projects that got all their pushes in their
+// first week and none since.
+type AbandonedProjectStats struct {
+	Count    int      `json:"count"`
+	Examples []string `json:"examples"`
+	// OldestAbandonedYear is the year the oldest of them was created
+	OldestAbandonedYear int `json:"oldest_abandoned_year,omitempty"`
+}
+
+// detectAbandonedProjects finds repos whose last push came within burstDays
+// of their creation and more than abandonedProjectMonths ago. The repo
+// listing has no commit counts, so a last push in the first week stands in
+// for the initial burst of commits; forks and empty repos are skipped.
+func detectAbandonedProjects(repos []*github.Repository, now time.Time) AbandonedProjectStats {
+	stats := AbandonedProjectStats{Examples: []string{}}
+	abandonedBefore := now.AddDate(0, -abandonedProjectMonths, 0)
+	for _, repo := range repos {
+		if repo.GetFork() || repo.CreatedAt == nil || repo.PushedAt == nil || repo.GetSize() == 0 {
+			continue
+		}
+		created, pushed := repo.GetCreatedAt().Time, repo.GetPushedAt().Time
+		if pushed.Sub(created) > burstDays*24*time.Hour || !pushed.Before(abandonedBefore) {
+			continue
+		}
+		stats.Count++
+		if len(stats.Examples) < maxAbandonedExamples {
+			stats.Examples = append(stats.Examples, repo.GetName())
+		}
+		if stats.OldestAbandonedYear == 0 || created.Year() < stats.OldestAbandonedYear {
+			stats.OldestAbandonedYear = created.Year()
+		}
+	}
+	return stats
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// pushedRepo is a repo last pushed to daysAgo days before now; a negative
+// daysAgo leaves PushedAt nil, like an empty repo.
+func pushedRepo(name string, now time.Time, daysAgo int) *github.Repository {
+	repo := &github.Repository{Name: github.String(name)}
+	if daysAgo >= 0 {
+		repo.PushedAt = &github.Timestamp{Time: now.AddDate(0, 0, -daysAgo)}
+	}
+	return repo
+}
+
+func TestDetectAbandonedRepos(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		repos []*github.Repository
+		want  AbandonmentStats
+	}{
+		{"no repos", nil, AbandonmentStats{StaleAfterDays: 180, DeadAfterDays: 730}},
+		{
+			"empty repos are ignored",
+			[]*github.Repository{pushedRepo("empty", now, -1), pushedRepo("other-empty", now, -1)},
+			AbandonmentStats{StaleAfterDays: 180, DeadAfterDays: 730},
+		},
+		{
+			"stale, dead and active",
+			[]*github.Repository{
+				pushedRepo("active", now, 10),
+				pushedRepo("stale", now, 200),
+				pushedRepo("dead", now, 800),
+				pushedRepo("empty", now, -1),
+				pushedRepo("recently-stale", now, 181),
+			},
+			AbandonmentStats{Stale: 3, Dead: 1, StaleAfterDays: 180, DeadAfterDays: 730, MostRecentlyAbandoned: "recently-stale"},
+		},
+		{
+			"exactly on the threshold is still active",
+			[]*github.Repository{pushedRepo("borderline", now, 180)},
+			AbandonmentStats{StaleAfterDays: 180, DeadAfterDays: 730},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectAbandonedRepos(tt.repos, 180, 730, now); got != tt.want {
+				t.Errorf("detectAbandonedRepos() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbandonmentThresholdsConfig(t *testing.T) {
+	t.Setenv("STALE_REPO_DAYS", "30")
+	t.Setenv("DEAD_REPO_DAYS", "90")
+	cfg := loadRoastConfig()
+	if cfg.StaleAfterDays != 30 || cfg.DeadAfterDays != 90 {
+		t.Fatalf("thresholds = %d, %d, want 30, 90", cfg.StaleAfterDays, cfg.DeadAfterDays)
+	}
+
+	now := time.Now()
+	got := detectAbandonedRepos([]*github.Repository{pushedRepo("quiet", now, 60)}, cfg.StaleAfterDays, cfg.DeadAfterDays, now)
+	if got.Stale != 1 || got.Dead != 0 {
+		t.Errorf("60 days quiet with 30/90 thresholds = %+v, want stale but not dead", got)
+	}
+}
+
+func TestRoastWithEmptyRepos(t *testing.T) {
+	now := time.Now()
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "add parser", 2))
+	gh.repos = append(gh.repos,
+		pushedRepo("empty", now, -1),
+		pushedRepo("old-1", now, 400),
+		pushedRepo("old-2", now, 300),
+		pushedRepo("old-3", now, 900),
+	)
+	s := newTestServer(t, gh)
+
+	w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var body RoastResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if got := body.Stats.Abandonment; got.Stale != 3 || got.Dead != 1 || got.MostRecentlyAbandoned != "old-2" {
+		t.Errorf("abandonment = %+v", got)
+	}
+}
+
+// burstRepo is a repo created createdDaysAgo days before now and last
+// pushed pushedDaysAgo days before now.
+func burstRepo(name string, now time.Time, createdDaysAgo, pushedDaysAgo int) *github.Repository {
+	return &github.Repository{
+		Name:      github.String(name),
+		Size:      github.Int(42),
+		CreatedAt: &github.Timestamp{Time: now.AddDate(0, 0, -createdDaysAgo)},
+		PushedAt:  &github.Timestamp{Time: now.AddDate(0, 0, -pushedDaysAgo)},
+	}
+}
+
+func TestDetectAbandonedProjects(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	fork := burstRepo("fork", now, 400, 398)
+	fork.Fork = github.Bool(true)
+	empty := burstRepo("empty", now, 400, 400)
+	empty.Size = github.Int(0)
+	unknown := burstRepo("unknown", now, 400, 398)
+	unknown.CreatedAt = nil
+
+	tests := []struct {
+		name  string
+		repos []*github.Repository
+		want  AbandonedProjectStats
+	}{
+		{"no repos", nil, AbandonedProjectStats{Examples: []string{}}},
+		{
+			"burst then silence",
+			[]*github.Repository{burstRepo("weekend-idea", now, 400, 397)},
+			AbandonedProjectStats{Count: 1, Examples: []string{"weekend-idea"}, OldestAbandonedYear: 2023},
+		},
+		{
+			"pushed on day seven still counts",
+			[]*github.Repository{burstRepo("just-a-week", now, 300, 293)},
+			AbandonedProjectStats{Count: 1, Examples: []string{"just-a-week"}, OldestAbandonedYear: 2023},
+		},
+		{
+			"pushed after the first week",
+			[]*github.Repository{burstRepo("kept-going", now, 400, 380)},
+			AbandonedProjectStats{Examples: []string{}},
+		},
+		{
+			"burst too recent to call abandoned",
+			[]*github.Repository{burstRepo("new-idea", now, 100, 98)},
+			AbandonedProjectStats{Examples: []string{}},
+		},
+		{
+			"forks, empty repos and missing dates skipped",
+			[]*github.Repository{fork, empty, unknown},
+			AbandonedProjectStats{Examples: []string{}},
+		},
+		{
+			"examples capped, oldest year kept",
+			[]*github.Repository{
+				burstRepo("a", now, 400, 399),
+				burstRepo("b", now, 1500, 1499),
+				burstRepo("c", now, 700, 700),
+				burstRepo("d", now, 2500, 2496),
+				burstRepo("alive", now, 2500, 3),
+			},
+			AbandonedProjectStats{Count: 4, Examples: []string{"a", "b", "c"}, OldestAbandonedYear: 2017},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectAbandonedProjects(tt.repos, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectAbandonedProjects() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbandonedProjectsRule(t *testing.T) {
+	rule := findRule(t, "abandoned_projects")
+	for count, want := range map[int]bool{0: false, 3: false, 4: true, 6: true} {
+		stats := CommitStats{TotalCommits: 10, AbandonedProjects: AbandonedProjectStats{Count: count}}
+		if got := rule.Triggered(stats); got != want {
+			t.Errorf("%d abandoned: triggered = %v, want %v", count, got, want)
+		}
+	}
+	stats := CommitStats{AbandonedProjects: AbandonedProjectStats{Count: 6}}
+	want := "You have 6 abandoned repos, each one a New Year's resolution that didn't survive February. The dev equivalent of a gym membership."
+	if line := rule.line(stats, defaultIntensity); line != want {
+		t.Errorf("line = %q", line)
+	}
+}
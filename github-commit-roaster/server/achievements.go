@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Achievement is a badge earned from an analysis.
+type Achievement struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Emoji       string `json:"emoji"`
+	Description string `json:"description"`
+
+	Earned func(stats CommitStats) bool `json:"-"`
+}
+
+// share returns part/total, or 0 when there is nothing to divide.
+func share(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// achievements lists every badge in the order they are awarded.
+var achievements = []Achievement{
+	{
+		ID:          "night_owl",
+		Name:        "Night Owl",
+		Emoji:       "🦉",
+		Description: "More than 60% of commits made during the late-night window",
+		Earned: func(s CommitStats) bool {
+			return share(s.LateNightCommits, s.TotalCommits) > 0.6
+		},
+	},
+	{
+		ID:          "merge_lord",
+		Name:        "Merge Lord",
+		Emoji:       "👑",
+		Description: "More than half of all commits are merges",
+		Earned: func(s CommitStats) bool {
+			return share(s.MergeCommits, s.TotalCommits) > 0.5
+		},
+	},
+	{
+		ID:          "commitment_issues",
+		Name:        "Commitment Issues",
+		Emoji:       "💔",
+		Description: "Went more than 14 days without a single commit",
+		Earned: func(s CommitStats) bool {
+			return s.LongestGapDays > 14
+		},
+	},
+	{
+		ID:          "potty_mouth",
+		Name:        "Potty Mouth",
+		Emoji:       "🧼",
+		Description: "At least 5 swear words in commit messages",
+		Earned: func(s CommitStats) bool {
+			return s.SwearWords >= 5
+		},
+	},
+	{
+		ID:          "novelist",
+		Name:        "Novelist",
+		Emoji:       "📚",
+		Description: "Commit messages average more than 200 characters",
+		Earned: func(s CommitStats) bool {
+			return s.AvgMessageLength > 200
+		},
+	},
+	{
+		ID:          "wordsmith",
+		Name:        "Wordsmith",
+		Emoji:       "🖋️",
+		Description: "At least 50 words counted with ?words=true and no vague word among the top 10",
+		Earned: func(s CommitStats) bool {
+			return s.Words != nil && s.Words.TotalWords >= 50 && !s.Words.hasVagueTopWord()
+		},
+	},
+}
+
+// earnedAchievements returns the badges stats qualifies for.
+func earnedAchievements(stats CommitStats) []Achievement {
+	earned := []Achievement{}
+	if stats.TotalCommits == 0 {
+		return earned
+	}
+	for _, a := range achievements {
+		if a.Earned(stats) {
+			earned = append(earned, a)
+		}
+	}
+	return earned
+}
+
+// messageLengthAndGap returns the average message length in characters and
+// the longest stretch in whole days between consecutive commits.
+func messageLengthAndGap(commits []NormalizedCommit) (float64, int) {
+	if len(commits) == 0 {
+		return 0, 0
+	}
+	chars := 0
+	dates := make([]time.Time, 0, len(commits))
+	for _, commit := range commits {
+		chars += utf8.RuneCountInString(commit.Message)
+		dates = append(dates, commit.Date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var longest time.Duration
+	for i := 1; i < len(dates); i++ {
+		longest = max(longest, dates[i].Sub(dates[i-1]))
+	}
+	return float64(chars) / float64(len(commits)), int(longest.Hours() / 24)
+}
+
+// handleAchievements serves GET /achievements, the full badge catalog.
+func handleAchievements(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"achievements": achievements})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAchievementBoundaries(t *testing.T) {
+	words := func(total int, top ...string) *WordStats {
+		w := &WordStats{TotalWords: total}
+		for _, word := range top {
+			w.Top = append(w.Top, WordCount{Word: word, Count: 1})
+		}
+		return w
+	}
+	tests := []struct {
+		id    string
+		stats CommitStats
+		want  bool
+	}{
+		{"night_owl", CommitStats{TotalCommits: 10, LateNightCommits: 6}, false},
+		{"night_owl", CommitStats{TotalCommits: 10, LateNightCommits: 7}, true},
+		{"merge_lord", CommitStats{TotalCommits: 10, MergeCommits: 5}, false},
+		{"merge_lord", CommitStats{TotalCommits: 10, MergeCommits: 6}, true},
+		{"commitment_issues", CommitStats{TotalCommits: 2, LongestGapDays: 14}, false},
+		{"commitment_issues", CommitStats{TotalCommits: 2, LongestGapDays: 15}, true},
+		{"potty_mouth", CommitStats{TotalCommits: 10, SwearWords: 4}, false},
+		{"potty_mouth", CommitStats{TotalCommits: 10, SwearWords: 5}, true},
+		{"novelist", CommitStats{TotalCommits: 1, AvgMessageLength: 200}, false},
+		{"novelist", CommitStats{TotalCommits: 1, AvgMessageLength: 200.5}, true},
+		{"wordsmith", CommitStats{TotalCommits: 10}, false},
+		{"wordsmith", CommitStats{TotalCommits: 10, Words: words(49, "refactor")}, false},
+		{"wordsmith", CommitStats{TotalCommits: 10, Words: words(50, "refactor", "stuff")}, false},
+		{"wordsmith", CommitStats{TotalCommits: 10, Words: words(50, "refactor", "parser")}, true},
+	}
+	for _, tt := range tests {
+		earned := false
+		for _, a := range earnedAchievements(tt.stats) {
+			earned = earned || a.ID == tt.id
+		}
+		if earned != tt.want {
+			t.Errorf("%s earned = %v for %+v, want %v", tt.id, earned, tt.stats, tt.want)
+		}
+	}
+}
+
+func TestNoAchievementsWithoutCommits(t *testing.T) {
+	got := earnedAchievements(CommitStats{LongestGapDays: 30, SwearWords: 10})
+	if got == nil || len(got) != 0 {
+		t.Errorf("earnedAchievements() = %v, want an empty list", got)
+	}
+}
+
+func TestMessageLengthAndGap(t *testing.T) {
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	commits := []NormalizedCommit{
+		{Message: "fix", Date: day.AddDate(0, 0, 20)},
+		{Message: "añadir", Date: day},
+		{Message: "add tests", Date: day.AddDate(0, 0, 5).Add(-time.Hour)},
+	}
+	avg, gap := messageLengthAndGap(commits)
+	if avg != 6 || gap != 15 {
+		t.Errorf("messageLengthAndGap() = %v, %d; want 6, 15", avg, gap)
+	}
+	if avg, gap := messageLengthAndGap(nil); avg != 0 || gap != 0 {
+		t.Errorf("messageLengthAndGap(nil) = %v, %d", avg, gap)
+	}
+}
+
+func TestHandleAchievements(t *testing.T) {
+	w := doRequest(handleAchievements, http.MethodGet, "/achievements", "/achievements", "")
+	var body struct {
+		Achievements []map[string]string `json:"achievements"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Achievements) != len(achievements) {
+		t.Fatalf("listed %d badges, want %d", len(body.Achievements), len(achievements))
+	}
+	for _, a := range body.Achievements {
+		for _, field := range []string{"id", "name", "emoji", "description"} {
+			if a[field] == "" {
+				t.Errorf("badge %q has no %s", a["id"], field)
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "time"
+
+// dayActivity counts commits per calendar day and splits them into weekday
+// and weekend commits.
+func dayActivity(commits []NormalizedCommit) (perActiveDay float64, weekday, weekend int) {
+	days := make(map[string]bool)
+	for _, commit := range commits {
+		days[commit.Date.Format(time.DateOnly)] = true
+		switch commit.Date.Weekday() {
+		case time.Saturday, time.Sunday:
+			weekend++
+		default:
+			weekday++
+		}
+	}
+	if len(days) > 0 {
+		perActiveDay = float64(len(commits)) / float64(len(days))
+	}
+	return perActiveDay, weekday, weekend
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// commitsAt returns one commit at each offset from start.
+func commitsAt(start time.Time, offsets ...time.Duration) []NormalizedCommit {
+	commits := make([]NormalizedCommit, len(offsets))
+	for i, offset := range offsets {
+		commits[i] = NormalizedCommit{Message: "change", Date: start.Add(offset)}
+	}
+	return commits
+}
+
+func TestDayActivity(t *testing.T) {
+	monday := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	saturday := monday.AddDate(0, 0, 5)
+	day := 24 * time.Hour
+	tests := []struct {
+		name         string
+		commits      []NormalizedCommit
+		perActiveDay float64
+		weekday      int
+		weekend      int
+	}{
+		{"no commits", nil, 0, 0, 0},
+		{"weekdays", commitsAt(monday, 0, time.Hour, day, 4*day), 4.0 / 3, 4, 0},
+		{"weekends", commitsAt(saturday, 0, time.Hour, 2*time.Hour, day), 2, 0, 4},
+		// Sunday 23:59 and Monday 00:00 fall either side of the week
+		{"midnight boundary", commitsAt(monday, -9*time.Hour-time.Minute, -9*time.Hour), 1, 1, 1},
+		{"nonstop", commitsAt(monday, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11), 12, 12, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perDay, weekday, weekend := dayActivity(tt.commits)
+			if perDay != tt.perActiveDay || weekday != tt.weekday || weekend != tt.weekend {
+				t.Errorf("dayActivity() = %v, %d, %d; want %v, %d, %d",
+					perDay, weekday, weekend, tt.perActiveDay, tt.weekday, tt.weekend)
+			}
+		})
+	}
+}
+
+func TestDayActivityRoasts(t *testing.T) {
+	monday := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	saturday := monday.AddDate(0, 0, 5)
+	tests := []struct {
+		name     string
+		commits  []NormalizedCommit
+		weekends bool
+		nonstop  bool
+	}{
+		{"weekday regular", commitsAt(monday, 0, 24*time.Hour, 48*time.Hour), false, false},
+		{"weekends only", commitsAt(saturday, 0, time.Hour, 24*time.Hour), true, false},
+		{"too few to tell", commitsAt(saturday, 0, time.Hour), false, false},
+		{"log off", commitsAt(monday, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := analyzeCommits(tt.commits, loadRoastConfig())
+			if got := findRule(t, "weekends_only").Triggered(stats); got != tt.weekends {
+				t.Errorf("weekends_only triggered = %v, want %v", got, tt.weekends)
+			}
+			if got := findRule(t, "nonstop").Triggered(stats); got != tt.nonstop {
+				t.Errorf("nonstop triggered = %v, want %v", got, tt.nonstop)
+			}
+		})
+	}
+}
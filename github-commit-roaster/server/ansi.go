@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// terminalWidth is the column count the ANSI rendering is wrapped to.
+const terminalWidth = 80
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiGray   = "\x1b[90m"
+)
+
+var flameArt = []string{
+	`      (  .      )`,
+	`   )           (              )`,
+	`         .  '   .   '  .  '  .`,
+	`  (    , )       (.   )  (   ',    )`,
+	`   .' ) ( . )    ,  ( ,     )   ( .`,
+	`  ). , ( .   (  ) ( , ')  .' (  ,    )`,
+}
+
+// isTerminalClient reports whether a User-Agent is a command-line HTTP
+// client, which gets the ANSI rendering by default.
+func isTerminalClient(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	return strings.HasPrefix(userAgent, "curl/") || strings.HasPrefix(userAgent, "wget/")
+}
+
+// painter wraps text in ANSI codes, or leaves it alone when color is off.
+type painter bool
+
+func (p painter) paint(code, text string) string {
+	if !p || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// renderTerminal draws a roast in a box for a terminal: a flame header, the
+// roast lines in red (the most damning one bold) and the headline stats in
+// gray. Without color it is the same drawing in plain text.
+func renderTerminal(response RoastResponse, color bool) string {
+	p := painter(color)
+	inner := terminalWidth - 4
+	var b strings.Builder
+
+	for _, line := range flameArt {
+		b.WriteString(p.paint(ansiYellow, line) + "\n")
+	}
+	boxLine := func(text, style string) {
+		pad := max(inner-runewidth.StringWidth(text), 0)
+		b.WriteString("│ " + p.paint(style, text) + strings.Repeat(" ", pad) + " │\n")
+	}
+
+	b.WriteString("╭" + strings.Repeat("─", terminalWidth-2) + "╮\n")
+	boxLine(fmt.Sprintf("🔥 ROAST OF @%s 🔥", response.Username), ansiBold+ansiYellow)
+	b.WriteString("├" + strings.Repeat("─", terminalWidth-2) + "┤\n")
+	for i, paragraph := range strings.Split(response.Roast, "\n\n") {
+		style := ansiRed
+		if i == 0 {
+			style = ansiBold + ansiRed
+		} else {
+			boxLine("", "")
+		}
+		for _, line := range wrapText(paragraph, inner) {
+			boxLine(line, style)
+		}
+	}
+	b.WriteString("╰" + strings.Repeat("─", terminalWidth-2) + "╯\n")
+
+	s := response.Stats
+	summary := fmt.Sprintf("commits: %d · repos: %d · late-night: %d · fixes: %d · merges: %d · severity: %d/100",
+		s.TotalCommits, s.ReposAnalyzed, s.LateNightCommits, s.FixCommits, s.MergeCommits, s.Severity)
+	for _, line := range wrapText(summary, terminalWidth) {
+		b.WriteString(p.paint(ansiGray, line) + "\n")
+	}
+	return b.String()
+}
+
+// wrapText word-wraps text to width display columns, measuring wide runes
+// such as emoji as two columns. Words longer than a line are split.
+func wrapText(text string, width int) []string {
+	var lines []string
+	line, lineWidth := "", 0
+	for _, word := range strings.Fields(text) {
+		wordWidth := runewidth.StringWidth(word)
+		for wordWidth > width {
+			if line != "" {
+				lines = append(lines, line)
+				line, lineWidth = "", 0
+			}
+			head := runewidth.Truncate(word, width, "")
+			lines = append(lines, head)
+			word = word[len(head):]
+			wordWidth = runewidth.StringWidth(word)
+		}
+		switch {
+		case line == "":
+			line, lineWidth = word, wordWidth
+		case lineWidth+1+wordWidth <= width:
+			line += " " + word
+			lineWidth += 1 + wordWidth
+		default:
+			lines = append(lines, line)
+			line, lineWidth = word, wordWidth
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with testdata/name, or rewrites it under -update.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s doesn't match the golden file (run with -update if the change is intended)\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// terminalRoast is a roast with a wide-rune username, a long first line and
+// a second paragraph, to exercise wrapping and padding.
+func terminalRoast() RoastResponse {
+	return RoastResponse{
+		Username: "octo🐙cat",
+		Roast: "Over 50% of your commits are late at night, between 22:00 and 05:00. Do you even sleep? " +
+			"Your commit log is a crime scene and git blame is the only witness.\n\n" +
+			"50% of your commits are fixes 🔥🔥. Maybe test before pushing?",
+		Stats: CommitStats{TotalCommits: 42, ReposAnalyzed: 3, LateNightCommits: 22, FixCommits: 21, MergeCommits: 2, Severity: 67},
+	}
+}
+
+func TestRenderTerminal(t *testing.T) {
+	tests := []struct {
+		golden string
+		color  bool
+	}{
+		{"roast.ansi.golden", true},
+		{"roast.text.golden", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			got := renderTerminal(terminalRoast(), tt.color)
+			golden(t, tt.golden, got)
+			if strings.Contains(got, "\x1b[") != tt.color {
+				t.Errorf("escape codes present = %v, want %v", !tt.color, tt.color)
+			}
+		})
+	}
+}
+
+func TestRenderTerminalAlignment(t *testing.T) {
+	plain := renderTerminal(terminalRoast(), false)
+	for _, line := range strings.Split(plain, "\n") {
+		if !strings.HasPrefix(line, "│") && !strings.HasPrefix(line, "╭") && !strings.HasPrefix(line, "╰") && !strings.HasPrefix(line, "├") {
+			continue
+		}
+		if w := runewidth.StringWidth(line); w != terminalWidth {
+			t.Errorf("box line is %d columns, want %d: %q", w, terminalWidth, line)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"empty", "", 10, []string{""}},
+		{"fits", "fix the build", 20, []string{"fix the build"}},
+		{"wraps at words", "fix the build again", 10, []string{"fix the", "build", "again"}},
+		{"emoji take two columns", "🔥🔥🔥 hot", 7, []string{"🔥🔥🔥", "hot"}},
+		{"long words are split", "abcdefghijkl", 5, []string{"abcde", "fghij", "kl"}},
+		{"wide runes aren't split in half", "🔥🔥🔥", 5, []string{"🔥🔥", "🔥"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.text, tt.width); !slices.Equal(got, tt.want) {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
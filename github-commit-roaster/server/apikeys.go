@@ -0,0 +1,352 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// anonymousKey is the shared usage bucket for callers without an API key.
+const anonymousKey = "anonymous"
+
+// quotaContextKey is where middleware leaves the caller's quotaCaller for
+// handlers that charge more than one request.
+const quotaContextKey = "quota"
+
+// APIKey identifies a third-party consumer and its limits.
+type APIKey struct {
+	Key           string    `json:"key"`
+	Name          string    `json:"name"`
+	DailyQuota    int       `json:"daily_quota"`
+	RatePerMinute int       `json:"rate_per_minute"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// quotaStatus is a caller's position against its daily quota.
+type quotaStatus struct {
+	Used      int
+	Remaining int
+	ResetAt   time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// quotaTracker enforces per-key daily quotas and per-minute rate limits.
+// Daily counters live in the Store when one is configured so they survive
+// restarts; rate windows are always in memory.
+type quotaTracker struct {
+	store *Store
+	anon  APIKey
+	now   func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]APIKey
+	daily   map[string]dailyCount // by key, without a store
+	windows map[string]rateWindow
+}
+
+// dailyCount is a key's request count for one day.
+type dailyCount struct {
+	day time.Time
+	n   int
+}
+
+func newQuotaTracker(store *Store, anon APIKey) (*quotaTracker, error) {
+	q := &quotaTracker{
+		store:   store,
+		anon:    anon,
+		now:     time.Now,
+		keys:    make(map[string]APIKey),
+		daily:   make(map[string]dailyCount),
+		windows: make(map[string]rateWindow),
+	}
+	if store != nil {
+		keys, err := store.LoadAPIKeys()
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			q.keys[k.Key] = k
+		}
+	}
+	return q, nil
+}
+
+// identify resolves the caller's key from X-API-Key. A missing header means
+// the anonymous bucket; an unknown key is rejected.
+func (q *quotaTracker) identify(c *gin.Context) (APIKey, bool) {
+	key := c.GetHeader("X-API-Key")
+	if key == "" {
+		return q.anon, true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	k, ok := q.keys[key]
+	return k, ok
+}
+
+func (q *quotaTracker) createKey(name string, dailyQuota, ratePerMinute int) (APIKey, error) {
+	secret, err := randomID()
+	if err != nil {
+		return APIKey{}, err
+	}
+	k := APIKey{
+		Key:           "rk_" + secret,
+		Name:          name,
+		DailyQuota:    dailyQuota,
+		RatePerMinute: ratePerMinute,
+		CreatedAt:     q.now().UTC(),
+	}
+	if q.store != nil {
+		if err := q.store.SaveAPIKey(k); err != nil {
+			return APIKey{}, err
+		}
+	}
+
+	q.mu.Lock()
+	q.keys[k.Key] = k
+	q.mu.Unlock()
+	return k, nil
+}
+
+func (q *quotaTracker) deleteKey(key string) (bool, error) {
+	q.mu.Lock()
+	_, ok := q.keys[key]
+	delete(q.keys, key)
+	q.mu.Unlock()
+
+	if ok && q.store != nil {
+		return true, q.store.DeleteAPIKey(key)
+	}
+	return ok, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// usage reports k's standing for today without counting a request.
+func (q *quotaTracker) usage(k APIKey) (quotaStatus, error) {
+	day := startOfDay(q.now())
+	var used int
+	if q.store != nil {
+		var err error
+		if used, err = q.store.Usage(k.Key, day); err != nil {
+			return quotaStatus{}, err
+		}
+	} else {
+		q.mu.Lock()
+		if count := q.daily[k.Key]; count.day.Equal(day) {
+			used = count.n
+		}
+		q.mu.Unlock()
+	}
+	return q.status(k, used, day), nil
+}
+
+func (q *quotaTracker) status(k APIKey, used int, day time.Time) quotaStatus {
+	return quotaStatus{
+		Used:      used,
+		Remaining: max(k.DailyQuota-used, 0),
+		ResetAt:   day.AddDate(0, 0, 1),
+	}
+}
+
+// allowRate applies the per-minute limit. It returns when the current window
+// resets if the caller is over the limit.
+func (q *quotaTracker) allowRate(k APIKey) (time.Time, bool) {
+	now := q.now()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	w := q.windows[k.Key]
+	if now.Sub(w.start) >= time.Minute {
+		w = rateWindow{start: now}
+	}
+	if w.count >= k.RatePerMinute {
+		return w.start.Add(time.Minute), false
+	}
+	w.count++
+	q.windows[k.Key] = w
+	return time.Time{}, true
+}
+
+// consume counts n requests against k's daily quota.
+func (q *quotaTracker) consume(k APIKey, n int) (quotaStatus, bool, error) {
+	day := startOfDay(q.now())
+	var used int
+	if q.store != nil {
+		var err error
+		if used, err = q.store.IncrementUsage(k.Key, day, n); err != nil {
+			return quotaStatus{}, false, err
+		}
+	} else {
+		q.mu.Lock()
+		count := q.daily[k.Key]
+		if !count.day.Equal(day) {
+			// A new day; yesterday's count is dropped rather than kept
+			count = dailyCount{day: day}
+		}
+		count.n += n
+		q.daily[k.Key] = count
+		used = count.n
+		q.mu.Unlock()
+	}
+	return q.status(k, used, day), used <= k.DailyQuota, nil
+}
+
+// middleware rejects unknown keys and callers over their rate limit or
+// daily quota with 429 and the time the limit resets.
+func (q *quotaTracker) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k, ok := q.identify(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+			return
+		}
+
+		if resetAt, ok := q.allowRate(k); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(resetAt).Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":      "API rate limit exceeded",
+				"reset_time": resetAt.Format(time.RFC1123),
+			})
+			return
+		}
+
+		caller := quotaCaller{tracker: q, key: k}
+		if !caller.charge(c, 1) {
+			return
+		}
+		c.Set(quotaContextKey, caller)
+		c.Next()
+	}
+}
+
+// quotaCaller is a caller identified by the quota middleware.
+type quotaCaller struct {
+	tracker *quotaTracker
+	key     APIKey
+}
+
+// charge counts n requests against the caller's daily quota. Over the quota
+// it aborts with 429 and returns false.
+func (qc quotaCaller) charge(c *gin.Context, n int) bool {
+	status, ok, err := qc.tracker.consume(qc.key, n)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "could not record usage"})
+		return false
+	}
+	c.Header("X-Quota-Limit", strconv.Itoa(qc.key.DailyQuota))
+	c.Header("X-Quota-Remaining", strconv.Itoa(status.Remaining))
+	if !ok {
+		c.Header("Retry-After", strconv.Itoa(int(time.Until(status.ResetAt).Seconds())+1))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error":      "daily quota exceeded",
+			"reset_time": status.ResetAt.Format(time.RFC1123),
+		})
+		return false
+	}
+	return true
+}
+
+// chargeMembers makes a request naming several users cost one request per
+// user: the middleware counted one, the rest are charged here. It returns
+// false once it has answered 429. Routes without the quota middleware are
+// free.
+func chargeMembers(c *gin.Context, members int) bool {
+	value, ok := c.Get(quotaContextKey)
+	if !ok || members <= 1 {
+		return true
+	}
+	return value.(quotaCaller).charge(c, members-1)
+}
+
+// handleUsage serves GET /v1/usage for the calling key.
+func (q *quotaTracker) handleUsage(c *gin.Context) {
+	k, ok := q.identify(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+		return
+	}
+	status, err := q.usage(k)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not read usage"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"key_name":        k.Name,
+		"daily_quota":     k.DailyQuota,
+		"rate_per_minute": k.RatePerMinute,
+		"used":            status.Used,
+		"remaining":       status.Remaining,
+		"reset_time":      status.ResetAt.Format(time.RFC1123),
+	})
+}
+
+// adminAuth guards admin routes with a static bearer token. Admin routes are
+// disabled when no token is configured.
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "admin API is not configured"})
+			return
+		}
+		given := c.GetHeader("Authorization")
+		if subtle.ConstantTimeCompare([]byte(given), []byte("Bearer "+token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// registerAdminRoutes mounts API key management under /admin.
+func registerAdminRoutes(r *gin.Engine, quotas *quotaTracker, token string, defaults APIKey) {
+	admin := r.Group("/admin", adminAuth(token))
+
+	admin.POST("/keys", func(c *gin.Context) {
+		req := struct {
+			Name          string `json:"name"`
+			DailyQuota    int    `json:"daily_quota"`
+			RatePerMinute int    `json:"rate_per_minute"`
+		}{DailyQuota: defaults.DailyQuota, RatePerMinute: defaults.RatePerMinute}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+			return
+		}
+		if req.DailyQuota <= 0 || req.RatePerMinute <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "daily_quota and rate_per_minute must be positive"})
+			return
+		}
+
+		k, err := quotas.createKey(req.Name, req.DailyQuota, req.RatePerMinute)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("could not create key: %v", err)})
+			return
+		}
+		c.JSON(http.StatusCreated, k)
+	})
+
+	admin.DELETE("/keys/:key", func(c *gin.Context) {
+		found, err := quotas.deleteKey(c.Param("key"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete key"})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestQuotas returns a quota tracker on a fixed clock, backed by store
+// when it isn't nil.
+func newTestQuotas(t *testing.T, store *Store, anon APIKey) *quotaTracker {
+	t.Helper()
+	q, err := newQuotaTracker(store, anon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.now = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return q
+}
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "roaster.db")
+	store, err := openStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, path
+}
+
+// quotaRouter serves stub roast and batch routes behind q's middleware.
+func quotaRouter(q *quotaTracker) *gin.Engine {
+	r := gin.New()
+	r.GET("/roast", q.middleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/roast/batch", q.middleware(), func(c *gin.Context) {
+		if chargeMembers(c, 10) {
+			c.Status(http.StatusOK)
+		}
+	})
+	r.GET("/v1/usage", q.handleUsage)
+	return r
+}
+
+// withKey sends a request carrying key as the X-API-Key header.
+func withKey(r http.Handler, method, target, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestQuotaMiddleware(t *testing.T) {
+	anon := APIKey{Key: anonymousKey, Name: anonymousKey, DailyQuota: 3, RatePerMinute: 100}
+	tests := []struct {
+		name     string
+		key      func(q *quotaTracker) string
+		statuses []int
+	}{
+		{
+			"anonymous daily quota",
+			func(*quotaTracker) string { return "" },
+			[]int{200, 200, 200, 429},
+		},
+		{
+			"key rate limit",
+			func(q *quotaTracker) string {
+				k, _ := q.createKey("bot", 100, 2)
+				return k.Key
+			},
+			[]int{200, 200, 429},
+		},
+		{
+			"unknown key",
+			func(*quotaTracker) string { return "rk_nope" },
+			[]int{401},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQuotas(t, nil, anon)
+			r := quotaRouter(q)
+			key := tt.key(q)
+			for i, want := range tt.statuses {
+				w := withKey(r, http.MethodGet, "/roast", key)
+				if w.Code != want {
+					t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, want)
+				}
+				if want == http.StatusTooManyRequests {
+					var body map[string]string
+					json.Unmarshal(w.Body.Bytes(), &body)
+					if body["reset_time"] == "" || w.Header().Get("Retry-After") == "" {
+						t.Errorf("429 without a reset time: %s", w.Body)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestQuotaChargesBatchMembers(t *testing.T) {
+	tests := []struct {
+		name   string
+		quota  int
+		status int
+		used   int
+	}{
+		{"room for the whole batch", 10, http.StatusOK, 10},
+		{"one short", 9, http.StatusTooManyRequests, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQuotas(t, nil, APIKey{Key: anonymousKey, DailyQuota: tt.quota, RatePerMinute: 100})
+			w := withKey(quotaRouter(q), http.MethodPost, "/roast/batch", "")
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			status, _ := q.usage(q.anon)
+			if status.Used != tt.used {
+				t.Errorf("used %d, want %d", status.Used, tt.used)
+			}
+		})
+	}
+}
+
+func TestQuotaNewDayInMemory(t *testing.T) {
+	q := newTestQuotas(t, nil, APIKey{Key: anonymousKey, DailyQuota: 2, RatePerMinute: 100})
+	now := q.now()
+	q.now = func() time.Time { return now }
+	for range 3 {
+		q.consume(q.anon, 1)
+	}
+	if status, _ := q.usage(q.anon); status.Used != 3 || status.Remaining != 0 {
+		t.Fatalf("usage = %+v, want 3 used", status)
+	}
+
+	for day := 1; day <= 5; day++ {
+		now = now.AddDate(0, 0, 1)
+		status, ok, _ := q.consume(q.anon, 1)
+		if !ok || status.Used != 1 {
+			t.Errorf("day %d: usage = %+v (allowed %v), want a fresh quota", day, status, ok)
+		}
+	}
+	if len(q.daily) != 1 {
+		t.Errorf("%d daily counters kept for one key, want 1", len(q.daily))
+	}
+}
+
+func TestQuotaSurvivesRestart(t *testing.T) {
+	store, path := openTestStore(t)
+	anon := APIKey{Key: anonymousKey, DailyQuota: 100, RatePerMinute: 100}
+	q := newTestQuotas(t, store, anon)
+	k, err := q.createKey("bot", 5, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := quotaRouter(q)
+	for range 3 {
+		withKey(r, http.MethodGet, "/roast", k.Key)
+	}
+	store.Close()
+
+	reopened, err := openStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	q = newTestQuotas(t, reopened, anon)
+	w := withKey(quotaRouter(q), http.MethodGet, "/v1/usage", k.Key)
+	if w.Code != http.StatusOK {
+		t.Fatalf("usage status = %d: %s", w.Code, w.Body)
+	}
+	var usage struct {
+		KeyName   string `json:"key_name"`
+		Used      int    `json:"used"`
+		Remaining int    `json:"remaining"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &usage); err != nil {
+		t.Fatal(err)
+	}
+	if usage.KeyName != "bot" || usage.Used != 3 || usage.Remaining != 2 {
+		t.Errorf("usage after restart = %+v, want bot with 3 used and 2 left", usage)
+	}
+}
+
+func TestAdminKeyRoutes(t *testing.T) {
+	q := newTestQuotas(t, nil, APIKey{Key: anonymousKey})
+	r := gin.New()
+	registerAdminRoutes(r, q, "admin-token", APIKey{DailyQuota: 1000, RatePerMinute: 60})
+	admin := func(method, target, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := admin(http.MethodPost, "/admin/keys", `{"name":"bot"}`, "wrong"); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d", w.Code)
+	}
+	if w := admin(http.MethodPost, "/admin/keys", `{"name":""}`, "admin-token"); w.Code != http.StatusBadRequest {
+		t.Errorf("no name: status = %d", w.Code)
+	}
+
+	w := admin(http.MethodPost, "/admin/keys", `{"name":"bot"}`, "admin-token")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d: %s", w.Code, w.Body)
+	}
+	var k APIKey
+	json.Unmarshal(w.Body.Bytes(), &k)
+	if !strings.HasPrefix(k.Key, "rk_") || k.DailyQuota != 1000 || k.RatePerMinute != 60 {
+		t.Errorf("created key = %+v, want the defaults", k)
+	}
+
+	if w := admin(http.MethodDelete, "/admin/keys/"+k.Key, "", "admin-token"); w.Code != http.StatusNoContent && w.Code != http.StatusOK {
+		t.Errorf("delete: status = %d", w.Code)
+	}
+	if _, ok := q.keys[k.Key]; ok {
+		t.Error("key still known after delete")
+	}
+	if w := admin(http.MethodDelete, "/admin/keys/"+k.Key, "", "admin-token"); w.Code != http.StatusNotFound {
+		t.Errorf("second delete: status = %d", w.Code)
+	}
+}
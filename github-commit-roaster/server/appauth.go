@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// GitHub rejects app JWTs living longer than 10 minutes
+	appJWTLifetime = 9 * time.Minute
+	// Backdate iat so a server clock slightly ahead of ours still accepts it
+	appJWTBackdate = 60 * time.Second
+	// Refresh installation tokens this long before GitHub expires them
+	appTokenRefreshMargin = 5 * time.Minute
+)
+
+// appTokenSource mints GitHub App installation tokens and caches them until
+// shortly before they expire. It is safe for concurrent use.
+type appTokenSource struct {
+	appID          string
+	installationID string
+	key            *rsa.PrivateKey
+	baseURL        string
+	httpClient     *http.Client
+	now            func() time.Time
+
+	mu    sync.Mutex
+	token *oauth2.Token
+	skew  time.Duration // GitHub's clock minus ours, learned from the Date header
+}
+
+func newAppTokenSource(appID, installationID string, keyPEM []byte) (*appTokenSource, error) {
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &appTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		baseURL:        "https://api.github.com/",
+		httpClient:     &http.Client{Timeout: 10 * time.Second, Transport: newGitHubTransport()},
+		now:            time.Now,
+	}, nil
+}
+
+// loadAppTokenSource sets up GitHub App auth from cfg. It returns nil
+// without error when the app isn't configured.
+func loadAppTokenSource(cfg GitHubAppConfig) (*appTokenSource, error) {
+	appID, installationID, keyPath := cfg.AppID, cfg.InstallationID, cfg.PrivateKeyPath
+	if appID == "" && installationID == "" && keyPath == "" {
+		return nil, nil
+	}
+	if appID == "" || installationID == "" || keyPath == "" {
+		return nil, errors.New("GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID and GITHUB_APP_PRIVATE_KEY_PATH must all be set")
+	}
+	if _, err := strconv.ParseInt(appID, 10, 64); err != nil {
+		return nil, fmt.Errorf("GITHUB_APP_ID must be numeric, got %q", appID)
+	}
+
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return newAppTokenSource(appID, installationID, keyPEM)
+}
+
+// Token returns a cached installation token, minting a new one when the
+// cached token is close to expiry.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().Add(s.skew)
+	if s.token != nil && now.Add(appTokenRefreshMargin).Before(s.token.Expiry) {
+		return s.token, nil
+	}
+
+	token, err := s.mint(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+func (s *appTokenSource) mint(ctx context.Context) (*oauth2.Token, error) {
+	jwt, err := s.signJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%sapp/installations/%s/access_tokens", s.baseURL, s.installationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Keep our idea of "now" in line with GitHub's for the next JWT and expiry checks
+	if serverTime, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		s.skew = serverTime.Sub(s.now())
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("minting installation token: GitHub returned %s", resp.Status)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: body.Token, TokenType: "token", Expiry: body.ExpiresAt}, nil
+}
+
+// signJWT builds the RS256 JWT that authenticates as the app itself.
+func (s *appTokenSource) signJWT() (string, error) {
+	now := s.now().Add(s.skew)
+	header := `{"alg":"RS256","typ":"JWT"}`
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appJWTBackdate).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// parsePrivateKey accepts the PKCS#1 keys GitHub hands out as well as PKCS#8.
+func parsePrivateKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(bytes.TrimSpace(keyPEM))
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
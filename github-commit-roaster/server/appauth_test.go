@@ -0,0 +1,246 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTokenEndpoint stands in for GitHub's installation token endpoint,
+// checking each JWT and handing out hour-long tokens on a clock offset
+// from ours by skew.
+type fakeTokenEndpoint struct {
+	t    *testing.T
+	key  *rsa.PublicKey
+	now  func() time.Time // our clock
+	skew time.Duration
+
+	mu     sync.Mutex
+	minted int
+	claims []map[string]any
+}
+
+func (e *fakeTokenEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+		http.NotFound(w, r)
+		return
+	}
+	claims, err := verifyJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), e.key)
+	if err != nil {
+		e.t.Errorf("bad app JWT: %v", err)
+		http.Error(w, `{"message":"A JSON web token could not be decoded"}`, http.StatusUnauthorized)
+		return
+	}
+
+	serverNow := e.now().Add(e.skew)
+	e.mu.Lock()
+	e.minted++
+	e.claims = append(e.claims, claims)
+	n := e.minted
+	e.mu.Unlock()
+
+	w.Header().Set("Date", serverNow.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"token":"ghs_%d","expires_at":%q}`, n, serverNow.Add(time.Hour).UTC().Format(time.RFC3339))
+}
+
+func verifyJWT(jwt string, key *rsa.PublicKey) (map[string]any, error) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%d parts", len(parts))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]any
+	return claims, json.Unmarshal(payload, &claims)
+}
+
+// testAppKey is generated once; RSA key generation is slow.
+var testAppKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+// newTestAppSource returns an app token source minting from a fake
+// endpoint whose clock runs skew ahead of the source's. Moving the returned
+// time moves both clocks.
+func newTestAppSource(t *testing.T, skew time.Duration) (*appTokenSource, *fakeTokenEndpoint, *time.Time) {
+	t.Helper()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	endpoint := &fakeTokenEndpoint{t: t, key: &testAppKey().PublicKey, now: clock, skew: skew}
+	api := httptest.NewServer(endpoint)
+	t.Cleanup(api.Close)
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(testAppKey())})
+	src, err := newAppTokenSource("1234", "42", keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.baseURL = api.URL + "/"
+	src.httpClient = api.Client()
+	src.now = clock
+	return src, endpoint, &now
+}
+
+func TestAppTokenRefresh(t *testing.T) {
+	tests := []struct {
+		name    string
+		advance time.Duration
+		minted  int
+	}{
+		{"fresh token is reused", 10 * time.Minute, 1},
+		{"just outside the margin", time.Hour - appTokenRefreshMargin - time.Second, 1},
+		{"inside the margin", time.Hour - appTokenRefreshMargin + time.Second, 2},
+		{"expired", 2 * time.Hour, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, endpoint, now := newTestAppSource(t, 0)
+			first, err := src.Token()
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			*now = now.Add(tt.advance)
+			second, err := src.Token()
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if endpoint.minted != tt.minted {
+				t.Errorf("minted %d tokens, want %d", endpoint.minted, tt.minted)
+			}
+			if reused := first.AccessToken == second.AccessToken; reused != (tt.minted == 1) {
+				t.Errorf("tokens %q then %q", first.AccessToken, second.AccessToken)
+			}
+		})
+	}
+}
+
+func TestAppTokenClockSkew(t *testing.T) {
+	tests := []struct {
+		name string
+		skew time.Duration
+	}{
+		{"github ahead", 30 * time.Minute},
+		{"github behind", -30 * time.Minute},
+		{"in sync", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, endpoint, now := newTestAppSource(t, tt.skew)
+			if _, err := src.Token(); err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if src.skew != tt.skew {
+				t.Errorf("learned skew %s, want %s", src.skew, tt.skew)
+			}
+
+			// The token expires an hour after GitHub's now, so it has to be
+			// refreshed by GitHub's clock rather than ours
+			*now = now.Add(time.Hour - appTokenRefreshMargin + time.Second)
+			if _, err := src.Token(); err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if endpoint.minted != 2 {
+				t.Fatalf("minted %d tokens, want a refresh", endpoint.minted)
+			}
+
+			// The second JWT was issued on GitHub's clock
+			iat := time.Unix(int64(endpoint.claims[1]["iat"].(float64)), 0)
+			exp := time.Unix(int64(endpoint.claims[1]["exp"].(float64)), 0)
+			serverNow := now.Add(tt.skew)
+			if !iat.Equal(serverNow.Add(-appJWTBackdate)) || !exp.Equal(serverNow.Add(appJWTLifetime)) {
+				t.Errorf("iat %s exp %s, want around GitHub's %s", iat, exp, serverNow)
+			}
+			if exp.Sub(iat) > 10*time.Minute {
+				t.Errorf("JWT lives %s, GitHub allows at most 10m", exp.Sub(iat))
+			}
+			if endpoint.claims[1]["iss"] != "1234" {
+				t.Errorf("iss = %v", endpoint.claims[1]["iss"])
+			}
+		})
+	}
+}
+
+func TestAppTokenMintFailure(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	}))
+	defer api.Close()
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(testAppKey())})
+	src, err := newAppTokenSource("1234", "42", keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.baseURL = api.URL + "/"
+	if _, err := src.Token(); err == nil {
+		t.Error("Token() succeeded on a 401")
+	}
+}
+
+func TestLoadAppTokenSource(t *testing.T) {
+	dir := t.TempDir()
+	pkcs1 := filepath.Join(dir, "pkcs1.pem")
+	os.WriteFile(pkcs1, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(testAppKey())}), 0o600)
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(testAppKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8 := filepath.Join(dir, "pkcs8.pem")
+	os.WriteFile(pkcs8, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes}), 0o600)
+	garbage := filepath.Join(dir, "garbage.pem")
+	os.WriteFile(garbage, []byte("not a key"), 0o600)
+
+	tests := []struct {
+		name    string
+		cfg     GitHubAppConfig
+		wantSrc bool
+		wantErr bool
+	}{
+		{"not configured falls back", GitHubAppConfig{}, false, false},
+		{"pkcs1 key", GitHubAppConfig{AppID: "1234", InstallationID: "42", PrivateKeyPath: pkcs1}, true, false},
+		{"pkcs8 key", GitHubAppConfig{AppID: "1234", InstallationID: "42", PrivateKeyPath: pkcs8}, true, false},
+		{"missing installation", GitHubAppConfig{AppID: "1234", PrivateKeyPath: pkcs1}, false, true},
+		{"non-numeric app id", GitHubAppConfig{AppID: "my-app", InstallationID: "42", PrivateKeyPath: pkcs1}, false, true},
+		{"missing key file", GitHubAppConfig{AppID: "1234", InstallationID: "42", PrivateKeyPath: filepath.Join(dir, "nope.pem")}, false, true},
+		{"not pem", GitHubAppConfig{AppID: "1234", InstallationID: "42", PrivateKeyPath: garbage}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := loadAppTokenSource(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadAppTokenSource() error = %v, want error %v", err, tt.wantErr)
+			}
+			if (src != nil) != tt.wantSrc {
+				t.Errorf("loadAppTokenSource() = %v, want a source %v", src, tt.wantSrc)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v50/github"
+	"golang.org/x/oauth2"
+	githuboauth "golang.org/x/oauth2/github"
+)
+
+const (
+	sessionCookie = "roast_session"
+	stateCookie   = "roast_oauth_state"
+	sessionTTL    = 24 * time.Hour
+	// sweepSessionsEvery is how often sessions that expired without being
+	// used again are dropped
+	sweepSessionsEvery = time.Hour
+)
+
+// session is a logged-in GitHub user. The OAuth token is only ever held
+// encrypted; it is decrypted per request when the user roasts themselves.
+type session struct {
+	login     string
+	nonce     []byte
+	sealed    []byte
+	expiresAt time.Time
+}
+
+// oauthLogin implements the GitHub OAuth web flow and keeps the resulting
+// tokens server-side, keyed by a signed session cookie.
+type oauthLogin struct {
+	config      *oauth2.Config
+	redirectTo  string
+	signKey     []byte
+	aead        cipher.AEAD
+	now         func() time.Time
+	mu          sync.Mutex
+	sessions    map[string]session
+	revokeToken func(token string) error
+}
+
+// loadOAuthLogin sets up login from cfg. It returns nil when login isn't
+// configured.
+func loadOAuthLogin(cfg OAuthConfig) (*oauthLogin, error) {
+	if cfg.ClientID == "" && cfg.ClientSecret == "" {
+		return nil, nil
+	}
+	if cfg.ClientID == "" || cfg.ClientSecret == "" || len(cfg.SessionSecret) < 32 {
+		return nil, errors.New("GITHUB_OAUTH_CLIENT_ID, GITHUB_OAUTH_CLIENT_SECRET and a SESSION_SECRET of at least 32 characters are required")
+	}
+
+	redirectTo := cfg.SuccessRedirect
+	if redirectTo == "" {
+		redirectTo = "/"
+	}
+	return newOAuthLogin(&oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Endpoint:     githuboauth.Endpoint,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       []string{"repo"},
+	}, cfg.SessionSecret, redirectTo)
+}
+
+func newOAuthLogin(config *oauth2.Config, secret, redirectTo string) (*oauthLogin, error) {
+	// Separate keys for cookie signing and token encryption
+	signKey := sha256.Sum256([]byte("cookie-signing:" + secret))
+	encKey := sha256.Sum256([]byte("token-encryption:" + secret))
+
+	block, err := aes.NewCipher(encKey[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	login := &oauthLogin{
+		config:     config,
+		redirectTo: redirectTo,
+		signKey:    signKey[:],
+		aead:       aead,
+		now:        time.Now,
+		sessions:   make(map[string]session),
+	}
+	login.revokeToken = login.revokeWithGitHub
+	return login, nil
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// sign appends an HMAC to value so cookies can't be forged.
+func (o *oauthLogin) sign(value string) string {
+	mac := hmac.New(sha256.New, o.signKey)
+	mac.Write([]byte(value))
+	return value + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify returns the value of a signed cookie, or false if it was tampered with.
+func (o *oauthLogin) verify(signed string) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", false
+	}
+	value := signed[:i]
+	return value, hmac.Equal([]byte(o.sign(value)), []byte(signed))
+}
+
+func (o *oauthLogin) setCookie(c *gin.Context, name, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(name, value, maxAge, "/", "", c.Request.TLS != nil, true)
+}
+
+// handleLogin redirects to GitHub's consent page.
+func (o *oauthLogin) handleLogin(c *gin.Context) {
+	state, err := randomID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not start login"})
+		return
+	}
+	o.setCookie(c, stateCookie, o.sign(state), 600)
+	c.Redirect(http.StatusFound, o.config.AuthCodeURL(state))
+}
+
+// handleCallback exchanges the code for a token and starts a session.
+func (o *oauthLogin) handleCallback(c *gin.Context) {
+	signedState, _ := c.Cookie(stateCookie)
+	state, ok := o.verify(signedState)
+	if !ok || state == "" || c.Query("state") != state {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid OAuth state"})
+		return
+	}
+	o.setCookie(c, stateCookie, "", -1)
+
+	ctx := c.Request.Context()
+	token, err := o.config.Exchange(ctx, c.Query("code"))
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "could not complete GitHub login"})
+		return
+	}
+
+	// Bind the session to the GitHub login so the token is only used for that user
+	client := github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(token)))
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "could not look up GitHub user"})
+		return
+	}
+
+	id, err := o.createSession(user.GetLogin(), token.AccessToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create session"})
+		return
+	}
+	o.setCookie(c, sessionCookie, o.sign(id), int(sessionTTL.Seconds()))
+	c.Redirect(http.StatusFound, o.redirectTo)
+}
+
+// handleLogout drops the session and revokes the token with GitHub.
+func (o *oauthLogin) handleLogout(c *gin.Context) {
+	if id, ok := o.sessionID(c); ok {
+		if token, ok := o.removeSession(id); ok {
+			if err := o.revokeToken(token); err != nil {
+				slog.Warn("Could not revoke OAuth token", "error", err)
+			}
+		}
+	}
+	o.setCookie(c, sessionCookie, "", -1)
+	c.JSON(http.StatusOK, gin.H{"logged_out": true})
+}
+
+func (o *oauthLogin) revokeWithGitHub(token string) error {
+	tp := github.BasicAuthTransport{Username: o.config.ClientID, Password: o.config.ClientSecret}
+	_, err := github.NewClient(tp.Client()).Authorizations.Revoke(context.Background(), o.config.ClientID, token)
+	return err
+}
+
+func (o *oauthLogin) createSession(login, token string) (string, error) {
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, o.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sessions[id] = session{
+		login:     login,
+		nonce:     nonce,
+		sealed:    o.aead.Seal(nil, nonce, []byte(token), []byte(id)),
+		expiresAt: o.now().Add(sessionTTL),
+	}
+	return id, nil
+}
+
+func (o *oauthLogin) removeSession(id string) (string, bool) {
+	o.mu.Lock()
+	s, ok := o.sessions[id]
+	delete(o.sessions, id)
+	o.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	return o.open(id, s)
+}
+
+// run drops expired sessions every sweepSessionsEvery until ctx is done.
+func (o *oauthLogin) run(ctx context.Context) {
+	ticker := time.NewTicker(sweepSessionsEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.sweepExpired()
+		}
+	}
+}
+
+func (o *oauthLogin) sweepExpired() {
+	now := o.now()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for id, s := range o.sessions {
+		if now.After(s.expiresAt) {
+			delete(o.sessions, id)
+		}
+	}
+}
+
+func (o *oauthLogin) open(id string, s session) (string, bool) {
+	token, err := o.aead.Open(nil, s.nonce, s.sealed, []byte(id))
+	if err != nil {
+		return "", false
+	}
+	return string(token), true
+}
+
+func (o *oauthLogin) sessionID(c *gin.Context) (string, bool) {
+	signed, err := c.Cookie(sessionCookie)
+	if err != nil {
+		return "", false
+	}
+	return o.verify(signed)
+}
+
+// tokenFor returns the logged-in user's token, but only when they are
+// roasting their own username.
+func (o *oauthLogin) tokenFor(c *gin.Context, username string) (string, bool) {
+	if o == nil {
+		return "", false
+	}
+	id, ok := o.sessionID(c)
+	if !ok {
+		return "", false
+	}
+
+	o.mu.Lock()
+	s, ok := o.sessions[id]
+	if ok && o.now().After(s.expiresAt) {
+		delete(o.sessions, id)
+		ok = false
+	}
+	o.mu.Unlock()
+
+	if !ok || !strings.EqualFold(s.login, username) {
+		return "", false
+	}
+	return o.open(id, s)
+}
+
+// registerAuthRoutes mounts the login routes, or stubs explaining that
+// login is disabled.
+func registerAuthRoutes(r *gin.Engine, login *oauthLogin) {
+	if login == nil {
+		notConfigured := func(c *gin.Context) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "GitHub login is not configured"})
+		}
+		r.GET("/auth/github/login", notConfigured)
+		r.GET("/auth/github/callback", notConfigured)
+		r.POST("/auth/logout", notConfigured)
+		return
+	}
+	r.GET("/auth/github/login", login.handleLogin)
+	r.GET("/auth/github/callback", login.handleCallback)
+	r.POST("/auth/logout", login.handleLogout)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+const testSessionSecret = "0123456789abcdef0123456789abcdef"
+
+// newTestLogin returns a login whose clock is read through the returned
+// pointer.
+func newTestLogin(t *testing.T) (*oauthLogin, *time.Time) {
+	t.Helper()
+	login, err := newOAuthLogin(&oauth2.Config{ClientID: "id", ClientSecret: "secret"}, testSessionSecret, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	login.now = func() time.Time { return now }
+	return login, &now
+}
+
+// withSession returns a context for a request carrying cookie as the
+// session cookie.
+func withSession(cookie string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/roast", nil)
+	if cookie != "" {
+		c.Request.AddCookie(&http.Cookie{Name: sessionCookie, Value: cookie})
+	}
+	return c
+}
+
+func TestTokenFor(t *testing.T) {
+	login, now := newTestLogin(t)
+	id, err := login.createSession("Octocat", "gho_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := login.sign(id)
+
+	tests := []struct {
+		name     string
+		cookie   string
+		username string
+		advance  time.Duration
+		want     string
+	}{
+		{"own username", cookie, "octocat", 0, "gho_token"},
+		{"someone else", cookie, "torvalds", 0, ""},
+		{"no cookie", "", "octocat", 0, ""},
+		{"forged cookie", id + ".forged", "octocat", 0, ""},
+		{"unknown session", login.sign("nope"), "octocat", 0, ""},
+		{"expired", cookie, "octocat", sessionTTL + time.Second, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*now = now.Add(tt.advance)
+			token, ok := login.tokenFor(withSession(tt.cookie), tt.username)
+			if token != tt.want || ok != (tt.want != "") {
+				t.Errorf("tokenFor() = %q, %v, want %q", token, ok, tt.want)
+			}
+		})
+	}
+	if len(login.sessions) != 0 {
+		t.Errorf("expired session was kept")
+	}
+
+	var nilLogin *oauthLogin
+	if _, ok := nilLogin.tokenFor(withSession(cookie), "octocat"); ok {
+		t.Error("tokenFor() on a disabled login returned a token")
+	}
+}
+
+func TestSweepExpiredSessions(t *testing.T) {
+	login, now := newTestLogin(t)
+	old, _ := login.createSession("old", "gho_old")
+	*now = now.Add(sessionTTL / 2)
+	recent, _ := login.createSession("recent", "gho_recent")
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    []string
+	}{
+		{"nothing expired", time.Hour, []string{old, recent}},
+		{"oldest expired", sessionTTL / 2, []string{recent}},
+		{"all expired", sessionTTL, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*now = now.Add(tt.advance)
+			login.sweepExpired()
+			if len(login.sessions) != len(tt.want) {
+				t.Errorf("%d sessions left, want %d", len(login.sessions), len(tt.want))
+			}
+			for _, id := range tt.want {
+				if _, ok := login.sessions[id]; !ok {
+					t.Errorf("session %s was swept", id)
+				}
+			}
+		})
+	}
+}
+
+func TestTokensAreEncrypted(t *testing.T) {
+	login, _ := newTestLogin(t)
+	id, _ := login.createSession("octocat", "gho_plaintext")
+	if strings.Contains(string(login.sessions[id].sealed), "gho_plaintext") {
+		t.Error("token stored in the clear")
+	}
+	// A sealed token only opens under its own session ID
+	if _, ok := login.open("other-id", login.sessions[id]); ok {
+		t.Error("token opened under another session ID")
+	}
+}
+
+func TestHandleLogoutRevokes(t *testing.T) {
+	login, _ := newTestLogin(t)
+	var revoked string
+	login.revokeToken = func(token string) error {
+		revoked = token
+		return nil
+	}
+	id, _ := login.createSession("octocat", "gho_token")
+
+	r := gin.New()
+	r.POST("/auth/logout", login.handleLogout)
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: login.sign(id)})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || revoked != "gho_token" {
+		t.Errorf("status %d, revoked %q", w.Code, revoked)
+	}
+	if len(login.sessions) != 0 {
+		t.Error("session kept after logout")
+	}
+}
+
+func TestLoadOAuthLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       OAuthConfig
+		wantLogin bool
+		wantErr   bool
+	}{
+		{"not configured", OAuthConfig{}, false, false},
+		{"configured", OAuthConfig{ClientID: "id", ClientSecret: "secret", SessionSecret: testSessionSecret}, true, false},
+		{"missing secret", OAuthConfig{ClientID: "id", SessionSecret: testSessionSecret}, false, true},
+		{"short session secret", OAuthConfig{ClientID: "id", ClientSecret: "secret", SessionSecret: "short"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			login, err := loadOAuthLogin(tt.cfg)
+			if (err != nil) != tt.wantErr || (login != nil) != tt.wantLogin {
+				t.Errorf("loadOAuthLogin() = %v, %v", login, err)
+			}
+		})
+	}
+}
+
+func TestRecordSkipsPrivateAnalyses(t *testing.T) {
+	tests := []struct {
+		name     string
+		private  bool
+		recorded bool
+	}{
+		{"public", false, true},
+		{"private", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, http.NotFoundHandler())
+			stats := CommitStats{TotalCommits: 12, FixCommits: 6, PrivateCommitsIncluded: tt.private}
+			opts := RoastOptions{Days: defaultWindowDays, Weights: defaultWeights()}
+			s.record("Octocat", stats, opts)
+
+			_, found, err := s.history.LatestHistory("octocat", opts.Days, opts.Weights)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != tt.recorded {
+				t.Errorf("history recorded = %v, want %v", found, tt.recorded)
+			}
+			if _, got := s.corpus.users["octocat"]; got != tt.recorded {
+				t.Errorf("corpus includes the analysis = %v, want %v", got, tt.recorded)
+			}
+			if got := s.stats.roasts == 1; got != tt.recorded {
+				t.Errorf("server stats counted %d roasts", s.stats.roasts)
+			}
+		})
+	}
+}
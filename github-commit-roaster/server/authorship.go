@@ -0,0 +1,52 @@
+package main
+
+import "strings"
+
+// AuthorshipStats counts commits whose committer isn't their author.
+type AuthorshipStats struct {
+	DiscrepantCommits int     `json:"discrepant_commits"`
+	DiscrepancyRatio  float64 `json:"discrepancy_ratio"`
+}
+
+// detectAuthorshipDiscrepancies compares author and committer names. Commits
+// missing either name are counted but can't be discrepant.
+func detectAuthorshipDiscrepancies(commits []NormalizedCommit) AuthorshipStats {
+	var stats AuthorshipStats
+	for _, commit := range commits {
+		author := strings.TrimSpace(commit.AuthorName)
+		committer := strings.TrimSpace(commit.CommitterName)
+		if author != "" && committer != "" && !strings.EqualFold(author, committer) {
+			stats.DiscrepantCommits++
+		}
+	}
+	stats.DiscrepancyRatio = share(stats.DiscrepantCommits, len(commits))
+	return stats
+}
+
+// minInconsistentNames is how many distinct author names one account needs
+// before its git config counts as inconsistent.
+const minInconsistentNames = 3
+
+// AuthorNameStats lists the git author names used by one GitHub account.
+type AuthorNameStats struct {
+	UniqueNames    []string `json:"unique_names"`
+	IsInconsistent bool     `json:"is_inconsistent"`
+}
+
+// detectAuthorNames collects the author names on commits GitHub attributes
+// to login, ignoring collaborators' commits in the same repos. Names
+// differing only in case count once, in their first spelling.
+func detectAuthorNames(commits []NormalizedCommit, login string) AuthorNameStats {
+	stats := AuthorNameStats{UniqueNames: []string{}}
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		name := strings.TrimSpace(commit.AuthorName)
+		if name == "" || !strings.EqualFold(commit.AuthorLogin, login) || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		stats.UniqueNames = append(stats.UniqueNames, name)
+	}
+	stats.IsInconsistent = len(stats.UniqueNames) >= minInconsistentNames
+	return stats
+}
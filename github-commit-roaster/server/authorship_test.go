@@ -0,0 +1,125 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// authoredCommit is a commit written by author and committed by committer.
+func authoredCommit(author, committer string) *github.RepositoryCommit {
+	commit := &github.Commit{Message: github.String("change")}
+	if author != "" {
+		commit.Author = &github.CommitAuthor{Name: github.String(author)}
+	}
+	if committer != "" {
+		commit.Committer = &github.CommitAuthor{Name: github.String(committer)}
+	}
+	return &github.RepositoryCommit{Commit: commit}
+}
+
+func TestDetectAuthorshipDiscrepancies(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []*github.RepositoryCommit
+		want    AuthorshipStats
+	}{
+		{"no commits", nil, AuthorshipStats{}},
+		{
+			"own commits",
+			[]*github.RepositoryCommit{authoredCommit("Octo Cat", "Octo Cat"), authoredCommit("octo cat", " Octo Cat ")},
+			AuthorshipStats{},
+		},
+		{
+			"squash-merged work",
+			[]*github.RepositoryCommit{
+				authoredCommit("Hubot", "Octo Cat"),
+				authoredCommit("Mona", "Octo Cat"),
+				authoredCommit("Octo Cat", "Octo Cat"),
+				authoredCommit("Octo Cat", "GitHub"),
+			},
+			AuthorshipStats{DiscrepantCommits: 3, DiscrepancyRatio: 0.75},
+		},
+		{
+			"missing names can't differ",
+			[]*github.RepositoryCommit{authoredCommit("Hubot", ""), authoredCommit("", "Octo Cat"), authoredCommit("Mona", "Octo Cat"), authoredCommit("", "")},
+			AuthorshipStats{DiscrepantCommits: 1, DiscrepancyRatio: 0.25},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits := make([]NormalizedCommit, len(tt.commits))
+			for i, c := range tt.commits {
+				commits[i] = normalizeCommit("project", c)
+			}
+			if got := detectAuthorshipDiscrepancies(commits); got != tt.want {
+				t.Errorf("detectAuthorshipDiscrepancies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectAuthorNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []commitSpec
+		want  AuthorNameStats
+	}{
+		{"no commits", nil, AuthorNameStats{UniqueNames: []string{}}},
+		{
+			"four names on four machines",
+			[]commitSpec{
+				{Author: "Octo Cat", Login: "octocat"},
+				{Author: "Octo", Login: "octocat"},
+				{Author: "octocat", Login: "OctoCat"},
+				{Author: "The Octocat", Login: "octocat"},
+				{Author: "Octo Cat", Login: "octocat"},
+			},
+			AuthorNameStats{UniqueNames: []string{"Octo Cat", "Octo", "octocat", "The Octocat"}, IsInconsistent: true},
+		},
+		{
+			"case and spacing count once",
+			[]commitSpec{{Author: "Octo Cat", Login: "octocat"}, {Author: "octo cat", Login: "octocat"}, {Author: " OCTO CAT ", Login: "octocat"}},
+			AuthorNameStats{UniqueNames: []string{"Octo Cat"}},
+		},
+		{
+			"collaborators and unlinked commits left out",
+			[]commitSpec{
+				{Author: "Octo Cat", Login: "octocat"},
+				{Author: "Octo", Login: "octocat"},
+				{Author: "Mona", Login: "monalisa"},
+				{Author: "Someone"},
+				{Login: "octocat"},
+			},
+			AuthorNameStats{UniqueNames: []string{"Octo Cat", "Octo"}},
+		},
+		{
+			"three names is inconsistent",
+			[]commitSpec{{Author: "A", Login: "octocat"}, {Author: "B", Login: "octocat"}, {Author: "C", Login: "octocat"}},
+			AuthorNameStats{UniqueNames: []string{"A", "B", "C"}, IsInconsistent: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectAuthorNames(normalizedSpecs("project", tt.specs...), "octocat"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectAuthorNames() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorNamesRule(t *testing.T) {
+	names := AuthorNameStats{UniqueNames: []string{"Octo Cat", "Octo", "octocat", "The Octocat"}, IsInconsistent: true}
+	rule := findRule(t, "many_names")
+	stats := CommitStats{TotalCommits: 10, AuthorNames: names}
+	if !rule.Triggered(stats) {
+		t.Fatal("four names didn't trigger")
+	}
+	if line := rule.line(stats, defaultIntensity); line != "You've committed under 4 different names. Are you a developer or a spy?" {
+		t.Errorf("line = %q", line)
+	}
+	if rule.Triggered(CommitStats{TotalCommits: 10, AuthorNames: AuthorNameStats{UniqueNames: []string{"A", "B"}}}) {
+		t.Error("two names triggered")
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// AutomationStats splits commits into human and bot-authored ones.
+type AutomationStats struct {
+	HumanCount      int      `json:"human_count"`
+	BotCount        int      `json:"bot_count"`
+	AutomationRatio float64  `json:"automation_ratio"`
+	BotAuthors      []string `json:"bot_authors"`
+}
+
+// isAutomatedCommit reports whether any of patterns appears in the commit's
+// author name, author email or message.
+func isAutomatedCommit(commit NormalizedCommit, patterns []string) bool {
+	fields := []string{
+		strings.ToLower(commit.AuthorName),
+		strings.ToLower(commit.AuthorEmail),
+		strings.ToLower(commit.Message),
+	}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		for _, field := range fields {
+			if strings.Contains(field, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// detectAutomatedCommits counts commits produced by bots such as Dependabot,
+// Renovate or GitHub Actions, recognized by patterns (RoastConfig's
+// BotPatterns).
+func detectAutomatedCommits(commits []NormalizedCommit, patterns []string) AutomationStats {
+	var stats AutomationStats
+	authors := make(map[string]bool)
+
+	for _, commit := range commits {
+		if !isAutomatedCommit(commit, patterns) {
+			stats.HumanCount++
+			continue
+		}
+		stats.BotCount++
+		if commit.AuthorName != "" {
+			authors[commit.AuthorName] = true
+		}
+	}
+
+	for author := range authors {
+		stats.BotAuthors = append(stats.BotAuthors, author)
+	}
+	sort.Strings(stats.BotAuthors)
+
+	if total := stats.HumanCount + stats.BotCount; total > 0 {
+		stats.AutomationRatio = math.Round(float64(stats.BotCount)/float64(total)*100) / 100
+	}
+	return stats
+}
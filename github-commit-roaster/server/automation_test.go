@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectAutomatedCommits(t *testing.T) {
+	tests := []struct {
+		name     string
+		commits  []NormalizedCommit
+		patterns []string
+		want     AutomationStats
+	}{
+		{"no commits", nil, defaultBotPatterns, AutomationStats{}},
+		{
+			"bot by name, email and message",
+			[]NormalizedCommit{
+				{AuthorName: "dependabot[bot]", Message: "Bump lodash"},
+				{AuthorName: "Renovate", AuthorEmail: "bot@renovateapp.com", Message: "Update dependency react to v18"},
+				{AuthorName: "Octo", Message: "chore(deps): bump express from 4.17 to 4.18"},
+				{AuthorName: "Octo", Message: "fix login"},
+			},
+			defaultBotPatterns,
+			AutomationStats{HumanCount: 1, BotCount: 3, AutomationRatio: 0.75, BotAuthors: []string{"Octo", "Renovate", "dependabot[bot]"}},
+		},
+		{
+			"humans only",
+			[]NormalizedCommit{{AuthorName: "Octo", Message: "add tests"}, {AuthorName: "Cat", Message: "refactor"}},
+			defaultBotPatterns,
+			AutomationStats{HumanCount: 2},
+		},
+		{
+			"configured patterns replace the defaults",
+			[]NormalizedCommit{
+				{AuthorName: "dependabot[bot]", Message: "Bump lodash"},
+				{AuthorName: "Release Bot", Message: "v1.2.3"},
+				{Message: "anonymous release"},
+			},
+			[]string{"RELEASE"},
+			AutomationStats{HumanCount: 1, BotCount: 2, AutomationRatio: 0.67, BotAuthors: []string{"Release Bot"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectAutomatedCommits(tt.commits, tt.patterns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectAutomatedCommits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTrendPoints = 10
+	maxTrendPoints     = 50
+
+	badgeHeight     = 20
+	badgeLabelWidth = 74
+	sparklineWidth  = 60
+	trendTextWidth  = 40
+)
+
+// Badge colors, shields.io style.
+const (
+	badgeLabelColor = "#555"
+	badgeGrayColor  = "#9f9f9f"
+)
+
+// severityColor is the badge color for a 0-100 severity: green for a
+// gentle roast through red for a brutal one.
+func severityColor(severity int) string {
+	switch {
+	case severity < 25:
+		return "#4c1"
+	case severity < 50:
+		return "#dfb317"
+	case severity < 75:
+		return "#fe7d37"
+	default:
+		return "#e05d44"
+	}
+}
+
+// trendArrow points the way the latest score moved; up means a harsher
+// roast than last time.
+func trendArrow(scores []int) string {
+	last, previous := scores[len(scores)-1], scores[len(scores)-2]
+	switch {
+	case last > previous:
+		return "↑"
+	case last < previous:
+		return "↓"
+	default:
+		return "→"
+	}
+}
+
+// sparklinePoints scales scores (0-100) into polyline points inside a
+// width x height box, inset by a pixel so the stroke isn't clipped.
+func sparklinePoints(scores []int, width, height int) string {
+	points := make([]string, len(scores))
+	step := float64(width-2) / float64(len(scores)-1)
+	for i, score := range scores {
+		x := 1 + step*float64(i)
+		y := 1 + float64(height-2)*(1-float64(min(max(score, 0), 100))/100)
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return strings.Join(points, " ")
+}
+
+// badgeSVG draws a two-part badge: label on the left and value on the
+// right, with extra SVG content drawn over the value's background.
+func badgeSVG(label, value, color string, valueWidth int, extra string) string {
+	width := badgeLabelWidth + valueWidth
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" role="img" aria-label="%s: %s">`,
+		width, badgeHeight, label, value)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`, badgeLabelWidth, badgeHeight, badgeLabelColor)
+	fmt.Fprintf(&b, `<rect x="%d" width="%d" height="%d" fill="%s"/>`, badgeLabelWidth, valueWidth, badgeHeight, color)
+	b.WriteString(`<g fill="#fff" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="%d" y="14" text-anchor="middle">%s</text>`, badgeLabelWidth/2, label)
+	b.WriteString(extra)
+	b.WriteString(`</g></svg>`)
+	return b.String()
+}
+
+// renderTrendBadge draws the sparkline of scores, oldest first, then the
+// latest score and which way it moved. It needs at least two scores.
+func renderTrendBadge(scores []int) string {
+	current := scores[len(scores)-1]
+	value := fmt.Sprintf("%d %s", current, trendArrow(scores))
+	line := fmt.Sprintf(`<polyline points="%s" fill="none" stroke="#fff" stroke-width="1.5" transform="translate(%d,2)"/>`,
+		sparklinePoints(scores, sparklineWidth, badgeHeight-4), badgeLabelWidth+2)
+	text := fmt.Sprintf(`<text x="%d" y="14" text-anchor="middle">%s</text>`,
+		badgeLabelWidth+sparklineWidth+4+trendTextWidth/2, value)
+	return badgeSVG("roast trend", value, severityColor(current), sparklineWidth+4+trendTextWidth, line+text)
+}
+
+// renderNoHistoryBadge is shown until a user has been roasted twice.
+func renderNoHistoryBadge() string {
+	const value = "no history"
+	text := fmt.Sprintf(`<text x="%d" y="14" text-anchor="middle">%s</text>`, badgeLabelWidth+35, value)
+	return badgeSVG("roast trend", value, badgeGrayColor, 70, text)
+}
+
+// trendBadgeCache keeps rendered trend badges until the user's history
+// gains a newer record.
+type trendBadgeCache struct {
+	mu      sync.Mutex
+	entries map[string]trendBadge
+}
+
+type trendBadge struct {
+	latest time.Time // CreatedAt of the newest record drawn
+	svg    string
+}
+
+func newTrendBadgeCache() *trendBadgeCache {
+	return &trendBadgeCache{entries: make(map[string]trendBadge)}
+}
+
+// render returns the badge for recs, drawing it only when the cached one
+// for key is out of date.
+func (b *trendBadgeCache) render(key string, recs []HistoryRecord) string {
+	if len(recs) < 2 {
+		return renderNoHistoryBadge()
+	}
+	latest := recs[len(recs)-1].CreatedAt
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cached, ok := b.entries[key]; ok && cached.latest.Equal(latest) {
+		return cached.svg
+	}
+	scores := make([]int, len(recs))
+	for i, rec := range recs {
+		if rec.Stats.TotalCommits > 0 {
+			scores[i] = rec.Stats.Severity
+		}
+	}
+	svg := renderTrendBadge(scores)
+	b.entries[key] = trendBadge{latest: latest, svg: svg}
+	return svg
+}
+
+// handleTrendBadge serves GET /badge/:username/trend.svg, a sparkline of
+// the user's last ?points= roast severities (default 10) from the roast
+// history. It never calls GitHub.
+func (s *server) handleTrendBadge(c *gin.Context) {
+	username := strings.ToLower(c.Param("username"))
+	points := defaultTrendPoints
+	if value := c.Query("points"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 2 || n > maxTrendPoints {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("points must be a number from 2 to %d", maxTrendPoints)})
+			return
+		}
+		points = n
+	}
+
+	recs, err := s.history.RecentHistory(username, points)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not read roast history"})
+		return
+	}
+	svg := s.badges.render(username+":"+strconv.Itoa(points), recs)
+	c.Header("Cache-Control", "max-age=300")
+	c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", []byte(svg))
+}
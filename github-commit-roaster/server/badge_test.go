@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderTrendBadge(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []int
+		arrow  string
+	}{
+		{"rising", []int{10, 30, 55, 80}, "↑"},
+		{"falling", []int{90, 60, 40, 5}, "↓"},
+		{"flat", []int{40, 40, 40}, "→"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trendArrow(tt.scores); got != tt.arrow {
+				t.Errorf("trendArrow() = %s, want %s", got, tt.arrow)
+			}
+			golden(t, "trend-"+tt.name+".svg.golden", renderTrendBadge(tt.scores))
+		})
+	}
+	golden(t, "trend-no-history.svg.golden", renderNoHistoryBadge())
+}
+
+func TestSeverityColor(t *testing.T) {
+	tests := []struct {
+		severity int
+		want     string
+	}{
+		{0, "#4c1"},
+		{24, "#4c1"},
+		{25, "#dfb317"},
+		{50, "#fe7d37"},
+		{75, "#e05d44"},
+		{100, "#e05d44"},
+	}
+	for _, tt := range tests {
+		if got := severityColor(tt.severity); got != tt.want {
+			t.Errorf("severityColor(%d) = %s, want %s", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestTrendBadgeCache(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	record := func(severity, daysLater int) HistoryRecord {
+		return HistoryRecord{Stats: CommitStats{TotalCommits: 10, Severity: severity}, CreatedAt: start.AddDate(0, 0, daysLater)}
+	}
+	badges := newTrendBadgeCache()
+
+	if got := badges.render("octocat:10", []HistoryRecord{record(50, 0)}); got != renderNoHistoryBadge() {
+		t.Error("one record didn't get the no-history badge")
+	}
+
+	recs := []HistoryRecord{record(20, 0), record(60, 1)}
+	first := badges.render("octocat:10", recs)
+	if first != renderTrendBadge([]int{20, 60}) {
+		t.Errorf("badge = %s", first)
+	}
+	// A stale copy is kept until a newer record arrives
+	badges.entries["octocat:10"] = trendBadge{latest: recs[1].CreatedAt, svg: "cached"}
+	if got := badges.render("octocat:10", recs); got != "cached" {
+		t.Error("an up-to-date badge was drawn again")
+	}
+	recs = append(recs, HistoryRecord{Stats: CommitStats{Severity: 90}, CreatedAt: start.AddDate(0, 0, 2)})
+	// A record with no commits scores 0, whatever its severity
+	if got := badges.render("octocat:10", recs); got != renderTrendBadge([]int{20, 60, 0}) {
+		t.Errorf("badge after a new record = %s", got)
+	}
+}
+
+func TestHandleTrendBadge(t *testing.T) {
+	s := newTestServer(t, http.NotFoundHandler())
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, severity := range []int{70, 40, 30} {
+		s.history.AppendHistory(HistoryRecord{
+			Username:  "octocat",
+			Stats:     CommitStats{TotalCommits: 5, Severity: severity},
+			CreatedAt: start.AddDate(0, 0, i),
+		})
+	}
+	badge := func(target string) (int, string) {
+		w := doRequest(s.handleTrendBadge, http.MethodGet, "/badge/:username/trend.svg", target, "")
+		return w.Code, w.Body.String()
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		want   string
+	}{
+		{"all points", "/badge/Octocat/trend.svg", http.StatusOK, renderTrendBadge([]int{70, 40, 30})},
+		{"last two", "/badge/octocat/trend.svg?points=2", http.StatusOK, renderTrendBadge([]int{40, 30})},
+		{"no history", "/badge/hubot/trend.svg", http.StatusOK, renderNoHistoryBadge()},
+		{"too few points", "/badge/octocat/trend.svg?points=1", http.StatusBadRequest, "points must be"},
+		{"too many points", "/badge/octocat/trend.svg?points=51", http.StatusBadRequest, "points must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := badge(tt.target)
+			if status != tt.status || !strings.Contains(body, tt.want) {
+				t.Errorf("status %d, body %s", status, body)
+			}
+		})
+	}
+}
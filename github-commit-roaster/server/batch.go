@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxBatchUsers = 10
+	// batchConcurrency is how many batch members are analyzed at once.
+	batchConcurrency = 4
+	// maxBatchCalls is the GitHub call budget shared by a whole batch.
+	maxBatchCalls = 250
+)
+
+// BatchResult is one member of a POST /roast/batch response: either the
+// roast or the error that member ran into.
+type BatchResult struct {
+	Username string         `json:"username"`
+	Status   int            `json:"status"`
+	Roast    *RoastResponse `json:"roast,omitempty"`
+	Error    gin.H          `json:"error,omitempty"`
+}
+
+// handleRoastBatch serves POST /roast/batch, roasting up to maxBatchUsers
+// public profiles concurrently. Members fail individually, invalid usernames
+// with 400; results keep the request order with duplicates removed.
+func (s *server) handleRoastBatch(c *gin.Context) {
+	var req struct {
+		Usernames []string `json:"usernames"`
+		Days      int      `json:"days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Usernames) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "usernames is required"})
+		return
+	}
+	if req.Days < 0 || req.Days > maxWindowDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be a number from 1 to %d", maxWindowDays)})
+		return
+	}
+
+	usernames := uniqueUsernames(req.Usernames)
+	if len(usernames) > maxBatchUsers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d usernames per batch", maxBatchUsers)})
+		return
+	}
+	if !chargeMembers(c, len(usernames)) {
+		return
+	}
+
+	opts, query := s.bodyRoastOptions(req.Days)
+
+	ctx := withCallBudget(c.Request.Context(), maxBatchCalls)
+	results := make([]BatchResult, len(usernames))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, username := range usernames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = BatchResult{Username: username, Status: http.StatusOK}
+			if err := validateGitHubUsername(username); err != nil {
+				results[i].Status, results[i].Error = http.StatusBadRequest, gin.H{"error": err.Error()}
+				return
+			}
+			cacheKey := roastCacheKey(username, query)
+			if cached, ok := s.cache.Get(ctx, cacheKey); ok {
+				s.prefetch.hit(cacheKey)
+				response := cached.hitResponse()
+				results[i].Roast = &response
+				return
+			}
+
+			client, authMode := s.clients.newClient(ctx)
+			result, err := s.sharedFetch(ctx, client, authMode, username, opts)
+			if err != nil {
+				results[i].Status, results[i].Error = s.analysisError(err)
+				return
+			}
+			s.record(username, result.stats, opts)
+			response := s.roastResponse(username, result, opts, false)
+			s.cache.Set(ctx, cacheKey, newCachedRoast(response), s.cacheTTL)
+			s.prefetch.stored(cacheKey, username)
+			results[i].Roast = &response
+		}()
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// bodyRoastOptions are the default roast options with the window set to
+// days, for endpoints taking JSON bodies rather than query strings. It also
+// returns the query GET /roast would use for the same roast, for cache keys;
+// the default window shares entries with plain GET /roast.
+func (s *server) bodyRoastOptions(days int) (RoastOptions, url.Values) {
+	opts := RoastOptions{
+		Intensity: defaultIntensity,
+		Weights:   s.cfg.Weights,
+		MaxLines:  s.cfg.MaxRoastLines,
+		Location:  time.UTC,
+		Days:      defaultWindowDays,
+	}
+	if days == 0 || days == defaultWindowDays {
+		return opts, nil
+	}
+	opts.Days = days
+	return opts, url.Values{"days": {strconv.Itoa(days)}}
+}
+
+// uniqueUsernames trims usernames and drops blanks and case-insensitive
+// duplicates, keeping the first spelling in request order.
+func uniqueUsernames(usernames []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, username := range usernames {
+		username = strings.TrimSpace(username)
+		if username == "" || seen[strings.ToLower(username)] {
+			continue
+		}
+		seen[strings.ToLower(username)] = true
+		unique = append(unique, username)
+	}
+	return unique
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+func TestUniqueUsernames(t *testing.T) {
+	tests := []struct {
+		name      string
+		usernames []string
+		want      []string
+	}{
+		{"none", nil, nil},
+		{"already unique", []string{"octocat", "hubot"}, []string{"octocat", "hubot"}},
+		{"first spelling wins", []string{"OctoCat", "hubot", "octocat", "HUBOT"}, []string{"OctoCat", "hubot"}},
+		{"trimmed and blanks dropped", []string{" octocat ", "", "  ", "octocat"}, []string{"octocat"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueUsernames(tt.usernames); !slices.Equal(got, tt.want) {
+				t.Errorf("uniqueUsernames(%q) = %q, want %q", tt.usernames, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoastBatch(t *testing.T) {
+	octocat := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	s := newTestServer(t, fakeGitHubs{
+		"octocat": octocat,
+		"hubot":   newFakeGitHub("hubot", fakeCommit("Hubot", "add chat ops", 3)),
+	})
+	batch := func(body string) (int, []BatchResult) {
+		w := doRequest(s.handleRoastBatch, http.MethodPost, "/roast/batch", "/roast/batch", body)
+		var resp struct {
+			Results []BatchResult `json:"results"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Results
+	}
+
+	status, results := batch(`{"usernames":["octocat","ghost"," Hubot","OCTOCAT","../orgs/x"]}`)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	want := []struct {
+		username string
+		status   int
+	}{{"octocat", http.StatusOK}, {"ghost", http.StatusNotFound}, {"Hubot", http.StatusOK}, {"../orgs/x", http.StatusBadRequest}}
+	if len(results) != len(want) {
+		t.Fatalf("%d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.Username != w.username || got.Status != w.status || (got.Roast != nil) != (w.status == http.StatusOK) {
+			t.Errorf("result %d = %s %d (roast %v), want %s %d", i, got.Username, got.Status, got.Roast != nil, w.username, w.status)
+		}
+	}
+	if results[1].Error == nil || results[3].Error == nil {
+		t.Error("a failed member has no error")
+	}
+
+	// Members already cached cost nothing
+	batch(`{"usernames":["octocat"]}`)
+	if calls := octocat.callCount("/repos/octocat/project/commits"); calls != 1 {
+		t.Errorf("octocat's commits fetched %d times, want 1", calls)
+	}
+
+	tooMany := make([]string, maxBatchUsers+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("user%d", i)
+	}
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no usernames", `{"usernames":[]}`},
+		{"not JSON", `usernames=octocat`},
+		{"too many", `{"usernames":["` + strings.Join(tooMany, `","`) + `"]}`},
+		{"days out of range", `{"usernames":["octocat"],"days":400}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := batch(tt.body); status != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", status)
+			}
+		})
+	}
+}
+
+func TestFetchCallBudgetExhausted(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	for _, name := range []string{"second", "third"} {
+		gh.repos = append(gh.repos, &github.Repository{Name: github.String(name)})
+		gh.commits[name] = []*github.RepositoryCommit{fakeCommit("Octo", "add "+name, 3)}
+	}
+	s := newTestServer(t, gh)
+	client, authMode := s.clients.newClient(t.Context())
+
+	// The user, their repos and the first repo's commits
+	ctx := withCallBudget(t.Context(), 3)
+	result, err := s.sharedFetch(ctx, client, authMode, "octocat", RoastOptions{Days: defaultWindowDays, Location: time.UTC})
+	if !errors.Is(err, errCallBudgetExhausted) || result != nil {
+		t.Fatalf("sharedFetch() = %v, %v; want no result and errCallBudgetExhausted", result, err)
+	}
+	if status, _ := s.analysisError(err); status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", status)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import "github.com/google/go-github/v50/github"
+
+// RepoRoast is the mini-roast for a single repository.
+type RepoRoast struct {
+	RepoName        string `json:"repo_name"`
+	URL             string `json:"url"`
+	CommitCount     int    `json:"commit_count"`
+	Roast           string `json:"roast"`
+	DominantPattern string `json:"dominant_pattern"`
+}
+
+// patternScores rates each roastable metric against the threshold at which
+// it starts getting roasted, so 1.0 means "right at the line" and metrics
+// with different thresholds can be compared.
+func patternScores(stats CommitStats) map[string]float64 {
+	if stats.TotalCommits == 0 {
+		return nil
+	}
+	total := float64(stats.TotalCommits)
+	return map[string]float64{
+		"late_night":       float64(stats.LateNightCommits) / total / 0.5,
+		"fixes":            float64(stats.FixCommits) / total / 0.5,
+		"merges":           float64(stats.MergeCommits) / total / (1.0 / 3),
+		"generic_messages": float64(stats.GenericMessages) / total / (1.0 / 3),
+		"swearing":         float64(stats.SwearWords) / total / 0.1,
+		"automation":       stats.Automation.AutomationRatio / 0.4,
+	}
+}
+
+// dominantPattern returns the metric that scored worst, or "" when nothing
+// scored at all.
+func dominantPattern(stats CommitStats) string {
+	worst, worstScore := "", 0.0
+	for pattern, score := range patternScores(stats) {
+		if score > worstScore || (score == worstScore && score > 0 && pattern < worst) {
+			worst, worstScore = pattern, score
+		}
+	}
+	return worst
+}
+
+// repoBreakdown runs the analysis separately on each repo's commits, in the
+// order the repos were listed. Language roasts are account-wide and left out.
+func repoBreakdown(repos []*github.Repository, commits []NormalizedCommit, cfg RoastConfig, opts RoastOptions) []RepoRoast {
+	byRepo := make(map[string][]NormalizedCommit)
+	for _, commit := range commits {
+		byRepo[commit.Repo] = append(byRepo[commit.Repo], commit)
+	}
+
+	opts.Languages = false
+	breakdown := make([]RepoRoast, 0, len(repos))
+	for _, repo := range repos {
+		repoCommits := byRepo[repo.GetName()]
+		stats := analyzeCommits(repoCommits, cfg)
+		stats.ReposAnalyzed = 1
+		breakdown = append(breakdown, RepoRoast{
+			RepoName:        repo.GetName(),
+			URL:             repo.GetHTMLURL(),
+			CommitCount:     len(repoCommits),
+			Roast:           generateRoast(stats, opts),
+			DominantPattern: dominantPattern(stats),
+		})
+	}
+	return breakdown
+}
+
+// severityScore condenses the pattern scores into 0-100. A metric sitting
+// exactly at its roast threshold contributes 50; twice the threshold or more
+// contributes 100.
+func severityScore(stats CommitStats) int {
+	return weightedSeverity(stats, nil)
+}
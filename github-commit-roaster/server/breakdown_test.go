@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+func TestDominantPattern(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats CommitStats
+		want  string
+	}{
+		{"no commits", CommitStats{}, ""},
+		{"clean history", CommitStats{TotalCommits: 10}, ""},
+		{"mostly fixes", CommitStats{TotalCommits: 10, FixCommits: 6, LateNightCommits: 2}, "fixes"},
+		// One swear in ten commits is already at the line, while 4 fixes aren't
+		{"thresholds differ", CommitStats{TotalCommits: 10, FixCommits: 4, SwearWords: 2}, "swearing"},
+		{"ties break alphabetically", CommitStats{TotalCommits: 10, FixCommits: 5, LateNightCommits: 5}, "fixes"},
+		{"automation", CommitStats{TotalCommits: 10, Automation: AutomationStats{AutomationRatio: 0.9}}, "automation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dominantPattern(tt.stats); got != tt.want {
+				t.Errorf("dominantPattern() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoBreakdown(t *testing.T) {
+	repos := []*github.Repository{
+		{Name: github.String("api"), HTMLURL: github.String("https://github.com/octocat/api")},
+		{Name: github.String("site")},
+		{Name: github.String("empty")},
+	}
+	noon := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	commits := []NormalizedCommit{
+		{Repo: "site", Message: "add about page", Date: noon},
+		{Repo: "api", Message: "fix", Date: noon},
+		{Repo: "api", Message: "fix again", Date: noon},
+		{Repo: "api", Message: "add endpoint", Date: noon},
+	}
+
+	got := repoBreakdown(repos, commits, loadRoastConfig(), RoastOptions{Languages: true})
+	want := []struct {
+		name     string
+		commits  int
+		dominant string
+	}{
+		{"api", 3, "fixes"},
+		{"site", 1, ""},
+		{"empty", 0, ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d repos, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		r := got[i]
+		if r.RepoName != w.name || r.CommitCount != w.commits || r.DominantPattern != w.dominant {
+			t.Errorf("repo %d = %s with %d commits, dominant %q; want %s with %d, %q",
+				i, r.RepoName, r.CommitCount, r.DominantPattern, w.name, w.commits, w.dominant)
+		}
+		if r.Roast == "" {
+			t.Errorf("%s has no roast", r.RepoName)
+		}
+	}
+	if got[0].URL != "https://github.com/octocat/api" {
+		t.Errorf("URL = %q", got[0].URL)
+	}
+}
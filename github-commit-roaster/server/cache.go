@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache holds roast responses so repeat lookups skip GitHub.
+type Cache interface {
+	Get(ctx context.Context, key string) (cachedRoast, bool)
+	Set(ctx context.Context, key string, value cachedRoast, ttl time.Duration)
+}
+
+// cachedRoast is a cached response along with the ETag of its JSON body,
+// so conditional requests can be answered without re-encoding it.
+type cachedRoast struct {
+	Response RoastResponse `json:"response"`
+	ETag     string        `json:"etag"`
+}
+
+func newCachedRoast(response RoastResponse) cachedRoast {
+	_, body, err := renderRoast(formatJSON, response)
+	if err != nil {
+		return cachedRoast{Response: response}
+	}
+	return cachedRoast{Response: response, ETag: roastETag(body)}
+}
+
+// hitResponse is the cached response as served from the cache, with
+// cache_hit set. The ETag still identifies the roast it was computed for.
+func (c cachedRoast) hitResponse() RoastResponse {
+	response := c.Response
+	response.Stats.CacheHit = true
+	return response
+}
+
+// presentationParams only change how a cached response is rendered.
+var presentationParams = map[string]bool{
+	"username":     true,
+	"format":       true,
+	"debug_timing": true,
+	"debug":        true,
+	"no_color":     true,
+}
+
+// roastCacheKey namespaces cached responses per username; the remaining
+// query parameters are part of the key since they change the response.
+func roastCacheKey(username string, query url.Values) string {
+	rest := url.Values{}
+	for name, values := range query {
+		if !presentationParams[name] {
+			rest[name] = values
+		}
+	}
+	return "roast:" + strings.ToLower(username) + ":" + rest.Encode()
+}
+
+// newRoastCache connects to Redis when redisURL is set, so instances share
+// one cache, and falls back to memory otherwise.
+func newRoastCache(redisURL string) Cache {
+	if redisURL == "" {
+		return newMemoryCache()
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		slog.Warn("Invalid REDIS_URL, caching in memory", "error", err)
+		return newMemoryCache()
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		slog.Warn("Could not reach Redis, caching in memory", "error", err)
+		client.Close()
+		return newMemoryCache()
+	}
+	return &redisCache{client: client}
+}
+
+type cacheEntry struct {
+	value     cachedRoast
+	expiresAt time.Time
+}
+
+// memoryCache is a Cache local to this instance.
+type memoryCache struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{now: time.Now, entries: make(map[string]cacheEntry)}
+}
+
+func (m *memoryCache) Get(_ context.Context, key string) (cachedRoast, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok || m.now().After(entry.expiresAt) {
+		delete(m.entries, key)
+		return cachedRoast{}, false
+	}
+	return entry.value, true
+}
+
+func (m *memoryCache) Set(_ context.Context, key string, value cachedRoast, ttl time.Duration) {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Drop expired entries as we go so the map doesn't grow forever
+	for k, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			delete(m.entries, k)
+		}
+	}
+	m.entries[key] = cacheEntry{value: value, expiresAt: now.Add(ttl)}
+}
+
+// redisCache is a Cache shared by every instance using the same Redis.
+// Responses are stored as JSON.
+type redisCache struct {
+	client *redis.Client
+}
+
+func (r *redisCache) Get(ctx context.Context, key string) (cachedRoast, bool) {
+	var value cachedRoast
+	data, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			slog.Warn("Redis cache read failed", "error", err)
+		}
+		return value, false
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		slog.Warn("Ignoring unreadable cache entry", "key", key, "error", err)
+		return value, false
+	}
+	if value.ETag == "" {
+		// Written before entries carried an ETag
+		return value, false
+	}
+	return value, true
+}
+
+func (r *redisCache) Set(ctx context.Context, key string, value cachedRoast, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		slog.Warn("Could not encode cache entry", "key", key, "error", err)
+		return
+	}
+	if err := r.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		slog.Warn("Redis cache write failed", "error", err)
+	}
+}
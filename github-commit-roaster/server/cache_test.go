@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func testRoast(username string) cachedRoast {
+	return newCachedRoast(RoastResponse{Username: username, Roast: "So many fixes.", Stats: CommitStats{TotalCommits: 3}})
+}
+
+func TestRoastCacheKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		query    string
+		want     string
+	}{
+		{"username only", "octocat", "username=octocat", "roast:octocat:"},
+		{"lowercased", "OctoCat", "username=OctoCat", "roast:octocat:"},
+		{"options kept", "octocat", "username=octocat&intensity=5&days=7", "roast:octocat:days=7&intensity=5"},
+		{"presentation dropped", "octocat", "username=octocat&format=svg&debug=true&debug_timing=true&no_color=true&days=7", "roast:octocat:days=7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			if got := roastCacheKey(tt.username, query); got != tt.want {
+				t.Errorf("roastCacheKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newMemoryCache()
+	cache.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	cache.Set(ctx, "roast:octocat:", testRoast("octocat"), time.Minute)
+	cache.Set(ctx, "roast:hubot:", testRoast("hubot"), time.Hour)
+	if got, ok := cache.Get(ctx, "roast:octocat:"); !ok || got.Response.Username != "octocat" {
+		t.Fatalf("Get() = %+v, %v", got, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get(ctx, "roast:octocat:"); ok {
+		t.Error("expired entry returned")
+	}
+	if _, ok := cache.Get(ctx, "roast:hubot:"); !ok {
+		t.Error("unexpired entry dropped")
+	}
+	if _, ok := cache.Get(ctx, "roast:nobody:"); ok {
+		t.Error("missing key found")
+	}
+
+	// Writes sweep out whatever has expired
+	now = now.Add(2 * time.Hour)
+	cache.Set(ctx, "roast:new:", testRoast("new"), time.Minute)
+	if len(cache.entries) != 1 {
+		t.Errorf("%d entries after the sweep, want 1", len(cache.entries))
+	}
+}
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, Cache) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cache := newRoastCache("redis://" + mr.Addr())
+	if _, ok := cache.(*redisCache); !ok {
+		t.Fatalf("newRoastCache() = %T, want *redisCache", cache)
+	}
+	t.Cleanup(func() { cache.(*redisCache).client.Close() })
+	return mr, cache
+}
+
+func TestRedisCache(t *testing.T) {
+	mr, cache := newTestRedis(t)
+	ctx := t.Context()
+	want := testRoast("octocat")
+
+	cache.Set(ctx, "roast:octocat:days=7", want, 10*time.Minute)
+	got, ok := cache.Get(ctx, "roast:octocat:days=7")
+	if !ok || got.ETag != want.ETag || got.Response.Roast != want.Response.Roast {
+		t.Fatalf("Get() = %+v, %v; want %+v", got, ok, want)
+	}
+	if keys := mr.Keys(); len(keys) != 1 || keys[0] != "roast:octocat:days=7" {
+		t.Errorf("Redis keys = %q", keys)
+	}
+	if ttl := mr.TTL("roast:octocat:days=7"); ttl != 10*time.Minute {
+		t.Errorf("TTL = %v, want 10m", ttl)
+	}
+
+	mr.FastForward(11 * time.Minute)
+	if _, ok := cache.Get(ctx, "roast:octocat:days=7"); ok {
+		t.Error("entry outlived its TTL")
+	}
+}
+
+func TestRedisCacheUnusableEntries(t *testing.T) {
+	mr, cache := newTestRedis(t)
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"not JSON", "{roast"},
+		{"written before ETags", `{"response":{"username":"octocat"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr.Set("roast:octocat:", tt.value)
+			if _, ok := cache.Get(t.Context(), "roast:octocat:"); ok {
+				t.Error("unusable entry returned as a hit")
+			}
+		})
+	}
+}
+
+func TestNewRoastCacheFallsBack(t *testing.T) {
+	tests := []struct {
+		name     string
+		redisURL string
+	}{
+		{"unset", ""},
+		{"invalid", "http://not-redis"},
+		{"unreachable", "redis://127.0.0.1:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cache, ok := newRoastCache(tt.redisURL).(*memoryCache); !ok {
+				t.Errorf("newRoastCache(%q) = %T, want *memoryCache", tt.redisURL, cache)
+			}
+		})
+	}
+}
+
+// recordingCache is a Cache that remembers every Set.
+type recordingCache struct {
+	*memoryCache
+	sets []recordedSet
+}
+
+type recordedSet struct {
+	key   string
+	value cachedRoast
+	ttl   time.Duration
+}
+
+func (r *recordingCache) Set(ctx context.Context, key string, value cachedRoast, ttl time.Duration) {
+	r.sets = append(r.sets, recordedSet{key, value, ttl})
+	r.memoryCache.Set(ctx, key, value, ttl)
+}
+
+func TestRoastHandlerUsesCache(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	s := newTestServer(t, gh)
+	cache := &recordingCache{memoryCache: newMemoryCache()}
+	s.cache = cache
+
+	tests := []struct {
+		target string
+		xCache string
+		sets   int
+	}{
+		{"/roast?username=octocat&debug_timing=true", "MISS", 1},
+		{"/roast?username=OctoCat", "HIT", 1},
+		{"/roast?username=octocat&days=7", "MISS", 2},
+	}
+	for _, tt := range tests {
+		w := doRequest(s.handleRoast, http.MethodGet, "/roast", tt.target, "")
+		if w.Code != http.StatusOK || w.Header().Get("X-Cache") != tt.xCache {
+			t.Fatalf("%s: status %d, X-Cache %q; want 200, %s", tt.target, w.Code, w.Header().Get("X-Cache"), tt.xCache)
+		}
+		if len(cache.sets) != tt.sets {
+			t.Fatalf("%s: %d cache writes, want %d", tt.target, len(cache.sets), tt.sets)
+		}
+	}
+
+	first := cache.sets[0]
+	if first.key != "roast:octocat:" || first.ttl != s.cacheTTL {
+		t.Errorf("first write went to %q for %v", first.key, first.ttl)
+	}
+	if first.value.Response.Username != "octocat" || first.value.ETag == "" {
+		t.Errorf("cached %+v", first.value)
+	}
+	if first.value.Response.Timing != nil {
+		t.Error("per-request timing was cached")
+	}
+	if calls := gh.callCount("/repos/octocat/project/commits"); calls != 2 {
+		t.Errorf("fetched commits %d times, want 2", calls)
+	}
+}
+
+func TestCacheConcurrentReadWrite(t *testing.T) {
+	caches := map[string]func(t *testing.T) Cache{
+		"memory": func(*testing.T) Cache { return newMemoryCache() },
+		"redis": func(t *testing.T) Cache {
+			_, cache := newTestRedis(t)
+			return cache
+		},
+	}
+	for name, newCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			cache := newCache(t)
+			ctx := t.Context()
+			const workers = 20
+			var wg sync.WaitGroup
+			for i := range workers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					own := fmt.Sprintf("roast:user%d:", i)
+					for n := range 50 {
+						cache.Set(ctx, own, testRoast(fmt.Sprintf("user%d", i)), time.Minute)
+						// Everyone also fights over one shared key
+						cache.Set(ctx, "roast:shared:", testRoast(fmt.Sprintf("user%d-%d", i, n)), time.Minute)
+						if got, ok := cache.Get(ctx, own); !ok || got.Response.Username != fmt.Sprintf("user%d", i) {
+							t.Errorf("Get(%s) = %q, %v", own, got.Response.Username, ok)
+							return
+						}
+						if _, ok := cache.Get(ctx, "roast:shared:"); !ok {
+							t.Error("shared key missing")
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
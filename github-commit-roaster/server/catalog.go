@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultCatalogLang = "en"
+
+// ruleTranslations localizes rule descriptions for ?lang=; anything missing
+// falls back to the English description on the rule itself.
+var ruleTranslations = map[string]map[string]string{
+	"es": {
+		"late_night":               "La mayoría de los commits caen en la franja nocturna.",
+		"weekends_only":            "Todos los commits caen en fin de semana.",
+		"nonstop":                  "Muchísimos commits por cada día con actividad.",
+		"swearing":                 "Palabrotas en los mensajes de commit.",
+		"merges":                   "Muchos commits de merge de pull requests.",
+		"branch_merges":            "Ramas fusionadas entre sí en lugar de rebase o squash.",
+		"squash_merges":            "Casi todo llega como pull request con squash.",
+		"designer":                 "Más CSS que JavaScript.",
+		"untested":                 "Pocos repositorios tienen archivos de test.",
+		"no_gitignore":             "Varios repositorios no tienen .gitignore.",
+		"web_editor":               "La mayoría de los commits se hicieron en el editor web de GitHub.",
+		"nine_to_five":             "Commits solo en horario laboral.",
+		"sleepless_percentile":     "Más commits nocturnos que casi todos los analizados hoy.",
+		"off_hours":                "Casi ningún commit en horario laboral.",
+		"slow_pace":                "Una cuenta antigua que hace commits a paso de tortuga.",
+		"bare_issue_refs":          "Mensajes que no son más que un número de issue.",
+		"abandoned_projects":       "Proyectos empezados con ganas y abandonados a la semana.",
+		"famous_abandoned":         "Repositorios con estrellas a los que ya nadie hace commits.",
+		"nobody_cares":             "Muchos commits y ni una estrella.",
+		"shell_heavy":              "Los scripts de shell son gran parte del código.",
+		"fixes":                    "Muchos commits que arreglan cosas.",
+		"fixups":                   "Commits fixup! y squash! que nunca se rebasaron.",
+		"generic_messages":         "Mensajes genéricos como \"update\" o \"changes\".",
+		"empty_messages":           "Commits con el mensaje vacío.",
+		"veteran_low_activity":     "Una cuenta veterana que ya casi no hace commits.",
+		"solo":                     "Trabaja solo en todos sus repositorios.",
+		"collaborator":             "Nunca trabaja solo.",
+		"no_commit_body":           "Mensajes de commit sin cuerpo.",
+		"no_issue_links":           "Los commits nunca enlazan un issue o pull request.",
+		"over_linked":              "Cada commit enlaza un issue.",
+		"unsigned":                 "Ningún commit está firmado.",
+		"always_signed":            "Todos los commits están firmados.",
+		"dmca":                     "Un repositorio no está disponible por motivos legales.",
+		"serial_starter":           "La mayoría de los repositorios están abandonados.",
+		"initial_commit_graveyard": "Repositorios que nunca pasaron del commit inicial.",
+		"mixed_scripts":            "Mensajes de commit en varios sistemas de escritura.",
+		"cross_repo_duplicates":    "El mismo mensaje de commit copiado entre repositorios.",
+		"automation":               "Los bots hacen la mayoría de los commits.",
+		"borrowed_glory":           "Commits escritos por otras personas.",
+		"code_profanity":           "Palabrotas en el propio código.",
+		"doc_only":                 "Commits que solo tocan documentación.",
+		"shouting":                 "Asuntos de commit escritos en MAYÚSCULAS.",
+		"debt_markers":             "Marcadores TODO, FIXME, HACK y XXX en los commits.",
+		"no_follow_through":        "Muchos commits y pocos issues o pull requests cerrados.",
+		"vague_vocabulary":         "La palabra más usada en los mensajes no dice nada, como \"stuff\" o \"misc\".",
+		"noreply_only":             "Todos los commits usan una dirección noreply de GitHub.",
+		"typos":                    "Asuntos de commit con faltas de ortografía comunes.",
+		"giant_commits":            "Commits que cambian cientos de líneas de golpe.",
+		"one_liners":               "La mayoría de los commits cambian una sola línea.",
+		"many_names":               "Commits con varios nombres de autor de git distintos.",
+	},
+}
+
+// languageRuleDescriptions describe the language roast lines, by catalog
+// language, with the language name filled in.
+var languageRuleDescriptions = map[string]string{
+	"en": "%s is one of the most used languages.",
+	"es": "%s es uno de los lenguajes más usados.",
+}
+
+// RuleInfo describes one roast rule in the GET /rules catalog.
+type RuleInfo struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Threshold   string   `json:"threshold"`
+	Metric      string   `json:"metric,omitempty"`
+	Enabled     bool     `json:"enabled"`   // false when the server weights Metric at 0
+	Templates   []string `json:"templates"` // fmt templates, gentlest intensity first
+}
+
+// ruleCatalog builds the catalog from roastRules and languageRoasts, so it
+// lists exactly what the renderer can produce.
+func ruleCatalog(cfg RoastConfig, lang string) []RuleInfo {
+	translations := ruleTranslations[lang]
+	catalog := make([]RuleInfo, 0, len(roastRules)+len(languageRoasts))
+	for _, rule := range roastRules {
+		description := rule.Description
+		if translated, ok := translations[rule.ID]; ok {
+			description = translated
+		}
+		catalog = append(catalog, RuleInfo{
+			ID:          rule.ID,
+			Description: description,
+			Threshold:   rule.Threshold,
+			Metric:      rule.Metric,
+			Enabled:     rule.Metric == "" || cfg.Weights.weight(rule.Metric) > 0,
+			Templates:   rule.Templates[:],
+		})
+	}
+	return append(catalog, languageCatalog(lang)...)
+}
+
+// languageCatalog lists the lines languageRoastLines can add, in language
+// order. Their ids are "language:" followed by the GitHub language name.
+func languageCatalog(lang string) []RuleInfo {
+	description, ok := languageRuleDescriptions[lang]
+	if !ok {
+		description = languageRuleDescriptions[defaultCatalogLang]
+	}
+	langs := make([]string, 0, len(languageRoasts))
+	for name := range languageRoasts {
+		langs = append(langs, name)
+	}
+	sort.Strings(langs)
+
+	catalog := make([]RuleInfo, len(langs))
+	for i, name := range langs {
+		catalog[i] = RuleInfo{
+			ID:          "language:" + name,
+			Description: fmt.Sprintf(description, name),
+			Threshold:   fmt.Sprintf("languages=true and %s in at least %g%% of repos (of code with deep=true), among the %d most used roasted languages", name, minLanguageShare, maxLanguageRoasts),
+			Enabled:     true,
+			Templates:   []string{languageRoasts[name]},
+		}
+	}
+	return catalog
+}
+
+// handleRules serves GET /rules.
+func (s *server) handleRules(c *gin.Context) {
+	lang := c.DefaultQuery("lang", defaultCatalogLang)
+	if _, ok := ruleTranslations[lang]; !ok && lang != defaultCatalogLang {
+		lang = defaultCatalogLang
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"lang":  lang,
+		"rules": ruleCatalog(s.cfg, lang),
+	})
+}
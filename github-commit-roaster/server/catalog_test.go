@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRuleCatalogMatchesRenderer(t *testing.T) {
+	catalog := ruleCatalog(loadRoastConfig(), defaultCatalogLang)
+	ids := make([]string, len(catalog))
+	for i, info := range catalog {
+		ids[i] = info.ID
+	}
+	var renderer []string
+	for _, rule := range roastRules {
+		renderer = append(renderer, rule.ID)
+	}
+	langs := make([]string, 0, len(languageRoasts))
+	for lang := range languageRoasts {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	for _, lang := range langs {
+		renderer = append(renderer, "language:"+lang)
+	}
+	if !slices.Equal(ids, renderer) {
+		t.Fatalf("catalog lists %q, renderer has %q", ids, renderer)
+	}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("rule %s listed twice", id)
+		}
+		seen[id] = true
+	}
+
+	// Every rule ID the renderer refers to elsewhere is in the catalog
+	referenced := make(map[string]string)
+	for _, group := range relatedRules {
+		for _, id := range group {
+			referenced[id] = "relatedRules"
+		}
+	}
+	for id := range keywordRules {
+		referenced[id] = "keywordRules"
+	}
+	for persona, lines := range personaTemplates {
+		for id := range lines {
+			referenced[id] = persona + " persona"
+		}
+	}
+	for lang, descriptions := range ruleTranslations {
+		for id := range descriptions {
+			referenced[id] = lang + " translations"
+		}
+	}
+	for id, where := range referenced {
+		if !seen[id] {
+			t.Errorf("%s refers to %s, which isn't in the catalog", where, id)
+		}
+	}
+
+	// The catalog's templates are the ones lines are rendered from
+	for i, info := range catalog {
+		want := []string{languageRoasts[strings.TrimPrefix(info.ID, "language:")]}
+		if i < len(roastRules) {
+			want = roastRules[i].Templates[:]
+		}
+		if info.Description == "" || info.Threshold == "" || !slices.Equal(info.Templates, want) {
+			t.Errorf("%s: entry %+v doesn't match the rule", info.ID, info)
+		}
+		for _, tmpl := range info.Templates {
+			if tmpl == "" {
+				t.Errorf("%s: empty template", info.ID)
+			}
+		}
+	}
+}
+
+func TestHandleRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		weights  Weights
+		lang     string
+		late     string // late_night's description
+		php      string // language:PHP's description
+		lateOn   bool
+		swearsOn bool
+	}{
+		{"english", "", nil, "en", "Most commits land in the late-night window.", "PHP is one of the most used languages.", true, true},
+		{"spanish", "?lang=es", nil, "es", "La mayoría de los commits caen en la franja nocturna.", "PHP es uno de los lenguajes más usados.", true, true},
+		{"unknown language", "?lang=xx", nil, "en", "Most commits land in the late-night window.", "PHP is one of the most used languages.", true, true},
+		{"weighted out", "", Weights{"latenight": 0, "swearing": 1}, "en", "Most commits land in the late-night window.", "PHP is one of the most used languages.", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, http.NotFoundHandler())
+			s.cfg.Weights = tt.weights
+			w := doRequest(s.handleRules, http.MethodGet, "/rules", "/rules"+tt.query, "")
+			var body struct {
+				Lang  string     `json:"lang"`
+				Rules []RuleInfo `json:"rules"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Lang != tt.lang || len(body.Rules) != len(roastRules)+len(languageRoasts) {
+				t.Fatalf("lang %q with %d rules", body.Lang, len(body.Rules))
+			}
+			for _, info := range body.Rules {
+				switch info.ID {
+				case "late_night":
+					if info.Description != tt.late || info.Enabled != tt.lateOn {
+						t.Errorf("late_night = %+v", info)
+					}
+				case "swearing":
+					if info.Enabled != tt.swearsOn {
+						t.Errorf("swearing enabled = %v", info.Enabled)
+					}
+				case "language:PHP":
+					if info.Description != tt.php || !info.Enabled || !slices.Equal(info.Templates, []string{languageRoasts["PHP"]}) {
+						t.Errorf("language:PHP = %+v", info)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Every language line a roast can contain is in the catalog.
+func TestRuleCatalogLanguageLines(t *testing.T) {
+	templates := make(map[string]bool)
+	for _, info := range ruleCatalog(loadRoastConfig(), defaultCatalogLang) {
+		for _, tmpl := range info.Templates {
+			templates[tmpl] = true
+		}
+	}
+	for lang := range languageRoasts {
+		for _, line := range languageRoastLines(map[string]float64{lang: 100}) {
+			if !templates[line] {
+				t.Errorf("%s line %q isn't in the catalog", lang, line)
+			}
+		}
+	}
+}
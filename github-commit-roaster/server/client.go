@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/oauth2"
+)
+
+// githubCalls counts every request made to the GitHub API since startup.
+var githubCalls atomic.Int64
+
+// countingTransport bumps githubCalls for each request it sends.
+type countingTransport struct {
+	next http.RoundTripper
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if budget, ok := req.Context().Value(callBudgetKey{}).(*callBudget); ok && !budget.take() {
+		return nil, errCallBudgetExhausted
+	}
+	githubCalls.Add(1)
+	return t.next.RoundTrip(req)
+}
+
+// errCallBudgetExhausted fails GitHub calls beyond a context's call budget.
+var errCallBudgetExhausted = errors.New("GitHub API call budget exhausted")
+
+type callBudgetKey struct{}
+
+// callBudget caps the GitHub calls made under one context, for work that
+// fans out such as a batch roast.
+type callBudget struct {
+	remaining atomic.Int64
+}
+
+func (b *callBudget) take() bool {
+	return b.remaining.Add(-1) >= 0
+}
+
+// withCallBudget limits the GitHub calls made with ctx, and any context
+// derived from it, to calls in total.
+func withCallBudget(ctx context.Context, calls int) context.Context {
+	budget := &callBudget{}
+	budget.remaining.Store(int64(calls))
+	return context.WithValue(ctx, callBudgetKey{}, budget)
+}
+
+// Bounds for GITHUB_API_TIMEOUT, the deadline on one request's GitHub calls.
+const (
+	defaultGitHubTimeout = 20 * time.Second
+	minGitHubTimeout     = 5 * time.Second
+)
+
+// newGitHubTransport is the transport for outbound GitHub traffic. It goes
+// through the proxy named by HTTPS_PROXY or HTTP_PROXY, except for hosts
+// listed in NO_PROXY. The environment is read here rather than through
+// http.ProxyFromEnvironment, which reads it once per process.
+func newGitHubTransport() *http.Transport {
+	proxy := httpproxy.FromEnvironment().ProxyFunc()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+	return transport
+}
+
+// Auth modes reported to clients in the X-Roast-Auth-Mode header.
+const (
+	authModeAuthenticated   = "authenticated"
+	authModeUnauthenticated = "unauthenticated"
+)
+
+// defaultUserAgent identifies our API traffic to GitHub unless
+// GITHUB_USER_AGENT overrides it.
+const defaultUserAgent = "commit-roaster/1.0"
+
+// clientFactory builds a GitHub API client per request. It prefers GitHub
+// App installation tokens, then a personal access token, and finally the
+// anonymous API (60 requests/hour).
+type clientFactory struct {
+	token        string
+	userAgent    string
+	app          oauth2.TokenSource // nil unless GitHub App auth is configured
+	maxRetryWait time.Duration
+	transport    http.RoundTripper
+	baseURL      *url.URL // API root; nil for api.github.com
+}
+
+func newClientFactory(token, userAgent string, app oauth2.TokenSource) *clientFactory {
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	return &clientFactory{
+		token:        token,
+		userAgent:    userAgent,
+		app:          app,
+		maxRetryWait: defaultMaxRetryWait,
+		transport:    newGitHubTransport(),
+	}
+}
+
+// httpClient is the base HTTP client every GitHub client is built on. Each
+// attempt, retries included, is counted.
+func (f *clientFactory) httpClient() *http.Client {
+	return &http.Client{Transport: retryTransport{
+		next:    countingTransport{next: f.transport},
+		maxWait: f.maxRetryWait,
+		now:     time.Now,
+	}}
+}
+
+// build wraps an HTTP client in a GitHub client with our user agent.
+func (f *clientFactory) build(httpClient *http.Client) *ghclient.Client {
+	client := github.NewClient(httpClient)
+	client.UserAgent = f.userAgent
+	if f.baseURL != nil {
+		client.BaseURL = f.baseURL
+	}
+	return ghclient.New(client)
+}
+
+// newClient returns a client along with the auth mode it uses.
+func (f *clientFactory) newClient(ctx context.Context) (*ghclient.Client, string) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, f.httpClient())
+	switch {
+	case f.app != nil:
+		return f.build(oauth2.NewClient(ctx, f.app)), authModeAuthenticated
+	case f.token != "":
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: f.token})
+		return f.build(oauth2.NewClient(ctx, ts)), authModeAuthenticated
+	default:
+		return f.build(f.httpClient()), authModeUnauthenticated
+	}
+}
+
+// newUserClient returns a client acting as a logged-in user.
+func (f *clientFactory) newUserClient(ctx context.Context, token string) *ghclient.Client {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, f.httpClient())
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	return f.build(oauth2.NewClient(ctx, ts))
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"golang.org/x/oauth2"
+)
+
+func TestNewClientAuthModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		app           oauth2.TokenSource
+		wantMode      string
+		authorization string
+	}{
+		{"anonymous", "", nil, authModeUnauthenticated, ""},
+		{"personal access token", "ghp_secret", nil, authModeAuthenticated, "Bearer ghp_secret"},
+		{
+			"github app wins over the token", "ghp_secret",
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ghs_installation"}),
+			authModeAuthenticated, "Bearer ghs_installation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				w.Write([]byte(`{"login":"octocat"}`))
+			}))
+			defer api.Close()
+
+			f := newClientFactory(tt.token, "", tt.app)
+			f.baseURL, _ = url.Parse(api.URL + "/")
+			client, mode := f.newClient(t.Context())
+			if mode != tt.wantMode {
+				t.Errorf("auth mode = %q, want %q", mode, tt.wantMode)
+			}
+			if _, _, err := client.Users.Get(t.Context(), "octocat"); err != nil {
+				t.Fatalf("Users.Get: %v", err)
+			}
+			if got != tt.authorization {
+				t.Errorf("Authorization = %q, want %q", got, tt.authorization)
+			}
+		})
+	}
+}
+
+func TestRoastReportsAuthMode(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"without a token", "", authModeUnauthenticated},
+		{"with a token", "ghp_secret", authModeAuthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix typo", 2)))
+			s.clients.token = tt.token
+
+			w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("X-Roast-Auth-Mode"); got != tt.want {
+				t.Errorf("X-Roast-Auth-Mode = %q, want %q", got, tt.want)
+			}
+			var body RoastResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Stats.AuthMode != tt.want || body.Stats.RateLimitRemaining != 59 {
+				t.Errorf("stats auth mode %q with %d calls left, want %q with 59",
+					body.Stats.AuthMode, body.Stats.RateLimitRemaining, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{"default", "", defaultUserAgent},
+		{"configured", "acme-roaster/2.3 (+https://acme.test)", "acme-roaster/2.3 (+https://acme.test)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = append(got, r.Header.Get("User-Agent"))
+				w.Write([]byte(`{"login":"octocat"}`))
+			}))
+			defer api.Close()
+
+			f := newClientFactory("", tt.userAgent, nil)
+			f.baseURL, _ = url.Parse(api.URL + "/")
+			client, _ := f.newClient(t.Context())
+			userClient := f.newUserClient(t.Context(), "gho_token")
+			for _, c := range []*ghclient.Client{client, userClient} {
+				if _, _, err := c.Users.Get(t.Context(), "octocat"); err != nil {
+					t.Fatalf("Users.Get: %v", err)
+				}
+			}
+			for _, ua := range got {
+				if ua != tt.want {
+					t.Errorf("User-Agent = %q, want %q", ua, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGitHubTransportProxy(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		target string
+		want   string // proxy URL; empty for a direct connection
+	}{
+		{"no proxy configured", nil, "https://api.github.com/users/octocat", ""},
+		{"HTTPS_PROXY", map[string]string{"HTTPS_PROXY": "http://proxy.corp:3128"}, "https://api.github.com/users/octocat", "http://proxy.corp:3128"},
+		{"HTTP_PROXY isn't used for https", map[string]string{"HTTP_PROXY": "http://proxy.corp:3128"}, "https://api.github.com/users/octocat", ""},
+		{"HTTP_PROXY for http", map[string]string{"HTTP_PROXY": "http://proxy.corp:3128"}, "http://ghe.corp/api/v3/users/octocat", "http://proxy.corp:3128"},
+		{
+			"NO_PROXY",
+			map[string]string{"HTTPS_PROXY": "http://proxy.corp:3128", "NO_PROXY": "ghe.corp,.internal"},
+			"https://ghe.corp/api/v3/users/octocat", "",
+		},
+		{
+			"NO_PROXY domain suffix",
+			map[string]string{"HTTPS_PROXY": "http://proxy.corp:3128", "NO_PROXY": ".internal"},
+			"https://github.internal/api/v3/users/octocat", "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "NO_PROXY", "no_proxy", "REQUEST_METHOD"} {
+				t.Setenv(name, tt.env[name])
+			}
+			transport := newGitHubTransport()
+			if transport.Proxy == nil {
+				t.Fatal("transport has no Proxy function")
+			}
+			proxy, err := transport.Proxy(httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			if proxy != nil {
+				got = proxy.String()
+			}
+			if got != tt.want {
+				t.Errorf("proxy for %s = %q, want %q", tt.target, got, tt.want)
+			}
+		})
+	}
+}
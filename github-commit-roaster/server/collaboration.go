@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CollaborationStats compares the user's commits with everyone else's in the
+// analyzed repos.
+type CollaborationStats struct {
+	SoloRepos       int     `json:"solo_repos"`
+	CollabRepos     int     `json:"collab_repos"`
+	SoloCommitRatio float64 `json:"solo_commit_ratio"` // user's share of all commits
+	AvgTeamSize     float64 `json:"avg_team_size"`
+}
+
+// repoContributors is one repo's contributor list, reduced to what the
+// collaboration stats need.
+type repoContributors struct {
+	Contributors int
+	UserCommits  int
+	TotalCommits int
+}
+
+// collaborationStats summarizes per-repo contributor counts.
+func collaborationStats(repos []repoContributors) CollaborationStats {
+	var stats CollaborationStats
+	userCommits, totalCommits, teamSizes := 0, 0, 0
+	for _, repo := range repos {
+		if repo.Contributors == 0 {
+			continue
+		}
+		if repo.Contributors == 1 && repo.UserCommits == repo.TotalCommits {
+			stats.SoloRepos++
+		} else {
+			stats.CollabRepos++
+		}
+		userCommits += repo.UserCommits
+		totalCommits += repo.TotalCommits
+		teamSizes += repo.Contributors
+	}
+	if counted := stats.SoloRepos + stats.CollabRepos; counted > 0 {
+		stats.AvgTeamSize = float64(teamSizes) / float64(counted)
+	}
+	stats.SoloCommitRatio = share(userCommits, totalCommits)
+	return stats
+}
+
+// fetchCollaboration lists the contributors of each repo, costing one call
+// per repo.
+func fetchCollaboration(ctx context.Context, client *ghclient.Client, owner string, repos []*github.Repository) CollaborationStats {
+	var counts []repoContributors
+	for _, repo := range repos {
+		spanCtx, span := startGitHubSpan(ctx, "Repositories.ListContributors", attribute.String("github.repo", repo.GetName()))
+		contributors, _, err := client.Repositories.ListContributors(spanCtx, owner, repo.GetName(),
+			&github.ListContributorsOptions{ListOptions: github.ListOptions{PerPage: 100}})
+		endSpan(span, err)
+		if err != nil {
+			continue // Skip repo if we can't get contributors
+		}
+
+		count := repoContributors{Contributors: len(contributors)}
+		for _, contributor := range contributors {
+			count.TotalCommits += contributor.GetContributions()
+			if strings.EqualFold(contributor.GetLogin(), owner) {
+				count.UserCommits += contributor.GetContributions()
+			}
+		}
+		counts = append(counts, count)
+	}
+	return collaborationStats(counts)
+}
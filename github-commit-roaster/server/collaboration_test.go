@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCollaborationStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		repos []repoContributors
+		want  CollaborationStats
+	}{
+		{"no repos", nil, CollaborationStats{}},
+		{
+			"solo and shared",
+			[]repoContributors{
+				{Contributors: 1, UserCommits: 30, TotalCommits: 30},
+				{Contributors: 3, UserCommits: 10, TotalCommits: 70},
+			},
+			CollaborationStats{SoloRepos: 1, CollabRepos: 1, SoloCommitRatio: 0.4, AvgTeamSize: 2},
+		},
+		{
+			// Someone else's one-person repo that the user forked
+			"single other contributor",
+			[]repoContributors{{Contributors: 1, UserCommits: 0, TotalCommits: 12}},
+			CollaborationStats{CollabRepos: 1, AvgTeamSize: 1},
+		},
+		{
+			"empty repos are skipped",
+			[]repoContributors{{}, {Contributors: 2, UserCommits: 5, TotalCommits: 10}},
+			CollaborationStats{CollabRepos: 1, SoloCommitRatio: 0.5, AvgTeamSize: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collaborationStats(tt.repos); got != tt.want {
+				t.Errorf("collaborationStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollaborationRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		collaboration *CollaborationStats
+		solo, collab  bool
+	}{
+		{"not analyzed", nil, false, false},
+		{"all solo", &CollaborationStats{SoloRepos: 3, SoloCommitRatio: 1}, true, false},
+		{"at the line", &CollaborationStats{SoloRepos: 3, CollabRepos: 1, SoloCommitRatio: 0.95}, false, false},
+		{"barely there", &CollaborationStats{CollabRepos: 4, SoloCommitRatio: 0.01}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, Collaboration: tt.collaboration}
+			if got := findRule(t, "solo").Triggered(stats); got != tt.solo {
+				t.Errorf("solo triggered = %v, want %v", got, tt.solo)
+			}
+			if got := findRule(t, "collaborator").Triggered(stats); got != tt.collab {
+				t.Errorf("collaborator triggered = %v, want %v", got, tt.collab)
+			}
+		})
+	}
+}
+
+func TestFetchCollaboration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repos := ghclient.NewMockRepositoriesService(ctrl)
+	contributor := func(login string, commits int) *github.Contributor {
+		return &github.Contributor{Login: github.String(login), Contributions: github.Int(commits)}
+	}
+	repos.EXPECT().ListContributors(gomock.Any(), "octocat", "solo", gomock.Any()).
+		Return([]*github.Contributor{contributor("Octocat", 20)}, nil, nil)
+	repos.EXPECT().ListContributors(gomock.Any(), "octocat", "team", gomock.Any()).
+		Return([]*github.Contributor{contributor("octocat", 5), contributor("hubot", 15)}, nil, nil)
+	repos.EXPECT().ListContributors(gomock.Any(), "octocat", "private", gomock.Any()).
+		Return(nil, nil, errors.New("403"))
+
+	got := fetchCollaboration(t.Context(), &ghclient.Client{Repositories: repos}, "octocat", []*github.Repository{
+		{Name: github.String("solo")}, {Name: github.String("team")}, {Name: github.String("private")},
+	})
+	want := CollaborationStats{SoloRepos: 1, CollabRepos: 1, SoloCommitRatio: 25.0 / 40, AvgTeamSize: 1.5}
+	if got != want {
+		t.Errorf("fetchCollaboration() = %+v, want %+v", got, want)
+	}
+}
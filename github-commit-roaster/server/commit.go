@@ -0,0 +1,46 @@
+package main
+
+import (
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// NormalizedCommit is the part of a GitHub commit the analyzer looks at,
+// flattened so the analysis never has to chase nil pointers.
+type NormalizedCommit struct {
+	Repo        string
+	SHA         string
+	Message     string
+	Date        time.Time
+	AuthorName  string
+	AuthorEmail string
+	// AuthorLogin is the GitHub account the author email maps to, if any.
+	AuthorLogin string
+	// CommitterName is who applied the commit, which differs from the
+	// author after squash merges, rebases and cherry-picks.
+	CommitterName  string
+	CommitterEmail string
+	// Verified is set when GitHub verified the commit's GPG/SSH signature.
+	Verified bool
+	// Parents is how many parent commits there are; 0 when unknown.
+	Parents int
+}
+
+func normalizeCommit(repo string, commit *github.RepositoryCommit) NormalizedCommit {
+	c := commit.GetCommit()
+	return NormalizedCommit{
+		Repo:        repo,
+		SHA:         commit.GetSHA(),
+		Message:     c.GetMessage(),
+		Date:        c.GetCommitter().GetDate().Time,
+		AuthorName:  c.GetAuthor().GetName(),
+		AuthorEmail: c.GetAuthor().GetEmail(),
+		AuthorLogin: commit.GetAuthor().GetLogin(),
+
+		CommitterName:  c.GetCommitter().GetName(),
+		CommitterEmail: c.GetCommitter().GetEmail(),
+		Verified:       c.GetVerification().GetVerified(),
+		Parents:        len(commit.Parents),
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "strings"
+
+// CommitBodyStats counts commits that explain themselves with a message
+// body after the subject line.
+type CommitBodyStats struct {
+	WithBody    int     `json:"with_body"`
+	WithoutBody int     `json:"without_body"`
+	BodyRatio   float64 `json:"body_ratio"`
+}
+
+// hasBody reports whether message has a blank line after the subject
+// followed by actual content.
+func hasBody(message string) bool {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	_, body, found := strings.Cut(message, "\n\n")
+	return found && strings.TrimSpace(body) != ""
+}
+
+// detectCommitBodies counts commits with and without a message body. Stock
+// "Initial commit" messages are left out.
+func detectCommitBodies(commits []NormalizedCommit) CommitBodyStats {
+	var stats CommitBodyStats
+	for _, commit := range commits {
+		if isInitialCommit(commit.Message) {
+			continue
+		}
+		if hasBody(commit.Message) {
+			stats.WithBody++
+		} else {
+			stats.WithoutBody++
+		}
+	}
+	stats.BodyRatio = share(stats.WithBody, stats.WithBody+stats.WithoutBody)
+	return stats
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestHasBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    bool
+	}{
+		{"subject only", "fix login", false},
+		{"subject and body", "fix login\n\nThe session cookie expired early.", true},
+		{"CRLF line endings", "fix login\r\n\r\nThe session cookie expired early.", true},
+		{"blank line, no body", "fix login\n\n", false},
+		{"blank line, whitespace body", "fix login\n\n  \n\t\n", false},
+		{"wrapped subject", "fix login\nand logout", false},
+		{"trailer only", "fix login\n\nSigned-off-by: Octo Cat <octo@example.com>", true},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasBody(tt.message); got != tt.want {
+				t.Errorf("hasBody(%q) = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCommitBodies(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     CommitBodyStats
+	}{
+		{"no commits", nil, CommitBodyStats{}},
+		{
+			"mixed",
+			[]string{"fix login\n\nCookie expired early.", "wip", "fix\n\n", "add tests\n\nCovers the expiry."},
+			CommitBodyStats{WithBody: 2, WithoutBody: 2, BodyRatio: 0.5},
+		},
+		{"initial commits are left out", []string{"Initial commit", "add readme"}, CommitBodyStats{WithoutBody: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCommitBodies(messages(tt.messages...)); got != tt.want {
+				t.Errorf("detectCommitBodies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
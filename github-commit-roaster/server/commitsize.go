@@ -0,0 +1,37 @@
+package main
+
+const (
+	// maxCommitSizeDetails caps the GetCommit calls deep mode spends on
+	// commit sizes when no deep scan fetched the diffs already.
+	maxCommitSizeDetails = 10
+	// A commit changing fewer lines than this is a one-liner.
+	tinyCommitLines = 2
+)
+
+// CommitSizeStats estimates commit size from a sample of commits' line
+// stats. Only set in deep mode.
+type CommitSizeStats struct {
+	SampledCommits int     `json:"sampled_commits"`
+	AvgCommitSize  float64 `json:"avg_commit_size"` // lines added plus deleted
+	LargestCommit  int     `json:"largest_commit"`
+	TinyCommits    int     `json:"tiny_commits"`
+}
+
+// commitSizes summarizes the line stats of the fetched commit details.
+func commitSizes(details []commitDetail) CommitSizeStats {
+	var stats CommitSizeStats
+	total := 0
+	for _, detail := range details {
+		size := detail.Additions + detail.Deletions
+		total += size
+		stats.LargestCommit = max(stats.LargestCommit, size)
+		if size < tinyCommitLines {
+			stats.TinyCommits++
+		}
+	}
+	stats.SampledCommits = len(details)
+	if len(details) > 0 {
+		stats.AvgCommitSize = float64(total) / float64(len(details))
+	}
+	return stats
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// sizedCommit is a fakeCommit whose GetCommit stats change the given lines.
+func sizedCommit(message string, hoursAgo, additions, deletions int) *github.RepositoryCommit {
+	commit := fakeCommit("Octo", message, hoursAgo)
+	commit.Stats = &github.CommitStats{Additions: github.Int(additions), Deletions: github.Int(deletions), Total: github.Int(additions + deletions)}
+	return commit
+}
+
+func TestCommitSizes(t *testing.T) {
+	sized := func(lines ...[2]int) []commitDetail {
+		details := make([]commitDetail, len(lines))
+		for i, l := range lines {
+			details[i] = commitDetail{Additions: l[0], Deletions: l[1]}
+		}
+		return details
+	}
+	tests := []struct {
+		name    string
+		details []commitDetail
+		want    CommitSizeStats
+	}{
+		{"nothing sampled", nil, CommitSizeStats{}},
+		{
+			"mixed sizes",
+			sized([2]int{10, 5}, [2]int{1, 0}, [2]int{1200, 200}, [2]int{0, 0}),
+			CommitSizeStats{SampledCommits: 4, AvgCommitSize: 354, LargestCommit: 1400, TinyCommits: 2},
+		},
+		{
+			"two lines isn't tiny",
+			sized([2]int{1, 1}, [2]int{2, 0}),
+			CommitSizeStats{SampledCommits: 2, AvgCommitSize: 2, LargestCommit: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitSizes(tt.details); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commitSizes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitSizeRules(t *testing.T) {
+	tests := []struct {
+		rule string
+		size *CommitSizeStats
+		want bool
+	}{
+		{"giant_commits", nil, false},
+		{"giant_commits", &CommitSizeStats{SampledCommits: 2, AvgCommitSize: 1400}, false},
+		{"giant_commits", &CommitSizeStats{SampledCommits: 3, AvgCommitSize: 499}, false},
+		{"giant_commits", &CommitSizeStats{SampledCommits: 3, AvgCommitSize: 1400}, true},
+		{"one_liners", nil, false},
+		{"one_liners", &CommitSizeStats{SampledCommits: 4, TinyCommits: 4}, false},
+		{"one_liners", &CommitSizeStats{SampledCommits: 10, TinyCommits: 5}, false},
+		{"one_liners", &CommitSizeStats{SampledCommits: 10, TinyCommits: 6}, true},
+	}
+	for _, tt := range tests {
+		rule := findRule(t, tt.rule)
+		if got := rule.Triggered(CommitStats{TotalCommits: 10, CommitSize: tt.size}); got != tt.want {
+			t.Errorf("%s with %+v: triggered = %v, want %v", tt.rule, tt.size, got, tt.want)
+		}
+	}
+	line := findRule(t, "giant_commits").line(CommitStats{CommitSize: &CommitSizeStats{AvgCommitSize: 1400}}, defaultIntensity)
+	if line != "1400-line commits on average — ever heard of small PRs?" {
+		t.Errorf("giant_commits line = %q", line)
+	}
+}
+
+func TestRoastDeepCommitSize(t *testing.T) {
+	var commits []*github.RepositoryCommit
+	for i := range maxCommitSizeDetails + 5 {
+		commits = append(commits, sizedCommit("rewrite everything", i+1, 1000, 400))
+	}
+	tests := []struct {
+		name   string
+		target string
+		want   *CommitSizeStats
+		calls  int
+	}{
+		{"not deep", "/roast?username=octocat", nil, 0},
+		{
+			"deep samples a capped number of commits",
+			"/roast?username=octocat&deep=true",
+			&CommitSizeStats{SampledCommits: maxCommitSizeDetails, AvgCommitSize: 1400, LargestCommit: 1400},
+			maxCommitSizeDetails,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, newFakeGitHub("octocat", commits...))
+			w := doRequest(s.handleRoast, http.MethodGet, "/roast", tt.target, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var body struct {
+				Stats struct {
+					CommitSize *CommitSizeStats `json:"commit_size"`
+				} `json:"stats"`
+				Metadata RoastMetadata `json:"metadata"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(body.Stats.CommitSize, tt.want) {
+				t.Errorf("commit_size = %+v, want %+v", body.Stats.CommitSize, tt.want)
+			}
+			if body.Metadata.DeepScanAPICalls != tt.calls {
+				t.Errorf("deep_scan_api_calls = %d, want %d", body.Metadata.DeepScanAPICalls, tt.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is every setting the server reads at startup. It is loaded once by
+// loadConfig, so handlers never read the environment themselves.
+type Config struct {
+	Port string
+
+	// GitHub access
+	GitHubToken     string
+	GitHubUserAgent string
+	GitHubAPIURL    string // API root, for GitHub Enterprise; "" for api.github.com
+	MaxRetryWait    time.Duration
+	GitHubTimeout   time.Duration
+	GitHubApp       GitHubAppConfig
+
+	// Optional GitHub login, for roasting your own private repos
+	OAuth OAuthConfig
+
+	// Optional files and stores
+	LanguageRoastsFile string
+	SQLitePath         string
+	RedisURL           string
+	CacheTTL           time.Duration
+
+	// Limits for callers without an API key, and defaults for new keys
+	AnonDailyQuota    int
+	AnonRatePerMinute int
+	KeyDailyQuota     int
+	KeyRatePerMinute  int
+	AdminToken        string
+
+	// Background jobs
+	PrefetchConcurrency int
+	PrefetchHotHits     int
+	FeaturedUsers       []string
+	FeaturedHour        int
+
+	WebhookSecret  string
+	WebhookComment bool // post roasts back as commit comments
+
+	// NoColor renders ANSI roasts as plain text for every request
+	NoColor bool
+
+	HTTPReadTimeout       time.Duration
+	HTTPReadHeaderTimeout time.Duration
+	HTTPWriteTimeout      time.Duration
+	HTTPIdleTimeout       time.Duration
+
+	Roast RoastConfig
+}
+
+// loadConfig reads the server settings from the environment and config
+// file, falling back to defaults for anything unset.
+func loadConfig() Config {
+	cfg := Config{
+		Port:               configuredPort(),
+		GitHubToken:        getenv("GITHUB_TOKEN"),
+		GitHubUserAgent:    getenv("GITHUB_USER_AGENT"),
+		GitHubAPIURL:       getenv("GITHUB_API_URL"),
+		MaxRetryWait:       envDuration("GITHUB_MAX_RETRY_WAIT", defaultMaxRetryWait),
+		GitHubTimeout:      envDuration("GITHUB_API_TIMEOUT", defaultGitHubTimeout),
+		LanguageRoastsFile: getenv("LANGUAGE_ROASTS_FILE"),
+		SQLitePath:         getenv("SQLITE_PATH"),
+		RedisURL:           getenv("REDIS_URL"),
+		CacheTTL:           configuredCacheTTL(),
+
+		GitHubApp: GitHubAppConfig{
+			AppID:          getenv("GITHUB_APP_ID"),
+			InstallationID: getenv("GITHUB_APP_INSTALLATION_ID"),
+			PrivateKeyPath: getenv("GITHUB_APP_PRIVATE_KEY_PATH"),
+		},
+		OAuth: OAuthConfig{
+			ClientID:        getenv("GITHUB_OAUTH_CLIENT_ID"),
+			ClientSecret:    getenv("GITHUB_OAUTH_CLIENT_SECRET"),
+			RedirectURL:     getenv("GITHUB_OAUTH_REDIRECT_URL"),
+			SuccessRedirect: getenv("OAUTH_SUCCESS_REDIRECT"),
+			SessionSecret:   getenv("SESSION_SECRET"),
+		},
+
+		AnonDailyQuota:      envInt("ANON_DAILY_QUOTA", defaultAnonDailyQuota),
+		AnonRatePerMinute:   envInt("ANON_RATE_PER_MINUTE", 10),
+		KeyDailyQuota:       envInt("DEFAULT_KEY_DAILY_QUOTA", 1000),
+		KeyRatePerMinute:    envInt("DEFAULT_KEY_RATE_PER_MINUTE", 60),
+		AdminToken:          getenv("ADMIN_TOKEN"),
+		PrefetchConcurrency: envInt("PREFETCH_CONCURRENCY", 2),
+		PrefetchHotHits:     envInt("PREFETCH_HOT_HITS", 5),
+		FeaturedUsers:       splitList(getenv("FEATURED_USERS")),
+		FeaturedHour:        envInt("FEATURED_HOUR", 4),
+		WebhookSecret:       getenv("GITHUB_WEBHOOK_SECRET"),
+		WebhookComment:      getenv("WEBHOOK_COMMENT") == "true",
+		// NO_COLOR only has to be set, whatever its value
+		NoColor: getenv("NO_COLOR") != "",
+
+		// Bounded timeouts so slow clients can't hold connections open
+		// forever; writes get long enough for a slow round of GitHub calls
+		HTTPReadTimeout:       envDuration("HTTP_READ_TIMEOUT", 10*time.Second),
+		HTTPReadHeaderTimeout: envDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		HTTPWriteTimeout:      envDuration("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		HTTPIdleTimeout:       envDuration("HTTP_IDLE_TIMEOUT", 120*time.Second),
+
+		Roast: loadRoastConfig(),
+	}
+	if cfg.GitHubTimeout < minGitHubTimeout {
+		slog.Warn("GITHUB_API_TIMEOUT below the minimum", "using", minGitHubTimeout)
+		cfg.GitHubTimeout = minGitHubTimeout
+	}
+	return cfg
+}
+
+// configuredPort is PORT, or defaultPort when unset. The -health check
+// reads it alone rather than loading the whole config.
+func configuredPort() string {
+	if port := getenv("PORT"); port != "" {
+		return port
+	}
+	return defaultPort
+}
+
+// configuredCacheTTL is CACHE_TTL_SECONDS when set, else the
+// ROAST_CACHE_TTL duration, else ten minutes.
+func configuredCacheTTL() time.Duration {
+	if seconds := envInt("CACHE_TTL_SECONDS", 0); seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return envDuration("ROAST_CACHE_TTL", 10*time.Minute)
+}
+
+// GitHubAppConfig identifies a GitHub App installation to authenticate as.
+// All fields are empty when app auth isn't configured.
+type GitHubAppConfig struct {
+	AppID          string
+	InstallationID string
+	PrivateKeyPath string
+}
+
+// OAuthConfig holds the GitHub OAuth app used for login. Login is disabled
+// when ClientID and ClientSecret are both empty.
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// SuccessRedirect is where users land after logging in; "/" when empty
+	SuccessRedirect string
+	// SessionSecret signs session cookies and encrypts stored tokens
+	SessionSecret string
+}
+
+// RoastConfig holds server-wide analysis settings.
+type RoastConfig struct {
+	// BotPatterns are case-insensitive substrings that mark a commit as
+	// automated when found in its author name, author email or message.
+	BotPatterns []string
+
+	// SwearWords are matched as whole words in commit messages.
+	SwearWords []string
+
+	// Weights are the default severity weights, overridable per request.
+	Weights Weights
+
+	// MaxCommits caps the commits analyzed per roast; beyond it a random
+	// sample is analyzed instead.
+	MaxCommits int
+
+	// MaxRoastLines keeps only the heaviest roast lines; 0 keeps them all.
+	// Requests can override it with ?max_lines=.
+	MaxRoastLines int
+
+	// LateNight is the window of hours commits count as late-night in.
+	LateNight HourWindow
+
+	// WorkHours is the window of hours commits count as on the clock in.
+	WorkHours HourWindow
+
+	// ShoutRatio is the share of upper-case letters above which a commit
+	// subject counts as shouting.
+	ShoutRatio float64
+
+	// A repo is stale, then dead, after this many days without a push.
+	StaleAfterDays int
+	DeadAfterDays  int
+
+	// Stopwords are left out of ?words=true counts: defaultStopwords plus
+	// any listed in STOPWORDS.
+	Stopwords map[string]bool
+
+	// GitignorePatterns is the checklist a deep-mode .gitignore is held to.
+	GitignorePatterns []string
+}
+
+// defaultMaxRoastLines keeps a roast to a readable handful of lines.
+const defaultMaxRoastLines = 5
+
+// defaultAnonDailyQuota is the daily quota every caller without an API key
+// shares. Batch, team and prefetch requests cost one per named user, so a
+// full 10-user batch spends a tenth of it.
+const defaultAnonDailyQuota = 100
+
+var defaultBotPatterns = []string{
+	"[bot]",
+	"chore(deps): bump",
+	"automated update by",
+	"update dependency",
+}
+
+// loadRoastConfig reads analysis settings from the environment, falling back
+// to defaults for anything unset.
+func loadRoastConfig() RoastConfig {
+	cfg := RoastConfig{
+		BotPatterns: defaultBotPatterns,
+		SwearWords:  englishProfanity,
+		Weights:     defaultWeights(),
+		LateNight:   defaultLateNight,
+		WorkHours:   defaultWorkHours,
+		MaxCommits:  defaultMaxCommits,
+
+		GitignorePatterns: defaultGitignorePatterns,
+	}
+	if n := envInt("MAX_COMMITS", defaultMaxCommits); n > 0 {
+		cfg.MaxCommits = n
+	}
+	cfg.MaxRoastLines = max(envInt("MAX_ROAST_LINES", defaultMaxRoastLines), 0)
+
+	cfg.ShoutRatio = envFloat("SHOUT_UPPERCASE_RATIO", defaultShoutRatio)
+	if cfg.ShoutRatio <= 0 || cfg.ShoutRatio >= 1 {
+		slog.Warn("SHOUT_UPPERCASE_RATIO must be between 0 and 1", "using", defaultShoutRatio)
+		cfg.ShoutRatio = defaultShoutRatio
+	}
+
+	cfg.StaleAfterDays = envInt("STALE_REPO_DAYS", 180)
+	cfg.DeadAfterDays = envInt("DEAD_REPO_DAYS", 730)
+
+	// LATE_NIGHT_START is inclusive and LATE_NIGHT_END exclusive
+	cfg.LateNight = HourWindow{
+		Start: envInt("LATE_NIGHT_START", defaultLateNight.Start),
+		End:   envInt("LATE_NIGHT_END", defaultLateNight.End),
+	}
+	if !cfg.LateNight.valid() {
+		slog.Warn("Invalid late-night window",
+			"window", fmt.Sprintf("%d-%d", cfg.LateNight.Start, cfg.LateNight.End),
+			"using", fmt.Sprintf("%d-%d", defaultLateNight.Start, defaultLateNight.End))
+		cfg.LateNight = defaultLateNight
+	}
+
+	// WORK_HOURS_START is inclusive and WORK_HOURS_END exclusive
+	cfg.WorkHours = HourWindow{
+		Start: envInt("WORK_HOURS_START", defaultWorkHours.Start),
+		End:   envInt("WORK_HOURS_END", defaultWorkHours.End),
+	}
+	if !cfg.WorkHours.valid() {
+		slog.Warn("Invalid work-hours window",
+			"window", fmt.Sprintf("%d-%d", cfg.WorkHours.Start, cfg.WorkHours.End),
+			"using", fmt.Sprintf("%d-%d", defaultWorkHours.Start, defaultWorkHours.End))
+		cfg.WorkHours = defaultWorkHours
+	}
+	cfg.Stopwords = stopwordSet(splitList(getenv("STOPWORDS")))
+
+	if patterns := splitList(getenv("BOT_PATTERNS")); len(patterns) > 0 {
+		cfg.BotPatterns = patterns
+	}
+	if patterns := splitList(getenv("GITIGNORE_PATTERNS")); len(patterns) > 0 {
+		cfg.GitignorePatterns = patterns
+	}
+
+	// ROAST_WEIGHTS=messages:3,latenight:0 uses the ?weights= syntax
+	if value := getenv("ROAST_WEIGHTS"); value != "" {
+		weights, err := parseWeights(value, cfg.Weights)
+		if err != nil {
+			slog.Warn("Ignoring ROAST_WEIGHTS", "error", err)
+		} else {
+			cfg.Weights = weights
+		}
+	}
+
+	// LANG_PROFANITY=de,es enables extra word lists from PROFANITY_DIR
+	if langs := splitList(getenv("LANG_PROFANITY")); len(langs) > 0 {
+		dir := getenv("PROFANITY_DIR")
+		if dir == "" {
+			dir = "profanity"
+		}
+		words, err := loadProfanityLists(dir, langs)
+		if err != nil {
+			slog.Warn("Could not load profanity lists", "error", err)
+		} else {
+			cfg.SwearWords = append(append([]string{}, englishProfanity...), words...)
+		}
+	}
+	return cfg
+}
+
+// envInt reads an integer env var, returning def when unset or invalid.
+func envInt(name string, def int) int {
+	value := getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid setting", "name", name, "value", value, "using", def)
+		return def
+	}
+	return n
+}
+
+// envFloat reads a decimal env var, returning def when unset or invalid.
+func envFloat(name string, def float64) float64 {
+	value := getenv(name)
+	if value == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Warn("Invalid setting", "name", name, "value", value, "using", def)
+		return def
+	}
+	return f
+}
+
+// envDuration reads a duration env var such as "10m", returning def when
+// unset or invalid.
+func envDuration(name string, def time.Duration) time.Duration {
+	value := getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Invalid setting", "name", name, "value", value, "using", def)
+		return def
+	}
+	return d
+}
+
+// splitList splits a comma-separated env value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
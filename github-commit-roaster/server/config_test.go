@@ -0,0 +1,161 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHTTPTimeoutsConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		read  time.Duration
+		write time.Duration
+		idle  time.Duration
+	}{
+		{"defaults", nil, 10 * time.Second, 60 * time.Second, 120 * time.Second},
+		{
+			"from the environment",
+			map[string]string{"HTTP_READ_TIMEOUT": "3s", "HTTP_WRITE_TIMEOUT": "1m30s", "HTTP_IDLE_TIMEOUT": "500ms"},
+			3 * time.Second, 90 * time.Second, 500 * time.Millisecond,
+		},
+		{"unparseable falls back", map[string]string{"HTTP_WRITE_TIMEOUT": "60"}, 10 * time.Second, 60 * time.Second, 120 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			cfg := loadConfig()
+			if cfg.HTTPReadTimeout != tt.read || cfg.HTTPWriteTimeout != tt.write || cfg.HTTPIdleTimeout != tt.idle {
+				t.Errorf("timeouts = %s, %s, %s, want %s, %s, %s",
+					cfg.HTTPReadTimeout, cfg.HTTPWriteTimeout, cfg.HTTPIdleTimeout, tt.read, tt.write, tt.idle)
+			}
+		})
+	}
+}
+
+func TestWriteTimeoutCutsSlowHandlers(t *testing.T) {
+	t.Setenv("HTTP_WRITE_TIMEOUT", "1ms")
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("too late"))
+	})
+	api := httptest.NewUnstartedServer(slow)
+	api.Config = newHTTPServer(loadConfig(), slow)
+	api.Start()
+	defer api.Close()
+
+	resp, err := http.Get(api.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("slow handler answered %d, want a connection error", resp.StatusCode)
+	}
+}
+
+func TestGitHubTimeoutConfig(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", defaultGitHubTimeout},
+		{"45s", 45 * time.Second},
+		{"5s", minGitHubTimeout},
+		{"1s", minGitHubTimeout},
+		{"soon", defaultGitHubTimeout},
+	}
+	for _, tt := range tests {
+		t.Setenv("GITHUB_API_TIMEOUT", tt.value)
+		if got := loadConfig().GitHubTimeout; got != tt.want {
+			t.Errorf("GITHUB_API_TIMEOUT=%q: timeout = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+// fakeEnv clears every setting and then sets env, so a test sees only what
+// it asks for whatever the real environment holds.
+func fakeEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for name := range knownSettings {
+		t.Setenv(name, "")
+	}
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	// configSummary is the part of Config each case pins down
+	type configSummary struct {
+		Port, GitHubToken, AppID, OAuthClientID string
+		CacheTTL                                time.Duration
+		AnonDailyQuota, FeaturedHour            int
+		FeaturedUsers                           []string
+		WebhookComment, NoColor                 bool
+		MaxCommits, StaleAfterDays              int
+	}
+	summarize := func(cfg Config) configSummary {
+		return configSummary{
+			Port: cfg.Port, GitHubToken: cfg.GitHubToken, AppID: cfg.GitHubApp.AppID, OAuthClientID: cfg.OAuth.ClientID,
+			CacheTTL: cfg.CacheTTL, AnonDailyQuota: cfg.AnonDailyQuota, FeaturedHour: cfg.FeaturedHour,
+			FeaturedUsers: cfg.FeaturedUsers, WebhookComment: cfg.WebhookComment, NoColor: cfg.NoColor,
+			MaxCommits: cfg.Roast.MaxCommits, StaleAfterDays: cfg.Roast.StaleAfterDays,
+		}
+	}
+	tests := []struct {
+		name string
+		env  map[string]string
+		want configSummary
+	}{
+		{
+			"empty environment",
+			nil,
+			configSummary{
+				Port: defaultPort, CacheTTL: 10 * time.Minute, AnonDailyQuota: defaultAnonDailyQuota, FeaturedHour: 4,
+				MaxCommits: defaultMaxCommits, StaleAfterDays: 180,
+			},
+		},
+		{
+			"every kind of setting",
+			map[string]string{
+				"PORT":                   "9000",
+				"GITHUB_TOKEN":           "ghp_test",
+				"GITHUB_APP_ID":          "42",
+				"GITHUB_OAUTH_CLIENT_ID": "client",
+				"ROAST_CACHE_TTL":        "90s",
+				"ANON_DAILY_QUOTA":       "7",
+				"FEATURED_HOUR":          "12",
+				"FEATURED_USERS":         "octocat, hubot",
+				"WEBHOOK_COMMENT":        "true",
+				"NO_COLOR":               "0",
+				"MAX_COMMITS":            "50",
+				"STALE_REPO_DAYS":        "30",
+			},
+			configSummary{
+				Port: "9000", GitHubToken: "ghp_test", AppID: "42", OAuthClientID: "client",
+				CacheTTL: 90 * time.Second, AnonDailyQuota: 7, FeaturedHour: 12,
+				FeaturedUsers: []string{"octocat", "hubot"}, WebhookComment: true, NoColor: true,
+				MaxCommits: 50, StaleAfterDays: 30,
+			},
+		},
+		{
+			"unusable values fall back",
+			map[string]string{"ANON_DAILY_QUOTA": "lots", "MAX_COMMITS": "-1", "ROAST_CACHE_TTL": "forever", "WEBHOOK_COMMENT": "yes"},
+			configSummary{
+				Port: defaultPort, CacheTTL: 10 * time.Minute, AnonDailyQuota: defaultAnonDailyQuota, FeaturedHour: 4,
+				MaxCommits: defaultMaxCommits, StaleAfterDays: 180,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeEnv(t, tt.env)
+			if got := summarize(loadConfig()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
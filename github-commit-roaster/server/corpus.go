@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minCorpusUsers is how many users must have been analyzed today before
+// percentiles mean anything.
+const minCorpusUsers = 10
+
+// corpusMetrics are the per-user ratios ranked against the corpus.
+func corpusMetrics(stats CommitStats) map[string]float64 {
+	total := float64(stats.TotalCommits)
+	return map[string]float64{
+		"late_night_ratio": float64(stats.LateNightCommits) / total,
+		"fix_ratio":        float64(stats.FixCommits) / total,
+		"merge_ratio":      float64(stats.MergeCommits) / total,
+		"generic_ratio":    float64(stats.GenericMessages) / total,
+		"swear_rate":       float64(stats.SwearWords) / total,
+		"weekend_ratio":    float64(stats.WeekendCommits) / total,
+		"severity":         float64(stats.Severity),
+	}
+}
+
+// CorpusStats keeps the metrics of every user analyzed today (UTC), so a
+// roast can say where a user ranks. Each user counts once, with their
+// latest roast. Like the memory cache it is per instance and starts empty.
+type CorpusStats struct {
+	mu    sync.Mutex
+	day   string
+	users map[string]map[string]float64
+	now   func() time.Time
+}
+
+func newCorpusStats() *CorpusStats {
+	return &CorpusStats{users: make(map[string]map[string]float64), now: time.Now}
+}
+
+// rollover starts a new corpus when the day has changed. The caller holds
+// c.mu.
+func (c *CorpusStats) rollover() {
+	if today := c.now().UTC().Format(time.DateOnly); today != c.day {
+		c.day = today
+		c.users = make(map[string]map[string]float64)
+	}
+}
+
+// add records username's metrics. Users without commits have no ratios
+// and are left out.
+func (c *CorpusStats) add(username string, stats CommitStats) {
+	if stats.TotalCommits == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollover()
+	c.users[strings.ToLower(username)] = corpusMetrics(stats)
+}
+
+// percentiles ranks stats against today's corpus: for each metric, the
+// percentage of users at or below its value. It returns nil until
+// minCorpusUsers have been analyzed.
+func (c *CorpusStats) percentiles(stats CommitStats) map[string]float64 {
+	if stats.TotalCommits == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	c.rollover()
+	distributions := make(map[string][]float64)
+	for _, metrics := range c.users {
+		for metric, value := range metrics {
+			distributions[metric] = append(distributions[metric], value)
+		}
+	}
+	users := len(c.users)
+	c.mu.Unlock()
+	if users < minCorpusUsers {
+		return nil
+	}
+
+	ranks := make(map[string]float64, len(distributions))
+	for metric, value := range corpusMetrics(stats) {
+		values := distributions[metric]
+		sort.Float64s(values)
+		ranks[metric] = percentileRank(values, value)
+	}
+	return ranks
+}
+
+// percentileRank is the percentage of sorted values at or below value,
+// rounded to a whole number.
+func percentileRank(sorted []float64, value float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	atOrBelow := sort.Search(len(sorted), func(i int) bool { return sorted[i] > value })
+	return math.Round(float64(atOrBelow) / float64(len(sorted)) * 100)
+}
+
+// ordinal writes n as "1st", "22nd", "94th".
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
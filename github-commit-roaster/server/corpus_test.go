@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPercentileRank(t *testing.T) {
+	// 1 to 100, so every value is its own percentile
+	hundred := make([]float64, 100)
+	for i := range hundred {
+		hundred[i] = float64(i + 1)
+	}
+	tests := []struct {
+		name   string
+		sorted []float64
+		value  float64
+		want   float64
+	}{
+		{"lowest", hundred, 1, 1},
+		{"below everyone", hundred, 0, 0},
+		{"94th", hundred, 94, 94},
+		{"between values", hundred, 50.5, 50},
+		{"highest", hundred, 100, 100},
+		{"above everyone", hundred, 1000, 100},
+		{"ties count as at or below", []float64{1, 2, 2, 2, 3}, 2, 80},
+		{"all equal", []float64{0.5, 0.5, 0.5}, 0.5, 100},
+		{"rounded", []float64{1, 2, 3}, 1, 33},
+		{"empty corpus", nil, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentileRank(tt.sorted, tt.value); got != tt.want {
+				t.Errorf("percentileRank(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := map[int]string{0: "0th", 1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 22: "22nd", 94: "94th", 100: "100th", 101: "101st", 111: "111th"}
+	for n, want := range tests {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// lateNightStats is a user with late late-night commits out of 100.
+func lateNightStats(late int) CommitStats {
+	return CommitStats{TotalCommits: 100, LateNightCommits: late}
+}
+
+func TestCorpusPercentiles(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := newCorpusStats()
+	c.now = func() time.Time { return now }
+
+	// A known distribution: late-night ratios of 0.01 to 1.00, added in
+	// random order
+	for _, i := range rand.Perm(100) {
+		if c.percentiles(lateNightStats(50)) != nil && len(c.users) < minCorpusUsers {
+			t.Fatalf("percentiles with only %d users", len(c.users))
+		}
+		c.add(fmt.Sprintf("user%d", i+1), lateNightStats(i+1))
+	}
+	c.add("Nobody", CommitStats{})
+	if len(c.users) != 100 {
+		t.Fatalf("%d users in the corpus, want 100", len(c.users))
+	}
+	for _, late := range []int{1, 25, 50, 94, 100} {
+		ranks := c.percentiles(lateNightStats(late))
+		if got := ranks["late_night_ratio"]; got != float64(late) {
+			t.Errorf("%d late-night commits: percentile %v, want %d", late, got, late)
+		}
+	}
+	if c.percentiles(CommitStats{}) != nil {
+		t.Error("percentiles for a user without commits")
+	}
+
+	// A user counts once, with their latest roast
+	c.add("USER1", lateNightStats(100))
+	if got := c.percentiles(lateNightStats(99))["late_night_ratio"]; got != 98 {
+		t.Errorf("after user1 re-roasted: percentile %v, want 98", got)
+	}
+
+	// A new day starts an empty corpus
+	now = now.Add(24 * time.Hour)
+	if ranks := c.percentiles(lateNightStats(94)); ranks != nil {
+		t.Errorf("percentiles the next day = %v, want none", ranks)
+	}
+}
+
+func TestCorpusMetricsSorted(t *testing.T) {
+	// Every metric the corpus ranks is reported for each user
+	c := newCorpusStats()
+	for i := range minCorpusUsers {
+		c.add(fmt.Sprint(i), CommitStats{TotalCommits: 10, FixCommits: i, Severity: i * 10})
+	}
+	ranks := c.percentiles(CommitStats{TotalCommits: 10, FixCommits: 5, Severity: 50})
+	var metrics []string
+	for metric := range ranks {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	want := []string{"fix_ratio", "generic_ratio", "late_night_ratio", "merge_ratio", "severity", "swear_rate", "weekend_ratio"}
+	if fmt.Sprint(metrics) != fmt.Sprint(want) {
+		t.Errorf("metrics = %v, want %v", metrics, want)
+	}
+	if ranks["fix_ratio"] != 60 || ranks["severity"] != 60 {
+		t.Errorf("fix_ratio %v, severity %v, want 60 each", ranks["fix_ratio"], ranks["severity"])
+	}
+}
+
+func TestSleeplessPercentileRule(t *testing.T) {
+	rule := findRule(t, "sleepless_percentile")
+	tests := []struct {
+		name        string
+		late        int
+		percentiles map[string]float64
+		want        bool
+	}{
+		{"no corpus yet", 80, nil, false},
+		{"89th", 80, map[string]float64{"late_night_ratio": 89}, false},
+		{"94th", 80, map[string]float64{"late_night_ratio": 94}, true},
+		{"top of a corpus that never stays up", 0, map[string]float64{"late_night_ratio": 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := lateNightStats(tt.late)
+			stats.Percentiles = tt.percentiles
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Fatalf("triggered = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				want := "Your late-night ratio puts you in the 94th percentile of sleep-deprived developers."
+				if line := rule.line(stats, defaultIntensity); line != want {
+					t.Errorf("line = %q", line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import "regexp"
+
+// debtMarkerPattern matches TODO-style markers as whole words. Only the
+// conventional upper-case spelling counts, so "todo app" isn't debt.
+var debtMarkerPattern = regexp.MustCompile(`\b(TODO|FIXME|HACK|XXX)\b`)
+
+// DebtMarkerStats counts technical-debt markers committed.
+type DebtMarkerStats struct {
+	Count    int            `json:"count"` // in commit messages
+	Commits  int            `json:"commits"`
+	ByMarker map[string]int `json:"by_marker"`
+	// Markers in lines added by the scanned diffs; only set by a deep scan
+	InDiffs int `json:"in_diffs"`
+}
+
+// total is every marker found, in messages and diffs.
+func (d DebtMarkerStats) total() int {
+	return d.Count + d.InDiffs
+}
+
+// detectDebtMarkers counts TODO, FIXME, HACK and XXX in commit messages.
+func detectDebtMarkers(commits []NormalizedCommit) DebtMarkerStats {
+	stats := DebtMarkerStats{ByMarker: map[string]int{}}
+	for _, commit := range commits {
+		markers := debtMarkerPattern.FindAllString(commit.Message, -1)
+		if len(markers) == 0 {
+			continue
+		}
+		stats.Commits++
+		stats.Count += len(markers)
+		for _, marker := range markers {
+			stats.ByMarker[marker]++
+		}
+	}
+	return stats
+}
+
+// scanPatchDebtMarkers counts markers in the lines added by the first
+// maxPatchScanCommits commit diffs.
+func scanPatchDebtMarkers(details []commitDetail) int {
+	count := 0
+	for i, detail := range details {
+		if i == maxPatchScanCommits {
+			break
+		}
+		for _, file := range detail.Files {
+			for _, line := range addedLines(file.GetPatch()) {
+				count += len(debtMarkerPattern.FindAllStringIndex(line, -1))
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectDebtMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     DebtMarkerStats
+	}{
+		{"no commits", nil, DebtMarkerStats{ByMarker: map[string]int{}}},
+		{
+			"marker-laden",
+			[]string{"TODO: handle errors, FIXME later", "HACK around the XXX bug", "add parser", "TODO TODO TODO"},
+			DebtMarkerStats{Count: 7, Commits: 3, ByMarker: map[string]int{"TODO": 4, "FIXME": 1, "HACK": 1, "XXX": 1}},
+		},
+		{
+			"whole words only",
+			[]string{"remove TODOS", "HACKATHON entry", "TODO_LIST cleanup", "XXXL shirts"},
+			DebtMarkerStats{ByMarker: map[string]int{}},
+		},
+		{
+			"lower case is prose",
+			[]string{"add todo app", "fixme: later", "quick hack"},
+			DebtMarkerStats{ByMarker: map[string]int{}},
+		},
+		{
+			"punctuation around markers",
+			[]string{"(TODO) wire up auth", "leave a FIXME.", "// HACK:"},
+			DebtMarkerStats{Count: 3, Commits: 3, ByMarker: map[string]int{"TODO": 1, "FIXME": 1, "HACK": 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDebtMarkers(messages(tt.messages...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectDebtMarkers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanPatchDebtMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		details []commitDetail
+		want    int
+	}{
+		{"nothing scanned", nil, 0},
+		{"added lines only", []commitDetail{patchDetail("a", "@@ -1 +1 @@\n-// TODO: old\n+// FIXME: new\n+// HACK XXX")}, 3},
+		{"context lines don't count", []commitDetail{patchDetail("a", "@@ -1,2 +1,2 @@\n // TODO kept\n+fixed()")}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scanPatchDebtMarkers(tt.details); got != tt.want {
+				t.Errorf("scanPatchDebtMarkers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebtMarkersRule(t *testing.T) {
+	rule := findRule(t, "debt_markers")
+	tests := []struct {
+		name    string
+		markers DebtMarkerStats
+		want    bool
+	}{
+		{"a few", DebtMarkerStats{Count: 4}, false},
+		{"in messages", DebtMarkerStats{Count: 23}, true},
+		{"messages and diffs add up", DebtMarkerStats{Count: 2, InDiffs: 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, DebtMarkers: tt.markers}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Fatalf("Triggered() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	stats := CommitStats{TotalCommits: 10, DebtMarkers: DebtMarkerStats{Count: 23}}
+	if line := rule.line(stats, 3); !strings.HasPrefix(line, "23 TODOs committed — the debt collector") {
+		t.Errorf("line = %q", line)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RoastDiff describes how a user changed between two analyses.
+type RoastDiff struct {
+	PreviousScore  int      `json:"previous_score"`
+	CurrentScore   int      `json:"current_score"`
+	ScoreDelta     int      `json:"score_delta"`
+	NewlyTriggered []string `json:"newly_triggered"`
+	Cleared        []string `json:"cleared"`
+	MetaRoast      string   `json:"meta_roast"`
+}
+
+// metricTrend phrases a change in one pattern for the meta-roast.
+type metricTrend struct {
+	improved string // takes the percentage drop
+	subject  string // what is "thriving" when it got worse
+	verb     string
+}
+
+var metricTrends = map[string]metricTrend{
+	"late_night":       {"you commit after dark %d%% less", "the late-night commits", "are"},
+	"fixes":            {"you ship %d%% fewer fix commits", "the fix commits", "are"},
+	"merges":           {"you merge %d%% less", "the merge commits", "are"},
+	"generic_messages": {"your messages are %d%% less generic", "the generic messages", "are"},
+	"swearing":         {"you swear %d%% less", "the swearing", "is"},
+	"automation":       {"the bots do %d%% less of your work", "the bot commits", "are"},
+}
+
+// triggeredRules returns the IDs of every rule that fires for stats.
+func triggeredRules(stats CommitStats) []string {
+	ids := []string{}
+	if stats.TotalCommits == 0 {
+		return ids
+	}
+	for _, rule := range roastRules {
+		if rule.Triggered(stats) {
+			ids = append(ids, rule.ID)
+		}
+	}
+	return ids
+}
+
+// diffStats compares two analyses of the same user, both scored with
+// weights.
+func diffStats(prev, curr CommitStats, weights Weights) RoastDiff {
+	diff := RoastDiff{
+		PreviousScore:  weightedSeverity(prev, weights),
+		CurrentScore:   weightedSeverity(curr, weights),
+		NewlyTriggered: []string{},
+		Cleared:        []string{},
+	}
+	diff.ScoreDelta = diff.CurrentScore - diff.PreviousScore
+
+	before := make(map[string]bool)
+	for _, id := range triggeredRules(prev) {
+		before[id] = true
+	}
+	for _, id := range triggeredRules(curr) {
+		if before[id] {
+			delete(before, id)
+		} else {
+			diff.NewlyTriggered = append(diff.NewlyTriggered, id)
+		}
+	}
+	for id := range before {
+		diff.Cleared = append(diff.Cleared, id)
+	}
+	sort.Strings(diff.Cleared)
+
+	diff.MetaRoast = metaRoast(patternScores(prev), patternScores(curr))
+	return diff
+}
+
+// metaRoast comments on the biggest improvement and the biggest regression
+// between two sets of pattern scores.
+func metaRoast(prev, curr map[string]float64) string {
+	bestMetric, bestDrop := "", 0.0
+	worstMetric, worstRise := "", 0.0
+	for metric := range metricTrends {
+		before, after := prev[metric], curr[metric]
+		if before > 0 && after < before {
+			if drop := (before - after) / before; drop > bestDrop || (drop == bestDrop && metric < bestMetric) {
+				bestMetric, bestDrop = metric, drop
+			}
+		}
+		if rise := after - before; rise > worstRise || (rise == worstRise && rise > 0 && metric < worstMetric) {
+			worstMetric, worstRise = metric, rise
+		}
+	}
+
+	var improvement string
+	if bestMetric != "" {
+		improvement = fmt.Sprintf(metricTrends[bestMetric].improved, int(math.Round(bestDrop*100)))
+	}
+
+	switch {
+	case bestMetric != "" && worstMetric != "":
+		t := metricTrends[worstMetric]
+		return fmt.Sprintf("Congratulations, %s; %s, however, %s thriving.", improvement, t.subject, t.verb)
+	case bestMetric != "":
+		return fmt.Sprintf("Congratulations, %s. Don't let it go to your head.", improvement)
+	case worstMetric != "":
+		t := metricTrends[worstMetric]
+		return fmt.Sprintf("Nothing improved, and %s %s thriving.", t.subject, t.verb)
+	default:
+		return "Exactly as bad as last time. Consistency is a virtue, I suppose."
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffStats(t *testing.T) {
+	clean := CommitStats{TotalCommits: 10}
+	fixer := CommitStats{TotalCommits: 10, FixCommits: 8}
+	owl := CommitStats{TotalCommits: 10, LateNightCommits: 8}
+	tests := []struct {
+		name        string
+		prev, curr  CommitStats
+		weights     Weights
+		wantDelta   int
+		wantNew     []string
+		wantCleared []string
+	}{
+		{"unchanged", fixer, fixer, nil, 0, []string{}, []string{}},
+		{"got worse", clean, fixer, nil, weightedSeverity(fixer, nil), []string{"fixes"}, []string{}},
+		{"swapped habits", fixer, owl, nil, 0, []string{"late_night"}, []string{"fixes"}},
+		// With fixes weighted out, only the late nights move the score
+		{"weighted", fixer, owl, Weights{"fixes": 0, "latenight": 1}, 80, []string{"late_night"}, []string{"fixes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffStats(tt.prev, tt.curr, tt.weights)
+			if diff.ScoreDelta != tt.wantDelta || diff.CurrentScore-diff.PreviousScore != diff.ScoreDelta {
+				t.Errorf("scores %d -> %d (delta %d), want delta %d",
+					diff.PreviousScore, diff.CurrentScore, diff.ScoreDelta, tt.wantDelta)
+			}
+			if !reflect.DeepEqual(diff.NewlyTriggered, tt.wantNew) || !reflect.DeepEqual(diff.Cleared, tt.wantCleared) {
+				t.Errorf("newly triggered %q, cleared %q; want %q, %q",
+					diff.NewlyTriggered, diff.Cleared, tt.wantNew, tt.wantCleared)
+			}
+			if diff.MetaRoast == "" {
+				t.Error("no meta-roast")
+			}
+		})
+	}
+}
+
+func TestMetaRoast(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev, curr map[string]float64
+		want       string
+	}{
+		{"no change", map[string]float64{"fixes": 1}, map[string]float64{"fixes": 1},
+			"Exactly as bad as last time. Consistency is a virtue, I suppose."},
+		{"improved", map[string]float64{"fixes": 1}, map[string]float64{"fixes": 0.25},
+			"Congratulations, you ship 75% fewer fix commits. Don't let it go to your head."},
+		{"regressed", map[string]float64{"swearing": 0}, map[string]float64{"swearing": 2},
+			"Nothing improved, and the swearing is thriving."},
+		{"both", map[string]float64{"late_night": 2, "merges": 0}, map[string]float64{"late_night": 1, "merges": 1},
+			"Congratulations, you commit after dark 50% less; the merge commits, however, are thriving."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metaRoast(tt.prev, tt.curr); got != tt.want {
+				t.Errorf("metaRoast() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestHistoryMatchesOptions(t *testing.T) {
+	store, _ := openTestStore(t)
+	stores := map[string]historyStore{"memory": newHistoryStore(nil), "sqlite": newHistoryStore(store)}
+	heavy := Weights{"fixes": 3}
+	for name, history := range stores {
+		t.Run(name, func(t *testing.T) {
+			start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+			records := []HistoryRecord{
+				{Username: "octocat", Stats: CommitStats{TotalCommits: 1}, Days: 30, Weights: defaultWeights(), CreatedAt: start},
+				{Username: "octocat", Stats: CommitStats{TotalCommits: 2}, Days: 7, Weights: defaultWeights(), CreatedAt: start.Add(time.Hour)},
+				{Username: "octocat", Stats: CommitStats{TotalCommits: 3}, Days: 30, Weights: heavy, CreatedAt: start.Add(2 * time.Hour)},
+			}
+			for _, rec := range records {
+				if err := history.AppendHistory(rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tests := []struct {
+				days    int
+				weights Weights
+				want    int // TotalCommits of the matching record; 0 for none
+			}{
+				{30, defaultWeights(), 1},
+				{7, defaultWeights(), 2},
+				{30, heavy, 3},
+				{7, heavy, 0},
+				{90, defaultWeights(), 0},
+			}
+			for _, tt := range tests {
+				rec, found, err := history.LatestHistory("octocat", tt.days, tt.weights)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if found != (tt.want != 0) || rec.Stats.TotalCommits != tt.want {
+					t.Errorf("LatestHistory(%d, %v) = %d commits, found %v; want %d",
+						tt.days, tt.weights, rec.Stats.TotalCommits, found, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRoastDiffUsername(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2)))
+	tests := []struct {
+		username string
+		status   int
+	}{
+		{"-octocat", http.StatusBadRequest},
+		{"octo_cat", http.StatusBadRequest},
+		{strings.Repeat("a", maxUsernameLength+1), http.StatusBadRequest},
+		{"octocat", http.StatusNotFound}, // valid, but never roasted
+	}
+	for _, tt := range tests {
+		w := doRequest(s.handleRoastDiff, http.MethodGet, "/roast/:username/diff", "/roast/"+tt.username+"/diff", "")
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.username, w.Code, tt.status, w.Body)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// DocOnlyStats counts sampled commits that touched only documentation or
+// only code.
+type DocOnlyStats struct {
+	Sampled             int     `json:"sampled"`
+	DocOnlyCommitCount  int     `json:"doc_only_commit_count"`
+	CodeOnlyCommitCount int     `json:"code_only_commit_count"`
+	DocOnlyRatio        float64 `json:"doc_only_ratio"`
+}
+
+// isDocFile reports whether filename is documentation: Markdown and text
+// files, anything under docs/, and changelogs.
+func isDocFile(filename string) bool {
+	name := strings.ToLower(filename)
+	base := path.Base(name)
+	switch {
+	case strings.HasSuffix(name, ".md"), strings.HasSuffix(name, ".txt"):
+		return true
+	case strings.HasPrefix(name, "docs/"), strings.Contains(name, "/docs/"):
+		return true
+	case strings.HasPrefix(base, "changelog"):
+		return true
+	}
+	return false
+}
+
+// classifyFiles returns "docs" when every file is documentation, "code"
+// when none is, and "mixed" otherwise. An empty list is "".
+func classifyFiles(filenames []string) string {
+	docs := 0
+	for _, name := range filenames {
+		if isDocFile(name) {
+			docs++
+		}
+	}
+	switch {
+	case len(filenames) == 0:
+		return ""
+	case docs == len(filenames):
+		return "docs"
+	case docs == 0:
+		return "code"
+	}
+	return "mixed"
+}
+
+// detectDocOnlyCommits classifies the sampled commits by the files they
+// touched.
+func detectDocOnlyCommits(details []commitDetail) DocOnlyStats {
+	stats := DocOnlyStats{Sampled: len(details)}
+	for _, detail := range details {
+		names := make([]string, 0, len(detail.Files))
+		for _, file := range detail.Files {
+			names = append(names, file.GetFilename())
+		}
+		switch classifyFiles(names) {
+		case "docs":
+			stats.DocOnlyCommitCount++
+		case "code":
+			stats.CodeOnlyCommitCount++
+		}
+	}
+	if stats.Sampled > 0 {
+		stats.DocOnlyRatio = float64(stats.DocOnlyCommitCount) / float64(stats.Sampled)
+	}
+	return stats
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+)
+
+func TestClassifyFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"pure docs", []string{"README.md", "docs/setup.html", "CHANGELOG", "NOTES.txt", "api/docs/index.rst"}, "docs"},
+		{"pure code", []string{"main.go", "server/handlers.go", "Makefile"}, "code"},
+		{"mixed", []string{"main.go", "README.md"}, "mixed"},
+		{"case-insensitive", []string{"Docs/Guide.MD", "ChangeLog.rst"}, "docs"},
+		{"docs-like names that aren't", []string{"docsgen/main.go", "markdown.go"}, "code"},
+		{"no files", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyFiles(tt.files); got != tt.want {
+				t.Errorf("classifyFiles(%q) = %q, want %q", tt.files, got, tt.want)
+			}
+		})
+	}
+}
+
+func filesDetail(names ...string) commitDetail {
+	var detail commitDetail
+	for _, name := range names {
+		detail.Files = append(detail.Files, &github.CommitFile{Filename: github.String(name)})
+	}
+	return detail
+}
+
+func TestDetectDocOnlyCommits(t *testing.T) {
+	details := []commitDetail{
+		filesDetail("README.md"),
+		filesDetail("main.go"),
+		filesDetail("main.go", "README.md"),
+		filesDetail("docs/index.md", "CHANGELOG.md"),
+		filesDetail(),
+	}
+	want := DocOnlyStats{Sampled: 5, DocOnlyCommitCount: 2, CodeOnlyCommitCount: 1, DocOnlyRatio: 0.4}
+	if got := detectDocOnlyCommits(details); got != want {
+		t.Errorf("detectDocOnlyCommits() = %+v, want %+v", got, want)
+	}
+	if got := detectDocOnlyCommits(nil); got != (DocOnlyStats{}) {
+		t.Errorf("detectDocOnlyCommits(nil) = %+v", got)
+	}
+}
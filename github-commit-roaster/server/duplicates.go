@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// minDuplicateRepos is how many different repos a message has to show up in
+// before it counts as copy-pasted.
+const minDuplicateRepos = 3
+
+// maxDuplicateExamples caps the examples returned in stats.
+const maxDuplicateExamples = 5
+
+// CrossRepoDup is one commit message reused across several repos.
+type CrossRepoDup struct {
+	Message string   `json:"message"`
+	Repos   []string `json:"repos"`
+}
+
+// CrossRepoDupStats summarizes commit messages pasted across repos.
+type CrossRepoDupStats struct {
+	Groups   int            `json:"groups"`
+	Examples []CrossRepoDup `json:"examples"`
+}
+
+// crossRepoIndex maps a normalized commit message to the repos it appears in.
+// It is filled while commits are aggregated so detection needs no extra pass.
+type crossRepoIndex map[string]map[string]bool
+
+func (idx crossRepoIndex) add(commit NormalizedCommit) {
+	msg := normalizeMessage(commit.Message)
+	if msg == "" {
+		return
+	}
+	if idx[msg] == nil {
+		idx[msg] = make(map[string]bool)
+	}
+	idx[msg][commit.Repo] = true
+}
+
+func (idx crossRepoIndex) stats() CrossRepoDupStats {
+	var dups []CrossRepoDup
+	for msg, repos := range idx {
+		if len(repos) < minDuplicateRepos {
+			continue
+		}
+		dup := CrossRepoDup{Message: msg}
+		for repo := range repos {
+			dup.Repos = append(dup.Repos, repo)
+		}
+		sort.Strings(dup.Repos)
+		dups = append(dups, dup)
+	}
+
+	// Most widespread first, then alphabetically so output is stable
+	sort.Slice(dups, func(i, j int) bool {
+		if len(dups[i].Repos) != len(dups[j].Repos) {
+			return len(dups[i].Repos) > len(dups[j].Repos)
+		}
+		return dups[i].Message < dups[j].Message
+	})
+
+	stats := CrossRepoDupStats{Groups: len(dups), Examples: dups}
+	if len(stats.Examples) > maxDuplicateExamples {
+		stats.Examples = stats.Examples[:maxDuplicateExamples]
+	}
+	return stats
+}
+
+// normalizeMessage reduces a commit message to its lowercased subject line
+// with whitespace collapsed, so trivial differences don't hide duplicates.
+func normalizeMessage(msg string) string {
+	subject, _, _ := strings.Cut(msg, "\n")
+	return strings.Join(strings.Fields(strings.ToLower(subject)), " ")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// inRepos is one commit with msg in each of repos.
+func inRepos(msg string, repos ...string) []NormalizedCommit {
+	commits := make([]NormalizedCommit, len(repos))
+	for i, repo := range repos {
+		commits[i] = NormalizedCommit{Repo: repo, Message: msg}
+	}
+	return commits
+}
+
+func crossRepoStats(commits []NormalizedCommit) CrossRepoDupStats {
+	idx := make(crossRepoIndex)
+	for _, commit := range commits {
+		idx.add(commit)
+	}
+	return idx.stats()
+}
+
+func TestCrossRepoDuplicates(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []NormalizedCommit
+		want    CrossRepoDupStats
+	}{
+		{"no commits", nil, CrossRepoDupStats{}},
+		{
+			"two repos is not enough",
+			inRepos("update readme", "a", "b"),
+			CrossRepoDupStats{},
+		},
+		{
+			"same repo repeatedly",
+			inRepos("wip", "a", "a", "a", "a"),
+			CrossRepoDupStats{},
+		},
+		{
+			"three repos",
+			inRepos("update readme", "c", "a", "b"),
+			CrossRepoDupStats{Groups: 1, Examples: []CrossRepoDup{{Message: "update readme", Repos: []string{"a", "b", "c"}}}},
+		},
+		{
+			"case, whitespace and body are ignored",
+			[]NormalizedCommit{
+				{Repo: "a", Message: "Update README"},
+				{Repo: "b", Message: "update   readme\n\nwith the new badge"},
+				{Repo: "c", Message: "  UPDATE readme  "},
+			},
+			CrossRepoDupStats{Groups: 1, Examples: []CrossRepoDup{{Message: "update readme", Repos: []string{"a", "b", "c"}}}},
+		},
+		{
+			"blank messages never match",
+			inRepos("  \n", "a", "b", "c"),
+			CrossRepoDupStats{},
+		},
+		{
+			"most widespread first",
+			append(inRepos("bump version", "a", "b", "c"), inRepos("initial commit", "a", "b", "c", "d")...),
+			CrossRepoDupStats{Groups: 2, Examples: []CrossRepoDup{
+				{Message: "initial commit", Repos: []string{"a", "b", "c", "d"}},
+				{Message: "bump version", Repos: []string{"a", "b", "c"}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := crossRepoStats(tt.commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrossRepoDuplicatesCapsExamples(t *testing.T) {
+	var commits []NormalizedCommit
+	for i := range maxDuplicateExamples + 2 {
+		commits = append(commits, inRepos(fmt.Sprintf("message %d", i), "a", "b", "c")...)
+	}
+	stats := crossRepoStats(commits)
+	if stats.Groups != maxDuplicateExamples+2 {
+		t.Errorf("Groups = %d, want %d", stats.Groups, maxDuplicateExamples+2)
+	}
+	if len(stats.Examples) != maxDuplicateExamples || stats.Examples[0].Message != "message 0" {
+		t.Errorf("Examples = %+v, want the first %d alphabetically", stats.Examples, maxDuplicateExamples)
+	}
+}
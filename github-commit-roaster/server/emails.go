@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// EmailStats summarizes the domains of commit author emails. Addresses are
+// never reported, only their domains.
+type EmailStats struct {
+	Domains      []string `json:"domains"` // most used first
+	NoreplyOnly  bool     `json:"noreply_only"`
+	UniqueEmails int      `json:"unique_emails"`
+}
+
+// isNoreplyEmail reports whether email is one of GitHub's noreply
+// addresses, used by the web editor and by accounts hiding their email.
+func isNoreplyEmail(email string) bool {
+	return email == "noreply@github.com" || strings.HasSuffix(email, "@users.noreply.github.com")
+}
+
+// emailDomain returns the registrable domain of email, so
+// dev.mail.company.co.uk becomes company.co.uk. It returns "" for
+// addresses without a usable domain.
+func emailDomain(email string) string {
+	_, host, ok := strings.Cut(email, "@")
+	host = strings.TrimSuffix(host, ".")
+	if !ok || host == "" {
+		return ""
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		// A bare suffix or a single label; report it as given
+		return host
+	}
+	return domain
+}
+
+// detectEmailDomains counts author email domains. NoreplyOnly is set when
+// every commit with an email used a GitHub noreply address.
+func detectEmailDomains(commits []NormalizedCommit) EmailStats {
+	stats := EmailStats{Domains: []string{}}
+	emails := make(map[string]bool)
+	domains := make(map[string]int)
+	noreply, withEmail := 0, 0
+	for _, commit := range commits {
+		email := strings.ToLower(strings.TrimSpace(commit.AuthorEmail))
+		if email == "" {
+			continue
+		}
+		withEmail++
+		emails[email] = true
+		if isNoreplyEmail(email) {
+			noreply++
+		}
+		if domain := emailDomain(email); domain != "" {
+			domains[domain]++
+		}
+	}
+	stats.UniqueEmails = len(emails)
+	stats.NoreplyOnly = withEmail > 0 && noreply == withEmail
+
+	for domain := range domains {
+		stats.Domains = append(stats.Domains, domain)
+	}
+	sort.Slice(stats.Domains, func(i, j int) bool {
+		a, b := stats.Domains[i], stats.Domains[j]
+		if domains[a] != domains[b] {
+			return domains[a] > domains[b]
+		}
+		return a < b
+	})
+	return stats
+}
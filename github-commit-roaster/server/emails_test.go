@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEmailDomain(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"dev@company.com", "company.com"},
+		{"dev@subdomain.company.com", "company.com"},
+		{"dev@dev.mail.company.co.uk", "company.co.uk"},
+		{"dev@company.com.", "company.com"},
+		{"1234+octocat@users.noreply.github.com", "github.com"},
+		{"root@localhost", "localhost"},
+		{"someone@co.uk", "co.uk"},
+		{"dev@", ""},
+		{"no-at-sign", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := emailDomain(tt.email); got != tt.want {
+				t.Errorf("emailDomain(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNoreplyEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"noreply@github.com", true},
+		{"1234+octocat@users.noreply.github.com", true},
+		{"octocat@users.noreply.github.com", true},
+		{"octocat@github.com", false},
+		{"noreply@example.com", false},
+	}
+	for _, tt := range tests {
+		if got := isNoreplyEmail(tt.email); got != tt.want {
+			t.Errorf("isNoreplyEmail(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestDetectEmailDomains(t *testing.T) {
+	withEmails := func(emails ...string) []NormalizedCommit {
+		commits := make([]NormalizedCommit, len(emails))
+		for i, email := range emails {
+			commits[i] = NormalizedCommit{Message: "fix", AuthorEmail: email}
+		}
+		return commits
+	}
+	tests := []struct {
+		name    string
+		commits []NormalizedCommit
+		want    EmailStats
+	}{
+		{"no commits", nil, EmailStats{Domains: []string{}}},
+		{"no emails", withEmails("", "  "), EmailStats{Domains: []string{}}},
+		{
+			"most used domain first",
+			withEmails("a@mail.work.io", "b@work.io", "me@gmail.com", " B@Work.IO "),
+			EmailStats{Domains: []string{"work.io", "gmail.com"}, UniqueEmails: 3},
+		},
+		{
+			"ties are alphabetical",
+			withEmails("me@zeta.dev", "me@alpha.dev"),
+			EmailStats{Domains: []string{"alpha.dev", "zeta.dev"}, UniqueEmails: 2},
+		},
+		{
+			"noreply only",
+			withEmails("noreply@github.com", "1+me@users.noreply.github.com", ""),
+			EmailStats{Domains: []string{"github.com"}, NoreplyOnly: true, UniqueEmails: 2},
+		},
+		{
+			"noreply and a real address",
+			withEmails("noreply@github.com", "me@example.dev"),
+			EmailStats{Domains: []string{"example.dev", "github.com"}, UniqueEmails: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectEmailDomains(tt.commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectEmailDomains() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoreplyOnlyRule(t *testing.T) {
+	rule := findRule(t, "noreply_only")
+	tests := []struct {
+		name    string
+		commits int
+		noreply bool
+		want    bool
+	}{
+		{"noreply only", 5, true, true},
+		{"too few commits", 4, true, false},
+		{"real addresses", 20, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: tt.commits, EmailDomains: EmailStats{NoreplyOnly: tt.noreply}}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Errorf("triggered = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
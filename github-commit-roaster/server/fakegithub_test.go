@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v50/github"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeGitHub is a canned GitHub REST API for handler tests: one user, their
+// public repos and each repo's commits. Anything else answers 404.
+type fakeGitHub struct {
+	login   string
+	repos   []*github.Repository
+	commits map[string][]*github.RepositoryCommit // by repo name
+	// languages are each repo's bytes of code per language, by repo name
+	languages map[string]map[string]int
+	// blocked repos answer their commit list with this status instead
+	blocked map[string]int
+	// delay holds every response back, for timeout tests
+	delay time.Duration
+
+	mu      sync.Mutex
+	calls   map[string]int        // by request path
+	queries map[string]url.Values // the last query string, by request path
+}
+
+// newFakeGitHub serves login with one repo holding commits.
+func newFakeGitHub(login string, commits ...*github.RepositoryCommit) *fakeGitHub {
+	return &fakeGitHub{
+		login:   login,
+		repos:   []*github.Repository{{Name: github.String("project"), Language: github.String("Go")}},
+		commits: map[string][]*github.RepositoryCommit{"project": commits},
+	}
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+		f.queries = make(map[string]url.Values)
+	}
+	f.calls[r.URL.Path]++
+	f.queries[r.URL.Path] = r.URL.Query()
+	f.mu.Unlock()
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("X-RateLimit-Limit", "60")
+	w.Header().Set("X-RateLimit-Remaining", "59")
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 2 && parts[0] == "users" && strings.EqualFold(parts[1], f.login):
+		writeFakeJSON(w, &github.User{
+			Login:     github.String(f.login),
+			CreatedAt: &github.Timestamp{Time: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)},
+		})
+	case len(parts) == 3 && parts[0] == "users" && strings.EqualFold(parts[1], f.login) && parts[2] == "repos":
+		writeFakeJSON(w, f.repos)
+	case len(parts) == 4 && parts[0] == "repos" && strings.EqualFold(parts[1], f.login) && parts[3] == "commits":
+		if status, ok := f.blocked[parts[2]]; ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"message":"Repository access blocked"}`))
+			return
+		}
+		commits, ok := f.commits[parts[2]]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		writeFakeJSON(w, commits)
+	case len(parts) == 4 && parts[0] == "repos" && strings.EqualFold(parts[1], f.login) && parts[3] == "languages":
+		langs, ok := f.languages[parts[2]]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		writeFakeJSON(w, langs)
+	case len(parts) == 5 && parts[0] == "repos" && strings.EqualFold(parts[1], f.login) && parts[3] == "commits":
+		for _, commit := range f.commits[parts[2]] {
+			if commit.GetSHA() == parts[4] {
+				writeFakeJSON(w, commit)
+				return
+			}
+		}
+		http.Error(w, `{"message":"No commit found for SHA"}`, http.StatusNotFound)
+	case r.URL.Path == "/user/repos":
+		// The authenticated user is always login
+		writeFakeJSON(w, f.repos)
+	case r.URL.Path == "/rate_limit":
+		writeFakeJSON(w, map[string]any{"resources": map[string]any{"core": map[string]int{"limit": 60, "remaining": 59}}})
+	case r.URL.Path == "/search/commits":
+		writeFakeJSON(w, &github.CommitsSearchResult{Total: github.Int(0)})
+	default:
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}
+}
+
+// fakeGitHubs serves several users, each from their own fakeGitHub, by the
+// login in the request path.
+type fakeGitHubs map[string]*fakeGitHub
+
+func (f fakeGitHubs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) >= 2 && (parts[0] == "users" || parts[0] == "repos") {
+		if gh, ok := f[strings.ToLower(parts[1])]; ok {
+			gh.ServeHTTP(w, r)
+			return
+		}
+	}
+	http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+}
+
+// callCount is how many requests were made for path.
+func (f *fakeGitHub) callCount(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[path]
+}
+
+// lastQuery is the query string of the last request for path.
+func (f *fakeGitHub) lastQuery(path string) url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queries[path]
+}
+
+func writeFakeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// fakeCommit is a commit made hoursAgo hours ago by name.
+func fakeCommit(name, message string, hoursAgo int) *github.RepositoryCommit {
+	return buildCommit(commitSpec{
+		SHA:     fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprint(name, message, hoursAgo)))),
+		Message: message,
+		Time:    time.Now().UTC().Add(-time.Duration(hoursAgo) * time.Hour),
+		Author:  name,
+	})
+}
+
+// newTestServer returns a server whose GitHub calls go to gh, with an
+// in-memory cache and history.
+func newTestServer(t *testing.T, gh http.Handler) *server {
+	t.Helper()
+	api := httptest.NewServer(gh)
+	t.Cleanup(api.Close)
+	baseURL, err := url.Parse(api.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clients := newClientFactory("", "", nil)
+	clients.baseURL = baseURL
+	clients.maxRetryWait = 0
+	return &server{
+		cfg:           loadRoastConfig(),
+		clients:       clients,
+		history:       newHistoryStore(nil),
+		stats:         newServerStats(),
+		cache:         newMemoryCache(),
+		cacheTTL:      time.Minute,
+		flights:       newFetchGroup(),
+		badges:        newTrendBadgeCache(),
+		corpus:        newCorpusStats(),
+		firstCommits:  newFirstCommitCache(),
+		githubTimeout: defaultGitHubTimeout,
+	}
+}
+
+// doRequest runs one request through handler mounted on method and route.
+func doRequest(handler gin.HandlerFunc, method, route, target string, body string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Handle(method, route, handler)
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
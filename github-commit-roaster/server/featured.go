@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	featuredJob = "featured"
+	// featuredCallBudget is roughly what one deep analysis costs: user,
+	// repo list, commit search, 10 commit lists, 10 language and 10
+	// contributor lookups and the diff sample.
+	featuredCallBudget = 3 + 10 + 10 + 10 + maxCommitDetails
+	featuredCheckEvery = 10 * time.Minute
+)
+
+// featuredRoast is the precomputed roast of the day.
+type featuredRoast struct {
+	Username    string      `json:"username"`
+	Roast       string      `json:"roast"`
+	Stats       CommitStats `json:"stats"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
+
+// featuredScheduler picks one of the candidate users once a day, at or after
+// the off-peak hour (UTC), and caches a deep roast of them. The last run is
+// kept in the Store when one is configured so restarts don't run it twice.
+type featuredScheduler struct {
+	srv        *server
+	store      *Store
+	candidates []string
+	hour       int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	current *featuredRoast
+}
+
+func newFeaturedScheduler(srv *server, store *Store, candidates []string, hour int) *featuredScheduler {
+	f := &featuredScheduler{
+		srv:        srv,
+		store:      store,
+		candidates: candidates,
+		hour:       hour,
+		now:        time.Now,
+	}
+	if store != nil {
+		_, result, found, err := store.LastJobRun(featuredJob)
+		if err != nil {
+			slog.Warn("Could not load featured roast", "error", err)
+		} else if found {
+			var roast featuredRoast
+			if err := json.Unmarshal(result, &roast); err == nil {
+				f.current = &roast
+			}
+		}
+	}
+	return f
+}
+
+// run checks periodically whether today's roast is due until ctx is done.
+func (f *featuredScheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(featuredCheckEvery)
+	defer ticker.Stop()
+	for {
+		if f.due() {
+			f.generate(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// due reports whether no roast has been generated today and the off-peak
+// hour has been reached.
+func (f *featuredScheduler) due() bool {
+	now := f.now().UTC()
+	if now.Hour() < f.hour {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.current == nil || startOfDay(f.current.GeneratedAt).Before(startOfDay(now))
+}
+
+// generate roasts the first candidate that can be analyzed, starting from a
+// different one each day.
+func (f *featuredScheduler) generate(ctx context.Context) {
+	client, authMode := f.srv.clients.newClient(ctx)
+
+	// Leave the rate limit to real users if a deep analysis would eat into it
+	limits, _, err := client.RateLimits(ctx)
+	if err != nil {
+		slog.Warn("Could not check rate limit for featured roast", "error", err)
+		return
+	}
+	if limits.GetCore().Remaining < featuredCallBudget {
+		return
+	}
+
+	now := f.now().UTC()
+	day := int(now.Unix() / 86400)
+	opts := RoastOptions{Languages: true, Deep: true, DeepScan: true, Intensity: defaultIntensity}
+	for i := range f.candidates {
+		username := f.candidates[(day+i)%len(f.candidates)]
+		result, err := f.srv.fetchAnalysis(ctx, client, authMode, username, opts, false)
+		if err != nil {
+			slog.Warn("Skipping featured candidate", "username", username, "error", err)
+			continue
+		}
+
+		roast := &featuredRoast{
+			Username:    username,
+			Roast:       generateRoast(result.stats, opts),
+			Stats:       result.stats,
+			GeneratedAt: now,
+		}
+		f.mu.Lock()
+		f.current = roast
+		f.mu.Unlock()
+
+		if f.store != nil {
+			encoded, _ := json.Marshal(roast)
+			if err := f.store.SaveJobRun(featuredJob, now, encoded); err != nil {
+				slog.Warn("Could not save featured roast", "error", err)
+			}
+		}
+		return
+	}
+}
+
+// handleFeatured serves GET /featured.
+func (f *featuredScheduler) handleFeatured(c *gin.Context) {
+	f.mu.Lock()
+	roast := f.current
+	f.mu.Unlock()
+	if roast == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no featured roast yet"})
+		return
+	}
+	c.JSON(http.StatusOK, roast)
+}
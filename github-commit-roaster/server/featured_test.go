@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFeaturedDue(t *testing.T) {
+	day := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		now  time.Time
+		last time.Time // zero for never generated
+		want bool
+	}{
+		{"before off-peak", day.Add(3 * time.Hour), time.Time{}, false},
+		{"never generated", day.Add(4 * time.Hour), time.Time{}, true},
+		{"generated yesterday", day.Add(5 * time.Hour), day.Add(-20 * time.Hour), true},
+		{"generated today", day.Add(23 * time.Hour), day.Add(4 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFeaturedScheduler(nil, nil, []string{"octocat"}, 4)
+			f.now = func() time.Time { return tt.now }
+			if !tt.last.IsZero() {
+				f.current = &featuredRoast{GeneratedAt: tt.last}
+			}
+			if got := f.due(); got != tt.want {
+				t.Errorf("due() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeaturedGenerate(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2), fakeCommit("Octo", "fix again", 1))
+	s := newTestServer(t, gh)
+	store, _ := openTestStore(t)
+	now := time.Date(2024, 6, 2, 5, 0, 0, 0, time.UTC)
+
+	// On an even day ghost is tried first, and can't be analyzed
+	f := newFeaturedScheduler(s, store, []string{"ghost", "octocat"}, 4)
+	f.now = func() time.Time { return now }
+	f.generate(t.Context())
+	if gh.callCount("/users/ghost") == 0 {
+		t.Error("ghost wasn't tried first")
+	}
+
+	r := gin.New()
+	r.GET("/featured", f.handleFeatured)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/featured", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if f.current.Username != "octocat" || !f.current.GeneratedAt.Equal(now) || f.current.Roast == "" {
+		t.Errorf("featured = %+v", f.current)
+	}
+
+	// A restart picks the roast up from the store and doesn't run again today
+	restarted := newFeaturedScheduler(s, store, []string{"octocat"}, 4)
+	restarted.now = func() time.Time { return now.Add(time.Hour) }
+	if restarted.current == nil || restarted.current.Username != "octocat" {
+		t.Fatalf("restart lost the featured roast: %+v", restarted.current)
+	}
+	if restarted.due() {
+		t.Error("featured roast due again after a restart")
+	}
+}
+
+func TestFeaturedNotYet(t *testing.T) {
+	f := newFeaturedScheduler(nil, nil, []string{"octocat"}, 4)
+	r := gin.New()
+	r.GET("/featured", f.handleFeatured)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/featured", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
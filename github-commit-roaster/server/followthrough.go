@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// FollowThroughStats compares commits with the issues and pull requests
+// actually closed in the user's repos over the analysis window.
+type FollowThroughStats struct {
+	IssuesClosed    int     `json:"issues_closed"`
+	PRsMerged       int     `json:"prs_merged"`
+	CommitsPerClose float64 `json:"commits_per_close"` // commits for every issue or PR closed; all commits if none were
+}
+
+// closed is how many issues and pull requests were closed in total.
+func (f FollowThroughStats) closed() int {
+	return f.IssuesClosed + f.PRsMerged
+}
+
+// followThroughStats relates the commit count to the closed counts.
+func followThroughStats(commits, issuesClosed, prsMerged int) FollowThroughStats {
+	stats := FollowThroughStats{IssuesClosed: issuesClosed, PRsMerged: prsMerged}
+	stats.CommitsPerClose = float64(commits) / float64(max(stats.closed(), 1))
+	return stats
+}
+
+// within reports whether t falls in the analysis window.
+func within(t, since, until time.Time) bool {
+	return !t.IsZero() && !t.Before(since) && !t.After(until)
+}
+
+// fetchFollowThrough counts the issues closed and pull requests merged in
+// owner's repos between since and until. It reads one page of each per
+// repo, so it costs two calls per repo.
+func fetchFollowThrough(ctx context.Context, client *ghclient.Client, owner string, repos []*github.Repository, commits int, since, until time.Time) FollowThroughStats {
+	issuesClosed, prsMerged := 0, 0
+	for _, repo := range repos {
+		spanCtx, span := startGitHubSpan(ctx, "Issues.ListByRepo", attribute.String("github.repo", repo.GetName()))
+		issues, _, err := client.Issues.ListByRepo(spanCtx, owner, repo.GetName(), &github.IssueListByRepoOptions{
+			State:       "closed",
+			Since:       since,
+			ListOptions: github.ListOptions{PerPage: 100},
+		})
+		endSpan(span, err)
+		if err == nil {
+			for _, issue := range issues {
+				// The issues API lists pull requests too; those are counted below
+				if !issue.IsPullRequest() && within(issue.GetClosedAt().Time, since, until) {
+					issuesClosed++
+				}
+			}
+		}
+
+		spanCtx, span = startGitHubSpan(ctx, "PullRequests.List", attribute.String("github.repo", repo.GetName()))
+		pulls, _, err := client.PullRequests.List(spanCtx, owner, repo.GetName(), &github.PullRequestListOptions{
+			State:       "closed",
+			Sort:        "updated",
+			Direction:   "desc",
+			ListOptions: github.ListOptions{PerPage: 100},
+		})
+		endSpan(span, err)
+		if err != nil {
+			continue // Skip repo if we can't list its pull requests
+		}
+		for _, pull := range pulls {
+			if within(pull.GetMergedAt().Time, since, until) {
+				prsMerged++
+			}
+		}
+	}
+	return followThroughStats(commits, issuesClosed, prsMerged)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFollowThroughStats(t *testing.T) {
+	tests := []struct {
+		name                 string
+		commits, issues, prs int
+		want                 float64
+	}{
+		{"closes as much as commits", 10, 5, 5, 1},
+		{"nothing closed", 40, 0, 0, 40},
+		{"no commits", 0, 2, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := followThroughStats(tt.commits, tt.issues, tt.prs)
+			if got.IssuesClosed != tt.issues || got.PRsMerged != tt.prs || got.CommitsPerClose != tt.want {
+				t.Errorf("followThroughStats() = %+v, want %v commits per close", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoFollowThroughRule(t *testing.T) {
+	rule := findRule(t, "no_follow_through")
+	tests := []struct {
+		name  string
+		stats CommitStats
+		want  bool
+	}{
+		{"not asked for", CommitStats{TotalCommits: 100}, false},
+		{"too few commits", CommitStats{TotalCommits: 19, FollowThrough: &FollowThroughStats{CommitsPerClose: 19}}, false},
+		{"closes enough", CommitStats{TotalCommits: 50, FollowThrough: &FollowThroughStats{IssuesClosed: 2, CommitsPerClose: 25}}, false},
+		{"all commits, no follow-through", CommitStats{TotalCommits: 60, FollowThrough: &FollowThroughStats{PRsMerged: 1, CommitsPerClose: 60}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rule.Triggered(tt.stats); got != tt.want {
+				t.Errorf("triggered = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchFollowThrough(t *testing.T) {
+	until := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	since := until.AddDate(0, 0, -30)
+	at := func(daysAgo int) *github.Timestamp {
+		return &github.Timestamp{Time: until.AddDate(0, 0, -daysAgo)}
+	}
+
+	ctrl := gomock.NewController(t)
+	issues := ghclient.NewMockIssuesService(ctrl)
+	pulls := ghclient.NewMockPullRequestsService(ctrl)
+	issues.EXPECT().ListByRepo(gomock.Any(), "octocat", "app", gomock.Any()).Return([]*github.Issue{
+		{ClosedAt: at(2)},
+		{ClosedAt: at(40)}, // before the window
+		{ClosedAt: at(3), PullRequestLinks: &github.PullRequestLinks{}}, // counted as a pull request
+	}, nil, nil)
+	pulls.EXPECT().List(gomock.Any(), "octocat", "app", gomock.Any()).Return([]*github.PullRequest{
+		{MergedAt: at(1)},
+		{MergedAt: at(5)},
+		{ClosedAt: at(4)}, // closed unmerged
+	}, nil, nil)
+	issues.EXPECT().ListByRepo(gomock.Any(), "octocat", "private", gomock.Any()).Return(nil, nil, errors.New("403"))
+	pulls.EXPECT().List(gomock.Any(), "octocat", "private", gomock.Any()).Return(nil, nil, errors.New("403"))
+
+	got := fetchFollowThrough(t.Context(), &ghclient.Client{Issues: issues, PullRequests: pulls}, "octocat",
+		[]*github.Repository{{Name: github.String("app")}, {Name: github.String("private")}}, 30, since, until)
+	want := FollowThroughStats{IssuesClosed: 1, PRsMerged: 2, CommitsPerClose: 10}
+	if got != want {
+		t.Errorf("fetchFollowThrough() = %+v, want %+v", got, want)
+	}
+}
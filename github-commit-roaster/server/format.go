@@ -0,0 +1,137 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Output formats for GET /roast, picked with ?format=.
+const (
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+	formatReport   = "report"
+	formatANSI     = "ansi"
+	formatText     = "text" // the ANSI drawing without color
+)
+
+// responseFormat validates ?format=, defaulting to JSON, or to ANSI for
+// curl and wget. ANSI turns into plain text with ?no_color=true or when
+// noColor is set server-wide.
+func responseFormat(c *gin.Context, noColor bool) (string, error) {
+	format := c.Query("format")
+	if format == "" {
+		format = formatJSON
+		if isTerminalClient(c.Request.UserAgent()) {
+			format = formatANSI
+		}
+	}
+	switch format {
+	case formatANSI:
+		if c.Query("no_color") == "true" || noColor {
+			return formatText, nil
+		}
+		return format, nil
+	case formatJSON, formatMarkdown, formatReport, formatText:
+		return format, nil
+	default:
+		return "", fmt.Errorf("format must be one of %s, %s, %s, %s, %s",
+			formatJSON, formatMarkdown, formatReport, formatANSI, formatText)
+	}
+}
+
+// renderRoast encodes response in the requested format, returning the
+// content type and body.
+func renderRoast(format string, response RoastResponse) (string, []byte, error) {
+	switch format {
+	case formatMarkdown:
+		return "text/markdown; charset=utf-8", []byte(renderMarkdown(response)), nil
+	case formatANSI, formatText:
+		return "text/plain; charset=utf-8", []byte(renderTerminal(response, format == formatANSI)), nil
+	case formatReport:
+		body, err := json.Marshal(reportCard(response))
+		return "application/json; charset=utf-8", body, err
+	default:
+		body, err := json.Marshal(response)
+		return "application/json; charset=utf-8", body, err
+	}
+}
+
+// roastETag is a strong validator for a rendered roast: a truncated
+// SHA-256 of the body.
+func roastETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// notModified sets the ETag header and, when If-None-Match already lists
+// etag, answers 304 Not Modified.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// writeRoast sends response in the requested format, or 304 Not Modified
+// when the caller already has it. etag is the body's ETag when already
+// known, so a matching request skips encoding the response.
+func writeRoast(c *gin.Context, format string, response RoastResponse, etag string) {
+	// The format can depend on the User-Agent, so caches must tell them apart
+	c.Header("Vary", "Accept-Encoding, User-Agent")
+	if etag != "" && notModified(c, etag) {
+		return
+	}
+	contentType, body, err := renderRoast(format, response)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not encode roast"})
+		return
+	}
+	if etag == "" && notModified(c, roastETag(body)) {
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// renderMarkdown formats a roast for pasting into a README or issue: a
+// heading, the roast lines as bullets and a table of the headline stats.
+func renderMarkdown(response RoastResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 🔥 Roast of @%s\n\n", response.Username)
+	for _, line := range strings.Split(response.Roast, "\n\n") {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+
+	s := response.Stats
+	b.WriteString("\n| Stat | Value |\n| --- | --- |\n")
+	rows := []struct {
+		name  string
+		value interface{}
+	}{
+		{"Commits", s.TotalCommits},
+		{"Repos analyzed", s.ReposAnalyzed},
+		{"Late-night commits", s.LateNightCommits},
+		{"Fix commits", s.FixCommits},
+		{"Merge commits", s.MergeCommits},
+		{"Generic messages", s.GenericMessages},
+		{"Swear words", s.SwearWords},
+		{"Severity", fmt.Sprintf("%d/100", s.Severity)},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %v |\n", row.name, row.value)
+	}
+
+	b.WriteString("\n## 📝 Report card\n\n")
+	b.WriteString(reportTable(reportCard(response)))
+	return b.String()
+}
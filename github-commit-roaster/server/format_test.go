@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestResponseFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		userAgent string
+		noColor   bool
+		want      string
+		wantErr   bool
+	}{
+		{"default", "", "Mozilla/5.0", false, formatJSON, false},
+		{"curl", "", "curl/8.5.0", false, formatANSI, false},
+		{"curl without color", "no_color=true", "curl/8.5.0", false, formatText, false},
+		{"server without color", "format=ansi", "", true, formatText, false},
+		{"markdown", "format=markdown", "curl/8.5.0", false, formatMarkdown, false},
+		{"unknown", "format=xml", "", false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/roast?"+tt.query, nil)
+			c.Request.Header.Set("User-Agent", tt.userAgent)
+			got, err := responseFormat(c, tt.noColor)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("responseFormat() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	response := RoastResponse{
+		Username: "octocat",
+		Roast:    "So many fixes.\n\nDo you sleep?",
+		Stats:    CommitStats{TotalCommits: 12, ReposAnalyzed: 2, LateNightCommits: 9, FixCommits: 8, Severity: 64},
+	}
+	md := renderMarkdown(response)
+	for _, want := range []string{
+		"# 🔥 Roast of @octocat\n",
+		"\n- So many fixes.\n- Do you sleep?\n",
+		"| Stat | Value |\n| --- | --- |\n",
+		"| Commits | 12 |\n",
+		"| Repos analyzed | 2 |\n",
+		"| Late-night commits | 9 |\n",
+		"| Fix commits | 8 |\n",
+		"| Severity | 64/100 |\n",
+		"## 📝 Report card",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRoastAsMarkdown(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2)))
+	w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat&format=markdown", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/markdown; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if body := w.Body.String(); !strings.HasPrefix(body, "# 🔥 Roast of @octocat") || !strings.Contains(body, "| Commits | 1 |") {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `"0123456789abcdef"`
+	tests := []struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		{"", false},
+		{etag, true},
+		{"W/" + etag, true},
+		{`"other", ` + etag, true},
+		{"*", true},
+		{`"other"`, false},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/roast", nil)
+		c.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+		if got := notModified(c, etag); got != tt.want {
+			t.Errorf("notModified() with If-None-Match %q = %v, want %v", tt.ifNoneMatch, got, tt.want)
+		}
+	}
+}
+
+func TestRoastConditionalGet(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2)))
+	r := gin.New()
+	r.GET("/roast", s.handleRoast)
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("/roast?username=octocat", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag != roastETag(first.Body.Bytes()) {
+		t.Fatalf("first request: status %d, ETag %q for its body", first.Code, etag)
+	}
+	if vary := first.Header().Get("Vary"); !strings.Contains(vary, "Accept-Encoding") {
+		t.Errorf("Vary = %q, want Accept-Encoding in it", vary)
+	}
+
+	second := get("/roast?username=octocat", etag)
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Errorf("second request: status %d with %d bytes, want an empty 304", second.Code, second.Body.Len())
+	}
+	if got := second.Header().Get("ETag"); got != etag {
+		t.Errorf("304 ETag = %q, want %q", got, etag)
+	}
+
+	other := get("/roast?username=octocat&intensity=5", etag)
+	if other.Code != http.StatusOK || other.Header().Get("ETag") == etag {
+		t.Errorf("different roast: status %d, ETag %q", other.Code, other.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// generatedFiles are written by go generate and committed.
+var generatedFiles = []string{
+	"internal/version/generated.go",
+	"internal/github/mock_client.go",
+}
+
+// TestGeneratedFilesUpToDate runs go generate on a copy of the module and
+// compares the output with the committed files.
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go generate")
+	}
+	dir := t.TempDir()
+	if err := copyModule(".", dir); err != nil {
+		t.Fatalf("copying module: %v", err)
+	}
+	// Generators that silently write nothing must not pass
+	for _, name := range generatedFiles {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("removing %s: %v", name, err)
+		}
+	}
+
+	cmd := exec.Command("go", "generate", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go generate: %v\n%s", err, out)
+	}
+
+	for _, name := range generatedFiles {
+		committed, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("reading committed %s: %v", name, err)
+		}
+		regenerated, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("reading regenerated %s: %v", name, err)
+		}
+		if !bytes.Equal(committed, regenerated) {
+			t.Errorf("%s is out of date; run make generate", name)
+		}
+	}
+}
+
+// copyModule copies the module's source tree from src to dst, leaving out
+// build output.
+func copyModule(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, path)
+		if entry.IsDir() {
+			if entry.Name() == "bin" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		if path == "github-commit-roaster" || !entry.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
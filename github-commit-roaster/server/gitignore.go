@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"strings"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxGitignoreRepos is how many repos get their .gitignore read, one call
+// each.
+const maxGitignoreRepos = 10
+
+// minGitignoreCoverage is the share of the checklist a .gitignore must
+// cover to count as well configured.
+const minGitignoreCoverage = 0.5
+
+// defaultGitignorePatterns are the ten most commonly needed ignore
+// patterns, checked unless GITIGNORE_PATTERNS lists others.
+var defaultGitignorePatterns = []string{
+	"node_modules",
+	".DS_Store",
+	".env",
+	"__pycache__",
+	"*.pyc",
+	"*.log",
+	"dist",
+	"build",
+	".idea",
+	".vscode",
+}
+
+// GitignoreStats sorts the checked repos by their .gitignore. Only set in
+// deep mode.
+type GitignoreStats struct {
+	Missing        int `json:"missing"`
+	Incomplete     int `json:"incomplete"` // covers under half the checklist
+	WellConfigured int `json:"well_configured"`
+}
+
+// normalizeIgnorePattern reduces a .gitignore line to the form compared
+// against the checklist: "/node_modules/" and "node_modules" are the same.
+func normalizeIgnorePattern(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "**/")
+	return strings.Trim(line, "/")
+}
+
+// gitignoreCoverage is the share of patterns a .gitignore lists. Comments
+// and negations don't count.
+func gitignoreCoverage(content string, patterns []string) float64 {
+	listed := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := normalizeIgnorePattern(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		listed[line] = true
+	}
+	covered := 0
+	for _, pattern := range patterns {
+		if listed[normalizeIgnorePattern(pattern)] {
+			covered++
+		}
+	}
+	return share(covered, len(patterns))
+}
+
+// add counts one repo's .gitignore. A nil file, which is what a
+// missing .gitignore or a directory of that name comes back as, counts as
+// missing.
+func (g *GitignoreStats) add(file *github.RepositoryContent, patterns []string) {
+	if file == nil {
+		g.Missing++
+		return
+	}
+	content, err := file.GetContent()
+	if err != nil || gitignoreCoverage(content, patterns) < minGitignoreCoverage {
+		g.Incomplete++
+		return
+	}
+	g.WellConfigured++
+}
+
+// fetchGitignores reads the .gitignore of up to maxGitignoreRepos
+// non-empty repos the user didn't fork. Repos whose contents can't be read
+// for reasons other than a missing file are left out.
+func fetchGitignores(ctx context.Context, client *ghclient.Client, owner string, repos []*github.Repository, patterns []string) GitignoreStats {
+	var stats GitignoreStats
+	checked := 0
+	for _, repo := range repos {
+		if checked == maxGitignoreRepos {
+			break
+		}
+		if repo.GetFork() || repo.GetSize() == 0 {
+			continue
+		}
+		checked++
+		spanCtx, span := startGitHubSpan(ctx, "Repositories.GetContents", attribute.String("github.repo", repo.GetName()))
+		file, _, _, err := client.Repositories.GetContents(spanCtx, owner, repo.GetName(), ".gitignore", nil)
+		endSpan(span, err)
+		if err != nil && !isNotFound(err) {
+			continue
+		}
+		stats.add(file, patterns)
+	}
+	return stats
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	ghclient "github-commit-roaster/internal/github"
+	"github.com/google/go-github/v50/github"
+	"go.uber.org/mock/gomock"
+)
+
+// gitignoreFile is a .gitignore as GitHub's contents API returns it.
+func gitignoreFile(content string) *github.RepositoryContent {
+	return &github.RepositoryContent{
+		Type:     github.String("file"),
+		Encoding: github.String("base64"),
+		Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+	}
+}
+
+func TestGitignoreCoverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    float64
+	}{
+		{"empty", "", 0},
+		{"one pattern", "node_modules\n", 0.1},
+		{"anchored and nested forms", "/node_modules/\n**/.DS_Store\n.env/\n  __pycache__  \n", 0.4},
+		{"comments and negations", "# node_modules\n!.env\n*.log\n", 0.1},
+		{"windows line endings", "dist\r\nbuild\r\n.idea\r\n", 0.3},
+		{"the whole checklist", "node_modules\n.DS_Store\n.env\n__pycache__\n*.pyc\n*.log\ndist\nbuild\n.idea\n.vscode\nvendor\n", 1},
+		{"near misses", "node_module\n.env.local\n*.logs\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gitignoreCoverage(tt.content, defaultGitignorePatterns); got != tt.want {
+				t.Errorf("gitignoreCoverage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitignoreStatsAdd(t *testing.T) {
+	half := "node_modules\n.DS_Store\n.env\n__pycache__\n*.pyc\n"
+	tests := []struct {
+		name string
+		file *github.RepositoryContent
+		want GitignoreStats
+	}{
+		{"nil content response", nil, GitignoreStats{Missing: 1}},
+		{"partial content", gitignoreFile("node_modules\n.DS_Store\n"), GitignoreStats{Incomplete: 1}},
+		{"just under half", gitignoreFile("node_modules\n.DS_Store\n.env\n__pycache__\n"), GitignoreStats{Incomplete: 1}},
+		{"half the checklist", gitignoreFile(half), GitignoreStats{WellConfigured: 1}},
+		{"empty file", gitignoreFile(""), GitignoreStats{Incomplete: 1}},
+		{"undecodable", &github.RepositoryContent{Encoding: github.String("base64"), Content: github.String("not base64!")}, GitignoreStats{Incomplete: 1}},
+		{"plain content", &github.RepositoryContent{Content: github.String(half)}, GitignoreStats{WellConfigured: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got GitignoreStats
+			got.add(tt.file, defaultGitignorePatterns)
+			if got != tt.want {
+				t.Errorf("add() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchGitignores(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	contents := ghclient.NewMockRepositoriesService(ctrl)
+	client := &ghclient.Client{Repositories: contents}
+	// Spans print the error, which needs the request it answered
+	failed := func(status int) error {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/octocat/x/contents/.gitignore", nil)
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: status, Request: req}, Message: http.StatusText(status)}
+	}
+	get := func(repo string) *gomock.Call {
+		return contents.EXPECT().GetContents(gomock.Any(), "octocat", repo, ".gitignore", gomock.Nil())
+	}
+	get("api").Return(gitignoreFile("node_modules\n.DS_Store\n.env\n__pycache__\n*.pyc\n*.log\n"), nil, nil, nil)
+	get("site").Return(gitignoreFile(".DS_Store\n"), nil, nil, nil)
+	get("bare").Return(nil, nil, nil, failed(http.StatusNotFound))
+	get("dir").Return(nil, []*github.RepositoryContent{{Type: github.String("file")}}, nil, nil)
+	get("broken").Return(nil, nil, nil, failed(http.StatusInternalServerError))
+
+	repo := func(name string, size int, fork bool) *github.Repository {
+		return &github.Repository{Name: github.String(name), Size: github.Int(size), Fork: github.Bool(fork)}
+	}
+	list := []*github.Repository{
+		repo("api", 10, false),
+		repo("site", 10, false),
+		repo("fork", 10, true),
+		repo("empty", 0, false),
+		repo("bare", 10, false),
+		repo("dir", 10, false),
+		repo("broken", 10, false),
+	}
+	got := fetchGitignores(t.Context(), client, "octocat", list, defaultGitignorePatterns)
+	if want := (GitignoreStats{Missing: 2, Incomplete: 1, WellConfigured: 1}); got != want {
+		t.Errorf("fetchGitignores() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNoGitignoreRule(t *testing.T) {
+	rule := findRule(t, "no_gitignore")
+	tests := []struct {
+		name  string
+		stats *GitignoreStats
+		want  bool
+	}{
+		{"not deep", nil, false},
+		{"two missing", &GitignoreStats{Missing: 2, Incomplete: 5}, false},
+		{"three missing", &GitignoreStats{Missing: 3, WellConfigured: 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, Gitignore: tt.stats}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Fatalf("triggered = %v, want %v", got, tt.want)
+			}
+			if want := "Half your repos have no `.gitignore`. Your repos probably have `.DS_Store` files committed everywhere."; tt.want && rule.line(stats, defaultIntensity) != want {
+				t.Errorf("line = %q", rule.line(stats, defaultIntensity))
+			}
+		})
+	}
+}
+
+func TestGitignorePatternsConfig(t *testing.T) {
+	t.Setenv("GITIGNORE_PATTERNS", "vendor, target")
+	cfg := loadRoastConfig()
+	if len(cfg.GitignorePatterns) != 2 || cfg.GitignorePatterns[0] != "vendor" || cfg.GitignorePatterns[1] != "target" {
+		t.Fatalf("GitignorePatterns = %q", cfg.GitignorePatterns)
+	}
+	if got := gitignoreCoverage("node_modules\n/vendor/\n", cfg.GitignorePatterns); got != 0.5 {
+		t.Errorf("coverage against the configured checklist = %v, want 0.5", got)
+	}
+}
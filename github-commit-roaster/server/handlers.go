@@ -0,0 +1,678 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ghclient "github-commit-roaster/internal/github"
+	"github-commit-roaster/internal/version"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v50/github"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// server holds the dependencies shared by the roast handlers.
+type server struct {
+	cfg      RoastConfig
+	clients  *clientFactory
+	login    *oauthLogin
+	history  historyStore
+	stats    *serverStats
+	cache    Cache
+	cacheTTL time.Duration
+	prefetch *prefetcher
+	flights  *fetchGroup
+	badges   *trendBadgeCache
+	corpus   *CorpusStats
+
+	// firstCommits keeps the commit search API off most roasts
+	firstCommits *firstCommitCache
+
+	// githubTimeout bounds the GitHub calls behind one analysis
+	githubTimeout time.Duration
+	// noColor renders ANSI roasts as plain text, per NO_COLOR
+	noColor bool
+}
+
+// RoastResponse is the body of a successful GET /roast.
+type RoastResponse struct {
+	Username      string        `json:"username"`
+	Roast         string        `json:"roast"`
+	Verdict       string        `json:"verdict"` // the worst finding in one short line
+	Stats         CommitStats   `json:"stats"`
+	Weights       Weights       `json:"weights"`
+	Achievements  []Achievement `json:"achievements"`
+	Metadata      RoastMetadata `json:"metadata"`
+	RepoBreakdown []RepoRoast   `json:"repo_breakdown,omitempty"`
+	Timing        *Timing       `json:"timing,omitempty"`
+	Debug         *RoastDebug   `json:"debug,omitempty"`
+	Team          *TeamDetails  `json:"team,omitempty"` // only on team roasts
+}
+
+// RoastDebug is extra detail returned with ?debug=true.
+type RoastDebug struct {
+	SingleflightGroupSize int `json:"singleflight_group_size"`
+}
+
+// RoastMetadata describes how a roast was produced.
+type RoastMetadata struct {
+	// Truncated is set when ?max_chars= dropped lines; FullLength is then
+	// the length of the whole roast, in characters
+	Truncated  bool `json:"truncated"`
+	FullLength int  `json:"full_length,omitempty"`
+
+	// GetCommit calls spent reading diffs, by deep_scan or deep mode's
+	// commit size sample
+	DeepScanAPICalls int `json:"deep_scan_api_calls"`
+}
+
+// analysis is the result of fetching and analyzing one user's activity.
+type analysis struct {
+	stats      CommitStats
+	repos      []*github.Repository
+	commits    []NormalizedCommit
+	extraCalls int
+	timing     Timing
+	groupSize  int // requests that shared this fetch
+}
+
+// roastOptions reads the per-request roast switches from the query string.
+func roastOptions(c *gin.Context, cfg RoastConfig) (RoastOptions, error) {
+	intensity := defaultIntensity
+	if value := c.Query("intensity"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < minIntensity || n > maxIntensity {
+			return RoastOptions{}, fmt.Errorf("intensity must be a number from 1 to 5")
+		}
+		intensity = n
+	}
+	weights, err := parseWeights(c.Query("weights"), cfg.Weights)
+	if err != nil {
+		return RoastOptions{}, err
+	}
+	days, since, until, err := parseWindow(c)
+	if err != nil {
+		return RoastOptions{}, err
+	}
+	persona := c.DefaultQuery("persona", defaultPersona)
+	if !validPersona(persona) {
+		return RoastOptions{}, fmt.Errorf("persona must be one of %s", strings.Join(personaNames(), ", "))
+	}
+	maxLines := cfg.MaxRoastLines
+	if value := c.Query("max_lines"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return RoastOptions{}, fmt.Errorf("max_lines must be a non-negative number")
+		}
+		maxLines = n
+	}
+	maxChars := 0
+	if value := c.Query("max_chars"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return RoastOptions{}, fmt.Errorf("max_chars must be a non-negative number")
+		}
+		maxChars = n
+	}
+	loc := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return RoastOptions{}, fmt.Errorf("unknown time zone %q", tz)
+		}
+	}
+	return RoastOptions{
+		Languages:      c.Query("languages") == "true",
+		Deep:           c.Query("deep") == "true",
+		DeepScan:       c.Query("deep_scan") == "true",
+		FollowThrough:  c.Query("follow_through") == "true",
+		IncludePrivate: c.Query("include_private") == "true",
+		Intensity:      intensity,
+		Persona:        persona,
+		Lang:           c.DefaultQuery("lang", defaultCatalogLang),
+		Weights:        weights,
+		MaxLines:       maxLines,
+		MaxChars:       maxChars,
+		Timeline:       c.Query("timeline") == "true",
+		Words:          c.Query("words") == "true",
+		SpellCheck:     c.Query("spellcheck") == "true",
+		Location:       loc,
+		Days:           days,
+		Since:          since,
+		Until:          until,
+	}, nil
+}
+
+// repoListOptions is who to list repos for and how: the 10 most recently
+// updated repos username owns. With includePrivate the authenticated
+// user's own repos are listed instead, the only way to see private ones.
+func repoListOptions(username string, includePrivate bool) (string, *github.RepositoryListOptions) {
+	opts := &github.RepositoryListOptions{
+		Type:        "owner",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 10},
+	}
+	if !includePrivate {
+		return username, opts
+	}
+	opts.Type = ""
+	opts.Visibility = "all"
+	opts.Affiliation = "owner"
+	return "", opts
+}
+
+// errUserNotFound is returned when GitHub has no such user.
+var errUserNotFound = errors.New("GitHub user not found")
+
+// isNotFound reports whether err is GitHub answering 404.
+func isNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil &&
+		errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// analyze fetches and analyzes username's recent activity. On failure it
+// writes the error response itself and returns false.
+func (s *server) analyze(c *gin.Context, username string, opts RoastOptions) (*analysis, bool) {
+	ctx := c.Request.Context()
+	client, authMode := s.clients.newClient(ctx)
+
+	// Logged-in users roasting themselves use their own token, which can
+	// see their private repos with ?include_private=true
+	userToken, ownRoast := s.login.tokenFor(c, username)
+	if ownRoast {
+		client, authMode = s.clients.newUserClient(ctx, userToken), authModeAuthenticated
+	}
+	if authMode == authModeUnauthenticated {
+		slog.Warn("Using unauthenticated API - rate limits will apply")
+	}
+	c.Header("X-Roast-Auth-Mode", authMode)
+
+	// Private roasts are never shared with other requests
+	var result *analysis
+	var err error
+	if ownRoast {
+		fetchCtx, cancel := context.WithTimeout(ctx, s.githubTimeout)
+		defer cancel()
+		result, err = s.fetchAnalysis(fetchCtx, client, authMode, username, opts, true)
+	} else {
+		result, err = s.sharedFetch(ctx, client, authMode, username, opts)
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			// The client went away; there's nobody to tell
+			c.Abort()
+		} else {
+			c.JSON(s.analysisError(err))
+		}
+		return nil, false
+	}
+	return result, true
+}
+
+// analysisError is the status and body reporting a failed analysis.
+func (s *server) analysisError(err error) (int, gin.H) {
+	switch {
+	case errors.Is(err, errUserNotFound):
+		return http.StatusNotFound, gin.H{"error": err.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, gin.H{
+			"error":           "GitHub API timeout",
+			"timeout_seconds": int(s.githubTimeout.Seconds()),
+		}
+	default:
+		return githubError(err)
+	}
+}
+
+// fetchAnalysis does the GitHub calls and analysis behind analyze. ownRoast
+// means client holds username's own token, so opts.IncludePrivate can list
+// their private repos.
+func (s *server) fetchAnalysis(ctx context.Context, client *ghclient.Client, authMode, username string, opts RoastOptions, ownRoast bool) (*analysis, error) {
+	// Remember the most recent rate limit GitHub reported
+	rateRemaining := -1
+	trackRate := func(resp *github.Response) {
+		if resp != nil {
+			rateRemaining = resp.Rate.Remaining
+		}
+	}
+
+	timing := Timing{PerRepoMs: make(map[string]float64)}
+	started := time.Now()
+
+	// The fetch, analyze and enrich spans follow the timing phases, so each
+	// covers only its own work
+	fetchCtx, fetchSpan := tracer.Start(ctx, "fetch", trace.WithAttributes(attribute.String("github.user", username)))
+
+	// Verify user exists
+	spanCtx, span := startGitHubSpan(fetchCtx, "Users.Get", attribute.String("github.user", username))
+	user, resp, err := client.Users.Get(spanCtx, username)
+	endSpan(span, err)
+	trackRate(resp)
+	timing.UserLookupMs = sinceMs(started)
+	if err != nil {
+		endSpan(fetchSpan, err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if isNotFound(err) {
+			return nil, errUserNotFound
+		}
+		// Rate limits, outages and network errors say nothing about the user
+		return nil, err
+	}
+
+	// Get repositories (limit to 10 most recent)
+	includePrivate := ownRoast && opts.IncludePrivate
+	listUser, repoOpts := repoListOptions(username, includePrivate)
+	phase := time.Now()
+	spanCtx, span = startGitHubSpan(fetchCtx, "Repositories.List", pageAttr(repoOpts.Page))
+	repos, resp, err := client.Repositories.List(spanCtx, listUser, repoOpts)
+	endSpan(span, err)
+	trackRate(resp)
+	timing.ListReposMs = sinceMs(phase)
+	if err != nil {
+		endSpan(fetchSpan, err)
+		return nil, err
+	}
+
+	// Get commits from the window (last 30 days by default), keeping a
+	// bounded sample of busy histories
+	since, until := opts.window(time.Now())
+	sampler := newCommitSampler(username, s.cfg.MaxCommits)
+
+	phase = time.Now()
+	var skipped []SkippedRepo
+	for _, repo := range repos {
+		if repo.GetDisabled() {
+			skipped = append(skipped, SkippedRepo{Name: repo.GetName(), Reason: skipDisabled})
+			continue
+		}
+		repoStarted := time.Now()
+		commitOpts := &github.CommitsListOptions{Since: since, Until: until}
+		spanCtx, span := startGitHubSpan(fetchCtx, "Repositories.ListCommits",
+			attribute.String("github.repo", repo.GetName()), pageAttr(commitOpts.Page))
+		commits, resp, err := client.Repositories.ListCommits(spanCtx, username, *repo.Name, commitOpts)
+		endSpan(span, err)
+		trackRate(resp)
+		timing.PerRepoMs[repo.GetName()] = sinceMs(repoStarted)
+		if errors.Is(err, errCallBudgetExhausted) {
+			// The rest of the repos would be missing, so the commits are incomplete
+			endSpan(fetchSpan, err)
+			return nil, err
+		}
+		if err != nil {
+			// Skip repo if we can't get commits, but say so when GitHub blocked it
+			if reason := unavailableReason(err); reason != "" {
+				slog.Warn("Skipping repo blocked by GitHub", "repo", username+"/"+repo.GetName(), "reason", reason)
+				skipped = append(skipped, SkippedRepo{Name: repo.GetName(), Reason: reason})
+			}
+			continue
+		}
+		for _, commit := range commits {
+			sampler.add(normalizeCommit(repo.GetName(), commit))
+		}
+	}
+	if ctx.Err() != nil {
+		// Every call after the deadline failed, so the commits are incomplete
+		endSpan(fetchSpan, ctx.Err())
+		return nil, ctx.Err()
+	}
+	allCommits := sampler.commits
+	timing.FetchCommitsMs = sinceMs(phase)
+	fetchSpan.SetAttributes(attribute.Int("github.repos", len(repos)), attribute.Int("commits", len(allCommits)))
+	fetchSpan.End()
+
+	_, span = tracer.Start(ctx, "analyze", trace.WithAttributes(attribute.Int("commits", len(allCommits))))
+	phase = time.Now()
+	stats := analyzeCommits(allCommits, s.cfg)
+	timing.AnalyzeMs = sinceMs(phase)
+	span.End()
+
+	// Enrichment makes the optional extra GitHub calls
+	ctx, span = tracer.Start(ctx, "enrich")
+	phase = time.Now()
+	stats.ReposAnalyzed = len(repos)
+	stats.SkippedRepos = skipped
+	stats.AuthorNames = detectAuthorNames(allCommits, username)
+	stats.Abandonment = detectAbandonedRepos(repos, s.cfg.StaleAfterDays, s.cfg.DeadAfterDays, time.Now())
+	stats.AbandonedProjects = detectAbandonedProjects(repos, time.Now())
+	stats.Popularity = detectPopularity(repos, allCommits)
+	stats.Since, stats.Until = since.UTC(), until.UTC()
+	stats.CommitsFetched = sampler.seen
+	stats.CommitsSampled = sampler.sampled()
+	stats.VelocityContext = commitVelocity(user.GetCreatedAt().Time, since, until, sampler.seen, time.Now())
+	stats.AuthMode = authMode
+	stats.RateLimitRemaining = rateRemaining
+	stats.PrivateCommitsIncluded = includePrivate
+	stats.FirstCommitDate = s.firstCommits.firstCommitDate(ctx, client, username)
+	stats.DeveloperVintage = developerVintage(stats.FirstCommitDate)
+	if opts.Timeline {
+		loc := opts.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+		stats.Timeline = buildTimeline(allCommits, since, until, loc)
+	}
+	if opts.Words {
+		words := countWords(allCommits, s.cfg.Stopwords)
+		stats.Words = &words
+	}
+	if opts.SpellCheck {
+		spelling := detectTypos(allCommits)
+		stats.Spelling = &spelling
+	}
+
+	// Language breakdown is cheap from the repo list; deep mode weighs it by
+	// bytes, and the language profile lists bytes for a few repos otherwise
+	if opts.Deep {
+		bytes := fetchLanguageBytes(ctx, client, username, repos)
+		stats.Languages = toPercentages(bytes)
+		profile := languageProfile(bytes)
+		stats.LanguageProfile = &profile
+		collaboration := fetchCollaboration(ctx, client, username, repos)
+		stats.Collaboration = &collaboration
+		coverage := fetchTestCoverage(ctx, client, username, repos)
+		stats.TestCoverage = &coverage
+		gitignores := fetchGitignores(ctx, client, username, repos, s.cfg.GitignorePatterns)
+		stats.Gitignore = &gitignores
+	} else {
+		stats.Languages = languageBreakdown(repos)
+		if opts.Languages {
+			profile := languageProfile(fetchLanguageBytes(ctx, client, username, repos[:min(len(repos), maxLanguageProfileRepos)]))
+			stats.LanguageProfile = &profile
+		}
+	}
+
+	// Deep scan reads commit diffs, costing up to maxCommitDetails extra
+	// calls; deep mode alone reads maxCommitSizeDetails for commit sizes
+	extraCalls := 0
+	var details []commitDetail
+	if opts.DeepScan {
+		details, extraCalls = fetchCommitDetails(ctx, client, username, allCommits, maxCommitDetails)
+		profanity := scanPatchProfanity(details, profanitySet(s.cfg.SwearWords))
+		docOnly := detectDocOnlyCommits(details)
+		stats.CodeCommentProfanity = &profanity
+		stats.DocOnly = &docOnly
+		stats.DebtMarkers.InDiffs = scanPatchDebtMarkers(details)
+	} else if opts.Deep {
+		details, extraCalls = fetchCommitDetails(ctx, client, username, allCommits, maxCommitSizeDetails)
+	}
+	if opts.Deep {
+		size := commitSizes(details)
+		stats.CommitSize = &size
+	}
+	if opts.FollowThrough {
+		followThrough := fetchFollowThrough(ctx, client, username, repos, stats.TotalCommits, since, until)
+		stats.FollowThrough = &followThrough
+	}
+	stats.Severity = weightedSeverity(stats, opts.Weights)
+	stats.TriggeredRules = rankedRules(stats, opts.Weights)
+	timing.EnrichMs = sinceMs(phase)
+	timing.TotalMs = sinceMs(started)
+	span.End()
+
+	return &analysis{stats: stats, repos: repos, commits: allCommits, extraCalls: extraCalls, timing: timing}, nil
+}
+
+// record saves an analysis to the roast history and the server stats.
+// Analyses that include private commits are left out of both.
+func (s *server) record(username string, stats CommitStats, opts RoastOptions) {
+	if stats.PrivateCommitsIncluded {
+		return
+	}
+	s.stats.recordRoast(stats)
+	s.corpus.add(username, stats)
+	err := s.history.AppendHistory(HistoryRecord{
+		Username:  strings.ToLower(username),
+		Stats:     stats,
+		Days:      opts.Days,
+		Weights:   opts.Weights,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		slog.Warn("Could not record roast history", "username", username, "error", err)
+	}
+}
+
+// roastResponse renders an analysis into the GET /roast body.
+func (s *server) roastResponse(username string, result *analysis, opts RoastOptions, perRepo bool) RoastResponse {
+	// Rank against everyone analyzed today, this user included
+	result.stats.Percentiles = s.corpus.percentiles(result.stats)
+	roast, truncation := roastText(result.stats, opts)
+	response := RoastResponse{
+		Username:     username,
+		Roast:        roast,
+		Verdict:      roastVerdict(result.stats, opts.Weights, opts.Lang),
+		Stats:        result.stats,
+		Weights:      opts.Weights,
+		Achievements: earnedAchievements(result.stats),
+		Metadata: RoastMetadata{
+			DeepScanAPICalls: result.extraCalls,
+		},
+	}
+	if truncation.Truncated {
+		response.Metadata.Truncated = true
+		response.Metadata.FullLength = truncation.FullLength
+	}
+	if perRepo {
+		response.RepoBreakdown = repoBreakdown(result.repos, result.commits, s.cfg, opts)
+	}
+	return response
+}
+
+// handleRoast serves GET /roast, and POST /roast once handleRoastPost has
+// turned the body into a query string.
+func (s *server) handleRoast(c *gin.Context) {
+	username := c.Query("username")
+	if err := validateGitHubUsername(username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := roastOptions(c, s.cfg)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := responseFormat(c, s.noColor)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Private roasts are never cached, so other callers can't see them
+	_, ownRoast := s.login.tokenFor(c, username)
+	cacheKey := roastCacheKey(username, c.Request.URL.Query())
+	if !ownRoast {
+		if cached, ok := s.cache.Get(c.Request.Context(), cacheKey); ok {
+			s.prefetch.hit(cacheKey)
+			c.Header("X-Cache", "HIT")
+			etag := ""
+			if format == formatJSON {
+				etag = cached.ETag
+			}
+			writeRoast(c, format, cached.hitResponse(), etag)
+			return
+		}
+	}
+
+	result, ok := s.analyze(c, username, opts)
+	if !ok {
+		return
+	}
+	s.record(username, result.stats, opts)
+	rendering := time.Now()
+	response := s.roastResponse(username, result, opts, c.Query("per_repo") == "true")
+	timing := result.timing
+	timing.RenderMs = sinceMs(rendering)
+	timing.TotalMs += timing.RenderMs
+
+	if !ownRoast {
+		s.cache.Set(c.Request.Context(), cacheKey, newCachedRoast(response), s.cacheTTL)
+		s.prefetch.stored(cacheKey, username)
+	}
+	// Timing describes this request only, so it stays out of the cache
+	switch c.Query("debug_timing") {
+	case "true":
+		timing.PerRepoMs = nil
+		response.Timing = &timing
+	case "detailed":
+		response.Timing = &timing
+	}
+	if c.Query("debug") == "true" {
+		response.Debug = &RoastDebug{SingleflightGroupSize: result.groupSize}
+	}
+	c.Header("X-Cache", "MISS")
+	writeRoast(c, format, response, "")
+}
+
+// handleRoastHead serves HEAD /roast from the cache alone, so checking a
+// roast never spends GitHub quota. A cold cache answers 200 without an
+// ETag.
+func (s *server) handleRoastHead(c *gin.Context) {
+	username := c.Query("username")
+	format, err := responseFormat(c, s.noColor)
+	if validateGitHubUsername(username) != nil || err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	_, ownRoast := s.login.tokenFor(c, username)
+	cached, ok := s.cache.Get(c.Request.Context(), roastCacheKey(username, c.Request.URL.Query()))
+	if !ok || ownRoast {
+		c.Header("X-Cache", "MISS")
+		c.Status(http.StatusOK)
+		return
+	}
+	contentType, body, err := renderRoast(format, cached.hitResponse())
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	// The same validator GET sends for this body
+	etag := roastETag(body)
+	if format == formatJSON && cached.ETag != "" {
+		etag = cached.ETag
+	}
+	c.Header("X-Cache", "HIT")
+	c.Header("Vary", "Accept-Encoding, User-Agent")
+	if notModified(c, etag) {
+		return
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Length", strconv.Itoa(len(body)))
+	c.Status(http.StatusOK)
+}
+
+// handleRoastDiff serves GET /roast/:username/diff, comparing a fresh
+// analysis with the most recent stored one that used the same window and
+// weights.
+func (s *server) handleRoastDiff(c *gin.Context) {
+	username := c.Param("username")
+	if err := validateGitHubUsername(username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := roastOptions(c, s.cfg)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous, found, err := s.history.LatestHistory(strings.ToLower(username), opts.Days, opts.Weights)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not read roast history"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "no previous roast with the same days and weights to compare against",
+			"hint":  fmt.Sprintf("roast %s first with GET /roast?username=%s", username, username),
+		})
+		return
+	}
+
+	result, ok := s.analyze(c, username, opts)
+	if !ok {
+		return
+	}
+	s.record(username, result.stats, opts)
+
+	c.JSON(http.StatusOK, gin.H{
+		"username":      username,
+		"previous_at":   previous.CreatedAt.Format(time.RFC3339),
+		"diff":          diffStats(previous.Stats, result.stats, opts.Weights),
+		"current_roast": generateRoast(result.stats, opts),
+	})
+}
+
+// handleSeverity serves GET /severity: just the 0-100 severity as plain
+// text, for scripts that want to gate on it.
+func (s *server) handleSeverity(c *gin.Context) {
+	username := c.Query("username")
+	if err := validateGitHubUsername(username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := roastOptions(c, s.cfg)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, ok := s.analyze(c, username, opts)
+	if !ok {
+		return
+	}
+	severity := 0
+	if result.stats.TotalCommits > 0 {
+		severity = result.stats.Severity
+	}
+	c.String(http.StatusOK, strconv.Itoa(severity))
+}
+
+// handlePing serves GET /ping and /health without touching GitHub, the
+// cache or the store.
+func handlePing(c *gin.Context) {
+	c.Header("Cache-Control", "no-cache, no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"pong":      true,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// handleVersion serves GET /version, the build metadata of this binary.
+func handleVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get())
+}
+
+// githubError is the status and body reporting a failed GitHub call.
+func githubError(err error) (int, gin.H) {
+	if rateLimitErr, ok := err.(*github.RateLimitError); ok {
+		resetTime := rateLimitErr.Rate.Reset.Format(time.RFC1123)
+		return http.StatusTooManyRequests, gin.H{
+			"error":      "GitHub API rate limit exceeded",
+			"reset_time": resetTime,
+			"solution":   "Create a .env file with GITHUB_TOKEN in your server directory",
+		}
+	} else if unavailableReason(err) == skipUnavailableLegal {
+		return http.StatusUnavailableForLegalReasons, gin.H{
+			"error":   "GitHub blocked this content for legal reasons",
+			"details": "the repository is unavailable, usually because of a DMCA takedown",
+		}
+	} else if errors.Is(err, errCallBudgetExhausted) {
+		return http.StatusServiceUnavailable, gin.H{"error": err.Error()}
+	}
+	return http.StatusInternalServerError, gin.H{
+		"error":   "Failed to fetch GitHub data",
+		"details": err.Error(),
+	}
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v50/github"
+)
+
+func TestPing(t *testing.T) {
+	r := gin.New()
+	r.GET("/ping", handlePing)
+	ping := func() (*httptest.ResponseRecorder, time.Duration) {
+		w := httptest.NewRecorder()
+		start := time.Now()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		return w, time.Since(start)
+	}
+	ping() // the first call pays for lazy initialization in encoding/json
+
+	w, took := ping()
+	if took > 5*time.Millisecond {
+		t.Errorf("/ping took %v, want under 5ms", took)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d", w.Code)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-cache, no-store" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	var body struct {
+		Pong      bool   `json:"pong"`
+		Timestamp string `json:"timestamp"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := time.Parse(time.RFC3339, body.Timestamp); !body.Pong || err != nil {
+		t.Errorf("body = %s", w.Body)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	w := doRequest(handleVersion, http.MethodGet, "/version", "/version", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("/version is not JSON: %v\n%s", err, w.Body)
+	}
+	for _, key := range []string{"version", "git_commit", "build_time", "go_version"} {
+		if body[key] == "" {
+			t.Errorf("%s missing from %s", key, w.Body)
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	var fixes []*github.RepositoryCommit
+	for i := range 10 {
+		fixes = append(fixes, fakeCommit("Octo", "fix it again", i+1))
+	}
+	tests := []struct {
+		name    string
+		commits []*github.RepositoryCommit
+		target  string
+		status  int
+		zero    bool
+	}{
+		{"no commits", nil, "/severity?username=octocat", http.StatusOK, true},
+		{"plenty to roast", fixes, "/severity?username=octocat", http.StatusOK, false},
+		{"no username", nil, "/severity", http.StatusBadRequest, false},
+		{"unknown user", nil, "/severity?username=nobody", http.StatusNotFound, false},
+	}
+	bareInt := regexp.MustCompile(`^(?:0|[1-9]\d?|100)$`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, newFakeGitHub("octocat", tt.commits...))
+			w := doRequest(s.handleSeverity, http.MethodGet, "/severity", tt.target, "")
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			body := w.Body.String()
+			if !bareInt.MatchString(body) {
+				t.Fatalf("body = %q, want a bare integer from 0 to 100", body)
+			}
+			if (body == "0") != tt.zero {
+				t.Errorf("severity = %s", body)
+			}
+		})
+	}
+}
+
+func TestRoastGitHubTimeout(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	gh.delay = 5 * time.Second
+	s := newTestServer(t, gh)
+	s.githubTimeout = time.Second
+
+	start := time.Now()
+	w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat", "")
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("took %s, want about the 1s timeout", elapsed)
+	}
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Error          string `json:"error"`
+		TimeoutSeconds int    `json:"timeout_seconds"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error != "GitHub API timeout" || body.TimeoutSeconds != 1 {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestRoastUserLookupErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		gh     http.Handler
+		status int
+		error  string
+	}{
+		{
+			"no such user",
+			newFakeGitHub("octocat"),
+			http.StatusNotFound, "GitHub user not found",
+		},
+		{
+			"GitHub outage",
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"message":"Server Error"}`))
+			}),
+			http.StatusInternalServerError, "Failed to fetch GitHub data",
+		},
+		{
+			"network error",
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err == nil {
+					conn.Close()
+				}
+			}),
+			http.StatusInternalServerError, "Failed to fetch GitHub data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.gh)
+			w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=ghost", "")
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != tt.error {
+				t.Errorf("error = %q, want %q", body.Error, tt.error)
+			}
+		})
+	}
+}
+
+func TestRoastCacheHitStat(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix login", 2)))
+	for i, want := range []bool{false, true, true} {
+		w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat", "")
+		var resp RoastResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Stats.CacheHit != want || (w.Header().Get("X-Cache") == "HIT") != want {
+			t.Errorf("request %d: cache_hit %v, X-Cache %q; want %v", i+1, resp.Stats.CacheHit, w.Header().Get("X-Cache"), want)
+		}
+	}
+	// The cached entry itself stays as analyzed
+	cached, ok := s.cache.Get(t.Context(), roastCacheKey("octocat", url.Values{"username": {"octocat"}}))
+	if !ok {
+		t.Fatal("roast not cached")
+	}
+	if cached.Response.Stats.CacheHit {
+		t.Error("cache_hit stored in the cache")
+	}
+}
+
+func TestRoastHead(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix login", 2))
+	s := newTestServer(t, gh)
+	r := gin.New()
+	allowMethodNotAllowed(r)
+	r.GET("/roast", s.handleRoast)
+	r.HEAD("/roast", s.handleRoastHead)
+	send := func(method string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, "/roast?username=octocat", nil))
+		return w
+	}
+
+	// A cold cache answers without asking GitHub
+	w := send(http.MethodHead)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" || w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("cold HEAD: status %d, ETag %q, X-Cache %q", w.Code, w.Header().Get("ETag"), w.Header().Get("X-Cache"))
+	}
+	if calls := gh.callCount("/users/octocat") + gh.callCount("/users/octocat/repos"); calls != 0 {
+		t.Errorf("cold HEAD made %d GitHub calls", calls)
+	}
+
+	send(http.MethodGet)
+	get := send(http.MethodGet)
+	if get.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("second GET: X-Cache = %q", get.Header().Get("X-Cache"))
+	}
+	calls := gh.callCount("/users/octocat")
+
+	w = send(http.MethodHead)
+	if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("warm HEAD: status %d, X-Cache %q", w.Code, w.Header().Get("X-Cache"))
+	}
+	if w.Header().Get("ETag") == "" || w.Header().Get("ETag") != get.Header().Get("ETag") {
+		t.Errorf("warm HEAD ETag = %q, GET's = %q", w.Header().Get("ETag"), get.Header().Get("ETag"))
+	}
+	if want := strconv.Itoa(get.Body.Len()); w.Header().Get("Content-Length") != want {
+		t.Errorf("Content-Length = %q, want %s", w.Header().Get("Content-Length"), want)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("HEAD sent a %d byte body", w.Body.Len())
+	}
+	if gh.callCount("/users/octocat") != calls {
+		t.Error("warm HEAD called GitHub")
+	}
+
+	w = send(http.MethodPost)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST to a GET-only route: status = %d, want 405", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); !strings.Contains(allow, "GET") || !strings.Contains(allow, "HEAD") {
+		t.Errorf("Allow = %q, want GET and HEAD", allow)
+	}
+}
+
+func TestRoastHandlerConcurrency(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2), fakeCommit("Octo", "wip", 30), fakeCommit("Octo", "fix again", 50))
+	gh.delay = 20 * time.Millisecond
+	s := newTestServer(t, gh)
+	r := gin.New()
+	r.GET("/roast", s.handleRoast)
+
+	const callers = 50
+	var (
+		start  = make(chan struct{})
+		wg     sync.WaitGroup
+		codes  [callers]int
+		bodies [callers]string
+	)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/roast?username=octocat", nil))
+			codes[i], bodies[i] = w.Code, w.Body.String()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	for i := range callers {
+		if codes[i] != http.StatusOK {
+			t.Fatalf("request %d: status = %d: %s", i, codes[i], bodies[i])
+		}
+		if bodies[i] != bodies[0] {
+			t.Errorf("request %d got a different roast:\n%s\n%s", i, bodies[i], bodies[0])
+		}
+	}
+	if calls := gh.callCount("/repos/octocat/project/commits"); calls != 1 {
+		t.Errorf("%d concurrent roasts fetched commits %d times, want once", callers, calls)
+	}
+}
+
+func TestRepoListOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		includePrivate bool
+		user           string
+		want           *github.RepositoryListOptions
+	}{
+		{
+			"public repos",
+			false, "octocat",
+			&github.RepositoryListOptions{Type: "owner", Sort: "updated", Direction: "desc", ListOptions: github.ListOptions{PerPage: 10}},
+		},
+		{
+			"private repos too",
+			true, "",
+			&github.RepositoryListOptions{Visibility: "all", Affiliation: "owner", Sort: "updated", Direction: "desc", ListOptions: github.ListOptions{PerPage: 10}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, opts := repoListOptions("octocat", tt.includePrivate)
+			if user != tt.user || !reflect.DeepEqual(opts, tt.want) {
+				t.Errorf("repoListOptions() = %q, %+v, want %q, %+v", user, opts, tt.user, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoastIncludePrivate(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		loggedIn bool
+		path     string // where repos were listed
+		query    map[string]string
+		private  bool
+	}{
+		{
+			"without a token",
+			"/roast?username=octocat&include_private=true", false,
+			"/users/octocat/repos", map[string]string{"type": "owner"}, false,
+		},
+		{
+			"own token, not asked for",
+			"/roast?username=octocat", true,
+			"/users/octocat/repos", map[string]string{"type": "owner"}, false,
+		},
+		{
+			"own token",
+			"/roast?username=octocat&include_private=true", true,
+			"/user/repos", map[string]string{"visibility": "all", "affiliation": "owner", "type": ""}, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 1))
+			s := newTestServer(t, gh)
+			s.login, _ = newTestLogin(t)
+			r := gin.New()
+			r.GET("/roast", s.handleRoast)
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.loggedIn {
+				id, err := s.login.createSession("octocat", "gho_token")
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.AddCookie(&http.Cookie{Name: sessionCookie, Value: s.login.sign(id)})
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+
+			if gh.callCount(tt.path) != 1 {
+				t.Fatalf("repos not listed from %s", tt.path)
+			}
+			query := gh.lastQuery(tt.path)
+			for name, want := range tt.query {
+				if got := query.Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			var body struct {
+				Stats struct {
+					PrivateCommitsIncluded bool `json:"private_commits_included"`
+				} `json:"stats"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Stats.PrivateCommitsIncluded != tt.private {
+				t.Errorf("private_commits_included = %v, want %v", body.Stats.PrivateCommitsIncluded, tt.private)
+			}
+		})
+	}
+}
+
+func TestRoastMaxChars(t *testing.T) {
+	var commits []*github.RepositoryCommit
+	for i := range 10 {
+		commits = append(commits, fakeCommit("Octo", "fix it again", i+1))
+	}
+	tests := []struct {
+		name      string
+		query     string
+		status    int
+		truncated bool
+	}{
+		{"no limit", "", http.StatusOK, false},
+		{"generous limit", "&max_chars=100000", http.StatusOK, false},
+		{"smaller than any line", "&max_chars=1", http.StatusOK, true},
+		{"negative", "&max_chars=-1", http.StatusBadRequest, false},
+		{"not a number", "&max_chars=short", http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, newFakeGitHub("octocat", commits...))
+			w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat"+tt.query, "")
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp RoastResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Metadata.Truncated != tt.truncated {
+				t.Errorf("truncated = %v, want %v", resp.Metadata.Truncated, tt.truncated)
+			}
+			if tt.truncated {
+				if resp.Roast != truncationMarker {
+					t.Errorf("roast = %q, want only the marker", resp.Roast)
+				}
+				if resp.Metadata.FullLength <= len([]rune(resp.Roast)) {
+					t.Errorf("full_length = %d, want the uncut length", resp.Metadata.FullLength)
+				}
+			} else if resp.Metadata.FullLength != 0 || strings.Contains(resp.Roast, truncationMarker) {
+				t.Errorf("uncut roast reports full_length %d: %q", resp.Metadata.FullLength, resp.Roast)
+			}
+		})
+	}
+}
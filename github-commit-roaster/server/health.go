@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// defaultPort is used when PORT isn't set.
+const defaultPort = "8080"
+
+// runHealthCheck implements the -health flag: it pings the server running
+// on port and returns the process exit code, for container images that have
+// no shell or curl to do it.
+func runHealthCheck(port string) int {
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get("http://127.0.0.1:" + port + "/ping")
+	if err != nil {
+		slog.Error("unhealthy", "error", err)
+		return 1
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("unhealthy", "status", resp.StatusCode)
+		return 1
+	}
+	return 0
+}
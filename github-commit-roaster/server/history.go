@@ -0,0 +1,80 @@
+package main
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// maxMemoryHistory caps how many records per user the in-memory history keeps.
+const maxMemoryHistory = 50
+
+// HistoryRecord is one past analysis of a user.
+type HistoryRecord struct {
+	Username string
+	Stats    CommitStats
+	// Days and Weights are the options the analysis ran with; scores are
+	// only comparable between records that share them. Days is 0 for an
+	// explicit since/until range.
+	Days      int
+	Weights   Weights
+	CreatedAt time.Time
+}
+
+// historyStore keeps past analyses so roasts can be compared over time.
+type historyStore interface {
+	AppendHistory(rec HistoryRecord) error
+	// LatestHistory returns username's latest record analyzed over the same
+	// days and with the same weights.
+	LatestHistory(username string, days int, weights Weights) (HistoryRecord, bool, error)
+	// RecentHistory returns up to n of username's latest records, oldest first.
+	RecentHistory(username string, n int) ([]HistoryRecord, error)
+}
+
+// newHistoryStore persists history in store when one is configured and
+// falls back to memory otherwise.
+func newHistoryStore(store *Store) historyStore {
+	if store != nil {
+		return store
+	}
+	return &memoryHistory{records: make(map[string][]HistoryRecord)}
+}
+
+// memoryHistory is a historyStore that forgets everything on restart.
+type memoryHistory struct {
+	mu      sync.Mutex
+	records map[string][]HistoryRecord
+}
+
+func (h *memoryHistory) AppendHistory(rec HistoryRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recs := append(h.records[rec.Username], rec)
+	if len(recs) > maxMemoryHistory {
+		recs = recs[len(recs)-maxMemoryHistory:]
+	}
+	h.records[rec.Username] = recs
+	return nil
+}
+
+func (h *memoryHistory) LatestHistory(username string, days int, weights Weights) (HistoryRecord, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recs := h.records[username]
+	for i := len(recs) - 1; i >= 0; i-- {
+		if recs[i].Days == days && maps.Equal(recs[i].Weights, weights) {
+			return recs[i], true, nil
+		}
+	}
+	return HistoryRecord{}, false, nil
+}
+
+func (h *memoryHistory) RecentHistory(username string, n int) ([]HistoryRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recs := h.records[username]
+	if len(recs) > n {
+		recs = recs[len(recs)-n:]
+	}
+	return append([]HistoryRecord(nil), recs...), nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import "fmt"
+
+// HourWindow is a half-open range of hours [Start, End). It wraps past
+// midnight when Start > End, so 22-5 covers 22:00 to 04:59.
+type HourWindow struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+var (
+	defaultLateNight = HourWindow{Start: 22, End: 5}
+	defaultWorkHours = HourWindow{Start: 9, End: 17}
+)
+
+// contains reports whether hour (0-23) falls in the window.
+func (w HourWindow) contains(hour int) bool {
+	if w.Start <= w.End {
+		return hour >= w.Start && hour < w.End
+	}
+	return hour >= w.Start || hour < w.End
+}
+
+func (w HourWindow) valid() bool {
+	return w.Start >= 0 && w.Start < 24 && w.End >= 0 && w.End < 24 && w.Start != w.End
+}
+
+// String formats the window as "22:00 and 05:00", for use after "between".
+func (w HourWindow) String() string {
+	return fmt.Sprintf("%02d:00 and %02d:00", w.Start, w.End)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHourWindowContains(t *testing.T) {
+	tests := []struct {
+		name   string
+		window HourWindow
+		in     []int
+	}{
+		{"default late night", defaultLateNight, []int{22, 23, 0, 1, 2, 3, 4}},
+		{"early hours only", HourWindow{Start: 1, End: 4}, []int{1, 2, 3}},
+		{"work hours", defaultWorkHours, []int{9, 10, 11, 12, 13, 14, 15, 16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := make(map[int]bool)
+			for _, h := range tt.in {
+				in[h] = true
+			}
+			for hour := range 24 {
+				if got := tt.window.contains(hour); got != in[hour] {
+					t.Errorf("%v contains(%d) = %v, want %v", tt.window, hour, got, in[hour])
+				}
+			}
+		})
+	}
+}
+
+func TestLateNightConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		want       HourWindow
+	}{
+		{"defaults", "", "", defaultLateNight},
+		{"doesn't cross midnight", "1", "4", HourWindow{Start: 1, End: 4}},
+		{"empty window", "3", "3", defaultLateNight},
+		{"out of range", "22", "24", defaultLateNight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LATE_NIGHT_START", tt.start)
+			t.Setenv("LATE_NIGHT_END", tt.end)
+			cfg := loadRoastConfig()
+			if cfg.LateNight != tt.want {
+				t.Fatalf("LateNight = %+v, want %+v", cfg.LateNight, tt.want)
+			}
+
+			// One commit per hour of the day
+			day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+			commits := messages(make([]string, 24)...)
+			for i := range commits {
+				commits[i].Date = day.Add(time.Duration(i) * time.Hour)
+			}
+			stats := analyzeCommits(commits, cfg)
+			if want := (tt.want.End - tt.want.Start + 24) % 24; stats.LateNightCommits != want {
+				t.Errorf("LateNightCommits = %d, want %d", stats.LateNightCommits, want)
+			}
+			if stats.LateNightWindow != tt.want {
+				t.Errorf("stats report window %+v, want %+v", stats.LateNightWindow, tt.want)
+			}
+			if line := findRule(t, "late_night").line(stats, defaultIntensity); !strings.Contains(line, "between "+tt.want.String()) {
+				t.Errorf("roast line doesn't name the window %v: %q", tt.want, line)
+			}
+		})
+	}
+}
+
+// commitsAtHours is one commit at each of the given UTC hours, a day apart.
+func commitsAtHours(hours ...int) []NormalizedCommit {
+	offsets := make([]time.Duration, len(hours))
+	for i, hour := range hours {
+		offsets[i] = time.Duration(24*i+hour) * time.Hour
+	}
+	return commitsAt(time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), offsets...)
+}
+
+func TestWorkHours(t *testing.T) {
+	officeHours := commitsAtHours(9, 10, 10, 11, 13, 14, 14, 15, 16, 16)
+	nightOwl := commitsAtHours(19, 20, 21, 22, 23, 0, 1, 6, 7, 8)
+	tests := []struct {
+		name       string
+		start, end string
+		commits    []NormalizedCommit
+		window     HourWindow
+		share      float64
+		fired      string
+	}{
+		{"work-hours heavy", "", "", officeHours, defaultWorkHours, 1, "nine_to_five"},
+		{"off-hours heavy", "", "", nightOwl, defaultWorkHours, 0, "off_hours"},
+		{"a bit of both", "", "", commitsAtHours(9, 10, 11, 12, 13, 20, 21, 22, 23, 0), defaultWorkHours, 0.5, ""},
+		{"too few commits", "", "", commitsAtHours(9, 10, 11), defaultWorkHours, 1, ""},
+		{"night shift window", "20", "2", nightOwl, HourWindow{Start: 20, End: 2}, 0.6, ""},
+		{"early shift window", "6", "14", nightOwl, HourWindow{Start: 6, End: 14}, 0.3, ""},
+		{"invalid window falls back", "9", "25", officeHours, defaultWorkHours, 1, "nine_to_five"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WORK_HOURS_START", tt.start)
+			t.Setenv("WORK_HOURS_END", tt.end)
+			cfg := loadRoastConfig()
+			if cfg.WorkHours != tt.window {
+				t.Fatalf("WorkHours = %+v, want %+v", cfg.WorkHours, tt.window)
+			}
+			stats := analyzeCommits(tt.commits, cfg)
+			if stats.WorkHoursShare != tt.share || stats.WorkHoursWindow != tt.window {
+				t.Errorf("work hours share %v in %v, want %v in %v", stats.WorkHoursShare, stats.WorkHoursWindow, tt.share, tt.window)
+			}
+			for _, id := range []string{"nine_to_five", "off_hours"} {
+				if got := findRule(t, id).Triggered(stats); got != (id == tt.fired) {
+					t.Errorf("%s triggered = %v", id, got)
+				}
+			}
+			if tt.fired != "" {
+				if line := findRule(t, tt.fired).line(stats, defaultIntensity); !strings.Contains(line, "between "+tt.window.String()) {
+					t.Errorf("roast line doesn't name the window %v: %q", tt.window, line)
+				}
+			}
+		})
+	}
+}
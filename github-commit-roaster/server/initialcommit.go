@@ -0,0 +1,26 @@
+package main
+
+import "strings"
+
+// isInitialCommit reports whether message is the stock "Initial commit",
+// which GitHub writes itself when a repo is created with a README. It says
+// nothing about how the user writes messages.
+func isInitialCommit(message string) bool {
+	return strings.EqualFold(strings.TrimSpace(message), "initial commit")
+}
+
+// countInitialCommitOnlyRepos counts repos whose only commit in the window
+// is "Initial commit".
+func countInitialCommitOnlyRepos(commits []NormalizedCommit) int {
+	byRepo := make(map[string][]NormalizedCommit)
+	for _, commit := range commits {
+		byRepo[commit.Repo] = append(byRepo[commit.Repo], commit)
+	}
+	count := 0
+	for _, repoCommits := range byRepo {
+		if len(repoCommits) == 1 && isInitialCommit(repoCommits[0].Message) {
+			count++
+		}
+	}
+	return count
+}
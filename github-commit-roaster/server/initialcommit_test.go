@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsInitialCommit(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"Initial commit", true},
+		{"initial commit\n", true},
+		{"  INITIAL COMMIT  ", true},
+		{"Initial commit of the parser", false},
+		{"initial", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isInitialCommit(tt.msg); got != tt.want {
+			t.Errorf("isInitialCommit(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestCountInitialCommitOnlyRepos(t *testing.T) {
+	commit := func(repo, msg string) NormalizedCommit { return NormalizedCommit{Repo: repo, Message: msg} }
+	tests := []struct {
+		name    string
+		commits []NormalizedCommit
+		want    int
+	}{
+		{"no commits", nil, 0},
+		{"one abandoned repo", []NormalizedCommit{commit("a", "Initial commit")}, 1},
+		{
+			"repos that moved on don't count",
+			[]NormalizedCommit{commit("a", "Initial commit"), commit("a", "add parser"), commit("b", "Initial commit"), commit("c", "add readme")},
+			1,
+		},
+		{"a custom first message isn't stock", []NormalizedCommit{commit("a", "first!")}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countInitialCommitOnlyRepos(tt.commits); got != tt.want {
+				t.Errorf("countInitialCommitOnlyRepos() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitialCommitGraveyardRule(t *testing.T) {
+	rule := findRule(t, "initial_commit_graveyard")
+	tests := []struct {
+		name        string
+		onlyInitial int
+		repos       int
+		want        bool
+	}{
+		{"one repo isn't a graveyard", 1, 1, false},
+		{"a third exactly", 2, 6, false},
+		{"over a third", 2, 5, true},
+		{"every repo", 6, 6, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{InitialCommitOnlyRepos: tt.onlyInitial, ReposAnalyzed: tt.repos}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Fatalf("Triggered() = %v, want %v", got, tt.want)
+			}
+			if tt.want && !strings.Contains(rule.line(stats, 3), "graveyard of ambition") {
+				t.Errorf("line = %q", rule.line(stats, 3))
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package github narrows the go-github client to the calls the roaster
+// makes, one interface per service, so tests can stand in for GitHub with
+// the mocks in mock_client.go.
+package github
+
+//go:generate go tool mockgen -source=client.go -destination=mock_client.go -package=github
+
+import (
+	"context"
+
+	gogithub "github.com/google/go-github/v50/github"
+)
+
+// UsersService is the part of go-github's UsersService the roaster uses.
+type UsersService interface {
+	Get(ctx context.Context, user string) (*gogithub.User, *gogithub.Response, error)
+}
+
+// RepositoriesService is the part of go-github's RepositoriesService the
+// roaster uses.
+type RepositoriesService interface {
+	List(ctx context.Context, user string, opts *gogithub.RepositoryListOptions) ([]*gogithub.Repository, *gogithub.Response, error)
+	ListCommits(ctx context.Context, owner, repo string, opts *gogithub.CommitsListOptions) ([]*gogithub.RepositoryCommit, *gogithub.Response, error)
+	GetCommit(ctx context.Context, owner, repo, sha string, opts *gogithub.ListOptions) (*gogithub.RepositoryCommit, *gogithub.Response, error)
+	ListLanguages(ctx context.Context, owner, repo string) (map[string]int, *gogithub.Response, error)
+	ListContributors(ctx context.Context, owner, repo string, opts *gogithub.ListContributorsOptions) ([]*gogithub.Contributor, *gogithub.Response, error)
+	GetContents(ctx context.Context, owner, repo, path string, opts *gogithub.RepositoryContentGetOptions) (*gogithub.RepositoryContent, []*gogithub.RepositoryContent, *gogithub.Response, error)
+	CreateComment(ctx context.Context, owner, repo, sha string, comment *gogithub.RepositoryComment) (*gogithub.RepositoryComment, *gogithub.Response, error)
+}
+
+// SearchService is the part of go-github's SearchService the roaster uses.
+type SearchService interface {
+	Commits(ctx context.Context, query string, opts *gogithub.SearchOptions) (*gogithub.CommitsSearchResult, *gogithub.Response, error)
+}
+
+// GitService is the part of go-github's GitService the roaster uses.
+type GitService interface {
+	GetTree(ctx context.Context, owner, repo, sha string, recursive bool) (*gogithub.Tree, *gogithub.Response, error)
+}
+
+// IssuesService is the part of go-github's IssuesService the roaster uses.
+type IssuesService interface {
+	ListByRepo(ctx context.Context, owner, repo string, opts *gogithub.IssueListByRepoOptions) ([]*gogithub.Issue, *gogithub.Response, error)
+}
+
+// PullRequestsService is the part of go-github's PullRequestsService the
+// roaster uses.
+type PullRequestsService interface {
+	List(ctx context.Context, owner, repo string, opts *gogithub.PullRequestListOptions) ([]*gogithub.PullRequest, *gogithub.Response, error)
+}
+
+// RateLimitsService is go-github's Client.RateLimits, which v50 has on the
+// client itself rather than on a service.
+type RateLimitsService interface {
+	RateLimits(ctx context.Context) (*gogithub.RateLimits, *gogithub.Response, error)
+}
+
+// Client mirrors the service fields of go-github's Client, so code written
+// against one reads the same against the other.
+type Client struct {
+	RateLimitsService
+
+	Users        UsersService
+	Repositories RepositoriesService
+	Search       SearchService
+	Git          GitService
+	Issues       IssuesService
+	PullRequests PullRequestsService
+}
+
+// New wraps a go-github client.
+func New(client *gogithub.Client) *Client {
+	return &Client{
+		RateLimitsService: client,
+
+		Users:        client.Users,
+		Repositories: client.Repositories,
+		Search:       client.Search,
+		Git:          client.Git,
+		Issues:       client.Issues,
+		PullRequests: client.PullRequests,
+	}
+}
@@ -0,0 +1,395 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: client.go
+//
+// Generated by this command:
+//
+//	mockgen -source=client.go -destination=mock_client.go -package=github
+//
+
+// Package github is a generated GoMock package.
+package github
+
+import (
+	context "context"
+	reflect "reflect"
+
+	github "github.com/google/go-github/v50/github"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUsersService is a mock of UsersService interface.
+type MockUsersService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockUsersServiceMockRecorder is the mock recorder for MockUsersService.
+type MockUsersServiceMockRecorder struct {
+	mock *MockUsersService
+}
+
+// NewMockUsersService creates a new mock instance.
+func NewMockUsersService(ctrl *gomock.Controller) *MockUsersService {
+	mock := &MockUsersService{ctrl: ctrl}
+	mock.recorder = &MockUsersServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsersService) EXPECT() *MockUsersServiceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockUsersService) Get(ctx context.Context, user string) (*github.User, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, user)
+	ret0, _ := ret[0].(*github.User)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockUsersServiceMockRecorder) Get(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUsersService)(nil).Get), ctx, user)
+}
+
+// MockRepositoriesService is a mock of RepositoriesService interface.
+type MockRepositoriesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoriesServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoriesServiceMockRecorder is the mock recorder for MockRepositoriesService.
+type MockRepositoriesServiceMockRecorder struct {
+	mock *MockRepositoriesService
+}
+
+// NewMockRepositoriesService creates a new mock instance.
+func NewMockRepositoriesService(ctrl *gomock.Controller) *MockRepositoriesService {
+	mock := &MockRepositoriesService{ctrl: ctrl}
+	mock.recorder = &MockRepositoriesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepositoriesService) EXPECT() *MockRepositoriesServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateComment mocks base method.
+func (m *MockRepositoriesService) CreateComment(ctx context.Context, owner, repo, sha string, comment *github.RepositoryComment) (*github.RepositoryComment, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", ctx, owner, repo, sha, comment)
+	ret0, _ := ret[0].(*github.RepositoryComment)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateComment indicates an expected call of CreateComment.
+func (mr *MockRepositoriesServiceMockRecorder) CreateComment(ctx, owner, repo, sha, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockRepositoriesService)(nil).CreateComment), ctx, owner, repo, sha, comment)
+}
+
+// GetCommit mocks base method.
+func (m *MockRepositoriesService) GetCommit(ctx context.Context, owner, repo, sha string, opts *github.ListOptions) (*github.RepositoryCommit, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommit", ctx, owner, repo, sha, opts)
+	ret0, _ := ret[0].(*github.RepositoryCommit)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCommit indicates an expected call of GetCommit.
+func (mr *MockRepositoriesServiceMockRecorder) GetCommit(ctx, owner, repo, sha, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommit", reflect.TypeOf((*MockRepositoriesService)(nil).GetCommit), ctx, owner, repo, sha, opts)
+}
+
+// GetContents mocks base method.
+func (m *MockRepositoriesService) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContents", ctx, owner, repo, path, opts)
+	ret0, _ := ret[0].(*github.RepositoryContent)
+	ret1, _ := ret[1].([]*github.RepositoryContent)
+	ret2, _ := ret[2].(*github.Response)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetContents indicates an expected call of GetContents.
+func (mr *MockRepositoriesServiceMockRecorder) GetContents(ctx, owner, repo, path, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContents", reflect.TypeOf((*MockRepositoriesService)(nil).GetContents), ctx, owner, repo, path, opts)
+}
+
+// List mocks base method.
+func (m *MockRepositoriesService) List(ctx context.Context, user string, opts *github.RepositoryListOptions) ([]*github.Repository, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, user, opts)
+	ret0, _ := ret[0].([]*github.Repository)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoriesServiceMockRecorder) List(ctx, user, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepositoriesService)(nil).List), ctx, user, opts)
+}
+
+// ListCommits mocks base method.
+func (m *MockRepositoriesService) ListCommits(ctx context.Context, owner, repo string, opts *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCommits", ctx, owner, repo, opts)
+	ret0, _ := ret[0].([]*github.RepositoryCommit)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListCommits indicates an expected call of ListCommits.
+func (mr *MockRepositoriesServiceMockRecorder) ListCommits(ctx, owner, repo, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommits", reflect.TypeOf((*MockRepositoriesService)(nil).ListCommits), ctx, owner, repo, opts)
+}
+
+// ListContributors mocks base method.
+func (m *MockRepositoriesService) ListContributors(ctx context.Context, owner, repo string, opts *github.ListContributorsOptions) ([]*github.Contributor, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContributors", ctx, owner, repo, opts)
+	ret0, _ := ret[0].([]*github.Contributor)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListContributors indicates an expected call of ListContributors.
+func (mr *MockRepositoriesServiceMockRecorder) ListContributors(ctx, owner, repo, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContributors", reflect.TypeOf((*MockRepositoriesService)(nil).ListContributors), ctx, owner, repo, opts)
+}
+
+// ListLanguages mocks base method.
+func (m *MockRepositoriesService) ListLanguages(ctx context.Context, owner, repo string) (map[string]int, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLanguages", ctx, owner, repo)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListLanguages indicates an expected call of ListLanguages.
+func (mr *MockRepositoriesServiceMockRecorder) ListLanguages(ctx, owner, repo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLanguages", reflect.TypeOf((*MockRepositoriesService)(nil).ListLanguages), ctx, owner, repo)
+}
+
+// MockSearchService is a mock of SearchService interface.
+type MockSearchService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockSearchServiceMockRecorder is the mock recorder for MockSearchService.
+type MockSearchServiceMockRecorder struct {
+	mock *MockSearchService
+}
+
+// NewMockSearchService creates a new mock instance.
+func NewMockSearchService(ctrl *gomock.Controller) *MockSearchService {
+	mock := &MockSearchService{ctrl: ctrl}
+	mock.recorder = &MockSearchServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchService) EXPECT() *MockSearchServiceMockRecorder {
+	return m.recorder
+}
+
+// Commits mocks base method.
+func (m *MockSearchService) Commits(ctx context.Context, query string, opts *github.SearchOptions) (*github.CommitsSearchResult, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commits", ctx, query, opts)
+	ret0, _ := ret[0].(*github.CommitsSearchResult)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Commits indicates an expected call of Commits.
+func (mr *MockSearchServiceMockRecorder) Commits(ctx, query, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commits", reflect.TypeOf((*MockSearchService)(nil).Commits), ctx, query, opts)
+}
+
+// MockGitService is a mock of GitService interface.
+type MockGitService struct {
+	ctrl     *gomock.Controller
+	recorder *MockGitServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockGitServiceMockRecorder is the mock recorder for MockGitService.
+type MockGitServiceMockRecorder struct {
+	mock *MockGitService
+}
+
+// NewMockGitService creates a new mock instance.
+func NewMockGitService(ctrl *gomock.Controller) *MockGitService {
+	mock := &MockGitService{ctrl: ctrl}
+	mock.recorder = &MockGitServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGitService) EXPECT() *MockGitServiceMockRecorder {
+	return m.recorder
+}
+
+// GetTree mocks base method.
+func (m *MockGitService) GetTree(ctx context.Context, owner, repo, sha string, recursive bool) (*github.Tree, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTree", ctx, owner, repo, sha, recursive)
+	ret0, _ := ret[0].(*github.Tree)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTree indicates an expected call of GetTree.
+func (mr *MockGitServiceMockRecorder) GetTree(ctx, owner, repo, sha, recursive any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockGitService)(nil).GetTree), ctx, owner, repo, sha, recursive)
+}
+
+// MockIssuesService is a mock of IssuesService interface.
+type MockIssuesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIssuesServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockIssuesServiceMockRecorder is the mock recorder for MockIssuesService.
+type MockIssuesServiceMockRecorder struct {
+	mock *MockIssuesService
+}
+
+// NewMockIssuesService creates a new mock instance.
+func NewMockIssuesService(ctrl *gomock.Controller) *MockIssuesService {
+	mock := &MockIssuesService{ctrl: ctrl}
+	mock.recorder = &MockIssuesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIssuesService) EXPECT() *MockIssuesServiceMockRecorder {
+	return m.recorder
+}
+
+// ListByRepo mocks base method.
+func (m *MockIssuesService) ListByRepo(ctx context.Context, owner, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByRepo", ctx, owner, repo, opts)
+	ret0, _ := ret[0].([]*github.Issue)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListByRepo indicates an expected call of ListByRepo.
+func (mr *MockIssuesServiceMockRecorder) ListByRepo(ctx, owner, repo, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByRepo", reflect.TypeOf((*MockIssuesService)(nil).ListByRepo), ctx, owner, repo, opts)
+}
+
+// MockPullRequestsService is a mock of PullRequestsService interface.
+type MockPullRequestsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPullRequestsServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockPullRequestsServiceMockRecorder is the mock recorder for MockPullRequestsService.
+type MockPullRequestsServiceMockRecorder struct {
+	mock *MockPullRequestsService
+}
+
+// NewMockPullRequestsService creates a new mock instance.
+func NewMockPullRequestsService(ctrl *gomock.Controller) *MockPullRequestsService {
+	mock := &MockPullRequestsService{ctrl: ctrl}
+	mock.recorder = &MockPullRequestsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPullRequestsService) EXPECT() *MockPullRequestsServiceMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockPullRequestsService) List(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, owner, repo, opts)
+	ret0, _ := ret[0].([]*github.PullRequest)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockPullRequestsServiceMockRecorder) List(ctx, owner, repo, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPullRequestsService)(nil).List), ctx, owner, repo, opts)
+}
+
+// MockRateLimitsService is a mock of RateLimitsService interface.
+type MockRateLimitsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitsServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockRateLimitsServiceMockRecorder is the mock recorder for MockRateLimitsService.
+type MockRateLimitsServiceMockRecorder struct {
+	mock *MockRateLimitsService
+}
+
+// NewMockRateLimitsService creates a new mock instance.
+func NewMockRateLimitsService(ctrl *gomock.Controller) *MockRateLimitsService {
+	mock := &MockRateLimitsService{ctrl: ctrl}
+	mock.recorder = &MockRateLimitsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitsService) EXPECT() *MockRateLimitsServiceMockRecorder {
+	return m.recorder
+}
+
+// RateLimits mocks base method.
+func (m *MockRateLimitsService) RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimits", ctx)
+	ret0, _ := ret[0].(*github.RateLimits)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RateLimits indicates an expected call of RateLimits.
+func (mr *MockRateLimitsServiceMockRecorder) RateLimits(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimits", reflect.TypeOf((*MockRateLimitsService)(nil).RateLimits), ctx)
+}
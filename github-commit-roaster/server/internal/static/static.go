@@ -0,0 +1,13 @@
+// Package static holds the minimal web frontend embedded in the binary.
+package static
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed web/**
+var files embed.FS
+
+// Files is the frontend with index.html at its root.
+var Files, _ = fs.Sub(files, "web")
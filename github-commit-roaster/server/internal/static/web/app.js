@@ -0,0 +1,33 @@
+const form = document.getElementById('roast-form');
+const output = document.getElementById('roast');
+
+function show(className, text) {
+  const p = document.createElement('p');
+  p.className = className;
+  p.textContent = text;
+  output.appendChild(p);
+}
+
+form.addEventListener('submit', async (event) => {
+  event.preventDefault();
+  const username = document.getElementById('username').value.trim();
+  output.textContent = '';
+  show('meta', 'Reading commit history…');
+
+  try {
+    const response = await fetch(`/roast?username=${encodeURIComponent(username)}`);
+    const body = await response.json();
+    output.textContent = '';
+    if (!response.ok) {
+      show('error', body.error || `Request failed with ${response.status}`);
+      return;
+    }
+    for (const line of body.roast.split('\n\n')) {
+      show('', line);
+    }
+    show('meta', `${body.stats.total_commits} commits · severity ${body.stats.severity}/100`);
+  } catch (err) {
+    output.textContent = '';
+    show('error', `Could not reach the server: ${err.message}`);
+  }
+});
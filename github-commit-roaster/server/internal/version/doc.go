@@ -0,0 +1,7 @@
+// Package version holds build metadata. The defaults in generated.go are
+// placeholders written by go generate; builds set the real values with
+// -ldflags "-X github-commit-roaster/internal/version.Version=...", or
+// stamp them into generated.go with `make stamp`. See the Makefile.
+package version
+
+//go:generate go run ../../cmd/gen-version
@@ -0,0 +1,9 @@
+// Code generated by gen-version; DO NOT EDIT.
+
+package version
+
+const (
+	generatedVersion   = "dev"
+	generatedGitCommit = "unknown"
+	generatedBuildTime = "unknown"
+)
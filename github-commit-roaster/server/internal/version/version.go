@@ -0,0 +1,34 @@
+package version
+
+import "runtime"
+
+var (
+	Version   = generatedVersion
+	GitCommit = generatedGitCommit
+	BuildTime = generatedBuildTime
+	// GoVersion is the toolchain that built the binary; it falls back to
+	// the runtime's version when not injected.
+	GoVersion = ""
+)
+
+// Info is the build metadata served by GET /version.
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	goVersion := GoVersion
+	if goVersion == "" {
+		goVersion = runtime.Version()
+	}
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: goVersion,
+	}
+}
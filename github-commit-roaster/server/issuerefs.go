@@ -0,0 +1,56 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// issueRefPattern matches issue and pull request references anywhere in
+	// a message, like "#42" or "octo-org/octo-repo#7", but not URL fragments
+	// or HTML entities.
+	issueRefPattern = regexp.MustCompile(`(?:^|[^\w&/.])(?:[\w.-]+/[\w.-]+)?#\d+\b`)
+	// closingRefPattern matches GitHub's closing keywords followed by a
+	// reference: "fixes #45", "Closes: org/repo#7", "resolved #1".
+	closingRefPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+(?:[\w.-]+/[\w.-]+)?#\d+\b`)
+	// bareRefMessage is a message with references and nothing else: "#123",
+	// "#12, #13".
+	bareRefMessage = regexp.MustCompile(`^(?:[\s,.;:()]*(?:[\w.-]+/[\w.-]+)?#\d+)+[\s,.;:()]*$`)
+)
+
+// IssueReferenceStats counts commits that link to an issue or pull request.
+type IssueReferenceStats struct {
+	LinkedCommits int     `json:"linked_commits"`
+	References    int     `json:"references"` // a commit can close several issues
+	LinkRatio     float64 `json:"link_ratio"`
+	// ClosingReferences use a closing keyword, like "fixes #45"
+	ClosingReferences int `json:"closing_references"`
+	// BareReferenceCommits say nothing but the reference, like "#123"
+	BareReferenceCommits int `json:"bare_reference_commits"`
+}
+
+// detectIssueReferences counts "#N" and "owner/repo#N" references in commit
+// messages, wherever they appear. Stock "Initial commit" messages are left
+// out.
+func detectIssueReferences(commits []NormalizedCommit) IssueReferenceStats {
+	var stats IssueReferenceStats
+	counted := 0
+	for _, commit := range commits {
+		if isInitialCommit(commit.Message) {
+			continue
+		}
+		counted++
+		refs := issueRefPattern.FindAllStringIndex(commit.Message, -1)
+		if len(refs) == 0 {
+			continue
+		}
+		stats.LinkedCommits++
+		stats.References += len(refs)
+		stats.ClosingReferences += len(closingRefPattern.FindAllStringIndex(commit.Message, -1))
+		if bareRefMessage.MatchString(strings.TrimSpace(commit.Message)) {
+			stats.BareReferenceCommits++
+		}
+	}
+	stats.LinkRatio = share(stats.LinkedCommits, counted)
+	return stats
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectIssueReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     IssueReferenceStats
+	}{
+		{"no commits", nil, IssueReferenceStats{}},
+		{"no references", []string{"add login", "fix typo"}, IssueReferenceStats{}},
+		{
+			"multiple references in one commit",
+			[]string{"Fixes #1, Closes #2", "add login"},
+			IssueReferenceStats{LinkedCommits: 1, References: 2, ClosingReferences: 2, LinkRatio: 0.5},
+		},
+		{
+			"cross-repo and mid-message",
+			[]string{"port the fix from octo-org/octo-repo#7", "see #12 for context"},
+			IssueReferenceStats{LinkedCommits: 2, References: 2, LinkRatio: 1},
+		},
+		{
+			"closing keyword variants",
+			[]string{"resolved #1", "Closes: octo-org/api#2", "fix #3", "fixed: #4"},
+			IssueReferenceStats{LinkedCommits: 4, References: 4, ClosingReferences: 4, LinkRatio: 1},
+		},
+		{
+			"bare references",
+			[]string{"#123", "#12, #13", "#7 add login"},
+			IssueReferenceStats{LinkedCommits: 3, References: 4, BareReferenceCommits: 2, LinkRatio: 1},
+		},
+		{
+			"not references",
+			[]string{"use C# for the tool", "escape &#39; in HTML", "see https://example.com/page#42", "color #fff"},
+			IssueReferenceStats{},
+		},
+		{
+			"closing keyword mid-sentence",
+			[]string{"parser now handles tabs, which fixes #8", "this prefix #1 is still a reference"},
+			IssueReferenceStats{LinkedCommits: 2, References: 2, ClosingReferences: 1, LinkRatio: 1},
+		},
+		{"initial commits are left out", []string{"Initial commit", "fixes #1"}, IssueReferenceStats{LinkedCommits: 1, References: 1, ClosingReferences: 1, LinkRatio: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectIssueReferences(messages(tt.messages...)); got != tt.want {
+				t.Errorf("detectIssueReferences() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueReferenceRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		refs  IssueReferenceStats
+		fired []string
+	}{
+		{"no references", IssueReferenceStats{}, []string{"no_issue_links"}},
+		{"some references", IssueReferenceStats{LinkedCommits: 5, References: 5, LinkRatio: 0.5}, nil},
+		{"every commit", IssueReferenceStats{LinkedCommits: 10, References: 10, LinkRatio: 1}, []string{"over_linked"}},
+		{"bare references", IssueReferenceStats{LinkedCommits: 3, References: 3, LinkRatio: 0.3, BareReferenceCommits: 2}, []string{"bare_issue_refs"}},
+	}
+	ids := []string{"no_issue_links", "over_linked", "bare_issue_refs"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, IssueReferences: tt.refs}
+			var fired []string
+			for _, id := range ids {
+				if findRule(t, id).Triggered(stats) {
+					fired = append(fired, id)
+				}
+			}
+			if !reflect.DeepEqual(fired, tt.fired) {
+				t.Errorf("fired %q, want %q", fired, tt.fired)
+			}
+		})
+	}
+
+	stats := CommitStats{TotalCommits: 10}
+	if line := findRule(t, "no_issue_links").line(stats, defaultIntensity); line != "Not a single commit references an issue. Your commits and your issues live entirely separate lives." {
+		t.Errorf("no_issue_links line = %q", line)
+	}
+	if findRule(t, "no_issue_links").Triggered(CommitStats{TotalCommits: 4}) {
+		t.Error("no_issue_links fired on 4 commits")
+	}
+	stats.IssueReferences.BareReferenceCommits = 3
+	if line := findRule(t, "bare_issue_refs").line(stats, defaultIntensity); line != "3 commit messages are just '#123'. Your git log reads like a raffle draw." {
+		t.Errorf("bare_issue_refs line = %q", line)
+	}
+}
+
+func TestIssueLinksInGitHygiene(t *testing.T) {
+	hygiene := func(ratio float64) int {
+		stats := CommitStats{TotalCommits: 10, IssueReferences: IssueReferenceStats{LinkRatio: ratio}}
+		for _, grade := range reportCard(RoastResponse{Stats: stats}).Categories {
+			if grade.Category == "Git Hygiene" {
+				if _, ok := grade.Metrics["issue_links"]; !ok {
+					t.Errorf("issue_links missing from %v", grade.Metrics)
+				}
+				return grade.Score
+			}
+		}
+		t.Fatal("no Git Hygiene category")
+		return 0
+	}
+	if linked, unlinked := hygiene(1), hygiene(0); linked <= unlinked {
+		t.Errorf("Git Hygiene scores %d fully linked and %d unlinked", linked, unlinked)
+	}
+}
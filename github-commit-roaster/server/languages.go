@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// languageRoasts maps a GitHub language name to the line used to roast it.
+// Entries can be added or replaced with a JSON object file named by
+// LANGUAGE_ROASTS_FILE.
+var languageRoasts = map[string]string{
+	"PHP":               "Still writing PHP in 2024? Bold.",
+	"JavaScript":        "JavaScript everywhere. I bet you think NaN !== NaN is a feature.",
+	"TypeScript":        "TypeScript: because you wanted JavaScript, but with more arguing with the compiler.",
+	"Java":              "So much Java. Your AbstractRoastFactoryBean is on its way.",
+	"Python":            "Python, huh? Significant whitespace, insignificant commit messages.",
+	"Go":                "Go developer spotted. if err != nil { return roast }",
+	"Rust":              "Rust detected. Have you told anyone yet today?",
+	"C++":               "C++? Enjoy your 40 minute builds and undefined behaviour.",
+	"C":                 "Writing C in this economy? Segfaults build character, I guess.",
+	"Ruby":              "Ruby? The 2010s called, they want their Rails app back.",
+	"HTML":              "HTML is your top language. That's not programming, that's typing angle brackets.",
+	"CSS":               "Mostly CSS. Centering divs professionally, are we?",
+	"Jupyter Notebook":  "Jupyter Notebooks everywhere. Running cells out of order is not a workflow.",
+	"Shell":             "Heavy on shell scripts. Nothing says 'reproducible' like a 400-line deploy.sh.",
+	"Perl":              "Perl? Are you maintaining this or keeping it alive as an act of mercy?",
+	"Visual Basic .NET": "Visual Basic. Someone has to keep the 90s running.",
+}
+
+// minLanguageShare is the share (in percent) a language needs before it
+// gets roasted, so a stray script in one repo doesn't dominate the output.
+const minLanguageShare = 10.0
+
+// maxLanguageRoasts caps how many language lines end up in a single roast.
+const maxLanguageRoasts = 2
+
+func loadLanguageRoasts(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var overrides map[string]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return err
+	}
+	for lang, line := range overrides {
+		languageRoasts[lang] = line
+	}
+	return nil
+}
+
+// languageBreakdown returns the share of repos per primary language, in percent.
+func languageBreakdown(repos []*github.Repository) map[string]float64 {
+	counts := make(map[string]int)
+	for _, repo := range repos {
+		if lang := repo.GetLanguage(); lang != "" {
+			counts[lang]++
+		}
+	}
+	return toPercentages(counts)
+}
+
+// fetchLanguageBytes adds up the bytes of code per language across repos.
+// It costs one API call per repo.
+func fetchLanguageBytes(ctx context.Context, client *ghclient.Client, owner string, repos []*github.Repository) map[string]int {
+	bytes := make(map[string]int)
+	for _, repo := range repos {
+		spanCtx, span := startGitHubSpan(ctx, "Repositories.ListLanguages", attribute.String("github.repo", repo.GetName()))
+		langs, _, err := client.Repositories.ListLanguages(spanCtx, owner, repo.GetName())
+		endSpan(span, err)
+		if err != nil {
+			continue // Skip repo if we can't get languages
+		}
+		for lang, n := range langs {
+			bytes[lang] += n
+		}
+	}
+	return bytes
+}
+
+// maxLanguageProfileRepos is how many of the most recently updated repos
+// the language profile lists languages for outside deep mode.
+const maxLanguageProfileRepos = 3
+
+// shellHeavyShare is the share of Shell bytes that makes a developer more
+// of an SRE.
+const shellHeavyShare = 0.2
+
+// LanguageProfile is the tech stack by bytes of code, from GitHub's
+// per-repo language counts. Only set with ?languages=true or deep mode.
+type LanguageProfile struct {
+	LanguageBytes    map[string]int `json:"language_bytes"`
+	DominantLanguage string         `json:"dominant_language"`
+	LanguageCount    int            `json:"language_count"`
+	// CSSToJSRatio is CSS bytes per JavaScript byte; 0 without JavaScript
+	CSSToJSRatio float64 `json:"css_to_js_ratio"`
+}
+
+// languageProfile summarizes per-language byte counts.
+func languageProfile(bytes map[string]int) LanguageProfile {
+	profile := LanguageProfile{LanguageBytes: bytes, LanguageCount: len(bytes)}
+	for lang, n := range bytes {
+		top := bytes[profile.DominantLanguage]
+		if n > top || (n == top && (profile.DominantLanguage == "" || lang < profile.DominantLanguage)) {
+			profile.DominantLanguage = lang
+		}
+	}
+	if js := bytes["JavaScript"]; js > 0 {
+		profile.CSSToJSRatio = math.Round(float64(bytes["CSS"])/float64(js)*100) / 100
+	}
+	return profile
+}
+
+// designer reports whether there is more CSS than JavaScript.
+func (p *LanguageProfile) designer() bool {
+	return p != nil && p.LanguageBytes["CSS"] > p.LanguageBytes["JavaScript"]
+}
+
+// shellHeavy reports whether Shell makes up more than shellHeavyShare of
+// the code.
+func (p *LanguageProfile) shellHeavy() bool {
+	if p == nil {
+		return false
+	}
+	total := 0
+	for _, n := range p.LanguageBytes {
+		total += n
+	}
+	return total > 0 && float64(p.LanguageBytes["Shell"])/float64(total) > shellHeavyShare
+}
+
+func toPercentages(counts map[string]int) map[string]float64 {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return nil
+	}
+
+	shares := make(map[string]float64, len(counts))
+	for lang, n := range counts {
+		shares[lang] = math.Round(float64(n)/float64(total)*1000) / 10
+	}
+	return shares
+}
+
+// languageRoastLines picks roast lines for the most used languages that have
+// an entry in languageRoasts.
+func languageRoastLines(shares map[string]float64) []string {
+	langs := make([]string, 0, len(shares))
+	for lang := range shares {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if shares[langs[i]] != shares[langs[j]] {
+			return shares[langs[i]] > shares[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+
+	var lines []string
+	for _, lang := range langs {
+		if shares[lang] < minLanguageShare || len(lines) == maxLanguageRoasts {
+			break
+		}
+		if line, ok := languageRoasts[lang]; ok {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ghclient "github-commit-roaster/internal/github"
+	"github.com/google/go-github/v50/github"
+	"go.uber.org/mock/gomock"
+)
+
+func reposWithLanguages(langs ...string) []*github.Repository {
+	repos := make([]*github.Repository, len(langs))
+	for i, lang := range langs {
+		repos[i] = &github.Repository{Name: github.String("repo"), Language: github.String(lang)}
+	}
+	return repos
+}
+
+func TestLanguageBreakdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		repos []*github.Repository
+		want  map[string]float64
+	}{
+		{"no repos", nil, nil},
+		{"no languages", reposWithLanguages("", ""), nil},
+		{
+			"several languages",
+			reposWithLanguages("Go", "Go", "PHP", "Python", "", "Rust", "Go", "PHP"),
+			map[string]float64{"Go": 42.9, "PHP": 28.6, "Python": 14.3, "Rust": 14.3},
+		},
+		{"one language", reposWithLanguages("Perl", "Perl"), map[string]float64{"Perl": 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := languageBreakdown(tt.repos); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("languageBreakdown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguageRoastLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		shares map[string]float64
+		want   []string
+	}{
+		{"nothing", nil, nil},
+		{
+			"top two roasted languages",
+			map[string]float64{"Go": 50, "PHP": 30, "Rust": 20},
+			[]string{languageRoasts["Go"], languageRoasts["PHP"]},
+		},
+		{
+			"languages without a roast are skipped",
+			map[string]float64{"Zig": 60, "Python": 40},
+			[]string{languageRoasts["Python"]},
+		},
+		{
+			"below the minimum share",
+			map[string]float64{"Haskell": 91, "PHP": 9},
+			nil,
+		},
+		{
+			"ties break alphabetically",
+			map[string]float64{"Ruby": 25, "C": 25, "Java": 25, "Shell": 25},
+			[]string{languageRoasts["C"], languageRoasts["Java"]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := languageRoastLines(tt.shares); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("languageRoastLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguageProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		bytes      map[string]int
+		dominant   string
+		ratio      float64
+		designer   bool
+		shellHeavy bool
+	}{
+		{"empty", map[string]int{}, "", 0, false, false},
+		{"go and shell", map[string]int{"Go": 700, "Shell": 300}, "Go", 0, false, true},
+		{"css over js", map[string]int{"CSS": 300, "JavaScript": 200, "HTML": 100}, "CSS", 1.5, true, false},
+		{"tie breaks alphabetically", map[string]int{"Rust": 10, "C": 10}, "C", 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := languageProfile(tt.bytes)
+			if p.DominantLanguage != tt.dominant || p.CSSToJSRatio != tt.ratio || p.LanguageCount != len(tt.bytes) {
+				t.Errorf("languageProfile() = %+v, want dominant %q and ratio %v", p, tt.dominant, tt.ratio)
+			}
+			if p.designer() != tt.designer {
+				t.Errorf("designer() = %v, want %v", p.designer(), tt.designer)
+			}
+			if p.shellHeavy() != tt.shellHeavy {
+				t.Errorf("shellHeavy() = %v, want %v", p.shellHeavy(), tt.shellHeavy)
+			}
+		})
+	}
+}
+
+func TestFetchLanguageBytes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repos := ghclient.NewMockRepositoriesService(ctrl)
+	client := &ghclient.Client{Repositories: repos}
+	repos.EXPECT().ListLanguages(gomock.Any(), "octocat", "api").Return(map[string]int{"Go": 900, "Shell": 100}, nil, nil)
+	repos.EXPECT().ListLanguages(gomock.Any(), "octocat", "gone").Return(nil, nil, errors.New("404"))
+	repos.EXPECT().ListLanguages(gomock.Any(), "octocat", "site").Return(map[string]int{"CSS": 300, "Shell": 50}, nil, nil)
+
+	list := []*github.Repository{{Name: github.String("api")}, {Name: github.String("gone")}, {Name: github.String("site")}}
+	got := fetchLanguageBytes(t.Context(), client, "octocat", list)
+	if want := map[string]int{"Go": 900, "Shell": 150, "CSS": 300}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fetchLanguageBytes() = %v, want %v", got, want)
+	}
+}
+
+func TestLanguageProfileRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *LanguageProfile
+		fired   string
+	}{
+		{"no profile", nil, ""},
+		{"balanced", &LanguageProfile{LanguageBytes: map[string]int{"Go": 900, "Shell": 100, "CSS": 10, "JavaScript": 20}}, ""},
+		{"designer", &LanguageProfile{LanguageBytes: map[string]int{"CSS": 300, "JavaScript": 200}}, "designer"},
+		{"CSS without JavaScript", &LanguageProfile{LanguageBytes: map[string]int{"Go": 900, "CSS": 1}}, "designer"},
+		{"shell heavy", &LanguageProfile{LanguageBytes: map[string]int{"Python": 700, "Shell": 300}}, "shell_heavy"},
+		{"shell at 20%", &LanguageProfile{LanguageBytes: map[string]int{"Python": 800, "Shell": 200}}, ""},
+	}
+	lines := map[string]string{
+		"designer":    "The designer called — they want their job title back.",
+		"shell_heavy": "You write more shell scripts than application code. SRE or developer?",
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, LanguageProfile: tt.profile}
+			for id, want := range lines {
+				rule := findRule(t, id)
+				if got := rule.Triggered(stats); got != (id == tt.fired) {
+					t.Errorf("%s triggered = %v", id, got)
+				} else if got && rule.line(stats, defaultIntensity) != want {
+					t.Errorf("%s line = %q", id, rule.line(stats, defaultIntensity))
+				}
+			}
+		})
+	}
+}
+
+func TestRoastLanguageProfile(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   *LanguageProfile
+		calls  int // ListLanguages calls
+	}{
+		{"not asked for", "/roast?username=octocat", nil, 0},
+		{
+			"the most recent repos only",
+			"/roast?username=octocat&languages=true",
+			&LanguageProfile{LanguageBytes: map[string]int{"Go": 3000, "Shell": 600}, DominantLanguage: "Go", LanguageCount: 2},
+			maxLanguageProfileRepos,
+		},
+		{
+			"deep mode reads every repo",
+			"/roast?username=octocat&deep=true",
+			&LanguageProfile{LanguageBytes: map[string]int{"Go": 5000, "Shell": 1500}, DominantLanguage: "Go", LanguageCount: 2},
+			maxLanguageProfileRepos + 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 1))
+			gh.repos = nil
+			gh.languages = make(map[string]map[string]int)
+			for i := range maxLanguageProfileRepos + 2 {
+				name := fmt.Sprintf("repo%d", i)
+				gh.repos = append(gh.repos, &github.Repository{Name: github.String(name), Language: github.String("Go")})
+				gh.languages[name] = map[string]int{"Go": 1000, "Shell": 100 * (i + 1)}
+			}
+			s := newTestServer(t, gh)
+			w := doRequest(s.handleRoast, http.MethodGet, "/roast", tt.target, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var body struct {
+				Stats struct {
+					LanguageProfile *LanguageProfile `json:"language_profile"`
+				} `json:"stats"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(body.Stats.LanguageProfile, tt.want) {
+				t.Errorf("language_profile = %+v, want %+v", body.Stats.LanguageProfile, tt.want)
+			}
+			calls := 0
+			for _, repo := range gh.repos {
+				calls += gh.callCount("/repos/octocat/" + repo.GetName() + "/languages")
+			}
+			if calls != tt.calls {
+				t.Errorf("%d ListLanguages calls, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestLoadLanguageRoasts(t *testing.T) {
+	saved := maps.Clone(languageRoasts)
+	t.Cleanup(func() { languageRoasts = saved })
+
+	path := filepath.Join(t.TempDir(), "roasts.json")
+	if err := os.WriteFile(path, []byte(`{"PHP": "PHP again.", "Zig": "Zig? Sure."}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadLanguageRoasts(path); err != nil {
+		t.Fatalf("loadLanguageRoasts() error = %v", err)
+	}
+	if languageRoasts["PHP"] != "PHP again." || languageRoasts["Zig"] != "Zig? Sure." {
+		t.Errorf("overrides not applied: PHP=%q Zig=%q", languageRoasts["PHP"], languageRoasts["Zig"])
+	}
+	if languageRoasts["Go"] != saved["Go"] {
+		t.Errorf("unrelated entry changed: Go=%q", languageRoasts["Go"])
+	}
+
+	if err := os.WriteFile(path, []byte(`not json`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadLanguageRoasts(path); err == nil {
+		t.Error("loadLanguageRoasts() accepted invalid JSON")
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github-commit-roaster/internal/version"
+)
+
+// newLogger returns the server's logger: text lines on w at level and
+// above, each carrying the running version so a line can be tied to the
+// deploy that wrote it.
+func newLogger(w io.Writer, level slog.Level) *slog.Logger {
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+	return slog.New(handler).With("version", version.Version)
+}
+
+// logLevel is LOG_LEVEL as a slog level: debug, info, warn or error,
+// defaulting to info.
+func logLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github-commit-roaster/internal/version"
+)
+
+func TestNewLogger(t *testing.T) {
+	defer func(v string) { version.Version = v }(version.Version)
+	version.Version = "1.2.3"
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, slog.LevelInfo)
+	logger.Debug("hidden")
+	logger.Info("Server running", "port", "8080")
+	logger.Warn("Redis cache write failed", "error", "timeout")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("%d lines logged at info, want 2:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, " version=1.2.3") {
+			t.Errorf("no version in %q", line)
+		}
+	}
+	if !strings.Contains(lines[1], `level=WARN msg="Redis cache write failed" version=1.2.3 error=timeout`) {
+		t.Errorf("warning = %q", lines[1])
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"DEBUG":   slog.LevelDebug,
+		"warn":    slog.LevelWarn,
+		"error":   slog.LevelError,
+		"verbose": slog.LevelInfo,
+	}
+	for value, want := range tests {
+		t.Setenv("LOG_LEVEL", value)
+		if got := logLevel(); got != want {
+			t.Errorf("LOG_LEVEL=%q: level %v, want %v", value, got, want)
+		}
+	}
+}
@@ -2,32 +2,157 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"flag"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"strings"
-	"time"
+	"syscall"
+
+	"github-commit-roaster/internal/static"
+	"github-commit-roaster/middleware"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/go-github/v50/github"
 	"github.com/joho/godotenv"
 	"golang.org/x/oauth2"
 )
 
+// maxPostBodyBytes limits the body of every POST request without an entry
+// in postBodyLimits.
+const maxPostBodyBytes = 1 << 20
+
+// maxWebhookBodyBytes is the largest payload GitHub delivers to a webhook.
+const maxWebhookBodyBytes = 25 << 20
+
+// postBodyLimits are body limits for single POST routes that differ from
+// maxPostBodyBytes, keyed by route path. /roast/share isn't served yet; its
+// limit is set here so the route can't ship with the general one. Push
+// payloads for big pushes run well past the general limit.
+var postBodyLimits = map[string]int64{
+	"/roast/share":    100 << 10,
+	"/webhook/github": maxWebhookBodyBytes,
+}
+
 func main() {
+	health := flag.Bool("health", false, "check that the server on PORT is up and exit")
+	configPath := flag.String("config", "", "YAML config file; env vars override its settings (default $CONFIG_FILE, then ./config.yaml)")
+	flag.Parse()
+
 	// Load environment variables
 	err := godotenv.Load()
+	slog.SetDefault(newLogger(os.Stdout, logLevel()))
+	if err != nil {
+		slog.Warn("No .env file found")
+	}
+	// Optional config file (--config, CONFIG_FILE or ./config.yaml); env vars
+	// win over it. A bad file stops startup rather than running half-configured
+	if err := loadConfigFile(*configPath); err != nil {
+		slog.Error("Invalid config file", "error", err)
+		os.Exit(1)
+	}
+
+	if *health {
+		os.Exit(runHealthCheck(configuredPort()))
+	}
+	cfg := loadConfig()
+
+	// Optional overrides for the language roast table
+	if path := cfg.LanguageRoastsFile; path != "" {
+		if err := loadLanguageRoasts(path); err != nil {
+			slog.Warn("Could not load language roasts", "path", path, "error", err)
+		}
+	}
+
+	// Tracing is a no-op unless OTEL_ env vars configure an exporter
+	shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		slog.Warn("Could not set up tracing", "error", err)
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+
+	// SIGTERM (e.g. a rolling deploy) stops background jobs and drains requests
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	var jobs backgroundJobs
+
+	// GitHub App auth takes priority over GITHUB_TOKEN when configured
+	var app oauth2.TokenSource
+	if src, err := loadAppTokenSource(cfg.GitHubApp); err != nil {
+		slog.Warn("Ignoring GitHub App config", "error", err)
+	} else if src != nil {
+		app = src
+	}
+	clients := newClientFactory(cfg.GitHubToken, cfg.GitHubUserAgent, app)
+	clients.maxRetryWait = cfg.MaxRetryWait
+	if cfg.GitHubAPIURL != "" {
+		// go-github needs the trailing slash to resolve paths under the root
+		baseURL, err := url.Parse(strings.TrimSuffix(cfg.GitHubAPIURL, "/") + "/")
+		if err != nil {
+			slog.Warn("Ignoring GITHUB_API_URL", "error", err)
+		} else {
+			clients.baseURL = baseURL
+		}
+	}
+
+	// Optional OAuth login so users can include their private repos
+	login, err := loadOAuthLogin(cfg.OAuth)
+	if err != nil {
+		slog.Warn("GitHub login disabled", "error", err)
+	}
+	if login != nil {
+		jobs.start(func() { login.run(ctx) })
+	}
+
+	// Optional SQLite store for state that has to survive restarts
+	var store *Store
+	if path := cfg.SQLitePath; path != "" {
+		if store, err = openStore(path); err != nil {
+			slog.Warn("Could not open SQLite store, keeping state in memory", "error", err)
+			store = nil
+		} else {
+			defer store.Close()
+		}
+	}
+
+	// Callers without an X-API-Key share these stricter limits
+	anonymous := APIKey{
+		Key:           anonymousKey,
+		Name:          anonymousKey,
+		DailyQuota:    cfg.AnonDailyQuota,
+		RatePerMinute: cfg.AnonRatePerMinute,
+	}
+	quotas, err := newQuotaTracker(store, anonymous)
 	if err != nil {
-		fmt.Println("Warning: No .env file found")
+		slog.Warn("Could not load API keys", "error", err)
+		quotas, _ = newQuotaTracker(nil, anonymous)
 	}
 
 	r := gin.Default()
+	allowMethodNotAllowed(r)
+	requests := &requestTracker{}
+	r.Use(requests.middleware())
+
+	// Registered before the middleware below so load balancer checks skip it
+	r.GET("/ping", handlePing)
+	r.GET("/health", handlePing)
+	r.GET("/version", handleVersion)
+
+	// Minimal embedded frontend
+	frontend := gin.WrapH(http.FileServer(http.FS(static.Files)))
+	r.GET("/", frontend)
+	r.GET("/app.js", frontend)
+
+	r.Use(tracingMiddleware())
 
 	// CORS middleware
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key, traceparent, tracestate")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -35,173 +160,118 @@ func main() {
 		c.Next()
 	})
 
-	r.GET("/roast", func(c *gin.Context) {
-		username := c.Query("username")
-		if username == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "username is required"})
-			return
-		}
-
-		ctx := context.Background()
-		token := os.Getenv("GITHUB_TOKEN")
-		var client *github.Client
+	// Cap POST bodies so nobody can make us buffer gigabytes
+	r.Use(limitPostBodies())
 
-		if token != "" {
-			ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-			tc := oauth2.NewClient(ctx, ts)
-			client = github.NewClient(tc)
-		} else {
-			client = github.NewClient(nil)
-			fmt.Println("Warning: Using unauthenticated API - rate limits will apply")
-		}
+	srv := &server{
+		cfg:     cfg.Roast,
+		clients: clients,
+		login:   login,
+		history: newHistoryStore(store),
+		stats:   newServerStats(),
+		// Set REDIS_URL to share the cache between instances
+		cache:         tracedCache{next: newRoastCache(cfg.RedisURL)},
+		cacheTTL:      cfg.CacheTTL,
+		flights:       newFetchGroup(),
+		badges:        newTrendBadgeCache(),
+		corpus:        newCorpusStats(),
+		firstCommits:  newFirstCommitCache(),
+		githubTimeout: cfg.GitHubTimeout,
+		noColor:       cfg.NoColor,
+	}
+	r.GET("/roast", quotas.middleware(), srv.handleRoast)
+	r.HEAD("/roast", quotas.middleware(), srv.handleRoastHead)
+	// The JSON body form keeps long option sets out of URLs and access logs
+	r.POST("/roast", quotas.middleware(), srv.handleRoastPost)
+	// Server-Sent Events for browsers that want lines as they're sent
+	r.GET("/roast/sse", quotas.middleware(), srv.handleRoastSSE)
+	r.GET("/roast/:username/diff", quotas.middleware(), srv.handleRoastDiff)
+	r.POST("/roast/batch", quotas.middleware(), srv.handleRoastBatch)
+	r.POST("/roast/team", quotas.middleware(), srv.handleRoastTeam)
+	r.GET("/severity", quotas.middleware(), srv.handleSeverity)
+	r.GET("/stats", srv.stats.handleStats)
+	r.GET("/badge/:username/trend.svg", srv.handleTrendBadge)
 
-		// Verify user exists
-		_, _, err := client.Users.Get(ctx, username)
-		if err != nil {
-			if _, ok := err.(*github.RateLimitError); ok {
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error": "GitHub API rate limit exceeded",
-					"solution": "Please provide a GitHub token in server/.env file",
-				})
-				return
-			}
-			c.JSON(http.StatusNotFound, gin.H{"error": "GitHub user not found"})
-			return
-		}
+	// Cache warming for frontends that know which profile is being viewed
+	srv.prefetch = newPrefetcher(srv, cfg.PrefetchConcurrency, cfg.PrefetchHotHits)
+	jobs.start(func() { srv.prefetch.run(ctx) })
+	r.POST("/prefetch", quotas.middleware(), srv.prefetch.handlePrefetch)
+	r.GET("/prefetch/:id", srv.prefetch.handlePrefetchJob)
+	r.GET("/achievements", handleAchievements)
+	r.GET("/rules", srv.handleRules)
 
-		// Get repositories (limit to 10 most recent)
-		repos, _, err := client.Repositories.List(ctx, username, &github.RepositoryListOptions{
-			Type:      "owner",
-			Sort:      "updated",
-			Direction: "desc",
-			ListOptions: github.ListOptions{PerPage: 10},
-		})
-		if err != nil {
-			handleGitHubError(c, err)
-			return
-		}
+	// Roast of the day, precomputed off-peak from FEATURED_USERS
+	if candidates := cfg.FeaturedUsers; len(candidates) > 0 {
+		featured := newFeaturedScheduler(srv, store, candidates, cfg.FeaturedHour)
+		jobs.start(func() { featured.run(ctx) })
+		r.GET("/featured", featured.handleFeatured)
+	}
 
-		// Get commits from last 30 days
-		thirtyDaysAgo := time.Now().AddDate(0, 0, -30)
-		var allCommits []*github.RepositoryCommit
-
-		for _, repo := range repos {
-			commits, _, err := client.Repositories.ListCommits(ctx, username, *repo.Name, &github.CommitsListOptions{
-				Since: thirtyDaysAgo,
-			})
-			if err != nil {
-				continue // Skip repo if we can't get commits
-			}
-			allCommits = append(allCommits, commits...)
-		}
+	registerAuthRoutes(r, login)
 
-		roast := generateRoast(allCommits)
-		
-		c.JSON(http.StatusOK, gin.H{
-			"username": username,
-			"roast":    roast,
-			"stats": gin.H{
-				"total_commits": len(allCommits),
-				"repos_analyzed": len(repos),
-			},
-		})
+	// API key usage and management
+	r.GET("/v1/usage", quotas.handleUsage)
+	registerAdminRoutes(r, quotas, cfg.AdminToken, APIKey{
+		DailyQuota:    cfg.KeyDailyQuota,
+		RatePerMinute: cfg.KeyRatePerMinute,
 	})
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-	fmt.Printf("🚀 Server running on port %s\n", port)
-	r.Run(":" + port)
-}
+	// Push webhook; set WEBHOOK_COMMENT=true to post roasts back as commit comments
+	r.POST("/webhook/github", webhookHandler(clients, cfg.Roast, cfg.WebhookSecret, cfg.WebhookComment))
 
-func handleGitHubError(c *gin.Context, err error) {
-	if rateLimitErr, ok := err.(*github.RateLimitError); ok {
-		resetTime := rateLimitErr.Rate.Reset.Format(time.RFC1123)
-		c.JSON(http.StatusTooManyRequests, gin.H{
-			"error": "GitHub API rate limit exceeded",
-			"reset_time": resetTime,
-			"solution": "Create a .env file with GITHUB_TOKEN in your server directory",
-		})
-	} else {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch GitHub data",
-			"details": err.Error(),
-		})
+	slog.Info("🚀 Server running", "port", cfg.Port)
+	httpServer := newHTTPServer(cfg, r)
+	slog.Info("HTTP timeouts", "read", httpServer.ReadTimeout, "read_header", httpServer.ReadHeaderTimeout,
+		"write", httpServer.WriteTimeout, "idle", httpServer.IdleTimeout)
+	logConfigSources()
+	if err := serve(ctx, stop, httpServer, requests); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server stopped", "error", err)
 	}
+	// The jobs write to the store, which closes once main returns; stop
+	// ends them too when the server failed rather than being signalled
+	stop()
+	jobs.wait()
 }
 
-func generateRoast(commits []*github.RepositoryCommit) string {
-	if len(commits) == 0 {
-		return "Wow, you haven't committed anything recently. Are you even a developer?"
-	}
-
-	// Analysis counters
-	lateNightCommits := 0
-	swearWords := 0
-	mergeCommits := 0
-	fixCommits := 0
-	genericMessages := 0
-	
-	for _, commit := range commits {
-		msg := strings.ToLower(*commit.Commit.Message)
-		commitTime := commit.Commit.Committer.Date
-		
-		// Check for late night commits (10pm-4am)
-		if commitTime.Hour() >= 22 || commitTime.Hour() <= 4 {
-			lateNightCommits++
-		}
-		
-		// Check message content
-		if containsAny(msg, "fix", "bug", "error") {
-			fixCommits++
-		}
-		if containsAny(msg, "merge", "pull") {
-			mergeCommits++
-		}
-		if containsAny(msg, "fuck", "shit", "damn", "wtf") {
-			swearWords++
+// limitPostBodies caps POST bodies at their route's postBodyLimits entry,
+// or maxPostBodyBytes for routes without one.
+func limitPostBodies() gin.HandlerFunc {
+	limitBody := middleware.NewBodySizeLimitMiddleware(maxPostBodyBytes)
+	routeLimits := make(map[string]gin.HandlerFunc, len(postBodyLimits))
+	for route, maxBytes := range postBodyLimits {
+		routeLimits[route] = middleware.NewBodySizeLimitMiddleware(maxBytes)
+	}
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost {
+			c.Next()
+			return
 		}
-		if strings.HasPrefix(msg, "update") || strings.HasPrefix(msg, "changes") {
-			genericMessages++
+		if limit, ok := routeLimits[c.FullPath()]; ok {
+			limit(c)
+			return
 		}
+		limitBody(c)
 	}
-	
-	// Generate roast lines
-	var roastLines []string
-	
-	if lateNightCommits > len(commits)/2 {
-		roastLines = append(roastLines, "Over 50% of your commits are late at night. Do you even sleep?")
-	}
-	
-	if swearWords > 0 {
-		roastLines = append(roastLines, fmt.Sprintf("Found %d swear words in commits. Someone needs a stress ball!", swearWords))
-	}
-	
-	if mergeCommits > len(commits)/3 {
-		roastLines = append(roastLines, "You merge more than you code. Git plumber much?")
-	}
-	
-	if fixCommits > len(commits)/2 {
-		roastLines = append(roastLines, "Most of your commits are fixes. Maybe test before committing?")
-	}
-	
-	if genericMessages > len(commits)/3 {
-		roastLines = append(roastLines, "Your commit messages are as generic as a motivational poster.")
-	}
-	
-	if len(roastLines) == 0 {
-		roastLines = append(roastLines, "Your commits are suspiciously clean. Are you even trying?")
-	}
-	
-	return strings.Join(roastLines, "\n\n")
 }
 
-func containsAny(s string, substrings ...string) bool {
-	for _, sub := range substrings {
-		if strings.Contains(s, sub) {
-			return true
-		}
+// allowMethodNotAllowed answers known paths called with the wrong method
+// with 405 and an Allow header, not 404.
+func allowMethodNotAllowed(r *gin.Engine) {
+	r.HandleMethodNotAllowed = true
+	r.NoMethod(func(c *gin.Context) {
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "method not allowed"})
+	})
+}
+
+// newHTTPServer returns the server for handler on cfg.Port, with cfg's
+// timeouts.
+func newHTTPServer(cfg Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
 	}
-	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// prMergeSubject is the subject of GitHub's merge button:
+	// "Merge pull request #12 from octocat/feature".
+	prMergeSubject = regexp.MustCompile(`^Merge pull request #\d+ from \S`)
+	// branchMergeSubject is git's own merge message: "Merge branch 'main'
+	// into feature", "Merge remote-tracking branch 'origin/main'".
+	branchMergeSubject = regexp.MustCompile(`^Merge (remote-tracking )?branch '[^']+'`)
+	// squashMergeSubject is the "(#123)" GitHub appends when squashing or
+	// rebasing a pull request.
+	squashMergeSubject = regexp.MustCompile(`\(#\d+\)$`)
+)
+
+// MergeStats splits merges into what produced them. Pull request and
+// branch merges have two or more parents; squash merges are regular
+// commits recognized by their "(#123)" suffix.
+type MergeStats struct {
+	PullRequestMerges int `json:"pull_request_merges"`
+	BranchMerges      int `json:"branch_merges"`
+	SquashMerges      int `json:"squash_merges"`
+}
+
+type mergeKind int
+
+const (
+	notAMerge mergeKind = iota
+	pullRequestMerge
+	branchMerge
+	squashMerge
+)
+
+// classifyMerge tells what kind of merge commit is. A commit with two or
+// more parents is a true merge, of a pull request when the subject says
+// so and of a branch otherwise. Commits whose parents are unknown (push
+// event payloads don't list them) are judged by the subject alone.
+func classifyMerge(commit NormalizedCommit) mergeKind {
+	subject, _, _ := strings.Cut(commit.Message, "\n")
+	subject = strings.TrimSpace(subject)
+	switch {
+	case commit.Parents >= 2 && prMergeSubject.MatchString(subject):
+		return pullRequestMerge
+	case commit.Parents >= 2:
+		return branchMerge
+	case commit.Parents == 0 && prMergeSubject.MatchString(subject):
+		return pullRequestMerge
+	case commit.Parents == 0 && branchMergeSubject.MatchString(subject):
+		return branchMerge
+	case squashMergeSubject.MatchString(subject):
+		return squashMerge
+	default:
+		return notAMerge
+	}
+}
+
+// add counts a merge of the given kind; notAMerge is ignored.
+func (m *MergeStats) add(kind mergeKind) {
+	switch kind {
+	case pullRequestMerge:
+		m.PullRequestMerges++
+	case branchMerge:
+		m.BranchMerges++
+	case squashMerge:
+		m.SquashMerges++
+	}
+}
+
+// trueMerges counts the commits with more than one parent.
+func (m MergeStats) trueMerges() int {
+	return m.PullRequestMerges + m.BranchMerges
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+)
+
+func TestClassifyMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		parents int
+		want    mergeKind
+	}{
+		{"pull request merge", "Merge pull request #12 from octocat/feature\n\nAdd search", 2, pullRequestMerge},
+		{"branch merge", "Merge branch 'main' into feature", 2, branchMerge},
+		{"remote-tracking branch", "Merge remote-tracking branch 'origin/main'", 2, branchMerge},
+		{"octopus merge", "Merge branches 'a', 'b' and 'c'", 3, branchMerge},
+		{"two parents, custom message", "bring in the new parser", 2, branchMerge},
+		{"squash merge", "Add search (#123)", 1, squashMerge},
+		{"squash merge with a body", "Add search (#123)\n\n* wip\n* wip", 1, squashMerge},
+		{"number mid-subject", "Revert (#123) partly", 1, notAMerge},
+		{"merge-looking subject, one parent", "Merge branch 'main' into feature", 1, notAMerge},
+		{"pull-to-refresh", "fix pull-to-refresh", 1, notAMerge},
+		{"merge in a sentence", "merge the config loaders", 1, notAMerge},
+		{"unknown parents, pull request subject", "Merge pull request #7 from octocat/fix", 0, pullRequestMerge},
+		{"unknown parents, branch subject", "Merge branch 'dev'", 0, branchMerge},
+		{"unknown parents, squash", "Tidy up (#9)", 0, squashMerge},
+		{"unknown parents, plain", "fix pull-to-refresh", 0, notAMerge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit := NormalizedCommit{Message: tt.message, Parents: tt.parents}
+			if got := classifyMerge(commit); got != tt.want {
+				t.Errorf("classifyMerge(%q, %d parents) = %v, want %v", tt.message, tt.parents, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCommitParents(t *testing.T) {
+	commit := fakeCommit("Octo", "Merge branch 'main'", 1)
+	commit.Parents = []*github.Commit{{SHA: github.String("a")}, {SHA: github.String("b")}}
+	if got := normalizeCommit("project", commit).Parents; got != 2 {
+		t.Errorf("Parents = %d, want 2", got)
+	}
+}
+
+func TestMergeStats(t *testing.T) {
+	commits := []NormalizedCommit{
+		{Message: "Merge pull request #1 from octocat/a", Parents: 2},
+		{Message: "Merge pull request #2 from octocat/b", Parents: 2},
+		{Message: "Merge branch 'main' into b", Parents: 2},
+		{Message: "Add search (#3)", Parents: 1},
+		{Message: "fix pull-to-refresh", Parents: 1},
+		{Message: "merge configs", Parents: 1},
+	}
+	stats := analyzeCommits(commits, loadRoastConfig())
+	want := MergeStats{PullRequestMerges: 2, BranchMerges: 1, SquashMerges: 1}
+	if stats.Merges != want {
+		t.Errorf("Merges = %+v, want %+v", stats.Merges, want)
+	}
+	if stats.MergeCommits != 3 {
+		t.Errorf("MergeCommits = %d, want the 3 with two parents", stats.MergeCommits)
+	}
+}
+
+func TestMergeRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		merges MergeStats
+		fired  []string
+	}{
+		{"no merges", MergeStats{}, nil},
+		{"pull request merges", MergeStats{PullRequestMerges: 4}, []string{"merges"}},
+		{"branch merges", MergeStats{BranchMerges: 3}, []string{"branch_merges"}},
+		{"squash merges", MergeStats{SquashMerges: 6}, []string{"squash_merges"}},
+		{"just under every threshold", MergeStats{PullRequestMerges: 3, BranchMerges: 2, SquashMerges: 5}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, Merges: tt.merges}
+			fired := map[string]bool{}
+			for _, id := range []string{"merges", "branch_merges", "squash_merges"} {
+				fired[id] = findRule(t, id).Triggered(stats)
+			}
+			for _, id := range tt.fired {
+				if !fired[id] {
+					t.Errorf("%s didn't fire", id)
+				}
+				delete(fired, id)
+			}
+			for id, ok := range fired {
+				if ok {
+					t.Errorf("%s fired", id)
+				}
+			}
+		})
+	}
+	line := findRule(t, "branch_merges").line(CommitStats{Merges: MergeStats{BranchMerges: 7}}, defaultIntensity)
+	if line != "7 'Merge branch main into main'-style commits. Your git graph is a bowl of spaghetti." {
+		t.Errorf("branch_merges line = %q", line)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// undeterminedLanguage is reported for messages with no recognizable words,
+// like "wip" or "asdf", in the BCP 47 spirit of "und".
+const undeterminedLanguage = "und"
+
+// messageKeywords are the words the content rules look for, per message
+// language. Matching is by substring, so stems like "corrig" catch
+// "corrige", "corrigé" and "corrigido" alike. Languages without an entry
+// get no keyword rules.
+type messageKeywords struct {
+	Fix     []string // a fix: "fixes"
+	Generic []string // prefixes of a message saying nothing: "generic_messages"
+}
+
+var keywordTables = map[string]messageKeywords{
+	"en": {
+		Fix:     []string{"fix", "bug", "error"},
+		Generic: []string{"update", "changes"},
+	},
+	"es": {
+		Fix:     []string{"arregl", "corrig", "solucion", "error", "fallo"},
+		Generic: []string{"actualiza", "cambios"},
+	},
+	"de": {
+		Fix:     []string{"behoben", "behebe", "fehler", "korrigier", "repariert"},
+		Generic: []string{"aktualisier", "änderungen"},
+	},
+	"fr": {
+		Fix:     []string{"corrig", "correction", "erreur", "bogue", "répar"},
+		Generic: []string{"mise à jour", "modifications"},
+	},
+	"pt": {
+		Fix:     []string{"corrig", "correção", "conserta", "erro"},
+		Generic: []string{"atualiza", "alterações", "mudanças"},
+	},
+}
+
+// keywordOrder is the order keyword tables are combined in.
+var keywordOrder = []string{"en", "es", "de", "fr", "pt"}
+
+// keywordsFor is the English keywords plus those of lang, since most
+// developers mix "fix:" and "update" into messages in their own language.
+// Messages too short to place, like "actualiza readme", get every table.
+// The second result is false when lang has no keyword table of its own.
+func keywordsFor(lang string) (messageKeywords, bool) {
+	langs := []string{"en"}
+	switch _, ok := keywordTables[lang]; {
+	case lang == undeterminedLanguage:
+		langs = keywordOrder
+	case !ok:
+		return keywordTables["en"], false
+	case lang != "en":
+		langs = append(langs, lang)
+	}
+	var keywords messageKeywords
+	for _, l := range langs {
+		table := keywordTables[l]
+		keywords.Fix = append(keywords.Fix, table.Fix...)
+		keywords.Generic = append(keywords.Generic, table.Generic...)
+	}
+	return keywords, true
+}
+
+// languageMarkers are common short words of each Latin-script language.
+// The language with the most markers in a message wins; ties go to the
+// earlier entry.
+var languageMarkers = []struct {
+	lang  string
+	words map[string]bool
+}{
+	{"en", wordSet("the", "and", "to", "of", "for", "with", "in", "on", "add", "fix", "remove", "update", "use", "when", "from", "is", "not")},
+	{"es", wordSet("el", "la", "los", "las", "de", "del", "que", "y", "con", "para", "por", "se", "una", "agrega", "añade", "arregla", "arreglo")},
+	{"de", wordSet("der", "die", "das", "und", "mit", "für", "von", "zu", "ist", "nicht", "ein", "eine", "den", "dem", "hinzugefügt", "behoben")},
+	{"fr", wordSet("le", "les", "des", "du", "et", "pour", "avec", "dans", "une", "est", "ajout", "ajoute", "sur", "pas")},
+	{"pt", wordSet("o", "os", "as", "do", "da", "dos", "das", "e", "em", "na", "no", "com", "uma", "não", "adiciona", "ajusta")},
+}
+
+// scriptLanguages maps scripts used by essentially one language to it.
+var scriptLanguages = map[string]string{
+	"Devanagari": "hi",
+	"Han":        "zh",
+	"Hiragana":   "ja",
+	"Katakana":   "ja",
+	"Hangul":     "ko",
+	"Cyrillic":   "ru",
+	"Arabic":     "ar",
+	"Greek":      "el",
+	"Hebrew":     "he",
+	"Thai":       "th",
+}
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// detectMessageLanguage guesses the language of one commit message: from
+// its script when that isn't Latin, otherwise from its common words.
+func detectMessageLanguage(msg string) string {
+	if script := commitScript(msg); script != "Latin" {
+		if lang, ok := scriptLanguages[script]; ok {
+			return lang
+		}
+		return undeterminedLanguage
+	}
+	best, bestHits := undeterminedLanguage, 0
+	words := messageWords(msg)
+	for _, marker := range languageMarkers {
+		hits := 0
+		for _, word := range words {
+			if marker.words[word] {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = marker.lang, hits
+		}
+	}
+	return best
+}
+
+// MessageLanguageStats is the share of commit messages in each detected
+// language, as ISO 639-1 codes ("und" when there were no clues).
+type MessageLanguageStats struct {
+	Distribution map[string]float64 `json:"distribution"`
+	Dominant     string             `json:"dominant"`
+	// KeywordCoverage is false when the dominant language has no keyword
+	// table, so the fix and generic message rules are skipped
+	KeywordCoverage bool `json:"keyword_coverage"`
+}
+
+// messageLanguageStats turns per-language message counts into shares. The
+// dominant language is the most common one that was detected; "und" only
+// wins when nothing was.
+func messageLanguageStats(counts map[string]int, total int) MessageLanguageStats {
+	stats := MessageLanguageStats{Distribution: make(map[string]float64, len(counts)), Dominant: undeterminedLanguage}
+	langs := make([]string, 0, len(counts))
+	for lang, count := range counts {
+		stats.Distribution[lang] = share(count, total)
+		if lang != undeterminedLanguage {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if counts[langs[i]] != counts[langs[j]] {
+			return counts[langs[i]] > counts[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	if len(langs) > 0 {
+		stats.Dominant = langs[0]
+	}
+	_, stats.KeywordCoverage = keywordsFor(stats.Dominant)
+	return stats
+}
+
+// skipsKeywordRules reports whether the keyword rules should stay quiet.
+// Stats from before language detection have no Dominant and keep them.
+func (m MessageLanguageStats) skipsKeywordRules() bool {
+	return m.Dominant != "" && !m.KeywordCoverage
+}
+
+// hasPrefixAny reports whether s starts with any of prefixes.
+func hasPrefixAny(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// keywordRules are the rules that read message keywords; they are skipped
+// when the messages are in a language the keyword tables don't cover,
+// rather than firing on zero matches.
+var keywordRules = map[string]bool{
+	"fixes":            true,
+	"generic_messages": true,
+}
+
+// languageDisclaimer explains skipped keyword rules in the stats.
+func languageDisclaimer(lang string) string {
+	return "Most commit messages are in a language (" + lang + ") the keyword rules don't understand; fix and generic message checks were skipped."
+}
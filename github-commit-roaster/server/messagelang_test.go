@@ -0,0 +1,159 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectMessageLanguage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"fix the login form", "en"},
+		{"arregla el error de la página", "es"},
+		{"Fehler bei der Anmeldung behoben", "de"},
+		{"corrige le bug dans les formulaires", "fr"},
+		{"corrige o erro na página do usuário", "pt"},
+		{"लॉगिन बग ठीक किया", "hi"},
+		{"修复登录问题", "zh"},
+		{"исправил сборку", "ru"},
+		{"ログインを修正", "ja"},
+		{"wip", undeterminedLanguage},
+		{"asdf", undeterminedLanguage},
+		{"", undeterminedLanguage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := detectMessageLanguage(tt.msg); got != tt.want {
+				t.Errorf("detectMessageLanguage(%q) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeywordsFor(t *testing.T) {
+	tests := []struct {
+		lang     string
+		fix      string // a fix keyword that must be included
+		covered  bool
+		fixCount int
+	}{
+		{"en", "fix", true, len(keywordTables["en"].Fix)},
+		{"es", "arregl", true, len(keywordTables["en"].Fix) + len(keywordTables["es"].Fix)},
+		{"de", "behoben", true, len(keywordTables["en"].Fix) + len(keywordTables["de"].Fix)},
+		{"hi", "fix", false, len(keywordTables["en"].Fix)},
+		{undeterminedLanguage, "répar", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			keywords, covered := keywordsFor(tt.lang)
+			if covered != tt.covered {
+				t.Errorf("covered = %v, want %v", covered, tt.covered)
+			}
+			if !containsAny(tt.fix, keywords.Fix...) {
+				t.Errorf("fix keywords %q miss %q", keywords.Fix, tt.fix)
+			}
+			if tt.fixCount > 0 && len(keywords.Fix) != tt.fixCount {
+				t.Errorf("%d fix keywords, want %d", len(keywords.Fix), tt.fixCount)
+			}
+		})
+	}
+}
+
+func TestMessageLanguageStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[string]int
+		total  int
+		want   MessageLanguageStats
+	}{
+		{
+			"nothing detected",
+			map[string]int{undeterminedLanguage: 2}, 2,
+			MessageLanguageStats{Distribution: map[string]float64{"und": 1}, Dominant: undeterminedLanguage, KeywordCoverage: true},
+		},
+		{
+			"detected beats undetermined",
+			map[string]int{undeterminedLanguage: 3, "es": 1}, 4,
+			MessageLanguageStats{Distribution: map[string]float64{"und": 0.75, "es": 0.25}, Dominant: "es", KeywordCoverage: true},
+		},
+		{
+			"ties are alphabetical",
+			map[string]int{"hi": 2, "en": 2}, 4,
+			MessageLanguageStats{Distribution: map[string]float64{"hi": 0.5, "en": 0.5}, Dominant: "en", KeywordCoverage: true},
+		},
+		{
+			"uncovered language",
+			map[string]int{"hi": 3, "en": 1}, 4,
+			MessageLanguageStats{Distribution: map[string]float64{"hi": 0.75, "en": 0.25}, Dominant: "hi"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageLanguageStats(tt.counts, tt.total); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messageLanguageStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMixedLanguageAnalysis(t *testing.T) {
+	hasRule := func(stats CommitStats, id string) bool {
+		for _, line := range ruleLines(stats, defaultWeights(), defaultIntensity, defaultPersona) {
+			if line.rule == id {
+				return true
+			}
+		}
+		return false
+	}
+	tests := []struct {
+		name       string
+		messages   []string
+		dominant   string
+		fixes      int
+		generic    int
+		disclaimer bool
+		fixesRule  bool
+	}{
+		{
+			"Spanish with English prefixes",
+			[]string{"arregla el error de la página", "fix: corrige el fallo del login", "actualiza el readme", "agrega la tabla de usuarios"},
+			"es", 2, 1, false, false,
+		},
+		{
+			"German and English",
+			[]string{"Fehler bei der Anmeldung behoben", "fix the build", "Fehler in der Suche behoben", "add tests for the parser"},
+			"de", 3, 0, false, true,
+		},
+		{
+			"mostly Hindi",
+			[]string{"लॉगिन बग ठीक किया", "खोज में बग ठीक किया", "पेज में बदलाव", "fix the build"},
+			"hi", 1, 0, true, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := analyzeCommits(messages(tt.messages...), loadRoastConfig())
+			if stats.MessageLanguages.Dominant != tt.dominant {
+				t.Errorf("dominant = %q, want %q (%v)", stats.MessageLanguages.Dominant, tt.dominant, stats.MessageLanguages.Distribution)
+			}
+			if stats.FixCommits != tt.fixes || stats.GenericMessages != tt.generic {
+				t.Errorf("%d fixes and %d generic messages, want %d and %d", stats.FixCommits, stats.GenericMessages, tt.fixes, tt.generic)
+			}
+			if got := stats.Disclaimer != ""; got != tt.disclaimer {
+				t.Errorf("disclaimer = %q", stats.Disclaimer)
+			}
+			if got := hasRule(stats, "fixes"); got != tt.fixesRule {
+				t.Errorf("fixes rule fired = %v, want %v", got, tt.fixesRule)
+			}
+		})
+	}
+
+	// An all-fix history in an uncovered language still can't fire the rule
+	stats := analyzeCommits(messages("बग fix", "बग fix", "बग fix"), loadRoastConfig())
+	stats.MessageLanguages = MessageLanguageStats{Dominant: "hi"}
+	if !findRule(t, "fixes").Triggered(stats) || hasRule(stats, "fixes") {
+		t.Error("keyword rule not skipped for an uncovered language")
+	}
+}
@@ -0,0 +1,47 @@
+// Package middleware holds Gin middleware that isn't specific to roasting.
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewBodySizeLimitMiddleware rejects request bodies larger than maxBytes
+// with 413. A declared Content-Length over the limit is rejected before
+// anything is read; otherwise at most maxBytes+1 bytes are read, so a
+// lying or chunked client can't make the server buffer more than that.
+func NewBodySizeLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	tooLarge := func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":     "request body too large",
+			"max_bytes": maxBytes,
+		})
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			tooLarge(c)
+			return
+		}
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+		c.Request.Body.Close()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "could not read request body"})
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			tooLarge(c)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
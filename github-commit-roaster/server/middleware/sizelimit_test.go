@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBodySizeLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const limit = 16
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64 // -1 for chunked
+		status        int
+	}{
+		{"no body", "", 0, http.StatusOK},
+		{"under the limit", strings.Repeat("a", limit-1), limit - 1, http.StatusOK},
+		{"exactly the limit", strings.Repeat("a", limit), limit, http.StatusOK},
+		{"one byte over", strings.Repeat("a", limit+1), limit + 1, http.StatusRequestEntityTooLarge},
+		{"declared too large", "a", 1 << 30, http.StatusRequestEntityTooLarge},
+		{"chunked and too large", strings.Repeat("a", 10*limit), -1, http.StatusRequestEntityTooLarge},
+		{"chunked and small", "a", -1, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			r := gin.New()
+			r.POST("/", NewBodySizeLimitMiddleware(limit), func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				received = string(body)
+				c.Status(http.StatusOK)
+			})
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			req.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.status == http.StatusOK {
+				if received != tt.body {
+					t.Errorf("handler read %d bytes, want %d", len(received), len(tt.body))
+				}
+				return
+			}
+			if received != "" {
+				t.Error("handler ran for a rejected body")
+			}
+			var resp struct {
+				Error    string `json:"error"`
+				MaxBytes int64  `json:"max_bytes"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Error != "request body too large" || resp.MaxBytes != limit {
+				t.Errorf("response = %+v", resp)
+			}
+		})
+	}
+}
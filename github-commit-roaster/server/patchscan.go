@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// maxCommitDetails caps the GetCommit calls a deep scan may spend.
+	maxCommitDetails = 20
+	// maxPatchScanCommits caps how many of those diffs are read for profanity.
+	maxPatchScanCommits = 15
+)
+
+// CodeCommentProfanity counts swear words added in commit diffs.
+type CodeCommentProfanity struct {
+	Count          int      `json:"count"`
+	CommitExamples []string `json:"commit_examples"`
+}
+
+// commitDetail is a commit together with the files it touched and its
+// line stats.
+type commitDetail struct {
+	SHA       string
+	Files     []*github.CommitFile
+	Additions int
+	Deletions int
+}
+
+// fetchCommitDetails fetches the file lists of up to limit commits. It also
+// returns how many API calls were made.
+func fetchCommitDetails(ctx context.Context, client *ghclient.Client, owner string, commits []NormalizedCommit, limit int) ([]commitDetail, int) {
+	var details []commitDetail
+	calls := 0
+
+	for _, commit := range commits {
+		if calls == limit {
+			break
+		}
+		calls++
+
+		spanCtx, span := startGitHubSpan(ctx, "Repositories.GetCommit",
+			attribute.String("github.repo", commit.Repo), attribute.String("github.sha", commit.SHA))
+		full, _, err := client.Repositories.GetCommit(spanCtx, owner, commit.Repo, commit.SHA, nil)
+		endSpan(span, err)
+		if err != nil {
+			continue // Skip commit if we can't get its diff
+		}
+		details = append(details, commitDetail{
+			SHA:       commit.SHA,
+			Files:     full.Files,
+			Additions: full.GetStats().GetAdditions(),
+			Deletions: full.GetStats().GetDeletions(),
+		})
+	}
+	return details, calls
+}
+
+// addedLines returns the lines a unified diff patch adds.
+func addedLines(patch string) []string {
+	var lines []string
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+			lines = append(lines, line[1:])
+		}
+	}
+	return lines
+}
+
+// scanPatchProfanity counts swear words in the lines added by the first
+// maxPatchScanCommits commit diffs.
+func scanPatchProfanity(details []commitDetail, swears map[string]bool) CodeCommentProfanity {
+	result := CodeCommentProfanity{CommitExamples: []string{}}
+
+	for i, detail := range details {
+		if i == maxPatchScanCommits {
+			break
+		}
+		found := 0
+		for _, file := range detail.Files {
+			for _, line := range addedLines(file.GetPatch()) {
+				found += countProfanity(line, swears)
+			}
+		}
+		if found > 0 {
+			result.Count += found
+			result.CommitExamples = append(result.CommitExamples, detail.SHA)
+		}
+	}
+	return result
+}
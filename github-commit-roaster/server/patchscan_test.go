@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddedLines(t *testing.T) {
+	patch := "--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n package main\n-// old\n+// damn this\n+func main() {}"
+	want := []string{"// damn this", "func main() {}"}
+	if got := addedLines(patch); !reflect.DeepEqual(got, want) {
+		t.Errorf("addedLines() = %q, want %q", got, want)
+	}
+	if got := addedLines(""); got != nil {
+		t.Errorf("addedLines(\"\") = %q", got)
+	}
+}
+
+func patchDetail(sha string, patches ...string) commitDetail {
+	detail := commitDetail{SHA: sha}
+	for _, patch := range patches {
+		detail.Files = append(detail.Files, &github.CommitFile{Patch: github.String(patch)})
+	}
+	return detail
+}
+
+func TestScanPatchProfanity(t *testing.T) {
+	swears := profanitySet(englishProfanity, nil)
+	tests := []struct {
+		name    string
+		details []commitDetail
+		want    CodeCommentProfanity
+	}{
+		{"nothing scanned", nil, CodeCommentProfanity{CommitExamples: []string{}}},
+		{
+			"added lines only",
+			[]commitDetail{
+				patchDetail("a1", "+// WTF does this do\n-// damn old comment"),
+				patchDetail("b2", "+x := 1", "+// shit, off by one\n+// damn"),
+				patchDetail("c3", "-// hell no"),
+			},
+			CodeCommentProfanity{Count: 3, CommitExamples: []string{"a1", "b2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scanPatchProfanity(tt.details, swears); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scanPatchProfanity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var many []commitDetail
+	for i := range maxPatchScanCommits + 5 {
+		many = append(many, patchDetail(fmt.Sprint(i), "+// damn"))
+	}
+	if got := scanPatchProfanity(many, swears); got.Count != maxPatchScanCommits {
+		t.Errorf("scanned %d diffs, want %d", got.Count, maxPatchScanCommits)
+	}
+}
+
+func TestFetchCommitDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repos := ghclient.NewMockRepositoriesService(ctrl)
+	client := &ghclient.Client{Repositories: repos}
+
+	commits := []NormalizedCommit{{Repo: "api", SHA: "a1"}, {Repo: "api", SHA: "gone"}, {Repo: "site", SHA: "c3"}, {Repo: "site", SHA: "d4"}}
+	repos.EXPECT().GetCommit(gomock.Any(), "octocat", "api", "a1", nil).Return(&github.RepositoryCommit{
+		Files: []*github.CommitFile{{Filename: github.String("main.go")}},
+		Stats: &github.CommitStats{Additions: github.Int(4), Deletions: github.Int(1)},
+	}, nil, nil)
+	repos.EXPECT().GetCommit(gomock.Any(), "octocat", "api", "gone", nil).Return(nil, nil, errors.New("404"))
+	repos.EXPECT().GetCommit(gomock.Any(), "octocat", "site", "c3", nil).Return(&github.RepositoryCommit{}, nil, nil)
+
+	details, calls := fetchCommitDetails(t.Context(), client, "octocat", commits, 3)
+	if calls != 3 {
+		t.Errorf("made %d calls, want 3", calls)
+	}
+	if len(details) != 2 || details[0].SHA != "a1" || details[1].SHA != "c3" {
+		t.Fatalf("details = %+v, want a1 and c3", details)
+	}
+	if details[0].Additions != 4 || details[0].Deletions != 1 || len(details[0].Files) != 1 {
+		t.Errorf("a1 detail = %+v", details[0])
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// defaultPersona is the voice of the rules' own templates.
+const defaultPersona = "sarcastic"
+
+// personaTemplates rephrase rules in another voice, keyed by persona and
+// rule ID. A persona has one phrasing per rule whatever the intensity, and
+// rules it doesn't cover keep their default phrasing. Templates take the
+// rule's Args, exactly like the rule's own templates.
+var personaTemplates = map[string]map[string]string{
+	"corporate": {
+		"late_night":               "Per our analysis, most of your deliverables ship between %s. Let's circle back on work-life balance.",
+		"weekends_only":            "Your commit cadence is 100% weekend-aligned. Let's sync on weekday bandwidth.",
+		"nonstop":                  "You're averaging %.0f commits per active day. Great hustle, but let's talk about burnout KPIs.",
+		"swearing":                 "We identified %d instances of non-inclusive language in commit messages. HR has been looped in.",
+		"merges":                   "Merge activity is outpacing feature delivery. Let's realign on value-add.",
+		"branch_merges":            "%d branch merges. Let's streamline our integration workflow going forward.",
+		"squash_merges":            "Every deliverable is a squash merge. Great optics, limited transparency.",
+		"designer":                 "More CSS than JavaScript. Let's revisit your role alignment with the design org.",
+		"untested":                 "Fewer than a third of your repos have tests. Let's talk about quality ownership.",
+		"no_gitignore":             "Several repos lack a .gitignore. Please align with our repository hygiene standards.",
+		"web_editor":               "%.0f%% of your commits come from the browser. Let's upskill on local tooling.",
+		"nine_to_five":             "All your commits land between %s. Exemplary adherence to core hours.",
+		"sleepless_percentile":     "Your late-night ratio benchmarks in the %s percentile. Let's discuss sustainable pacing.",
+		"off_hours":                "Hardly any of your commits land between %s. Let's align your output with core hours.",
+		"slow_pace":                "You're tracking to %d commits this year. Let's discuss a performance improvement plan.",
+		"bare_issue_refs":          "%d commit messages are just a ticket number. Let's add some narrative to our deliverables.",
+		"abandoned_projects":       "%d initiatives were sunset after their first sprint. Let's talk about follow-through.",
+		"famous_abandoned":         "%s has strong brand equity and zero ongoing investment. Let's revisit the roadmap.",
+		"nobody_cares":             "%d commits with zero stakeholder engagement. Let's work on visibility.",
+		"shell_heavy":              "More shell than application code. Have you considered a lateral move to platform?",
+		"fixes":                    "Most of your commits are remediation. Let's shift left on quality.",
+		"fixups":                   "%d unsquashed fixup commits remain in scope. Please action before EOD.",
+		"generic_messages":         "Your commit messages lack actionable insights. Let's double-click on that.",
+		"empty_messages":           "%d commits were submitted without a description. That's a documentation gap.",
+		"veteran_low_activity":     "After %d years on the platform, your output has been rightsized to near zero.",
+		"solo":                     "%.0f%% of your commits are siloed. Let's leverage cross-functional synergies.",
+		"collaborator":             "You're a strong team player. Have you considered owning a workstream?",
+		"no_commit_body":           "Under 10% of your commits include a body. Stakeholders need more visibility.",
+		"no_issue_links":           "None of your commits are linked to a ticket. That's a traceability risk.",
+		"over_linked":              "Every commit references a ticket. Process compliance: exceeds expectations.",
+		"unsigned":                 "Zero signed commits. Infosec would like to schedule a quick touchpoint.",
+		"always_signed":            "Every commit is signed. Audit will love you.",
+		"dmca":                     "One of your repos is subject to a legal hold. Please loop in Legal.",
+		"serial_starter":           "%d of your last %d initiatives have been deprioritized indefinitely.",
+		"initial_commit_graveyard": "%d projects never progressed past kickoff. Let's revisit the roadmap.",
+		"mixed_scripts":            "Your commits span %d writing systems. Great for our global footprint.",
+		"cross_repo_duplicates":    "The message '%s' was reused across %d repos. Let's not copy-paste our messaging.",
+		"automation":               "%.0f%% of your output is automated. Impressive headcount efficiency.",
+		"borrowed_glory":           "%.0f%% of your attributed commits were authored by other resources.",
+		"code_profanity":           "Non-compliant language was found in the codebase itself. Please remediate.",
+		"doc_only":                 "1 in %d of your commits is documentation-only. Let's focus on shipping.",
+		"shouting":                 "%d commit messages were in ALL CAPS. Let's keep communications professional.",
+		"debt_markers":             "%d TODOs committed. Technical debt is trending above forecast.",
+		"no_follow_through":        "%d commits against %d closed issues or PRs. Let's focus on closing the loop.",
+		"vague_vocabulary":         "Your most used word is '%s' (%d times). Let's align on more actionable language.",
+		"noreply_only":             "All your commits come from GitHub's web editor. Let's invest in proper tooling.",
+		"typos":                    "%s — let's circle back on proofreading before we ship.",
+		"giant_commits":            "Your commits average %.0f lines. Let's break deliverables into smaller increments.",
+		"one_liners":               "%d of %d sampled commits are one-liners. Let's consolidate our deliverables.",
+		"many_names":               "You've committed under %d different names. Let's align on a single source of truth.",
+	},
+	"pirate": {
+		"late_night":               "Arr, most o' yer commits be made between %s. Even the night watch sleeps, matey!",
+		"weekends_only":            "Not a single weekday commit! Ye only sail on Saturdays, landlubber?",
+		"nonstop":                  "%.0f commits a day! Ye be bailin' water faster than the ship be sinkin'.",
+		"swearing":                 "%d curses in yer commits! Ye swear worse than a sailor — and I be one.",
+		"merges":                   "Ye merge more than ye plunder. A true bosun o' the branches.",
+		"branch_merges":            "%d branch merges! Yer rigging be tangled worse than a kraken's knitting.",
+		"squash_merges":            "Every haul be squashed into one chest. What be ye hidin' below decks?",
+		"designer":                 "More CSS than JavaScript! Ye paint the ship but never sail her.",
+		"untested":                 "Fewer than a third o' yer ships were ever tested at sea. The rest sail on prayers!",
+		"no_gitignore":             "No .gitignore aboard! Every barnacle an' .DS_Store gets hauled into the hold!",
+		"web_editor":               "%.0f%% o' yer commits scribbled in a browser! Where be yer terminal, landlubber?",
+		"nine_to_five":             "Ye only sail between %s. A pirate with office hours? Shameful!",
+		"sleepless_percentile":     "Yer night watches rank in the %s percentile o' the fleet. Even the ghosts sleep more!",
+		"off_hours":                "Ye never sail between %s. Plunderin' by moonlight only, eh?",
+		"slow_pace":                "At this pace ye'll push %d commits this year. The barnacles move faster!",
+		"bare_issue_refs":          "%d messages be naught but a number. A treasure map with no X!",
+		"abandoned_projects":       "%d ships launched and scuttled within a week. Davy Jones thanks ye for the fleet!",
+		"famous_abandoned":         "%s be a famous ship left to rot in the harbour. Shiver me timbers!",
+		"nobody_cares":             "%d commits and not a single star to steer by. Lost at sea, ye are!",
+		"shell_heavy":              "More shell scripts than cargo! Ye be a deckhand, not a captain.",
+		"fixes":                    "Most o' yer commits be patchin' holes. Yer hull be more patch than plank!",
+		"fixups":                   "%d fixups left unsquashed in the hold. Walk the plank, ye forgetful swab!",
+		"generic_messages":         "Yer commit messages be as bland as hardtack.",
+		"empty_messages":           "%d commits with nary a word. Cat got yer tongue, or the kraken?",
+		"veteran_low_activity":     "%d years at sea and ye barely lift an oar these days.",
+		"solo":                     "%.0f%% o' yer commits be solo. A crew o' one, aye?",
+		"collaborator":             "Ye sail on every ship but yer own. Time to captain yer own vessel!",
+		"no_commit_body":           "Under 10% o' yer commits tell the tale behind 'em. A map with no X!",
+		"no_issue_links":           "Not one commit points to an issue. Sailin' without a chart, ye are.",
+		"over_linked":              "Every commit be linked to an issue. Ye log the weather in the captain's log too?",
+		"unsigned":                 "Not a single signed commit. Any scallywag could fly yer colours!",
+		"always_signed":            "Every commit signed and sealed. A most honourable pirate.",
+		"dmca":                     "The admiralty seized one o' yer repos! Bold piracy, that.",
+		"serial_starter":           "%d o' yer last %d ships be rottin' in the harbour.",
+		"initial_commit_graveyard": "%d repos sank right after launch. Davy Jones be collectin' 'em.",
+		"mixed_scripts":            "Yer commits be in %d scripts. A worldly buccaneer!",
+		"cross_repo_duplicates":    "'%s' be written in %d different logs. Copyin' the captain's log, eh?",
+		"automation":               "%.0f%% o' yer commits be from bots. A ghost ship, crewed by the undead!",
+		"borrowed_glory":           "%.0f%% o' yer commits were writ by other hands. Plundered glory!",
+		"code_profanity":           "Curses be carved right into yer code. Blimey!",
+		"doc_only":                 "1 in %d o' yer commits be naught but scribblin' in the ship's log.",
+		"shouting":                 "%d commit messages SHOUTED LIKE A STORM. We hear ye from the crow's nest!",
+		"debt_markers":             "%d TODOs committed. Yer debts be pilin' up like doubloons — someone else's.",
+		"no_follow_through":        "%d commits and but %d issues or PRs closed. Ye hoist every sail and never make port!",
+		"vague_vocabulary":         "Yer favourite word be '%s', %d times. A captain's log with naught but 'arr' in it!",
+		"noreply_only":             "Every commit o' yers came through GitHub's web editor. Ye've never touched a real terminal, landlubber!",
+		"typos":                    "%s — yer spellin' be as crooked as a drunken parrot's flight!",
+		"giant_commits":            "Yer commits average %.0f lines. That be a whole treasure chest dumped on deck at once!",
+		"one_liners":               "%d of %d sampled commits be one-liners. Ye fire one cannonball and call it a broadside!",
+		"many_names":               "Ye've sailed under %d different names. A true pirate, hidin' from the Navy!",
+	},
+	"shakespearean": {
+		"late_night":               "Thy commits do come between %s. To sleep, perchance to dream — or not at all.",
+		"weekends_only":            "No weekday doth see thy labour. Art thou a knight of Saturday alone?",
+		"nonstop":                  "%.0f commits each day! Thou dost protest too much, methinks.",
+		"swearing":                 "%d oaths most foul besmirch thy commits. Out, damned word!",
+		"merges":                   "Thou mergest more than thou createst. A weaver of others' threads.",
+		"branch_merges":            "%d branches merged, each into the other. A tangled web thou hast woven.",
+		"squash_merges":            "All thy works are squashed to one. What secrets lie in those lost branches?",
+		"designer":                 "More CSS than JavaScript. Thou art a painter, not a playwright.",
+		"untested":                 "Fewer than a third of thy works were ever tried. The rest rely on fortune's favour.",
+		"no_gitignore":             "Thy repos lack a .gitignore, and so all refuse is kept as treasure.",
+		"web_editor":               "%.0f%% of thy commits were writ upon a web form. Hast thou no quill of thine own?",
+		"nine_to_five":             "Thou committest only between %s. Thy muse keepeth strict hours.",
+		"sleepless_percentile":     "Thy late-night labours rank in the %s percentile. Macbeth hath murdered sleep, and so hast thou.",
+		"off_hours":                "Thou committest never between %s. What dost thou with thy days?",
+		"slow_pace":                "At this pace, %d commits this year. Tomorrow, and tomorrow, and tomorrow, creeps in this petty pace.",
+		"bare_issue_refs":          "%d messages are but a number. Words, words, words? Nay, not one.",
+		"abandoned_projects":       "%d works begun in passion and forsaken within the week. Love's labour's lost, %[1]d times.",
+		"famous_abandoned":         "%s, once beloved, now forsaken. Fame is a fickle mistress.",
+		"nobody_cares":             "%d commits, and no star doth shine upon them. Full of sound and fury, signifying nothing.",
+		"shell_heavy":              "More shell than substance. To script or to program, that is the question.",
+		"fixes":                    "Most of thy commits do mend what was broken. Something is rotten in thy codebase.",
+		"fixups":                   "%d fixups linger unsquashed. What's done cannot be undone — but it could be rebased.",
+		"generic_messages":         "Thy commit messages are words, words, words — signifying nothing.",
+		"empty_messages":           "%d commits without a word. The rest is silence.",
+		"veteran_low_activity":     "%d years upon this stage, and now thou playest but a silent part.",
+		"solo":                     "%.0f%% of thy commits are solitary. A soliloquy, not a play.",
+		"collaborator":             "Thou playest in every company but thine own. Wilt thou not write thy own play?",
+		"no_commit_body":           "Under 10% of thy commits give reason. Brevity is the soul of wit, but not of history.",
+		"no_issue_links":           "Not one commit nameth its issue. What's in a name? Context, good sir.",
+		"over_linked":              "Each commit doth cite an issue. Thou art a scribe most diligent.",
+		"unsigned":                 "No commit bears thy seal. Who is't that can say 'this is mine'?",
+		"always_signed":            "Every commit bears thy seal. Honest as the day is long.",
+		"dmca":                     "One of thy repos is banished by law. Exeunt, pursued by lawyers.",
+		"serial_starter":           "%d of thy last %d works lie forsaken. Parting is such sweet sorrow.",
+		"initial_commit_graveyard": "%d repos died in their first scene. Alas, poor repo, I knew it well.",
+		"mixed_scripts":            "Thy commits speak in %d scripts. A tongue for every court.",
+		"cross_repo_duplicates":    "'%s' is writ in %d repos. A twice-told tale, vexing the ear.",
+		"automation":               "%.0f%% of thy commits are penned by machines. All the world's a cron job.",
+		"borrowed_glory":           "%.0f%% of commits called thine were writ by others. Borrowed plumes, fair sir.",
+		"code_profanity":           "Foul words lurk within thy very code. A plague o' both thy branches!",
+		"doc_only":                 "1 in %d of thy commits is but a scroll of documentation. Much ado about nothing.",
+		"shouting":                 "%d commits SHOUT IN CAPITALS. Speak the speech, I pray you, trippingly on the tongue.",
+		"debt_markers":             "%d TODOs committed. Neither a borrower nor a lender be — yet here thy debts are.",
+		"no_follow_through":        "%d commits, yet but %d issues or PRs closed. Thou art full of sound and fury, finishing nothing.",
+		"vague_vocabulary":         "Thy most used word is '%s', %d times. Words, words, words — and none of them meaning aught.",
+		"noreply_only":             "Every commit of thine came from GitHub's web editor. A terminal! A terminal! My kingdom for a terminal!",
+		"typos":                    "%s — thou dost murder the King's English most foully.",
+		"giant_commits":            "Thy commits average %.0f lines. Brevity is the soul of wit, and thou hast none.",
+		"one_liners":               "%d of %d sampled commits change but a line. Much ado about nothing, %[1]d times over.",
+		"many_names":               "Thou hast committed under %d names. What's in a name? Apparently, everything.",
+	},
+}
+
+// validPersona reports whether name is a persona ?persona= accepts.
+func validPersona(name string) bool {
+	_, ok := personaTemplates[name]
+	return ok || name == defaultPersona
+}
+
+// personaNames lists every persona, for error messages.
+func personaNames() []string {
+	names := []string{defaultPersona}
+	for name := range personaTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// personaLine renders rule in persona's voice, reporting false when the
+// persona has no phrasing for it.
+func personaLine(rule roastRule, stats CommitStats, persona string) (string, bool) {
+	tmpl, ok := personaTemplates[persona][rule.ID]
+	if !ok {
+		return "", false
+	}
+	if rule.Args == nil {
+		return tmpl, true
+	}
+	return fmt.Sprintf(tmpl, rule.Args(stats)...), true
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPersonaChangesPhrasingOnly(t *testing.T) {
+	stats := CommitStats{TotalCommits: 10, SwearWords: 5, FixCommits: 8, LateNightCommits: 6, Shouting: ShoutingStats{ShoutingCommits: 3}}
+	base := ruleLines(stats, defaultWeights(), defaultIntensity, defaultPersona)
+	if len(base) < 3 {
+		t.Fatalf("only %d rules fire", len(base))
+	}
+	for _, persona := range personaNames()[1:] {
+		t.Run(persona, func(t *testing.T) {
+			lines := ruleLines(stats, defaultWeights(), defaultIntensity, persona)
+			if len(lines) != len(base) {
+				t.Fatalf("%d lines, want %d", len(lines), len(base))
+			}
+			for i, line := range lines {
+				if line.rule != base[i].rule || line.weight != base[i].weight {
+					t.Errorf("line %d is %s (%g), want %s (%g)", i, line.rule, line.weight, base[i].rule, base[i].weight)
+				}
+				_, covered := personaTemplates[persona][line.rule]
+				if covered == (line.text == base[i].text) {
+					t.Errorf("%s: persona covers it = %v, but text %q", line.rule, covered, line.text)
+				}
+				if strings.Contains(line.text, "%!") {
+					t.Errorf("%s: bad template: %q", line.rule, line.text)
+				}
+			}
+		})
+	}
+}
+
+func TestPersonaNames(t *testing.T) {
+	want := []string{"sarcastic", "corporate", "pirate", "shakespearean"}
+	if got := personaNames(); !slices.Equal(got, want) {
+		t.Errorf("personaNames() = %q, want %q", got, want)
+	}
+	for _, name := range want {
+		if !validPersona(name) {
+			t.Errorf("validPersona(%q) = false", name)
+		}
+	}
+	if validPersona("yoda") {
+		t.Error(`validPersona("yoda") = true`)
+	}
+}
+
+func TestRoastOptionsPersona(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"", defaultPersona, false},
+		{"persona=pirate", "pirate", false},
+		{"persona=yoda", "", true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/roast?"+tt.query, nil)
+		opts, err := roastOptions(c, loadRoastConfig())
+		if (err != nil) != tt.wantErr || opts.Persona != tt.want {
+			t.Errorf("%q: persona %q, error %v", tt.query, opts.Persona, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "sarcastic, corporate, pirate, shakespearean") {
+			t.Errorf("error doesn't list the personas: %v", err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math"
+
+	"github.com/google/go-github/v50/github"
+)
+
+const (
+	// famousRepoStars is how many stars make a repo famous.
+	famousRepoStars = 50
+	// busyCommitCount is how many commits in the window make a busy user.
+	busyCommitCount = 50
+)
+
+// PopularityStats weighs the stars and forks of the listed repos against
+// the commits that went into them during the window.
+type PopularityStats struct {
+	TotalStars int `json:"total_stars"`
+	TotalForks int `json:"total_forks"`
+	// StarsPerCommit is TotalStars over the window's commits; 0 without
+	// commits
+	StarsPerCommit float64 `json:"stars_per_commit"`
+	// FamousIdleRepos have famousRepoStars or more but no commits in the
+	// window
+	FamousIdleRepos []string `json:"famous_idle_repos"`
+}
+
+// detectPopularity adds up stars and forks and finds famous repos that
+// nobody committed to during the window.
+func detectPopularity(repos []*github.Repository, commits []NormalizedCommit) PopularityStats {
+	stats := PopularityStats{FamousIdleRepos: []string{}}
+	active := make(map[string]bool)
+	for _, commit := range commits {
+		active[commit.Repo] = true
+	}
+	for _, repo := range repos {
+		stats.TotalStars += repo.GetStargazersCount()
+		stats.TotalForks += repo.GetForksCount()
+		if repo.GetStargazersCount() >= famousRepoStars && !active[repo.GetName()] {
+			stats.FamousIdleRepos = append(stats.FamousIdleRepos, repo.GetName())
+		}
+	}
+	if len(commits) > 0 {
+		stats.StarsPerCommit = math.Round(float64(stats.TotalStars)/float64(len(commits))*100) / 100
+	}
+	return stats
+}
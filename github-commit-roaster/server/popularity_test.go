@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// starredRepo is a repo with stars and forks.
+func starredRepo(name string, stars, forks int) *github.Repository {
+	return &github.Repository{Name: github.String(name), StargazersCount: github.Int(stars), ForksCount: github.Int(forks)}
+}
+
+// repoCommits is n commits to repo.
+func repoCommits(repo string, n int) []NormalizedCommit {
+	specs := make([]commitSpec, n)
+	for i := range specs {
+		specs[i] = commitSpec{Message: "work", Author: "Octo", Time: time.Date(2024, 6, 1, i%24, 0, 0, 0, time.UTC)}
+	}
+	return normalizedSpecs(repo, specs...)
+}
+
+func TestDetectPopularity(t *testing.T) {
+	tests := []struct {
+		name    string
+		repos   []*github.Repository
+		commits []NormalizedCommit
+		want    PopularityStats
+	}{
+		{
+			"famous and abandoned",
+			[]*github.Repository{starredRepo("hit", 1200, 80), starredRepo("side", 3, 0)},
+			repoCommits("side", 2),
+			PopularityStats{TotalStars: 1203, TotalForks: 80, StarsPerCommit: 601.5, FamousIdleRepos: []string{"hit"}},
+		},
+		{
+			"busy and unstarred",
+			[]*github.Repository{starredRepo("grind", 0, 0), starredRepo("more-grind", 0, 0)},
+			append(repoCommits("grind", 60), repoCommits("more-grind", 40)...),
+			PopularityStats{FamousIdleRepos: []string{}},
+		},
+		{
+			"famous and maintained",
+			[]*github.Repository{starredRepo("hit", 1200, 80)},
+			repoCommits("hit", 3),
+			PopularityStats{TotalStars: 1200, TotalForks: 80, StarsPerCommit: 400, FamousIdleRepos: []string{}},
+		},
+		{
+			"just under famous",
+			[]*github.Repository{starredRepo("almost", famousRepoStars-1, 0)},
+			nil,
+			PopularityStats{TotalStars: famousRepoStars - 1, FamousIdleRepos: []string{}},
+		},
+		{
+			"stars per commit rounded",
+			[]*github.Repository{starredRepo("a", 10, 0), starredRepo("b", 0, 0)},
+			repoCommits("b", 3),
+			PopularityStats{TotalStars: 10, StarsPerCommit: 3.33, FamousIdleRepos: []string{}},
+		},
+		{"no repos", nil, nil, PopularityStats{FamousIdleRepos: []string{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectPopularity(tt.repos, tt.commits)
+			if got.TotalStars != tt.want.TotalStars || got.TotalForks != tt.want.TotalForks || got.StarsPerCommit != tt.want.StarsPerCommit || !slices.Equal(got.FamousIdleRepos, tt.want.FamousIdleRepos) {
+				t.Errorf("detectPopularity() = %+v, want %+v", got, tt.want)
+			}
+			if got.FamousIdleRepos == nil {
+				t.Error("FamousIdleRepos is nil, want an empty list in the JSON")
+			}
+		})
+	}
+}
+
+func TestPopularityRules(t *testing.T) {
+	famous, nobody := findRule(t, "famous_abandoned"), findRule(t, "nobody_cares")
+	tests := []struct {
+		name           string
+		stats          CommitStats
+		famous, nobody bool
+	}{
+		{"famous and abandoned", CommitStats{CommitsFetched: 2, ReposAnalyzed: 2, Popularity: PopularityStats{TotalStars: 1203, FamousIdleRepos: []string{"hit"}}}, true, false},
+		{"busy and unstarred", CommitStats{CommitsFetched: 100, ReposAnalyzed: 2}, false, true},
+		{"one commit short of busy", CommitStats{CommitsFetched: busyCommitCount - 1, ReposAnalyzed: 2}, false, false},
+		{"busy with a single star", CommitStats{CommitsFetched: 100, ReposAnalyzed: 2, Popularity: PopularityStats{TotalStars: 1}}, false, false},
+		{"no repos analyzed", CommitStats{CommitsFetched: 100}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := famous.Triggered(tt.stats); got != tt.famous {
+				t.Errorf("famous_abandoned triggered = %v, want %v", got, tt.famous)
+			}
+			if got := nobody.Triggered(tt.stats); got != tt.nobody {
+				t.Errorf("nobody_cares triggered = %v, want %v", got, tt.nobody)
+			}
+		})
+	}
+
+	stats := tests[0].stats
+	if want := "hit: famous repo, abandoned. The stars are still shining; you're not."; famous.line(stats, defaultIntensity) != want {
+		t.Errorf("famous_abandoned line = %q", famous.line(stats, defaultIntensity))
+	}
+	stats = tests[1].stats
+	if want := "100 commits and zero stars. Nobody cares, but you keep pushing. Respect."; nobody.line(stats, defaultIntensity) != want {
+		t.Errorf("nobody_cares line = %q", nobody.line(stats, defaultIntensity))
+	}
+}
+
+func TestRoastPopularity(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "add feature", 2))
+	gh.repos = []*github.Repository{starredRepo("project", 2, 1), starredRepo("hit", 900, 40)}
+	s := newTestServer(t, gh)
+	w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var resp RoastResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	got := resp.Stats.Popularity
+	if got.TotalStars != 902 || got.TotalForks != 41 || !slices.Equal(got.FamousIdleRepos, []string{"hit"}) {
+		t.Errorf("popularity = %+v, want 902 stars, 41 forks and hit idle", got)
+	}
+}
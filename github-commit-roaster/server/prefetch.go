@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	prefetchTimeout    = 2 * time.Minute
+	refreshCheckEvery  = 30 * time.Second
+	refreshLeadDivisor = 10 // refresh hot entries in the last tenth of their TTL
+
+	maxPrefetchBatch = 50        // usernames one POST /prefetch may list
+	prefetchJobTTL   = time.Hour // how long finished jobs stay queryable
+)
+
+// prefetchJob is a batch of usernames warmed by one POST /prefetch.
+type prefetchJob struct {
+	ID        string    `json:"id"`
+	Usernames []string  `json:"usernames"`
+	Completed []string  `json:"completed"` // cached, or already fresh
+	Failed    []string  `json:"failed"`
+	Done      bool      `json:"done"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// prefetchEntry tracks a cached default roast the prefetcher may refresh.
+type prefetchEntry struct {
+	username   string
+	fetchedAt  time.Time
+	hits       int
+	prefetched bool // filled by /prefetch and not read yet
+	inflight   bool
+}
+
+// prefetcher warms the cache with default-option roasts in the background.
+// It has its own concurrency budget so warming never competes with real
+// requests for more than a few GitHub calls at a time, and it refreshes
+// entries read at least hotHits times shortly before they expire.
+type prefetcher struct {
+	srv     *server
+	sem     chan struct{}
+	hotHits int
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*prefetchEntry
+	jobs    map[string]*prefetchJob
+}
+
+func newPrefetcher(srv *server, concurrency, hotHits int) *prefetcher {
+	return &prefetcher{
+		srv:     srv,
+		sem:     make(chan struct{}, max(concurrency, 1)),
+		hotHits: hotHits,
+		now:     time.Now,
+		entries: make(map[string]*prefetchEntry),
+		jobs:    make(map[string]*prefetchJob),
+	}
+}
+
+// fresh reports whether key needs no fetch: it is cached and still fresh,
+// or already being fetched. The caller must hold p.mu.
+func (p *prefetcher) fresh(key string) bool {
+	e, ok := p.entries[key]
+	return ok && (e.inflight || p.now().Sub(e.fetchedAt) < p.srv.cacheTTL)
+}
+
+// request starts a background fetch of username unless one is running or
+// the cached roast is still fresh. It returns false when the concurrency
+// budget is used up.
+func (p *prefetcher) request(username string) bool {
+	key := roastCacheKey(username, nil)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fresh(key) {
+		return true
+	}
+	select {
+	case p.sem <- struct{}{}:
+	default:
+		return false
+	}
+	p.entries[key] = &prefetchEntry{username: username, inflight: true, prefetched: true}
+	go p.fetch(key, username)
+	return true
+}
+
+// startJob warms every username in the background, waiting for slots in
+// the concurrency budget rather than giving up when it's used up.
+func (p *prefetcher) startJob(usernames []string) (*prefetchJob, error) {
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	job := &prefetchJob{ID: id, Usernames: usernames, Completed: []string{}, Failed: []string{}, CreatedAt: p.now().UTC()}
+	p.mu.Lock()
+	for jobID, old := range p.jobs {
+		if old.Done && p.now().Sub(old.CreatedAt) > prefetchJobTTL {
+			delete(p.jobs, jobID)
+		}
+	}
+	p.jobs[id] = job
+	p.mu.Unlock()
+
+	go func() {
+		var wg sync.WaitGroup
+		for _, username := range usernames {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok := p.warm(username)
+				p.mu.Lock()
+				defer p.mu.Unlock()
+				if ok {
+					job.Completed = append(job.Completed, username)
+				} else {
+					job.Failed = append(job.Failed, username)
+				}
+			}()
+		}
+		wg.Wait()
+		p.mu.Lock()
+		job.Done = true
+		p.mu.Unlock()
+	}()
+	return job, nil
+}
+
+// warm fetches username once a slot is free, unless its roast is fresh by
+// then. It reports whether the roast ended up cached.
+func (p *prefetcher) warm(username string) bool {
+	key := roastCacheKey(username, nil)
+	p.sem <- struct{}{}
+	p.mu.Lock()
+	if p.fresh(key) {
+		p.mu.Unlock()
+		<-p.sem
+		return true
+	}
+	p.entries[key] = &prefetchEntry{username: username, inflight: true, prefetched: true}
+	p.mu.Unlock()
+	return p.fetch(key, username)
+}
+
+// job returns a snapshot of the job with the given ID.
+func (p *prefetcher) job(id string) (prefetchJob, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	job, ok := p.jobs[id]
+	if !ok {
+		return prefetchJob{}, false
+	}
+	snapshot := *job
+	snapshot.Completed = append([]string{}, job.Completed...)
+	snapshot.Failed = append([]string{}, job.Failed...)
+	return snapshot, true
+}
+
+// fetch analyzes username and caches the result, reporting whether it
+// succeeded. The caller must hold a slot in p.sem.
+func (p *prefetcher) fetch(key, username string) bool {
+	defer func() { <-p.sem }()
+	ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+	defer cancel()
+
+	opts := RoastOptions{Intensity: defaultIntensity, Weights: p.srv.cfg.Weights, MaxLines: p.srv.cfg.MaxRoastLines}
+	client, authMode := p.srv.clients.newClient(ctx)
+	result, err := p.srv.fetchAnalysis(ctx, client, authMode, username, opts, false)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e := p.entries[key]
+	if err != nil {
+		slog.Warn("Prefetch failed", "username", username, "error", err)
+		delete(p.entries, key)
+		return false
+	}
+	p.srv.cache.Set(ctx, key, newCachedRoast(p.srv.roastResponse(username, result, opts, false)), p.srv.cacheTTL)
+	if e == nil {
+		e = &prefetchEntry{username: username}
+		p.entries[key] = e
+	}
+	e.inflight = false
+	e.fetchedAt = p.now()
+	if e.prefetched {
+		p.srv.stats.prefetches.Add(1)
+	} else {
+		p.srv.stats.refreshes.Add(1)
+	}
+	return true
+}
+
+// hit counts a cache hit on key.
+func (p *prefetcher) hit(key string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.entries[key]
+	if !ok {
+		return
+	}
+	e.hits++
+	if e.prefetched {
+		e.prefetched = false
+		p.srv.stats.prefetchHits.Add(1)
+	}
+}
+
+// stored notes a default-option roast the handler just cached, so it can
+// be refreshed if it turns out to be popular.
+func (p *prefetcher) stored(key, username string) {
+	if p == nil || key != roastCacheKey(username, nil) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.entries[key]; ok && e.inflight {
+		return
+	}
+	p.entries[key] = &prefetchEntry{username: username, fetchedAt: p.now()}
+}
+
+// run refreshes hot entries until ctx is done.
+func (p *prefetcher) run(ctx context.Context) {
+	ticker := time.NewTicker(refreshCheckEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.refreshHot()
+		}
+	}
+}
+
+// refreshHot refetches hot entries close to expiry and forgets cold,
+// expired ones.
+func (p *prefetcher) refreshHot() {
+	ttl := p.srv.cacheTTL
+	now := p.now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, e := range p.entries {
+		if e.inflight {
+			continue
+		}
+		age := now.Sub(e.fetchedAt)
+		if e.hits < p.hotHits {
+			if age >= ttl {
+				delete(p.entries, key)
+			}
+			continue
+		}
+		if age < ttl-ttl/refreshLeadDivisor {
+			continue
+		}
+		select {
+		case p.sem <- struct{}{}:
+		default:
+			return // budget used up; try again next tick
+		}
+		e.inflight = true
+		e.hits = 0
+		go p.fetch(key, e.username)
+	}
+}
+
+// handlePrefetch serves POST /prefetch. A single username is fetched right
+// away if the budget allows; a list of usernames becomes a job whose
+// progress GET /prefetch/:id reports.
+func (p *prefetcher) handlePrefetch(c *gin.Context) {
+	var req struct {
+		Username  string   `json:"username"`
+		Usernames []string `json:"usernames"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Username == "" && len(req.Usernames) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username or usernames is required"})
+		return
+	}
+	if len(req.Usernames) > 0 {
+		p.handlePrefetchBatch(c, req.Usernames)
+		return
+	}
+	if err := validateGitHubUsername(req.Username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !p.request(req.Username) {
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "prefetch budget exhausted, try again later"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"username": req.Username, "status": "accepted"})
+}
+
+func (p *prefetcher) handlePrefetchBatch(c *gin.Context, usernames []string) {
+	if len(usernames) > maxPrefetchBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d usernames per request", maxPrefetchBatch)})
+		return
+	}
+	unique := uniqueUsernames(usernames)
+	if len(unique) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username or usernames is required"})
+		return
+	}
+	for _, username := range unique {
+		if err := validateGitHubUsername(username); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "username": username})
+			return
+		}
+	}
+	if !chargeMembers(c, len(unique)) {
+		return
+	}
+
+	job, err := p.startJob(unique)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not start prefetch"})
+		return
+	}
+	c.Header("Location", "/prefetch/"+job.ID)
+	c.JSON(http.StatusAccepted, gin.H{"job_id": job.ID, "usernames": unique, "status": "accepted"})
+}
+
+// handlePrefetchJob serves GET /prefetch/:id.
+func (p *prefetcher) handlePrefetchJob(c *gin.Context) {
+	job, ok := p.job(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "prefetch job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
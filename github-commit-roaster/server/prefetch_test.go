@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventually fails the test unless cond holds within a couple of seconds.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// idle reports whether p has no fetch in flight.
+func (p *prefetcher) idle() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.entries {
+		if e.inflight {
+			return false
+		}
+	}
+	return len(p.sem) == 0
+}
+
+func TestPrefetchWarmsCache(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	s := newTestServer(t, gh)
+	s.prefetch = newPrefetcher(s, 2, 3)
+	key := roastCacheKey("octocat", nil)
+
+	w := doRequest(s.prefetch.handlePrefetch, http.MethodPost, "/prefetch", "/prefetch", `{"username":"octocat"}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	eventually(t, "the prefetch", s.prefetch.idle)
+	if _, ok := s.cache.Get(t.Context(), key); !ok {
+		t.Fatal("prefetch didn't fill the cache")
+	}
+
+	// A fresh entry makes another prefetch a no-op
+	doRequest(s.prefetch.handlePrefetch, http.MethodPost, "/prefetch", "/prefetch", `{"username":"octocat"}`)
+	eventually(t, "the second prefetch", s.prefetch.idle)
+	if calls := gh.callCount("/repos/octocat/project/commits"); calls != 1 {
+		t.Errorf("fetched commits %d times, want 1", calls)
+	}
+
+	// Reading the prefetched roast counts as a converted hit, once
+	for range 2 {
+		w = doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat", "")
+		if w.Header().Get("X-Cache") != "HIT" {
+			t.Fatalf("X-Cache = %q, want HIT", w.Header().Get("X-Cache"))
+		}
+	}
+	if s.stats.prefetches.Load() != 1 || s.stats.prefetchHits.Load() != 1 {
+		t.Errorf("%d prefetches, %d converted hits; want 1 and 1", s.stats.prefetches.Load(), s.stats.prefetchHits.Load())
+	}
+}
+
+func TestPrefetchBudget(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	gh.delay = 200 * time.Millisecond
+	s := newTestServer(t, gh)
+	s.prefetch = newPrefetcher(s, 1, 3)
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"username":"octocat"}`, http.StatusAccepted},
+		{`{"username":"octocat"}`, http.StatusAccepted}, // already in flight
+		{`{"username":"hubot"}`, http.StatusServiceUnavailable},
+		{`{}`, http.StatusBadRequest},
+		{`{"username":"../orgs/x"}`, http.StatusBadRequest},
+		{`{"usernames":["octocat","../orgs/x"]}`, http.StatusBadRequest},
+		{`{"usernames":[" ",""]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := doRequest(s.prefetch.handlePrefetch, http.MethodPost, "/prefetch", "/prefetch", tt.body)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.body, w.Code, tt.status)
+		}
+	}
+	eventually(t, "the prefetch", s.prefetch.idle)
+}
+
+func TestRefreshHot(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	s := newTestServer(t, gh)
+	p := newPrefetcher(s, 2, 3)
+	s.prefetch = p
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	hot, cold, young := roastCacheKey("octocat", nil), roastCacheKey("hubot", nil), roastCacheKey("mona", nil)
+	p.entries[hot] = &prefetchEntry{username: "octocat", fetchedAt: now.Add(-s.cacheTTL * 95 / 100), hits: 3}
+	p.entries[cold] = &prefetchEntry{username: "hubot", fetchedAt: now.Add(-s.cacheTTL), hits: 2}
+	p.entries[young] = &prefetchEntry{username: "mona", fetchedAt: now.Add(-s.cacheTTL / 2), hits: 10}
+
+	p.refreshHot()
+	eventually(t, "the refresh", p.idle)
+
+	if gh.callCount("/repos/octocat/project/commits") != 1 || s.stats.refreshes.Load() != 1 {
+		t.Errorf("hot entry refreshed %d times", s.stats.refreshes.Load())
+	}
+	if e := p.entries[hot]; e == nil || e.hits != 0 || !e.fetchedAt.Equal(now) {
+		t.Errorf("hot entry after refresh = %+v", e)
+	}
+	if _, ok := p.entries[cold]; ok {
+		t.Error("cold expired entry kept")
+	}
+	if e := p.entries[young]; e == nil || e.hits != 10 {
+		t.Error("young hot entry refreshed early")
+	}
+}
+
+func TestPrefetchJob(t *testing.T) {
+	s := newTestServer(t, fakeGitHubs{
+		"octocat": newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2)),
+		"hubot":   newFakeGitHub("hubot", fakeCommit("Hubot", "add chat ops", 3)),
+	})
+	s.prefetch = newPrefetcher(s, 2, 3)
+	r := gin.New()
+	r.POST("/prefetch", s.prefetch.handlePrefetch)
+	r.GET("/prefetch/:id", s.prefetch.handlePrefetchJob)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/prefetch", `{"usernames":["octocat"," Hubot","OCTOCAT","ghost",""]}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var accepted struct {
+		JobID     string   `json:"job_id"`
+		Usernames []string `json:"usernames"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &accepted); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"octocat", "Hubot", "ghost"}; !slices.Equal(accepted.Usernames, want) {
+		t.Errorf("usernames = %q, want %q", accepted.Usernames, want)
+	}
+	if loc := w.Header().Get("Location"); loc != "/prefetch/"+accepted.JobID {
+		t.Errorf("Location = %q", loc)
+	}
+
+	var job prefetchJob
+	eventually(t, "the job to finish", func() bool {
+		w := do(http.MethodGet, "/prefetch/"+accepted.JobID, "")
+		json.Unmarshal(w.Body.Bytes(), &job)
+		return w.Code == http.StatusOK && job.Done
+	})
+	slices.Sort(job.Completed)
+	if !slices.Equal(job.Completed, []string{"Hubot", "octocat"}) || !slices.Equal(job.Failed, []string{"ghost"}) {
+		t.Errorf("job = %+v, want octocat and Hubot done and ghost failed", job)
+	}
+	for _, username := range []string{"octocat", "hubot"} {
+		if _, ok := s.cache.Get(t.Context(), roastCacheKey(username, nil)); !ok {
+			t.Errorf("%s isn't cached", username)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"unknown job", http.MethodGet, "/prefetch/nope", "", http.StatusNotFound},
+		{"nothing to fetch", http.MethodPost, "/prefetch", `{"usernames":[" ",""]}`, http.StatusBadRequest},
+		{"too many", http.MethodPost, "/prefetch", `{"usernames":["` + strings.Repeat(`a","`, maxPrefetchBatch) + `a"]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(tt.method, tt.target, tt.body); w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// englishProfanity is always enabled; other languages are opt-in.
+var englishProfanity = []string{
+	"fuck", "fucking", "fucked", "shit", "shitty", "damn", "dammit", "damnit", "wtf",
+}
+
+// loadProfanityLists reads "<lang>.txt" word lists from dir for each
+// language in langs. Lists hold one word per line; "#" starts a comment.
+func loadProfanityLists(dir string, langs []string) ([]string, error) {
+	var words []string
+	for _, lang := range langs {
+		f, err := os.Open(filepath.Join(dir, filepath.Base(lang)+".txt"))
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			word := strings.TrimSpace(scanner.Text())
+			if word == "" || strings.HasPrefix(word, "#") {
+				continue
+			}
+			words = append(words, strings.ToLower(word))
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return words, nil
+}
+
+// profanitySet builds a lookup set from word lists.
+func profanitySet(lists ...[]string) map[string]bool {
+	set := make(map[string]bool)
+	for _, list := range lists {
+		for _, word := range list {
+			set[strings.ToLower(word)] = true
+		}
+	}
+	return set
+}
+
+// messageWords splits a message into lowercase words on any rune that isn't
+// a letter, digit or apostrophe, so matching respects word boundaries in
+// every script.
+func messageWords(msg string) []string {
+	return strings.FieldsFunc(strings.ToLower(msg), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// countProfanity counts whole-word matches of swear words in msg.
+func countProfanity(msg string, swears map[string]bool) int {
+	count := 0
+	for _, word := range messageWords(msg) {
+		if swears[word] {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadProfanityLists(t *testing.T) {
+	dir := t.TempDir()
+	list := "# Dutch, for the tests\nverdomme\n\n  Kut  \n# godverdomme is left out\n"
+	if err := os.WriteFile(filepath.Join(dir, "nl.txt"), []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := loadProfanityLists(dir, []string{"nl"})
+	if err != nil {
+		t.Fatalf("loadProfanityLists() error = %v", err)
+	}
+	if want := []string{"verdomme", "kut"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("loadProfanityLists() = %q, want %q", words, want)
+	}
+
+	if _, err := loadProfanityLists(dir, []string{"xx"}); err == nil {
+		t.Error("missing list did not fail")
+	}
+	// Language names can't climb out of the directory
+	if _, err := loadProfanityLists(dir, []string{"../nl"}); err != nil {
+		t.Errorf("../nl should resolve to nl.txt inside dir: %v", err)
+	}
+}
+
+func TestBundledProfanityLists(t *testing.T) {
+	words, err := loadProfanityLists("profanity", []string{"de", "es", "fr"})
+	if err != nil {
+		t.Fatalf("bundled lists: %v", err)
+	}
+	if len(words) == 0 {
+		t.Error("bundled lists are empty")
+	}
+}
+
+func TestCountProfanity(t *testing.T) {
+	swears := profanitySet(englishProfanity, []string{"verdomme", "kut", "putain"})
+	tests := []struct {
+		msg  string
+		want int
+	}{
+		{"fix the build", 0},
+		{"WTF is this", 1},
+		{"verdomme, weer kapot", 1},
+		{"Verdomme! Kut!", 2},
+		{"putain de merde", 1},
+		{"shitake mushrooms and scunthorpe", 0},
+		{"kutje", 0},
+		{"damn-it: damn", 2},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := countProfanity(tt.msg, swears); got != tt.want {
+				t.Errorf("countProfanity(%q) = %d, want %d", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// gradeScale maps category scores to letter grades, best first.
+var gradeScale = [...]struct {
+	Letter string
+	Min    int // lowest score earning the grade
+	Points float64
+}{
+	{"A", 90, 4},
+	{"B", 80, 3},
+	{"C", 70, 2},
+	{"D", 60, 1},
+	{"F", 0, 0},
+}
+
+// incompleteGrade is given when there are no commits to grade.
+const incompleteGrade = "I"
+
+// reportCategory is one subject on the report card; see reportCategories.
+type reportCategory struct {
+	Name     string
+	Metrics  []string
+	Comments [len(gradeScale)]string
+}
+
+// ReportCard is the body of GET /roast?format=report.
+type ReportCard struct {
+	Username   string        `json:"username"`
+	GPA        float64       `json:"gpa"`
+	Categories []ReportGrade `json:"categories"`
+	Roast      string        `json:"roast"`
+}
+
+// ReportGrade is one category's grade and the metric scores behind it.
+type ReportGrade struct {
+	Category string             `json:"category"`
+	Grade    string             `json:"grade"`
+	Score    int                `json:"score"` // 0-100, higher is better
+	Comment  string             `json:"comment"`
+	Metrics  map[string]float64 `json:"metrics"`
+}
+
+// gradeFor returns the gradeScale index for a 0-100 score.
+func gradeFor(score int) int {
+	for i, grade := range gradeScale {
+		if score >= grade.Min {
+			return i
+		}
+	}
+	return len(gradeScale) - 1
+}
+
+// categoryScore turns metric scores into 0-100: each metric counts fully
+// against the category from twice its roast level.
+func categoryScore(scores map[string]float64, metrics []string) int {
+	penalty := 0.0
+	for _, metric := range metrics {
+		penalty += math.Min(scores[metric], 2) / 2
+	}
+	return int(math.Round(100 * (1 - penalty/float64(len(metrics)))))
+}
+
+// reportCard grades a roast response by category.
+func reportCard(response RoastResponse) ReportCard {
+	card := ReportCard{Username: response.Username, Roast: response.Roast}
+	scores := reportScores(response.Stats)
+	points := 0.0
+	for _, category := range reportCategories {
+		metrics := make(map[string]float64, len(category.Metrics))
+		for _, metric := range category.Metrics {
+			metrics[metric] = math.Round(scores[metric]*100) / 100
+		}
+		if scores == nil {
+			card.Categories = append(card.Categories, ReportGrade{
+				Category: category.Name,
+				Grade:    incompleteGrade,
+				Comment:  "Nothing committed, nothing to grade.",
+				Metrics:  metrics,
+			})
+			continue
+		}
+		score := categoryScore(scores, category.Metrics)
+		grade := gradeFor(score)
+		points += gradeScale[grade].Points
+		card.Categories = append(card.Categories, ReportGrade{
+			Category: category.Name,
+			Grade:    gradeScale[grade].Letter,
+			Score:    score,
+			Comment:  category.Comments[grade],
+			Metrics:  metrics,
+		})
+	}
+	if scores != nil {
+		card.GPA = math.Round(points/float64(len(reportCategories))*100) / 100
+	}
+	return card
+}
+
+// reportTable formats a report card as a markdown table and GPA line.
+func reportTable(card ReportCard) string {
+	var b strings.Builder
+	b.WriteString("| Subject | Grade | Teacher's comment |\n| --- | :---: | --- |\n")
+	for _, grade := range card.Categories {
+		fmt.Fprintf(&b, "| %s | **%s** | %s |\n", grade.Category, grade.Grade, grade.Comment)
+	}
+	fmt.Fprintf(&b, "\n**GPA: %.2f / 4.00**\n", card.GPA)
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGradeFor(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{100, "A"}, {90, "A"},
+		{89, "B"}, {80, "B"},
+		{79, "C"}, {70, "C"},
+		{69, "D"}, {60, "D"},
+		{59, "F"}, {0, "F"}, {-5, "F"},
+	}
+	for _, tt := range tests {
+		if got := gradeScale[gradeFor(tt.score)].Letter; got != tt.want {
+			t.Errorf("gradeFor(%d) = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		scores  map[string]float64
+		metrics []string
+		want    int
+	}{
+		{"spotless", map[string]float64{"a": 0, "b": 0}, []string{"a", "b"}, 100},
+		{"one metric at its roast level", map[string]float64{"a": 1, "b": 0}, []string{"a", "b"}, 75},
+		{"twice the roast level counts fully", map[string]float64{"a": 2, "b": 0}, []string{"a", "b"}, 50},
+		{"worse than that is capped", map[string]float64{"a": 10, "b": 0}, []string{"a", "b"}, 50},
+		{"everything maxed", map[string]float64{"a": 2, "b": 3}, []string{"a", "b"}, 0},
+		{"rounds half away from zero", map[string]float64{"a": 0.5, "b": 0.2}, []string{"a", "b"}, 83},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := categoryScore(tt.scores, tt.metrics); got != tt.want {
+				t.Errorf("categoryScore() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportCard(t *testing.T) {
+	tests := []struct {
+		name   string
+		stats  CommitStats
+		grades map[string]string
+		gpa    float64
+	}{
+		{
+			"no commits",
+			CommitStats{},
+			map[string]string{"Message Quality": "I", "Work-Life Balance": "I", "Git Hygiene": "I", "Consistency": "I"},
+			0,
+		},
+		{
+			"clean",
+			CommitStats{TotalCommits: 10, CommitsPerActiveDay: 1, IssueReferences: IssueReferenceStats{LinkRatio: 1}},
+			map[string]string{"Message Quality": "A", "Work-Life Balance": "A", "Git Hygiene": "A", "Consistency": "A"},
+			4,
+		},
+		{
+			// Message Quality sits exactly on the A boundary at 90
+			"mixed",
+			CommitStats{
+				TotalCommits: 10, GenericMessages: 2, LateNightCommits: 5, WeekendCommits: 5, FixCommits: 5,
+				LongestGapDays: 7, CommitsPerActiveDay: 2, IssueReferences: IssueReferenceStats{LinkRatio: 1},
+			},
+			map[string]string{"Message Quality": "A", "Work-Life Balance": "F", "Git Hygiene": "B", "Consistency": "B"},
+			2.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := reportCard(RoastResponse{Username: "octocat", Stats: tt.stats})
+			if len(card.Categories) != len(reportCategories) {
+				t.Fatalf("%d categories, want %d", len(card.Categories), len(reportCategories))
+			}
+			for _, grade := range card.Categories {
+				if grade.Grade != tt.grades[grade.Category] {
+					t.Errorf("%s: grade %s (score %d), want %s", grade.Category, grade.Grade, grade.Score, tt.grades[grade.Category])
+				}
+				if grade.Comment == "" {
+					t.Errorf("%s: no comment", grade.Category)
+				}
+			}
+			if card.GPA != tt.gpa {
+				t.Errorf("GPA = %v, want %v", card.GPA, tt.gpa)
+			}
+		})
+	}
+}
+
+func TestReportTable(t *testing.T) {
+	stats := CommitStats{TotalCommits: 10, LateNightCommits: 10, WeekendCommits: 10, CommitsPerActiveDay: 1, IssueReferences: IssueReferenceStats{LinkRatio: 1}}
+	table := reportTable(reportCard(RoastResponse{Username: "octocat", Stats: stats}))
+	for _, want := range []string{
+		"| Subject | Grade | Teacher's comment |",
+		"| Work-Life Balance | **F** | Go outside.",
+		"| Message Quality | **A** |",
+		"**GPA: 3.00 / 4.00**",
+	} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table lacks %q:\n%s", want, table)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxRetryWait is the longest retryTransport will wait before its
+// single retry.
+const defaultMaxRetryWait = 10 * time.Second
+
+// retryTransport retries a request once when GitHub answers 403 or 429 and
+// says when to come back, either with Retry-After (secondary rate limits) or
+// with an exhausted X-RateLimit-Remaining and its X-RateLimit-Reset. If the
+// wait would exceed maxWait the response is returned as is. Search requests
+// are never retried: their limit is exhausted far more often, and waiting
+// for it would delay every roast for a detail it can do without.
+type retryTransport struct {
+	next    http.RoundTripper
+	maxWait time.Duration
+	now     func() time.Time
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests) {
+		return resp, err
+	}
+	if isSearchRequest(req) {
+		return resp, err
+	}
+	wait, ok := t.retryAfter(resp)
+	if !ok || wait > t.maxWait {
+		return resp, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, err // can't replay the body
+		}
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return resp, nil
+	case <-timer.C:
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return t.next.RoundTrip(retry)
+}
+
+// isSearchRequest reports whether req goes to the search API, on
+// github.com or under a GitHub Enterprise /api/v3 prefix.
+func isSearchRequest(req *http.Request) bool {
+	return strings.HasPrefix(strings.TrimPrefix(req.URL.Path, "/api/v3"), "/search/")
+}
+
+// retryAfter reads how long GitHub asked us to wait.
+func (t retryTransport) retryAfter(resp *http.Response) (time.Duration, bool) {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(t.now()), 0), true
+		}
+	}
+	return 0, false
+}
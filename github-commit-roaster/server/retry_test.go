@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedTransport answers with one status per request, in order, and
+// records the bodies it was sent.
+type scriptedTransport struct {
+	statuses []int
+	headers  http.Header // sent with every non-200 answer
+	calls    int
+	bodies   []string
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := s.statuses[min(s.calls, len(s.statuses)-1)]
+	s.calls++
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(body))
+	}
+	w := httptest.NewRecorder()
+	if status != http.StatusOK {
+		for k, v := range s.headers {
+			w.Header()[k] = v
+		}
+	}
+	w.WriteHeader(status)
+	return w.Result(), nil
+}
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	resetIn := func(d time.Duration) http.Header {
+		return http.Header{
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(now.Add(d).Unix(), 10)},
+		}
+	}
+	tests := []struct {
+		name     string
+		path     string
+		statuses []int
+		headers  http.Header
+		want     int
+		calls    int
+	}{
+		{"429 then 200", "/users/octocat", []int{429, 200}, http.Header{"Retry-After": {"0"}}, 200, 2},
+		{"403 with an exhausted limit", "/users/octocat", []int{403, 200}, resetIn(-time.Second), 200, 2},
+		{"retries only once", "/users/octocat", []int{429, 429, 200}, http.Header{"Retry-After": {"0"}}, 429, 2},
+		{"403 without a wait is a real 403", "/users/octocat", []int{403, 200}, nil, 403, 1},
+		{"wait over the cap", "/users/octocat", []int{429, 200}, http.Header{"Retry-After": {"60"}}, 429, 1},
+		{"reset over the cap", "/users/octocat", []int{403, 200}, resetIn(time.Hour), 403, 1},
+		{"search isn't retried", "/search/commits", []int{429, 200}, http.Header{"Retry-After": {"0"}}, 429, 1},
+		{"enterprise search isn't retried", "/api/v3/search/commits", []int{429, 200}, http.Header{"Retry-After": {"0"}}, 429, 1},
+		{"success", "/users/octocat", []int{200}, nil, 200, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &scriptedTransport{statuses: tt.statuses, headers: tt.headers}
+			client := &http.Client{Transport: retryTransport{next: next, maxWait: time.Second, now: func() time.Time { return now }}}
+			resp, err := client.Get("https://api.github.com" + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want || next.calls != tt.calls {
+				t.Errorf("got %d after %d calls, want %d after %d", resp.StatusCode, next.calls, tt.want, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetryTransportReplaysBody(t *testing.T) {
+	next := &scriptedTransport{statuses: []int{429, 200}, headers: http.Header{"Retry-After": {"0"}}}
+	client := &http.Client{Transport: retryTransport{next: next, maxWait: time.Second, now: time.Now}}
+	resp, err := client.Post("https://api.github.com/graphql", "application/json", strings.NewReader(`{"query":"{}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(next.bodies) != 2 || next.bodies[1] != next.bodies[0] {
+		t.Errorf("status %d, bodies sent %q", resp.StatusCode, next.bodies)
+	}
+}
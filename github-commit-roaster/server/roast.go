@@ -0,0 +1,303 @@
+package main
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CommitStats is everything the analysis learned about a user's activity.
+// It is returned as the "stats" object of a roast response.
+type CommitStats struct {
+	TotalCommits           int        `json:"total_commits"`
+	Since                  time.Time  `json:"since"`
+	Until                  time.Time  `json:"until"`
+	CommitsFetched         int        `json:"commits_fetched"`
+	CommitsSampled         bool       `json:"commits_sampled"` // TotalCommits is a sample of CommitsFetched
+	ReposAnalyzed          int        `json:"repos_analyzed"`
+	LateNightCommits       int        `json:"late_night_commits"`
+	LateNightWindow        HourWindow `json:"late_night_window"`
+	WorkHoursShare         float64    `json:"work_hours_share"`
+	WorkHoursWindow        HourWindow `json:"work_hours_window"`
+	SwearWords             int        `json:"swear_words"`
+	MergeCommits           int        `json:"merge_commits"` // commits with two or more parents
+	Merges                 MergeStats `json:"merges"`
+	FixCommits             int        `json:"fix_commits"`
+	FixupCommits           int        `json:"fixup_commits"`
+	GenericMessages        int        `json:"generic_messages"`
+	EmptyMessages          int        `json:"empty_messages"`
+	InitialCommitOnlyRepos int        `json:"initial_commit_only_repos"`
+	AvgMessageLength       float64    `json:"avg_message_length"`
+	LongestGapDays         int        `json:"longest_gap_days"`
+
+	CommitsPerActiveDay float64 `json:"commits_per_active_day"`
+	WeekdayCommits      int     `json:"weekday_commits"`
+	WeekendCommits      int     `json:"weekend_commits"`
+
+	Severity int `json:"severity"`
+	// CacheHit is set on roasts served from the cache rather than analyzed
+	// for this request
+	CacheHit bool `json:"cache_hit"`
+	// Percentiles rank the user's ratios among today's roasts, once there
+	// are enough of them
+	Percentiles    map[string]float64 `json:"percentiles,omitempty"`
+	TriggeredRules []string           `json:"triggered_rules"` // most damning first, including any cut from the roast
+	Languages      map[string]float64 `json:"languages,omitempty"`
+	Scripts        []string           `json:"scripts"`
+	PrimaryScript  string             `json:"primary_script"`
+	ScriptCount    int                `json:"script_count"`
+
+	// Only set with languages, from a few repos, or in deep mode
+	LanguageProfile *LanguageProfile `json:"language_profile,omitempty"`
+
+	CrossRepoDuplicates CrossRepoDupStats   `json:"cross_repo_duplicates"`
+	Automation          AutomationStats     `json:"automation"`
+	Authorship          AuthorshipStats     `json:"authorship"`
+	AuthorNames         AuthorNameStats     `json:"author_names"`
+	WebEdits            WebEditStats        `json:"web_edits"`
+	CommitBody          CommitBodyStats     `json:"commit_body"`
+	IssueReferences     IssueReferenceStats `json:"issue_references"`
+	Verification        VerificationStats   `json:"verification"`
+	DebtMarkers         DebtMarkerStats     `json:"debt_markers"`
+	Shouting            ShoutingStats       `json:"shouting"`
+	EmailDomains        EmailStats          `json:"email_domains"`
+
+	MessageLanguages MessageLanguageStats `json:"message_languages"`
+	// Disclaimer says which rules were skipped because the messages are in
+	// a language they can't read
+	Disclaimer string `json:"disclaimer,omitempty"`
+
+	// Only set in deep mode, which lists each repo's contributors, reads a
+	// sample of commits for their size, lists a few repo trees and reads
+	// their .gitignore files
+	Collaboration *CollaborationStats `json:"collaboration,omitempty"`
+	CommitSize    *CommitSizeStats    `json:"commit_size,omitempty"`
+	TestCoverage  *TestCoverageStats  `json:"test_coverage,omitempty"`
+	Gitignore     *GitignoreStats     `json:"gitignore,omitempty"`
+
+	// Only set when a deep scan fetched commit diffs
+	CodeCommentProfanity *CodeCommentProfanity `json:"code_comment_profanity,omitempty"`
+	DocOnly              *DocOnlyStats         `json:"doc_only,omitempty"`
+
+	// Only set with follow_through, which lists each repo's issues and PRs
+	FollowThrough *FollowThroughStats `json:"follow_through,omitempty"`
+
+	// Only set with words, the message word frequency
+	Words *WordStats `json:"words,omitempty"`
+
+	// Only set with spellcheck
+	Spelling *SpellingStats `json:"spelling,omitempty"`
+
+	// Request metadata, so clients can warn about degraded mode up front
+	AuthMode           string `json:"auth_mode"`
+	RateLimitRemaining int    `json:"rate_limit_remaining"`
+
+	PrivateCommitsIncluded bool `json:"private_commits_included"`
+	CoalescedRequest       bool `json:"coalesced_request"` // shared a concurrent request's GitHub calls
+
+	Timeline []TimelineBucket `json:"timeline,omitempty"`
+
+	SkippedRepos []SkippedRepo    `json:"skipped_repos,omitempty"`
+	Abandonment  AbandonmentStats `json:"abandonment"`
+
+	AbandonedProjects AbandonedProjectStats `json:"abandoned_projects"`
+	Popularity        PopularityStats       `json:"popularity"`
+
+	FirstCommitDate  *time.Time `json:"first_commit_date,omitempty"`
+	DeveloperVintage string     `json:"developer_vintage,omitempty"`
+
+	VelocityContext CommitVelocityContext `json:"velocity_context"`
+}
+
+// RoastOptions are the per-request switches that change what gets roasted.
+type RoastOptions struct {
+	Languages     bool   // add language-specific roast lines
+	Deep          bool   // spend extra API calls for more accurate data and commit sizes
+	DeepScan      bool   // read commit diffs, up to maxCommitDetails extra calls
+	FollowThrough bool   // count issues and PRs closed, two extra calls per repo
+	Intensity     int    // phrasing harshness, 1 (gentle) to 5 (brutal)
+	Persona       string // voice of the roast; "" or defaultPersona for the default
+	Lang          string // language of the verdict; unknown languages get English
+	Weights       Weights
+	MaxLines      int            // keep only the heaviest lines; 0 keeps all
+	MaxChars      int            // cut the roast at a line boundary; 0 for no limit
+	Timeline      bool           // add the per-day commit timeline to stats
+	Words         bool           // add the message word frequency to stats
+	SpellCheck    bool           // check commit subjects for known misspellings
+	Location      *time.Location // time zone for the timeline's days
+
+	// IncludePrivate lists private repos too. It only takes effect when a
+	// logged-in user roasts themselves, whose OAuth token has the repo
+	// scope; everyone else gets public repos only.
+	IncludePrivate bool
+
+	// The analysis window: the last Days days, or Since to Until (now if zero)
+	Days         int
+	Since, Until time.Time
+}
+
+func analyzeCommits(commits []NormalizedCommit, cfg RoastConfig) CommitStats {
+	stats := CommitStats{TotalCommits: len(commits), CommitsFetched: len(commits), LateNightWindow: cfg.LateNight, WorkHoursWindow: cfg.WorkHours}
+	workHourCommits := 0
+	dupes := make(crossRepoIndex)
+	swears := profanitySet(cfg.SwearWords)
+	languages := make(map[string]int)
+
+	for _, commit := range commits {
+		dupes.add(commit)
+
+		msg := strings.ToLower(commit.Message)
+		commitTime := commit.Date
+
+		// Blank messages (--allow-empty-message, some merge tooling) say nothing at all
+		if strings.TrimSpace(msg) == "" {
+			stats.EmptyMessages++
+		}
+
+		// Check for late night commits, by default 22:00 up to (not including) 05:00
+		if cfg.LateNight.contains(commitTime.Hour()) {
+			stats.LateNightCommits++
+		}
+		if cfg.WorkHours.contains(commitTime.Hour()) {
+			workHourCommits++
+		}
+
+		// Check message content in its own language; rebase leftovers
+		// aren't counted as regular fixes
+		lang := detectMessageLanguage(msg)
+		languages[lang]++
+		keywords, _ := keywordsFor(lang)
+		if strings.HasPrefix(msg, "fixup!") || strings.HasPrefix(msg, "squash!") {
+			stats.FixupCommits++
+		} else if containsAny(msg, keywords.Fix...) {
+			stats.FixCommits++
+		}
+		stats.Merges.add(classifyMerge(commit))
+		stats.SwearWords += countProfanity(msg, swears)
+		if hasPrefixAny(msg, keywords.Generic...) {
+			stats.GenericMessages++
+		}
+	}
+
+	stats.MergeCommits = stats.Merges.trueMerges()
+	stats.WorkHoursShare = share(workHourCommits, len(commits))
+	stats.AvgMessageLength, stats.LongestGapDays = messageLengthAndGap(commits)
+	stats.CommitsPerActiveDay, stats.WeekdayCommits, stats.WeekendCommits = dayActivity(commits)
+	stats.CrossRepoDuplicates = dupes.stats()
+	stats.Automation = detectAutomatedCommits(commits, cfg.BotPatterns)
+	stats.Authorship = detectAuthorshipDiscrepancies(commits)
+	stats.WebEdits = detectWebEdits(commits)
+	stats.CommitBody = detectCommitBodies(commits)
+	stats.IssueReferences = detectIssueReferences(commits)
+	stats.InitialCommitOnlyRepos = countInitialCommitOnlyRepos(commits)
+	stats.Verification = detectVerification(commits)
+	stats.DebtMarkers = detectDebtMarkers(commits)
+	stats.Shouting = detectShouting(commits, cfg.ShoutRatio)
+	stats.EmailDomains = detectEmailDomains(commits)
+	stats.MessageLanguages = messageLanguageStats(languages, len(commits))
+	if !stats.MessageLanguages.KeywordCoverage {
+		stats.Disclaimer = languageDisclaimer(stats.MessageLanguages.Dominant)
+	}
+
+	stats.Scripts = detectCommitLanguages(commits)
+	stats.ScriptCount = len(scriptCounts(commits))
+	if len(stats.Scripts) > 0 {
+		stats.PrimaryScript = stats.Scripts[0]
+	}
+
+	return stats
+}
+
+// moreLinesMarker ends a roast cut short by MaxLines, and
+// truncationMarker one cut short by MaxChars.
+const (
+	moreLinesMarker  = "(and more)"
+	truncationMarker = "…and it gets worse"
+)
+
+// roastTruncation says whether MaxChars cut a roast short, and how long
+// the roast would have been, in characters.
+type roastTruncation struct {
+	Truncated  bool
+	FullLength int
+}
+
+func generateRoast(stats CommitStats, opts RoastOptions) string {
+	roast, _ := roastText(stats, opts)
+	return roast
+}
+
+// roastText renders the roast like generateRoast and cuts it to
+// opts.MaxChars.
+func roastText(stats CommitStats, opts RoastOptions) (string, roastTruncation) {
+	return truncateRoast(roastLines(stats, opts), opts.MaxChars)
+}
+
+// roastLines are the lines of the roast, heaviest first.
+func roastLines(stats CommitStats, opts RoastOptions) []string {
+	if stats.TotalCommits == 0 {
+		return []string{"Wow, you haven't committed anything recently. Are you even a developer?"}
+	}
+
+	// Generate roast lines
+	intensity := vintageIntensity(opts.Intensity, stats.DeveloperVintage)
+	lines := mergeRelated(ruleLines(stats, opts.Weights, intensity, opts.Persona))
+
+	if opts.Languages {
+		for _, line := range languageRoastLines(stats.Languages) {
+			lines = append(lines, weightedLine{text: line, weight: languageLineWeight})
+		}
+	}
+
+	if len(lines) == 0 {
+		return []string{"Your commits are suspiciously clean. Are you even trying?"}
+	}
+
+	texts := topLines(lines, opts.MaxLines)
+	if len(texts) < len(lines) {
+		texts = append(texts, moreLinesMarker)
+	}
+	return texts
+}
+
+// truncateRoast joins lines into a roast of at most maxChars characters
+// (0 for no limit). Lines are kept whole, heaviest first, and the roast
+// ends with truncationMarker when any were dropped; a limit shorter than
+// the heaviest line leaves only the marker.
+func truncateRoast(lines []string, maxChars int) (string, roastTruncation) {
+	const separator = "\n\n"
+	full := strings.Join(lines, separator)
+	truncation := roastTruncation{FullLength: utf8.RuneCountInString(full)}
+	if maxChars <= 0 || truncation.FullLength <= maxChars {
+		return full, truncation
+	}
+
+	truncation.Truncated = true
+	budget := maxChars - utf8.RuneCountInString(separator+truncationMarker)
+	var kept []string
+	used := 0
+	for _, line := range lines {
+		if line == moreLinesMarker {
+			break
+		}
+		cost := utf8.RuneCountInString(line)
+		if len(kept) > 0 {
+			cost += utf8.RuneCountInString(separator)
+		}
+		if used+cost > budget {
+			break
+		}
+		kept = append(kept, line)
+		used += cost
+	}
+	return strings.Join(append(kept, truncationMarker), separator), truncation
+}
+
+func containsAny(s string, substrings ...string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+func TestAnalyzeCommitsEmptyMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     int
+	}{
+		{"all written", []string{"add login", "fix typo"}, 0},
+		{"empty", []string{"", "add login"}, 1},
+		{"whitespace only", []string{" ", "\n\t\n", "\r\n", "add login"}, 3},
+		{"leading blank line", []string{"\nadd login"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := analyzeCommits(messages(tt.messages...), loadRoastConfig())
+			if stats.EmptyMessages != tt.want {
+				t.Errorf("EmptyMessages = %d, want %d", stats.EmptyMessages, tt.want)
+			}
+			roast := generateRoast(stats, RoastOptions{Intensity: defaultIntensity})
+			if got := strings.Contains(roast, "Speechless, I see"); got != (tt.want > 0) {
+				t.Errorf("roast calls out empty messages = %v: %q", got, roast)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCommitsFixups(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		fixups   int
+		fixes    int
+	}{
+		{"none", []string{"add login", "fix typo"}, 0, 1},
+		{"fixup and squash", []string{"fixup! add login", "squash! fix typo", "Fixup! add tests"}, 3, 0},
+		{"not at the start", []string{"revert fixup! add login", "fix the fixup! parser"}, 0, 2},
+		{"no bang", []string{"fixup add login", "squash commits"}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := analyzeCommits(messages(tt.messages...), loadRoastConfig())
+			if stats.FixupCommits != tt.fixups || stats.FixCommits != tt.fixes {
+				t.Errorf("%d fixups and %d fixes, want %d and %d", stats.FixupCommits, stats.FixCommits, tt.fixups, tt.fixes)
+			}
+			triggered := findRule(t, "fixups").Triggered(stats)
+			if triggered != (tt.fixups > 0) {
+				t.Errorf("fixups rule triggered = %v with %d fixups", triggered, tt.fixups)
+			}
+		})
+	}
+}
+
+func TestTopLines(t *testing.T) {
+	lines := func() []weightedLine {
+		return []weightedLine{
+			{rule: "a", text: "light", weight: 0.5},
+			{rule: "b", text: "heavy", weight: 3},
+			{rule: "c", text: "tied first", weight: 1},
+			{rule: "d", text: "tied second", weight: 1},
+		}
+	}
+	tests := []struct {
+		limit int
+		want  []string
+	}{
+		{0, []string{"heavy", "tied first", "tied second", "light"}},
+		{1, []string{"heavy"}},
+		{3, []string{"heavy", "tied first", "tied second"}},
+		{9, []string{"heavy", "tied first", "tied second", "light"}},
+	}
+	for _, tt := range tests {
+		if got := topLines(lines(), tt.limit); !slices.Equal(got, tt.want) {
+			t.Errorf("topLines(%d) = %q, want %q", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestRoastLinesMaxLines(t *testing.T) {
+	// Swearing is five times its threshold, fixes 1.6 and late nights 1.2
+	stats := CommitStats{TotalCommits: 10, SwearWords: 5, FixCommits: 8, LateNightCommits: 6, Verification: VerificationStats{VerifiedCount: 5, UnverifiedCount: 5}}
+	swearing := findRule(t, "swearing").line(stats, defaultIntensity)
+	fixes := findRule(t, "fixes").line(stats, defaultIntensity)
+
+	all := roastLines(stats, RoastOptions{Intensity: defaultIntensity})
+	if len(all) < 3 || slices.Contains(all, moreLinesMarker) {
+		t.Fatalf("unlimited roast = %q", all)
+	}
+	got := roastLines(stats, RoastOptions{Intensity: defaultIntensity, MaxLines: 2})
+	if want := []string{swearing, fixes, moreLinesMarker}; !slices.Equal(got, want) {
+		t.Errorf("roastLines() with MaxLines 2 = %q, want %q", got, want)
+	}
+	if got := roastLines(stats, RoastOptions{Intensity: defaultIntensity, MaxLines: len(all)}); slices.Contains(got, moreLinesMarker) {
+		t.Errorf("a limit with room for every line still says there's more: %q", got)
+	}
+}
+
+// fuzzCommit builds a commit from fuzz input, leaving out each nested
+// field whose bit in missing is set.
+func fuzzCommit(missing uint8, message, name, email string, unix int64) *github.RepositoryCommit {
+	present := func(bit uint8) bool { return missing&(1<<bit) == 0 }
+	commit := &github.RepositoryCommit{SHA: github.String(fmt.Sprintf("%x", unix))}
+	if present(0) {
+		commit.Commit = &github.Commit{}
+		if present(1) {
+			commit.Commit.Message = &message
+		}
+		author := &github.CommitAuthor{Name: &name, Email: &email}
+		if present(2) {
+			author.Date = &github.Timestamp{Time: time.Unix(unix, 0)}
+		}
+		if present(3) {
+			commit.Commit.Author = author
+		}
+		if present(4) {
+			commit.Commit.Committer = author
+		}
+	}
+	if present(5) {
+		commit.Author = &github.User{Login: &name}
+	}
+	if present(6) {
+		commit.Committer = &github.User{}
+	}
+	if present(7) {
+		commit.Parents = []*github.Commit{nil, {}}
+	}
+	return commit
+}
+
+func FuzzGenerateRoast(f *testing.F) {
+	f.Add(uint8(0), uint8(0xff), "fix: typo", "Octo", "octo@example.com", int64(1718000000), uint8(3))
+	f.Add(uint8(0xff), uint8(0), "WIP!!! damn", "dependabot[bot]", "", int64(0), uint8(5))
+	f.Add(uint8(0x0f), uint8(0xf0), "", "", "noreply", int64(-62135596800), uint8(1))
+	f.Add(uint8(0x55), uint8(0xaa), "Merge pull request #1 from octo/fix\n\nTODO", "Octo", "x@y", int64(253402300799), uint8(0))
+	cfg := loadRoastConfig()
+	f.Fuzz(func(t *testing.T, first, second uint8, message, name, email string, unix int64, intensity uint8) {
+		commits := []NormalizedCommit{
+			normalizeCommit("project", fuzzCommit(first, message, name, email, unix)),
+			normalizeCommit("project", fuzzCommit(second, message, name, email, unix)),
+			normalizeCommit("other", fuzzCommit(first^second, message, name, email, unix/2)),
+		}
+		stats := analyzeCommits(commits, cfg)
+		opts := RoastOptions{Intensity: int(intensity)%maxIntensity + 1}
+		if roast := generateRoast(stats, opts); roast == "" {
+			t.Fatal("empty roast")
+		}
+	})
+}
+
+func TestTruncateRoast(t *testing.T) {
+	// Three 30-character lines, 94 characters joined; the separator and
+	// marker take 20 of any limit that cuts the roast short
+	a, b, c := strings.Repeat("a", 30), strings.Repeat("b", 30), strings.Repeat("c", 30)
+	lines := []string{a, b, c}
+	tests := []struct {
+		name     string
+		lines    []string
+		maxChars int
+		want     string
+		cut      bool
+	}{
+		{"no limit", lines, 0, a + "\n\n" + b + "\n\n" + c, false},
+		{"exactly the full length", lines, 94, a + "\n\n" + b + "\n\n" + c, false},
+		{"one under", lines, 93, a + "\n\n" + b + "\n\n" + truncationMarker, true},
+		{"room for two lines exactly", lines, 82, a + "\n\n" + b + "\n\n" + truncationMarker, true},
+		{"one short of two lines", lines, 81, a + "\n\n" + truncationMarker, true},
+		{"room for one line exactly", lines, 50, a + "\n\n" + truncationMarker, true},
+		{"smaller than any line", lines, 49, truncationMarker, true},
+		{"smaller than the marker", lines, 5, truncationMarker, true},
+		{"characters, not bytes", []string{strings.Repeat("ü", 30), b}, 50, strings.Repeat("ü", 30) + "\n\n" + truncationMarker, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncation := truncateRoast(tt.lines, tt.maxChars)
+			if got != tt.want {
+				t.Errorf("roast = %q, want %q", got, tt.want)
+			}
+			if truncation.Truncated != tt.cut {
+				t.Errorf("Truncated = %v, want %v", truncation.Truncated, tt.cut)
+			}
+			if full := len([]rune(strings.Join(tt.lines, "\n\n"))); truncation.FullLength != full {
+				t.Errorf("FullLength = %d, want %d", truncation.FullLength, full)
+			}
+			if tt.cut && len(tt.want) > len(truncationMarker) && len([]rune(got)) > tt.maxChars {
+				t.Errorf("%d characters over a limit of %d", len([]rune(got)), tt.maxChars)
+			}
+		})
+	}
+}
+
+func TestRoastTextMaxCharsKeepsHeaviest(t *testing.T) {
+	// Swearing is five times its threshold, so it survives any cut that
+	// leaves a line at all
+	stats := CommitStats{TotalCommits: 10, SwearWords: 5, FixCommits: 8, LateNightCommits: 6, Verification: VerificationStats{VerifiedCount: 5, UnverifiedCount: 5}}
+	swearing := findRule(t, "swearing").line(stats, defaultIntensity)
+	full, _ := roastText(stats, RoastOptions{Intensity: defaultIntensity})
+
+	limit := len([]rune(swearing)) + len([]rune("\n\n"+truncationMarker))
+	got, truncation := roastText(stats, RoastOptions{Intensity: defaultIntensity, MaxChars: limit})
+	if want := swearing + "\n\n" + truncationMarker; got != want {
+		t.Errorf("roast = %q, want %q", got, want)
+	}
+	if !truncation.Truncated || truncation.FullLength != len([]rune(full)) {
+		t.Errorf("truncation = %+v, want a cut %d-character roast", truncation, len([]rune(full)))
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoastRequest is the body of POST /roast. Each field is the GET /roast
+// query parameter of the same name; weights is an object rather than the
+// "metric:weight,..." string.
+type RoastRequest struct {
+	Username       string         `json:"username"`
+	Days           *int           `json:"days"`
+	Since          string         `json:"since"`
+	Until          string         `json:"until"`
+	Intensity      *int           `json:"intensity"`
+	Persona        string         `json:"persona"`
+	Lang           string         `json:"lang"`
+	TZ             string         `json:"tz"`
+	Weights        map[string]int `json:"weights"`
+	MaxLines       *int           `json:"max_lines"`
+	MaxChars       *int           `json:"max_chars"`
+	Languages      bool           `json:"languages"`
+	Deep           bool           `json:"deep"`
+	DeepScan       bool           `json:"deep_scan"`
+	FollowThrough  bool           `json:"follow_through"`
+	IncludePrivate bool           `json:"include_private"`
+	Timeline       bool           `json:"timeline"`
+	Words          bool           `json:"words"`
+	SpellCheck     bool           `json:"spellcheck"`
+	PerRepo        bool           `json:"per_repo"`
+	Format         string         `json:"format"`
+	NoColor        bool           `json:"no_color"`
+}
+
+// query is the GET /roast query string for the same roast. Unset fields are
+// left out, so defaults and cache keys match the GET form exactly.
+func (r RoastRequest) query() url.Values {
+	query := url.Values{}
+	set := func(name, value string) {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	setInt := func(name string, n *int) {
+		if n != nil {
+			query.Set(name, strconv.Itoa(*n))
+		}
+	}
+	setBool := func(name string, b bool) {
+		if b {
+			query.Set(name, "true")
+		}
+	}
+	set("username", r.Username)
+	setInt("days", r.Days)
+	set("since", r.Since)
+	set("until", r.Until)
+	setInt("intensity", r.Intensity)
+	set("persona", r.Persona)
+	set("lang", r.Lang)
+	set("tz", r.TZ)
+	set("weights", formatWeightsParam(r.Weights))
+	setInt("max_lines", r.MaxLines)
+	setInt("max_chars", r.MaxChars)
+	setBool("languages", r.Languages)
+	setBool("deep", r.Deep)
+	setBool("deep_scan", r.DeepScan)
+	setBool("follow_through", r.FollowThrough)
+	setBool("include_private", r.IncludePrivate)
+	setBool("timeline", r.Timeline)
+	setBool("words", r.Words)
+	setBool("spellcheck", r.SpellCheck)
+	setBool("per_repo", r.PerRepo)
+	set("format", r.Format)
+	setBool("no_color", r.NoColor)
+	return query
+}
+
+// formatWeightsParam writes weights in the ?weights= syntax, sorted so the
+// same weights always make the same cache key.
+func formatWeightsParam(weights map[string]int) string {
+	pairs := make([]string, 0, len(weights))
+	for metric, weight := range weights {
+		pairs = append(pairs, fmt.Sprintf("%s:%d", metric, weight))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// handleRoastPost serves POST /roast. The body is turned into the
+// equivalent query string and handed to handleRoast, so both forms share
+// one validation path and produce the same response. The body size limit
+// applies as it does to every POST.
+func (s *server) handleRoastPost(c *gin.Context) {
+	var req RoastRequest
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": roastBodyError(err)})
+		return
+	}
+	if decoder.More() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be a single JSON object"})
+		return
+	}
+	c.Request.URL.RawQuery = req.query().Encode()
+	s.handleRoast(c)
+}
+
+// roastBodyError explains why a POST /roast body didn't decode, naming the
+// offending field where there is one.
+func roastBodyError(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Sprintf("%s must be %s", typeErr.Field, jsonKind(typeErr.Type.String()))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields
+		return "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	case errors.Is(err, io.EOF):
+		return "body is required"
+	default:
+		return "body must be a JSON object"
+	}
+}
+
+// jsonKind describes a Go type of RoastRequest the way a JSON client would.
+func jsonKind(goType string) string {
+	switch {
+	case goType == "string":
+		return "a string"
+	case goType == "bool":
+		return "true or false"
+	case strings.HasPrefix(goType, "map["):
+		return "an object of numbers"
+	case strings.HasSuffix(goType, "int"):
+		return "a whole number"
+	default:
+		return "a " + goType
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoastRequestQuery(t *testing.T) {
+	days, intensity := 7, 3
+	tests := []struct {
+		name string
+		req  RoastRequest
+		want string
+	}{
+		{"username only", RoastRequest{Username: "octocat"}, "username=octocat"},
+		{
+			"every kind of field",
+			RoastRequest{Username: "octocat", Days: &days, Intensity: &intensity, TZ: "Europe/Paris", Deep: true},
+			"days=7&deep=true&intensity=3&tz=Europe%2FParis&username=octocat",
+		},
+		{
+			"weights are sorted",
+			RoastRequest{Username: "octocat", Weights: map[string]int{"swearing": 0, "fixes": 3, "latenight": 2}},
+			"username=octocat&weights=fixes%3A3%2Clatenight%3A2%2Cswearing%3A0",
+		},
+		{"false and empty fields are left out", RoastRequest{Username: "octocat", Persona: "", Languages: false}, "username=octocat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.query().Encode(); got != tt.want {
+				t.Errorf("query() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// The same roast asked for either way shares a cache entry
+	get, _ := url.ParseQuery("username=octocat&days=7&intensity=3")
+	post := RoastRequest{Username: "octocat", Days: &days, Intensity: &intensity}.query()
+	if roastCacheKey("octocat", get) != roastCacheKey("octocat", post) {
+		t.Errorf("cache keys differ: %q vs %q", roastCacheKey("octocat", get), roastCacheKey("octocat", post))
+	}
+}
+
+func TestRoastPostErrors(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2)))
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"unknown field", `{"username":"octocat","token":"ghp_x"}`, `unknown field "token"`},
+		{"wrong type", `{"username":"octocat","days":"seven"}`, "days must be a whole number"},
+		{"wrong weights type", `{"username":"octocat","weights":"fixes:3"}`, "weights must be an object of numbers"},
+		{"two objects", `{"username":"octocat"}{"username":"hubot"}`, "body must be a single JSON object"},
+		{"not an object", `["octocat"]`, "body must be a JSON object"},
+		// Values are checked by the same code as the GET form
+		{"bad value", `{"username":"octocat","intensity":9}`, ""},
+		{"unknown weight", `{"username":"octocat","weights":{"typos":2}}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(s.handleRoastPost, http.MethodPost, "/roast", "/roast", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body.Error == "" || tt.want != "" && body.Error != tt.want {
+				t.Errorf("error = %q, want %q", body.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoastPostMatchesGet(t *testing.T) {
+	gh := newFakeGitHub("octocat",
+		fakeCommit("Octo", "fix", 2), fakeCommit("Octo", "fix again", 3), fakeCommit("Octo", "wip", 26))
+	get := doRequest(newTestServer(t, gh).handleRoast, http.MethodGet, "/roast",
+		"/roast?username=octocat&intensity=5&weights=fixes:3,swearing:0", "")
+	post := doRequest(newTestServer(t, gh).handleRoastPost, http.MethodPost, "/roast", "/roast",
+		`{"username":"octocat","intensity":5,"weights":{"swearing":0,"fixes":3}}`)
+	if get.Code != http.StatusOK || post.Code != http.StatusOK {
+		t.Fatalf("GET %d, POST %d: %s", get.Code, post.Code, post.Body)
+	}
+	// Only the window, taken from the clock, may differ between the two
+	decode := func(body []byte) RoastResponse {
+		var resp RoastResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatal(err)
+		}
+		resp.Stats.Since, resp.Stats.Until = time.Time{}, time.Time{}
+		return resp
+	}
+	if got, want := decode(post.Body.Bytes()), decode(get.Body.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("POST response differs from GET:\n%s\n%s", post.Body, get.Body)
+	}
+}
@@ -0,0 +1,1030 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	minIntensity     = 1
+	maxIntensity     = 5
+	defaultIntensity = 3
+)
+
+// roastRule is one thing the roaster can call out. Templates hold a phrasing
+// per intensity level, from gentle ribbing (1) to brutal (5); they are
+// fmt format strings filled with Args.
+type roastRule struct {
+	ID          string
+	Description string // what the rule calls out, for the GET /rules catalog
+	Threshold   string // when it fires, in words
+	Metric      string // weight that silences the rule at 0, if any
+	Triggered   func(stats CommitStats) bool
+	Templates   [maxIntensity]string
+	Args        func(stats CommitStats) []interface{}
+}
+
+// line renders the rule at the given intensity; out-of-range values fall
+// back to the default.
+func (r roastRule) line(stats CommitStats, intensity int) string {
+	if intensity < minIntensity || intensity > maxIntensity {
+		intensity = defaultIntensity
+	}
+	tmpl := r.Templates[intensity-minIntensity]
+	if r.Args == nil {
+		return tmpl
+	}
+	return fmt.Sprintf(tmpl, r.Args(stats)...)
+}
+
+// languageLineWeight ranks language roasts below any triggered rule.
+const languageLineWeight = 0.5
+
+// weightedLine is a rendered roast line and how much it should count when
+// the roast has to be cut short.
+type weightedLine struct {
+	rule   string // ID of the rule behind the line; empty for language lines
+	text   string
+	weight float64
+}
+
+// relatedRules make near-the-same point; when several of a group fire they
+// are rendered as one line.
+var relatedRules = [][]string{
+	{"generic_messages", "empty_messages", "no_commit_body"},
+	{"fixes", "fixups"},
+	{"serial_starter", "initial_commit_graveyard", "abandoned_projects"},
+	{"veteran_low_activity", "slow_pace"},
+}
+
+// ruleWeight ranks a triggered rule: rules backed by a pattern score weigh
+// as much as that score (1.0 at the roast threshold), the rest count as
+// sitting right at their threshold. Either way the caller's metric weight
+// scales it.
+func ruleWeight(rule roastRule, scores map[string]float64, weights Weights) float64 {
+	weight := 1.0
+	if score, ok := scores[rule.ID]; ok {
+		weight = score
+	}
+	if rule.Metric != "" {
+		weight *= float64(weights.weight(rule.Metric))
+	}
+	return weight
+}
+
+// ruleLines renders every rule that fires for stats in persona's voice,
+// skipping those whose metric the caller weighted to zero.
+func ruleLines(stats CommitStats, weights Weights, intensity int, persona string) []weightedLine {
+	scores := patternScores(stats)
+	var lines []weightedLine
+	for _, rule := range roastRules {
+		if rule.Metric != "" && weights.weight(rule.Metric) == 0 {
+			continue // the caller doesn't care about this metric
+		}
+		if keywordRules[rule.ID] && stats.MessageLanguages.skipsKeywordRules() {
+			continue // the messages are in a language the keywords don't cover
+		}
+		if rule.Triggered(stats) {
+			text, ok := personaLine(rule, stats, persona)
+			if !ok {
+				text = rule.line(stats, intensity)
+			}
+			lines = append(lines, weightedLine{
+				rule:   rule.ID,
+				text:   text,
+				weight: ruleWeight(rule, scores, weights),
+			})
+		}
+	}
+	return lines
+}
+
+// rankedRules lists the IDs of the rules that fire for stats, most
+// damning first, whether or not the roast has room for them.
+func rankedRules(stats CommitStats, weights Weights) []string {
+	if stats.TotalCommits == 0 {
+		return nil
+	}
+	lines := ruleLines(stats, weights, defaultIntensity, defaultPersona)
+	sortByWeight(lines)
+	ids := make([]string, len(lines))
+	for i, line := range lines {
+		ids[i] = line.rule
+	}
+	return ids
+}
+
+// mergeRelated folds lines from the same relatedRules group into one,
+// weighing as much as its heaviest part.
+func mergeRelated(lines []weightedLine) []weightedLine {
+	group := make(map[string]int)
+	for i, ids := range relatedRules {
+		for _, id := range ids {
+			group[id] = i
+		}
+	}
+	merged := make(map[int]int) // group -> index of its line in out
+	var out []weightedLine
+	for _, line := range lines {
+		g, ok := group[line.rule]
+		if !ok {
+			out = append(out, line)
+			continue
+		}
+		if j, seen := merged[g]; seen {
+			out[j].text += " " + line.text
+			out[j].weight = math.Max(out[j].weight, line.weight)
+			continue
+		}
+		merged[g] = len(out)
+		out = append(out, line)
+	}
+	return out
+}
+
+// sortByWeight orders lines heaviest first, keeping rule order for ties.
+func sortByWeight(lines []weightedLine) {
+	sort.SliceStable(lines, func(a, b int) bool {
+		return lines[a].weight > lines[b].weight
+	})
+}
+
+// topLines returns the text of the limit heaviest lines, heaviest first, or
+// of every line when limit is 0.
+func topLines(lines []weightedLine, limit int) []string {
+	sortByWeight(lines)
+	if limit > 0 && limit < len(lines) {
+		lines = lines[:limit]
+	}
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	return texts
+}
+
+// roastRules lists every commit-based roast in the order lines are rendered.
+var roastRules = []roastRule{
+	{
+		ID:          "late_night",
+		Description: "Most commits land in the late-night window.",
+		Threshold:   "more than half of commits between LATE_NIGHT_START and LATE_NIGHT_END",
+		Metric:      "latenight",
+		Triggered: func(s CommitStats) bool {
+			return s.LateNightCommits > s.TotalCommits/2
+		},
+		Templates: [maxIntensity]string{
+			"You do a lot of your committing between %s. Maybe get some rest?",
+			"Most of your commits happen between %s. Night owl much?",
+			"Over 50%% of your commits are late at night, between %s. Do you even sleep?",
+			"Over half your commits land between %s. Your sleep schedule is a bigger mess than your codebase.",
+			"Over half your commits land between %s. Nothing good was ever pushed at 3am, and you're living proof.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.LateNightWindow}
+		},
+	},
+	{
+		ID:          "nine_to_five",
+		Description: "Commits only during work hours.",
+		Threshold:   "at least 10 commits and 90% of them between WORK_HOURS_START and WORK_HOURS_END",
+		Triggered: func(s CommitStats) bool {
+			return s.TotalCommits >= 10 && s.WorkHoursShare >= 0.9
+		},
+		Templates: [maxIntensity]string{
+			"Nearly all your commits land between %s. A healthy work-life balance!",
+			"Nearly all your commits land between %s. Not a minute of unpaid overtime.",
+			"Nearly all your commits land between %s. Clock in, commit, clock out.",
+			"Nearly all your commits land between %s. Your passion for code ends exactly when the paycheck does.",
+			"Nearly all your commits land between %s. You don't have a side project, you have a timesheet.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.WorkHoursWindow}
+		},
+	},
+	{
+		ID:          "off_hours",
+		Description: "Commits almost never during work hours.",
+		Threshold:   "at least 10 commits and under 10% of them between WORK_HOURS_START and WORK_HOURS_END",
+		Triggered: func(s CommitStats) bool {
+			return s.TotalCommits >= 10 && s.WorkHoursShare < 0.1
+		},
+		Templates: [maxIntensity]string{
+			"Hardly any of your commits land between %s. A true hobbyist!",
+			"Hardly any of your commits land between %s. What do you do all day?",
+			"Hardly any of your commits land between %s. Does your employer know this is where your energy goes?",
+			"Hardly any of your commits land between %s. Your day job gets the meetings, GitHub gets the real you.",
+			"Hardly any of your commits land between %s. Either you're unemployed or your boss is paying for a very expensive nap.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.WorkHoursWindow}
+		},
+	},
+	{
+		ID:          "sleepless_percentile",
+		Description: "More late-night commits than nearly everyone roasted today.",
+		Threshold:   "late-night ratio in the 90th percentile or above of today's roasts",
+		Metric:      "latenight",
+		Triggered: func(s CommitStats) bool {
+			return s.LateNightCommits > 0 && s.Percentiles["late_night_ratio"] >= 90
+		},
+		Templates: [maxIntensity]string{
+			"Your late-night ratio is in the %s percentile of today's roasts. Night owl!",
+			"Your late-night ratio puts you in the %s percentile. Most developers are asleep by then.",
+			"Your late-night ratio puts you in the %s percentile of sleep-deprived developers.",
+			"Your late-night ratio puts you in the %s percentile. Even the other insomniacs are worried about you.",
+			"Your late-night ratio puts you in the %s percentile. You've out-sleepless a corpus of people who chose to get roasted by a bot.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{ordinal(int(s.Percentiles["late_night_ratio"]))}
+		},
+	},
+	{
+		ID:          "weekends_only",
+		Description: "Every commit lands on a weekend.",
+		Threshold:   "no weekday commits and at least 3 weekend commits",
+		Triggered: func(s CommitStats) bool {
+			return s.WeekdayCommits == 0 && s.WeekendCommits >= 3
+		},
+		Templates: [maxIntensity]string{
+			"All your commits land on weekends. A hobbyist at heart!",
+			"Not a single weekday commit. Coding is strictly a weekend thing for you?",
+			"Zero weekday commits. Do you have a day job?",
+			"Zero weekday commits. Either your day job doesn't involve code or you're hiding it very well.",
+			"Zero weekday commits. Monday to Friday you're a ghost; Saturday you remember GitHub exists.",
+		},
+	},
+	{
+		ID:          "nonstop",
+		Description: "Very many commits per day with any activity.",
+		Threshold:   "10 or more commits per active day",
+		Triggered: func(s CommitStats) bool {
+			return s.CommitsPerActiveDay >= 10
+		},
+		Templates: [maxIntensity]string{
+			"%.0f commits per active day. Productive!",
+			"%.0f commits per active day. Remember to take breaks.",
+			"%.0f commits per active day. Log off.",
+			"%.0f commits per active day. Save-file-as-commit is not a workflow. Log off.",
+			"%.0f commits per active day. You don't commit code, you commit keystrokes. Log off before git does it for you.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.CommitsPerActiveDay}
+		},
+	},
+	{
+		ID:          "swearing",
+		Description: "Swear words in commit messages.",
+		Threshold:   "any swear word",
+		Metric:      "swearing",
+		Triggered: func(s CommitStats) bool {
+			return s.SwearWords > 0
+		},
+		Templates: [maxIntensity]string{
+			"Spotted %d spicy words in your commits. We've all been there.",
+			"Found %d swear words in commits. Rough week?",
+			"Found %d swear words in commits. Someone needs a stress ball!",
+			"Found %d swear words in commits. Your git log reads like a sailor's diary.",
+			"Found %d swear words in commits. Your code swears at you because you swear at it first.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.SwearWords}
+		},
+	},
+	{
+		ID:          "merges",
+		Description: "Lots of pull request merge commits.",
+		Threshold:   "more than a third of commits merge a pull request",
+		Metric:      "merges",
+		Triggered: func(s CommitStats) bool {
+			return s.Merges.PullRequestMerges > s.TotalCommits/3
+		},
+		Templates: [maxIntensity]string{
+			"Lots of merging going on. Very collaborative of you!",
+			"You merge quite a lot compared to how much you write.",
+			"You merge more than you code. Git plumber much?",
+			"You merge more than you code. Clicking the green button isn't a personality.",
+			"You merge more than you code. You're not a developer, you're a very slow CI bot.",
+		},
+	},
+	{
+		ID:          "branch_merges",
+		Description: "Branches merged into each other instead of rebased or squashed.",
+		Threshold:   "more than a quarter of commits merge a branch",
+		Metric:      "merges",
+		Triggered: func(s CommitStats) bool {
+			return s.Merges.BranchMerges > s.TotalCommits/4
+		},
+		Templates: [maxIntensity]string{
+			"%d branch merges in your history. A rebase now and then keeps things tidy.",
+			"%d 'Merge branch' commits. Your history has more bubbles than a bath.",
+			"%d 'Merge branch main into main'-style commits. Your git graph is a bowl of spaghetti.",
+			"%d branch merges and not a rebase in sight. Every path through your history ends in 'Merge branch'.",
+			"%d branch merges. Your git graph is a railway map drawn by someone who lost a bet with a plate of spaghetti.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Merges.BranchMerges}
+		},
+	},
+	{
+		ID:          "web_editor",
+		Description: "Most commits made in GitHub's web editor.",
+		Threshold:   "at least 3 commits and half of all commits made in the browser",
+		Triggered: func(s CommitStats) bool {
+			return s.WebEdits.WebCommits >= 3 && s.WebEdits.WebEditRatio >= 0.5
+		},
+		Templates: [maxIntensity]string{
+			"%.0f%% of your commits came from the web editor. Handy for quick fixes!",
+			"%.0f%% of your commits were made in the browser. Your local clone must be lonely.",
+			"%.0f%% of your commits were typed into a web form — have you met the terminal?",
+			"%.0f%% of your commits were typed into a web form. Your IDE is a textarea and your CI is hope.",
+			"%.0f%% of your commits were typed into a web form. You don't use git, you fill in GitHub's paperwork.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.WebEdits.WebEditRatio * 100}
+		},
+	},
+	{
+		ID:          "untested",
+		Description: "Few repos have any test files.",
+		Threshold:   "under 30% of listed repo trees have test files; deep mode only",
+		Triggered: func(s CommitStats) bool {
+			return s.TestCoverage.untested()
+		},
+		Templates: [maxIntensity]string{
+			"Fewer than a third of your repos have test files. Worth adding a few?",
+			"Fewer than a third of your repos have detectable test files. Brave.",
+			"Fewer than a third of your repos have detectable test files. The other 70% live on hope and prayers.",
+			"Fewer than a third of your repos have test files. Your QA process is the users.",
+			"Fewer than a third of your repos have test files. You don't write software, you write incident reports in advance.",
+		},
+	},
+	{
+		ID:          "no_gitignore",
+		Description: "Several repos have no .gitignore.",
+		Threshold:   "3 or more checked repos without a .gitignore; deep mode only",
+		Triggered: func(s CommitStats) bool {
+			return s.Gitignore != nil && s.Gitignore.Missing >= 3
+		},
+		Templates: [maxIntensity]string{
+			"A few of your repos have no `.gitignore`. GitHub has templates for that.",
+			"Several of your repos have no `.gitignore`. Hope nothing secret is in there.",
+			"Half your repos have no `.gitignore`. Your repos probably have `.DS_Store` files committed everywhere.",
+			"Half your repos have no `.gitignore`. Somewhere in there is a committed `node_modules` the size of a moon.",
+			"Half your repos have no `.gitignore`. Your `.env` file is public and someone is mining crypto with it right now.",
+		},
+	},
+	{
+		ID:          "designer",
+		Description: "More CSS than JavaScript.",
+		Threshold:   "more CSS bytes than JavaScript bytes in the language profile",
+		Triggered: func(s CommitStats) bool {
+			return s.LanguageProfile.designer()
+		},
+		Templates: [maxIntensity]string{
+			"More CSS than JavaScript. You clearly care how things look.",
+			"More CSS than JavaScript. Is this a developer's profile or a designer's?",
+			"The designer called — they want their job title back.",
+			"More CSS than JavaScript. Your biggest algorithm is a flexbox.",
+			"More CSS than JavaScript. You don't write software, you write very expensive wallpaper.",
+		},
+	},
+	{
+		ID:          "shell_heavy",
+		Description: "Shell scripts make up a big part of the code.",
+		Threshold:   "more than 20% of code bytes are Shell",
+		Triggered: func(s CommitStats) bool {
+			return s.LanguageProfile.shellHeavy()
+		},
+		Templates: [maxIntensity]string{
+			"Lots of shell scripts in there. Automating everything, nice.",
+			"A fifth of your code is shell. Someone has to glue it all together.",
+			"You write more shell scripts than application code. SRE or developer?",
+			"You write more shell scripts than application code. Your app is a cron job with delusions of grandeur.",
+			"You write more shell scripts than application code. `set -e` is the closest thing you have to error handling.",
+		},
+	},
+	{
+		ID:          "squash_merges",
+		Description: "Nearly everything lands as a squashed pull request.",
+		Threshold:   "more than half of commits end in a (#123) pull request number",
+		Metric:      "merges",
+		Triggered: func(s CommitStats) bool {
+			return s.Merges.SquashMerges > s.TotalCommits/2
+		},
+		Templates: [maxIntensity]string{
+			"Most of your commits are squash merges. Tidy!",
+			"Most of your commits end in (#123). Squashing is nice, but do you ever commit on your own?",
+			"Everything you ship is a squash merge. Twenty 'wip' commits hidden under one (#123), we know what you did.",
+			"Your history is all squash merges. Whatever happened in those branches, the squash button is your alibi.",
+			"Every commit is a squash merge. You don't have a history, you have a cover-up with pull request numbers.",
+		},
+	},
+	{
+		ID:          "fixes",
+		Description: "Lots of commits fixing things.",
+		Threshold:   "more than half of commits mention fix, bug or error",
+		Metric:      "fixes",
+		Triggered: func(s CommitStats) bool {
+			return s.FixCommits > s.TotalCommits/2
+		},
+		Templates: [maxIntensity]string{
+			"Lots of fixes lately. At least you clean up after yourself!",
+			"More than half your commits are fixes. Tests might save you some time.",
+			"Most of your commits are fixes. Maybe test before committing?",
+			"Most of your commits are fixes. You don't write features, you write future bug reports.",
+			"Most of your commits are fixes for your other commits. It's bugs all the way down and you're the one digging.",
+		},
+	},
+	{
+		ID:          "fixups",
+		Description: "fixup! and squash! commits that were never rebased away.",
+		Threshold:   "any fixup! or squash! commit",
+		Triggered: func(s CommitStats) bool {
+			return s.FixupCommits > 0
+		},
+		Templates: [maxIntensity]string{
+			"%d fixup commits made it into history. Easy to miss!",
+			"%d 'fixup!' commits in your history. `git rebase -i --autosquash` is your friend.",
+			"%d 'fixup!' and 'squash!' commits in your history. You forgot to squash your fixups, rookie.",
+			"%d 'fixup!' commits made it to the main branch. You did the hard part and skipped the one command that mattered.",
+			"%d 'fixup!' commits in your history. Interactive rebase is interactive; you're supposed to actually interact with it.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.FixupCommits}
+		},
+	},
+	{
+		ID:          "generic_messages",
+		Description: "Generic messages like \"update\" or \"changes\".",
+		Threshold:   "more than a third of messages start with update or changes",
+		Metric:      "messages",
+		Triggered: func(s CommitStats) bool {
+			return s.GenericMessages > s.TotalCommits/3
+		},
+		Templates: [maxIntensity]string{
+			"Your commit messages could be a touch more descriptive.",
+			"A lot of your commit messages just say 'update'. Future you will wonder what changed.",
+			"Your commit messages are as generic as a motivational poster.",
+			"'update', 'changes', 'update'. Your commit messages have the storytelling skills of a parking ticket.",
+			"Your commit messages are so generic that git blame gives up and blames itself.",
+		},
+	},
+	{
+		ID:          "empty_messages",
+		Description: "Commits with an empty message.",
+		Threshold:   "any empty message",
+		Metric:      "messages",
+		Triggered: func(s CommitStats) bool {
+			return s.EmptyMessages > 0
+		},
+		Templates: [maxIntensity]string{
+			"%d of your commits have no message at all. Maybe just forgot?",
+			"%d commits with a blank message. The strong, silent type.",
+			"%d commits with no message? Speechless, I see.",
+			"%d commits with no message. Even 'wip' would have been an upgrade.",
+			"%d commits with no message. You didn't just fail to explain your code, you refused to.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.EmptyMessages}
+		},
+	},
+	{
+		ID:          "veteran_low_activity",
+		Description: "A long-time GitHub user who barely commits any more.",
+		Threshold:   "veteran account with fewer than 10 commits in the window",
+		Triggered: func(s CommitStats) bool {
+			return s.DeveloperVintage == vintageVeteran && s.TotalCommits < 10
+		},
+		Templates: [maxIntensity]string{
+			"%d years of committing and only a handful lately. Enjoying semi-retirement?",
+			"In over %d years you've seen every framework come and go. Lately you mostly watch.",
+			"In over %d years you've mastered exactly one test framework — and your repos don't use it.",
+			"Over %d years in the game and this month you managed a few commits. The legacy code is running itself.",
+			"Over %d years of experience, and your recent commit history fits on a sticky note. Seniority is not a substitute for output.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{yearsSince(*s.FirstCommitDate, time.Now())}
+		},
+	},
+	{
+		ID:          "slow_pace",
+		Description: "An old account committing at a crawl.",
+		Threshold:   "account over 5 years old on pace for fewer than 50 commits a year",
+		Triggered: func(s CommitStats) bool {
+			return s.VelocityContext.slowVeteran()
+		},
+		Templates: [maxIntensity]string{
+			"At your current pace you'll push %d commits this year. Taking it easy?",
+			"At your current pace you'll push %d commits this year. The typical developer does a hundred or more.",
+			"At your current pace you'll push %d commits this year. My Roomba does more meaningful work.",
+			"At your current pace you'll push %d commits this year. Glaciers have better velocity.",
+			"At your current pace you'll push %d commits this year. Your account is older than some of your users and less active than all of them.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.VelocityContext.YearlyPace}
+		},
+	},
+	{
+		ID:          "solo",
+		Description: "Works alone in every repo.",
+		Threshold:   "over 95% of commits in solo repos; deep mode only",
+		Triggered: func(s CommitStats) bool {
+			return s.Collaboration != nil && s.Collaboration.SoloCommitRatio > 0.95
+		},
+		Templates: [maxIntensity]string{
+			"%.0f%% of the commits in your repos are yours. A true independent spirit.",
+			"%.0f%% of your commits are solo. Pair programming is a thing, you know.",
+			"%.0f%% of your commits are solo. Either you work alone or nobody pairs with you.",
+			"%.0f%% of your commits are solo. Your code review process is you, squinting.",
+			"%.0f%% of your commits are solo. Nobody else will touch your code, and honestly, fair.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Collaboration.SoloCommitRatio * 100}
+		},
+	},
+	{
+		ID:          "collaborator",
+		Description: "Never works alone.",
+		Threshold:   "under 5% of commits in solo repos; deep mode only",
+		Triggered: func(s CommitStats) bool {
+			return s.Collaboration != nil && s.Collaboration.CollabRepos > 0 && s.Collaboration.SoloCommitRatio < 0.05
+		},
+		Templates: [maxIntensity]string{
+			"You're a great team player. Maybe try leading something of your own?",
+			"You contribute a little to a lot of teams. Very supportive!",
+			"You've collaborated on so many projects you might want to start one of your own.",
+			"Your share of the commits in your own repos is a rounding error. Are you a contributor or a spectator?",
+			"Less than 5% of the commits in your repos are yours. You're the honorary member of your own projects.",
+		},
+	},
+	{
+		ID:          "no_commit_body",
+		Description: "Commit messages without a body.",
+		Threshold:   "under 10% of commits have a body",
+		Metric:      "messages",
+		Triggered: func(s CommitStats) bool {
+			counted := s.CommitBody.WithBody + s.CommitBody.WithoutBody
+			return counted > 0 && s.CommitBody.BodyRatio < 0.1
+		},
+		Templates: [maxIntensity]string{
+			"Hardly any of your commits have a body. A line or two of 'why' goes a long way.",
+			"Less than 10% of your commits have a body. Subject lines can only say so much.",
+			"Less than 10% of your commits have a body. Commit messages without 'why' are instructions without context — great for confusion.",
+			"Less than 10% of your commits have a body. Your reviewers have to reverse-engineer your intentions like it's a CTF.",
+			"Less than 10% of your commits have a body. Whoever inherits your code will be holding a séance to find out why.",
+		},
+	},
+	{
+		ID:          "no_issue_links",
+		Description: "Commits never reference an issue or pull request.",
+		Threshold:   "no #N references in 5 or more commits",
+		Triggered: func(s CommitStats) bool {
+			return s.TotalCommits >= 5 && s.IssueReferences.LinkedCommits == 0
+		},
+		Templates: [maxIntensity]string{
+			"None of your commits mention an issue. Maybe link one now and then?",
+			"Not one commit references an issue. Your issue tracker must feel lonely.",
+			"Not a single commit references an issue. Your commits and your issues live entirely separate lives.",
+			"Not a single commit references an issue. Project management is a rumour in your repos.",
+			"Not a single commit references an issue. Your changes appear out of nowhere, like a magician nobody asked for.",
+		},
+	},
+	{
+		ID:          "over_linked",
+		Description: "Every commit references an issue.",
+		Threshold:   "95% or more of 5 or more commits reference an issue",
+		Triggered: func(s CommitStats) bool {
+			return s.TotalCommits >= 5 && s.IssueReferences.LinkRatio >= 0.95
+		},
+		Templates: [maxIntensity]string{
+			"Every one of your commits links an issue. Very organised!",
+			"You link an issue in every commit. The project manager loves you.",
+			"You reference an issue for every single commit, including 'fix typo in README'. Relax a bit.",
+			"You reference an issue for every single commit. Did you open a ticket to fix a typo? You did, didn't you.",
+			"You reference an issue for every single commit. Your process has process. Jira is your love language.",
+		},
+	},
+	{
+		ID:          "bare_issue_refs",
+		Description: "Messages that are nothing but an issue number.",
+		Threshold:   "any message that is only a reference like #123",
+		Triggered: func(s CommitStats) bool {
+			return s.IssueReferences.BareReferenceCommits > 0
+		},
+		Templates: [maxIntensity]string{
+			"%d commit messages are just an issue number. A few words would help.",
+			"%d commit messages say nothing but '#123'. The issue tracker isn't a commit message.",
+			"%d commit messages are just '#123'. Your git log reads like a raffle draw.",
+			"%d commit messages are just '#123'. Reviewers love opening a browser tab to learn what you did.",
+			"%d commit messages are just '#123'. You've outsourced your own history to a ticket nobody will read after it's closed.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.IssueReferences.BareReferenceCommits}
+		},
+	},
+	{
+		ID:          "unsigned",
+		Description: "No commit is signed.",
+		Threshold:   "no verified signatures in 5 or more commits",
+		Triggered: func(s CommitStats) bool {
+			return s.TotalCommits >= 5 && s.Verification.VerifiedCount == 0
+		},
+		Templates: [maxIntensity]string{
+			"None of your commits are signed. Might be worth setting up a key!",
+			"Not one signed commit. Anyone could be you, really.",
+			"Not a single GPG-signed commit. Identity theft is not a joke — apparently it's not your concern either.",
+			"Not a single signed commit. If someone impersonated you, would anyone even notice the quality drop?",
+			"Not a single signed commit. Your commits are unverified, unsigned and, frankly, unclaimed.",
+		},
+	},
+	{
+		ID:          "always_signed",
+		Description: "Every commit is signed.",
+		Threshold:   "every one of 5 or more commits verified",
+		Triggered: func(s CommitStats) bool {
+			return s.TotalCommits >= 5 && s.Verification.UnverifiedCount == 0
+		},
+		Templates: [maxIntensity]string{
+			"Every commit is signed. Security-conscious, nice!",
+			"Every single commit is signed. Very thorough.",
+			"Every commit is GPG-signed. You probably sign your grocery lists too.",
+			"Every commit is GPG-signed. Your code may be questionable, but it's authentically questionable.",
+			"Every commit is GPG-signed. The cryptography is flawless; shame about what it's protecting.",
+		},
+	},
+	{
+		ID:          "dmca",
+		Description: "A repo is unavailable for legal reasons.",
+		Threshold:   "any repo blocked with HTTP 451",
+		Triggered: func(s CommitStats) bool {
+			return hasSkipReason(s.SkippedRepos, skipUnavailableLegal)
+		},
+		Templates: [maxIntensity]string{
+			"One of your repos is unavailable for legal reasons. Interesting!",
+			"One of your repos got taken down for legal reasons. Living on the edge.",
+			"One of your repos got DMCA'd — bold strategy.",
+			"One of your repos got DMCA'd. Finally, code that somebody cared enough about to complain.",
+			"One of your repos got DMCA'd. Your most impactful contribution to open source was a legal notice.",
+		},
+	},
+	{
+		ID:          "serial_starter",
+		Description: "Most repos have gone stale.",
+		Threshold:   "at least 3 stale repos and 70% of repos stale",
+		Triggered: func(s CommitStats) bool {
+			return s.Abandonment.Stale >= 3 && s.Abandonment.Stale*10 >= s.ReposAnalyzed*7
+		},
+		Templates: [maxIntensity]string{
+			"%d of your last %d repos haven't seen a push in a while. Lots of side projects!",
+			"%d of your last %d repos are gathering dust. Finishing is the hard part, huh?",
+			"You abandon projects faster than New Year's resolutions — %d of your last %d repos are dead.",
+			"%d of your last %d repos are dead. Your GitHub is less a portfolio, more a list of things you got bored of.",
+			"%d of your last %d repos are dead. You don't have side projects, you have a hobby of typing `git init`.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Abandonment.Stale, s.ReposAnalyzed}
+		},
+	},
+	{
+		ID:          "abandoned_projects",
+		Description: "Projects started in a burst and dropped within a week.",
+		Threshold:   "4 or more repos whose pushes all came in their first week, over 6 months ago",
+		Triggered: func(s CommitStats) bool {
+			return s.AbandonedProjects.Count >= 4
+		},
+		Templates: [maxIntensity]string{
+			"You have %d repos that got one busy week and then nothing. Plenty of ideas, at least!",
+			"You have %d repos abandoned after their first week. Maybe finish one before starting the next?",
+			"You have %d abandoned repos, each one a New Year's resolution that didn't survive February. The dev equivalent of a gym membership.",
+			"You have %d repos that lived for a week and died. Your GitHub is a hospice for weekend ideas.",
+			"You have %d repos abandoned after a week of enthusiasm. You don't ship projects, you speed-run the honeymoon phase.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.AbandonedProjects.Count}
+		},
+	},
+	{
+		ID:          "famous_abandoned",
+		Description: "Starred repos nobody commits to any more.",
+		Threshold:   "a repo with 50 or more stars and no commits in the window",
+		Triggered: func(s CommitStats) bool {
+			return len(s.Popularity.FamousIdleRepos) > 0
+		},
+		Templates: [maxIntensity]string{
+			"%s has a fan club but no recent commits. Time for a release?",
+			"%s has plenty of stars and no commits lately. Your fans are waiting.",
+			"%s: famous repo, abandoned. The stars are still shining; you're not.",
+			"%s has the stars of a hit and the commit history of a tombstone.",
+			"%s has a crowd of stargazers and no maintainer. You peaked and then you left the building.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Popularity.FamousIdleRepos[0]}
+		},
+	},
+	{
+		ID:          "nobody_cares",
+		Description: "Lots of commits, not a single star.",
+		Threshold:   "50 or more commits in the window and no stars on any repo",
+		Triggered: func(s CommitStats) bool {
+			return s.CommitsFetched >= busyCommitCount && s.ReposAnalyzed > 0 && s.Popularity.TotalStars == 0
+		},
+		Templates: [maxIntensity]string{
+			"%d commits and no stars yet. Your audience will find you!",
+			"%d commits and not one star. Maybe tell someone about your projects?",
+			"%d commits and zero stars. Nobody cares, but you keep pushing. Respect.",
+			"%d commits and zero stars. You're shouting into the void and the void isn't even listening.",
+			"%d commits and zero stars. Not even your mum starred it, and she stars everything.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.CommitsFetched}
+		},
+	},
+	{
+		ID:          "initial_commit_graveyard",
+		Description: "Repos that never got past the initial commit.",
+		Threshold:   "at least 2 such repos and over a third of repos",
+		Triggered: func(s CommitStats) bool {
+			return s.InitialCommitOnlyRepos >= 2 && s.InitialCommitOnlyRepos*3 > s.ReposAnalyzed
+		},
+		Templates: [maxIntensity]string{
+			"You have %d repos that are just an 'Initial commit'. Lots of ideas on the go!",
+			"You have %d repos whose whole history is 'Initial commit'. Plenty of fresh starts.",
+			"You have %d repos whose entire history is 'Initial commit' — the graveyard of ambition.",
+			"You have %d repos whose entire history is 'Initial commit'. You don't finish projects, you announce them.",
+			"You have %d repos whose entire history is 'Initial commit'. Your GitHub is a cemetery and every headstone is a README.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.InitialCommitOnlyRepos}
+		},
+	},
+	{
+		ID:          "mixed_scripts",
+		Description: "Commit messages in several writing systems.",
+		Threshold:   "3 or more scripts",
+		Triggered: func(s CommitStats) bool {
+			return s.ScriptCount >= 3
+		},
+		Templates: [maxIntensity]string{
+			"Your commits come in %d different scripts. How international!",
+			"Your commits are in %d different scripts. Your reviewers must own a lot of dictionaries.",
+			"Your commits are in %d different scripts. Either you're multilingual or you had rotating interns.",
+			"Your commits are in %d different scripts. Nobody, including you, can read the whole log.",
+			"Your commits are in %d different scripts. Your git log is a Rosetta Stone with none of the historical value.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.ScriptCount}
+		},
+	},
+	{
+		ID:          "cross_repo_duplicates",
+		Description: "The same commit message copied across repos.",
+		Threshold:   "any duplicate group",
+		Triggered: func(s CommitStats) bool {
+			return s.CrossRepoDuplicates.Groups > 0
+		},
+		Templates: [maxIntensity]string{
+			"The message '%s' shows up in %d different repos. Consistent, at least!",
+			"The message '%s' appears across %d different repos. Copy-paste is a strong workflow.",
+			"The message '%s' appears across %d different repos. `git init` is not development.",
+			"The message '%s' appears across %d different repos. You don't start projects, you start graveyards.",
+			"The message '%s' appears across %d different repos. Your GitHub is a template factory with no production line.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			top := s.CrossRepoDuplicates.Examples[0]
+			return []interface{}{top.Message, len(top.Repos)}
+		},
+	},
+	{
+		ID:          "automation",
+		Description: "Bots make most of the commits.",
+		Threshold:   "over 40% of commits automated",
+		Metric:      "automation",
+		Triggered: func(s CommitStats) bool {
+			return s.Automation.AutomationRatio > 0.4
+		},
+		Templates: [maxIntensity]string{
+			"%.0f%% of your commits come from bots. Nice automation setup!",
+			"%.0f%% of your commits are from bots. The robots are pulling their weight.",
+			"%.0f%% of your 'commits' are from automated bots. Your contribution graph is sponsored by cron jobs.",
+			"%.0f%% of your 'commits' are from bots. Dependabot should be asking for your salary.",
+			"%.0f%% of your 'commits' are from bots. If they unplug the CI server, your GitHub flatlines.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Automation.AutomationRatio * 100}
+		},
+	},
+	{
+		ID:          "borrowed_glory",
+		Description: "Commits authored by someone else.",
+		Threshold:   "over 25% of commits written by other people",
+		Triggered: func(s CommitStats) bool {
+			return s.Authorship.DiscrepancyRatio > 0.25
+		},
+		Templates: [maxIntensity]string{
+			"%.0f%% of your commits were authored by someone else. Lots of squash merging going on!",
+			"%.0f%% of the commits 'by you' were written by someone else. Generous with the merge button.",
+			"%.0f%% of commits 'by you' weren't actually authored by you. How much of your commit history is borrowed glory?",
+			"%.0f%% of commits 'by you' were written by other people. You're less a developer, more a curator.",
+			"%.0f%% of commits 'by you' were written by other people. Your contribution graph is a plagiarism report.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Authorship.DiscrepancyRatio * 100}
+		},
+	},
+	{
+		ID:          "code_profanity",
+		Description: "Swear words in the code itself.",
+		Threshold:   "any swear word in added lines; deep scan only",
+		Metric:      "swearing",
+		Triggered: func(s CommitStats) bool {
+			return s.CodeCommentProfanity != nil && s.CodeCommentProfanity.Count > 0
+		},
+		Templates: [maxIntensity]string{
+			"A few colourful words made it into your actual code. Happens to the best of us.",
+			"Found profanity in your code changes, not just your commit messages. The reviewers will notice.",
+			"Found profanity in your actual code changes, not just commit messages. Setting a great example for the intern.",
+			"Found profanity in your actual code changes. Your comments are less documentation, more therapy session.",
+			"Found profanity in your actual code changes. Somewhere a linter is filing an HR complaint.",
+		},
+	},
+	{
+		ID:          "doc_only",
+		Description: "Commits that only touch documentation.",
+		Threshold:   "over 20% of scanned commits; deep scan only",
+		Triggered: func(s CommitStats) bool {
+			return s.DocOnly != nil && s.DocOnly.DocOnlyRatio > 0.2
+		},
+		Templates: [maxIntensity]string{
+			"1 in %d of your commits only touch documentation. Very thorough!",
+			"1 in %d of your commits are documentation-only. The README is looking great, at least.",
+			"1 in %d of your commits are documentation-only. Documenting the future that never arrived.",
+			"1 in %d of your commits are documentation-only. README-driven development, minus the development.",
+			"1 in %d of your commits are documentation-only. You write product brochures for software that doesn't exist.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{int(math.Round(1 / s.DocOnly.DocOnlyRatio))}
+		},
+	},
+	{
+		ID:          "shouting",
+		Description: "Commit subjects written in ALL CAPS.",
+		Threshold:   "3 or more shouting subjects",
+		Triggered: func(s CommitStats) bool {
+			return s.Shouting.ShoutingCommits >= 3
+		},
+		Templates: [maxIntensity]string{
+			"%d of your commit messages are in ALL CAPS. Caps lock is a little sticky, huh?",
+			"%d commit messages in ALL CAPS. Your keyboard's caps lock key deserves a rest.",
+			"%d commit messages in ALL CAPS. WE GET IT, YOU'RE ANGRY.",
+			"%d commit messages in ALL CAPS. Your git log reads like a comment section.",
+			"%d commit messages in ALL CAPS. YELLING AT GIT WON'T MAKE THE TESTS PASS.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Shouting.ShoutingCommits}
+		},
+	},
+	{
+		ID:          "debt_markers",
+		Description: "TODO, FIXME, HACK and XXX markers committed.",
+		Threshold:   "5 or more markers",
+		Triggered: func(s CommitStats) bool {
+			return s.DebtMarkers.total() >= 5
+		},
+		Templates: [maxIntensity]string{
+			"%d TODOs and FIXMEs committed. Future you has a busy week ahead.",
+			"%d TODOs committed. That's not a codebase, that's a to-do list with extra steps.",
+			"%d TODOs committed — the debt collector is coming.",
+			"%d TODOs committed. Your technical debt has technical debt, and it's compounding.",
+			"%d TODOs committed. At this point HACK isn't a marker, it's your job title.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.DebtMarkers.total()}
+		},
+	},
+	{
+		ID:          "no_follow_through",
+		Description: "Lots of commits, few issues or pull requests closed.",
+		Threshold:   "20 or more commits and over 25 per issue or PR closed; follow_through only",
+		Triggered: func(s CommitStats) bool {
+			return s.FollowThrough != nil && s.TotalCommits >= 20 && s.FollowThrough.CommitsPerClose > 25
+		},
+		Templates: [maxIntensity]string{
+			"%d commits, %d issues or PRs closed. Plenty of momentum, not much landing.",
+			"%d commits and %d issues or PRs closed. Busy hands, open tickets.",
+			"%d commits, %d issues or PRs closed. All commits, no follow-through.",
+			"%d commits, %d issues or PRs closed. You start things for a living; finishing is someone else's job.",
+			"%d commits, %d issues or PRs closed. Your backlog isn't a list, it's a monument.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.TotalCommits, s.FollowThrough.closed()}
+		},
+	},
+	{
+		ID:          "vague_vocabulary",
+		Description: "The most used word in commit messages says nothing, like \"stuff\" or \"misc\".",
+		Threshold:   "a vague word used 5 or more times tops the list; words only",
+		Triggered: func(s CommitStats) bool {
+			return s.Words != nil && s.Words.VagueWord != "" && s.Words.Top[0].Count >= 5
+		},
+		Templates: [maxIntensity]string{
+			"Your most used word is '%s' (%d times). A little more detail would go a long way.",
+			"Your most used word is '%s', %d times over. Very descriptive.",
+			"Your most used word is '%s' — %d times. Your vocabulary peaked in the commit template.",
+			"Your most used word is '%s', %d times. Your git log reads like a shrug in text form.",
+			"Your most used word is '%s'. %d times. Future archaeologists will assume you were paid per vague noun.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Words.VagueWord, s.Words.Top[0].Count}
+		},
+	},
+	{
+		ID:          "noreply_only",
+		Description: "Every commit is authored with a GitHub noreply address.",
+		Threshold:   "5 or more commits, all from noreply addresses",
+		Triggered: func(s CommitStats) bool {
+			return s.TotalCommits >= 5 && s.EmailDomains.NoreplyOnly
+		},
+		Templates: [maxIntensity]string{
+			"All your commits come from a GitHub noreply address. The web editor is handy, we get it.",
+			"Every one of your commits was typed into a browser tab.",
+			"All your commits come from GitHub's web editor. Do you even have a terminal?",
+			"All your commits come from GitHub's web editor. Your local clone is a rumour.",
+			"All your commits come from GitHub's web editor. You don't use git, you use a textarea.",
+		},
+	},
+	{
+		ID:          "typos",
+		Description: "Commit subjects with common misspellings.",
+		Threshold:   "over 20% of 5 or more checked subjects misspelled; spellcheck only",
+		Triggered: func(s CommitStats) bool {
+			return s.Spelling != nil && s.Spelling.CheckedSubjects >= 5 && s.Spelling.MisspellingRate > 0.2
+		},
+		Templates: [maxIntensity]string{
+			"%s — a spell checker might be worth a look.",
+			"%s — your typing is faster than your spelling.",
+			"%s — your commits need autocorrect more than code review.",
+			"%s — your commit log reads like a ransom note written in a hurry.",
+			"%s — English is clearly not the only thing you compile with warnings.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.Spelling.quotedTypos()}
+		},
+	},
+	{
+		ID:          "giant_commits",
+		Description: "Commits that change hundreds of lines at once.",
+		Threshold:   "an average of 500 or more lines changed over 3 or more sampled commits; deep only",
+		Triggered: func(s CommitStats) bool {
+			return s.CommitSize != nil && s.CommitSize.SampledCommits >= 3 && s.CommitSize.AvgCommitSize >= 500
+		},
+		Templates: [maxIntensity]string{
+			"Your commits average %.0f lines. Reviewers would appreciate smaller bites.",
+			"Your commits average %.0f lines. Ever heard of small PRs?",
+			"%.0f-line commits on average — ever heard of small PRs?",
+			"%.0f lines per commit. Your reviewers just approve and pray.",
+			"%.0f lines per commit. That's not a commit, that's a hostage situation.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.CommitSize.AvgCommitSize}
+		},
+	},
+	{
+		ID:          "one_liners",
+		Description: "Most commits change a single line.",
+		Threshold:   "over half of 5 or more sampled commits change fewer than 2 lines; deep only",
+		Triggered: func(s CommitStats) bool {
+			return s.CommitSize != nil && s.CommitSize.SampledCommits >= 5 && share(s.CommitSize.TinyCommits, s.CommitSize.SampledCommits) > 0.5
+		},
+		Templates: [maxIntensity]string{
+			"%d of %d sampled commits change a single line. Very focused, at least.",
+			"%d of %d sampled commits change a single line. Squashing is free, you know.",
+			"%d of %d sampled commits are one-liners. Padding the contribution graph?",
+			"%d of %d sampled commits are one-liners. You commit every keystroke like it's a save button.",
+			"%d of %d sampled commits are one-liners. Your green squares are a lie and we all know it.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{s.CommitSize.TinyCommits, s.CommitSize.SampledCommits}
+		},
+	},
+	{
+		ID:          "many_names",
+		Description: "Commits under several different git author names.",
+		Threshold:   "3 or more author names on the user's own commits",
+		Triggered: func(s CommitStats) bool {
+			return s.AuthorNames.IsInconsistent
+		},
+		Templates: [maxIntensity]string{
+			"You've committed under %d different names. Maybe sync your git config?",
+			"You've committed under %d different names. Which one's on the payroll?",
+			"You've committed under %d different names. Are you a developer or a spy?",
+			"You've committed under %d different names. Your git config has an identity crisis.",
+			"You've committed under %d different names. Witness protection called, they want their paperwork back.",
+		},
+		Args: func(s CommitStats) []interface{} {
+			return []interface{}{len(s.AuthorNames.UniqueNames)}
+		},
+	},
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v50/github"
+)
+
+func TestRuleLineIntensity(t *testing.T) {
+	stats := CommitStats{TotalCommits: 10, FixCommits: 8}
+	fixes := findRule(t, "fixes")
+
+	seen := make(map[string]int)
+	for intensity := minIntensity; intensity <= maxIntensity; intensity++ {
+		line := fixes.line(stats, intensity)
+		if prev, ok := seen[line]; ok {
+			t.Errorf("intensities %d and %d share the phrasing %q", prev, intensity, line)
+		}
+		seen[line] = intensity
+	}
+
+	tests := []struct {
+		name      string
+		intensity int
+		want      int
+	}{
+		{"gentle", 1, 1},
+		{"brutal", 5, 5},
+		{"unset", 0, defaultIntensity},
+		{"too high", 9, defaultIntensity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := fixes.line(stats, tt.intensity), fixes.line(stats, tt.want); got != want {
+				t.Errorf("line() at %d = %q, want %q", tt.intensity, got, want)
+			}
+		})
+	}
+}
+
+func TestRoastIntensity(t *testing.T) {
+	stats := CommitStats{TotalCommits: 10, FixCommits: 8, LateNightCommits: 7}
+	gentle := generateRoast(stats, RoastOptions{Intensity: 1})
+	brutal := generateRoast(stats, RoastOptions{Intensity: 5})
+	if gentle == brutal {
+		t.Errorf("intensity 1 and 5 roast alike: %q", gentle)
+	}
+	if got := generateRoast(stats, RoastOptions{Intensity: defaultIntensity}); got == gentle || got == brutal {
+		t.Errorf("default intensity matches an extreme: %q", got)
+	}
+}
+
+func TestRoastOptionsIntensity(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    int
+		wantErr bool
+	}{
+		{"", defaultIntensity, false},
+		{"intensity=1", 1, false},
+		{"intensity=5", 5, false},
+		{"intensity=0", 0, true},
+		{"intensity=6", 0, true},
+		{"intensity=loud", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/roast?"+tt.query, nil)
+			opts, err := roastOptions(c, loadRoastConfig())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("roastOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && opts.Intensity != tt.want {
+				t.Errorf("Intensity = %d, want %d", opts.Intensity, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRelated(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []weightedLine
+		want  []weightedLine
+	}{
+		{"nothing related", []weightedLine{{rule: "late_night", text: "a", weight: 1}, {rule: "swearing", text: "b", weight: 2}}, []weightedLine{{rule: "late_night", text: "a", weight: 1}, {rule: "swearing", text: "b", weight: 2}}},
+		{
+			"a group folds into its first line, as heavy as its heaviest",
+			[]weightedLine{
+				{rule: "generic_messages", text: "Generic.", weight: 1},
+				{rule: "late_night", text: "Late.", weight: 1.5},
+				{rule: "no_commit_body", text: "No bodies.", weight: 3},
+				{rule: "empty_messages", text: "Empty.", weight: 2},
+			},
+			[]weightedLine{
+				{rule: "generic_messages", text: "Generic. No bodies. Empty.", weight: 3},
+				{rule: "late_night", text: "Late.", weight: 1.5},
+			},
+		},
+		{
+			"groups merge separately",
+			[]weightedLine{{rule: "fixes", text: "Fixes.", weight: 1}, {rule: "serial_starter", text: "Dead.", weight: 1}, {rule: "fixups", text: "Fixups.", weight: 1}},
+			[]weightedLine{{rule: "fixes", text: "Fixes. Fixups.", weight: 1}, {rule: "serial_starter", text: "Dead.", weight: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeRelated(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeRelated() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoastLeadsWithMostDamning(t *testing.T) {
+	// Swearing is five times its threshold, fixes 1.6 and late nights 1.2
+	stats := CommitStats{TotalCommits: 10, SwearWords: 5, FixCommits: 8, FixupCommits: 4, LateNightCommits: 6}
+	weights := defaultWeights()
+	ranked := rankedRules(stats, weights)
+	if len(ranked) < 4 || ranked[0] != "swearing" {
+		t.Fatalf("rankedRules() = %q, want swearing first", ranked)
+	}
+	// Merging only affects the roast text, not the ranking
+	if !slices.Contains(ranked, "fixes") || !slices.Contains(ranked, "fixups") {
+		t.Errorf("rankedRules() = %q, want fixes and fixups listed apart", ranked)
+	}
+
+	lines := roastLines(stats, RoastOptions{Intensity: defaultIntensity, Weights: weights, MaxLines: 2})
+	if len(lines) != 3 || lines[0] != findRule(t, "swearing").line(stats, defaultIntensity) || lines[2] != moreLinesMarker {
+		t.Errorf("roastLines() = %q, want swearing, one more and the marker", lines)
+	}
+	// Fixes and fixups come out as one line
+	fixes, fixups := findRule(t, "fixes").line(stats, defaultIntensity), findRule(t, "fixups").line(stats, defaultIntensity)
+	if lines[1] != fixes+" "+fixups {
+		t.Errorf("second line = %q, want fixes and fixups merged", lines[1])
+	}
+}
+
+func TestRoastKeepsEveryTriggeredRule(t *testing.T) {
+	var commits []*github.RepositoryCommit
+	for i := range 10 {
+		commits = append(commits, fakeCommit("Octo", "fix this damn shit wtf", i+1))
+	}
+	s := newTestServer(t, newFakeGitHub("octocat", commits...))
+	w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat&max_lines=1", "")
+	var body RoastResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(body.Roast, "\n\n")
+	if len(lines) != 2 || lines[1] != moreLinesMarker {
+		t.Errorf("roast = %q, want one line and the marker", body.Roast)
+	}
+	if len(body.Stats.TriggeredRules) < 2 {
+		t.Errorf("triggered_rules = %q, want every rule that fired", body.Stats.TriggeredRules)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+)
+
+// defaultMaxCommits caps how many commits one analysis keeps in memory.
+const defaultMaxCommits = 1000
+
+// commitSampler keeps a uniform random sample of at most limit commits out
+// of however many are added (reservoir sampling), so memory stays bounded
+// no matter how busy the user has been.
+type commitSampler struct {
+	limit   int
+	seen    int
+	commits []NormalizedCommit
+	rng     *rand.Rand
+}
+
+// newCommitSampler seeds the sampler from username so repeat roasts of the
+// same activity keep the same sample.
+func newCommitSampler(username string, limit int) *commitSampler {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(username)))
+	seed := h.Sum64()
+	return &commitSampler{limit: limit, rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (s *commitSampler) add(commit NormalizedCommit) {
+	s.seen++
+	if len(s.commits) < s.limit {
+		s.commits = append(s.commits, commit)
+		return
+	}
+	if j := s.rng.IntN(s.seen); j < s.limit {
+		s.commits[j] = commit
+	}
+}
+
+// sampled reports whether commits were dropped.
+func (s *commitSampler) sampled() bool {
+	return s.seen > len(s.commits)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// syntheticCommit is commit i of a busy history, with the payload fields
+// the analyzer never reads filled in.
+func syntheticCommit(i int) *github.RepositoryCommit {
+	when := &github.Timestamp{Time: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Minute)}
+	who := &github.CommitAuthor{Name: github.String("Octo Cat"), Email: github.String("octo@example.com"), Date: when}
+	sha := fmt.Sprintf("%040x", i)
+	return &github.RepositoryCommit{
+		SHA:         github.String(sha),
+		NodeID:      github.String("C_" + sha),
+		URL:         github.String("https://api.github.com/repos/octocat/project/commits/" + sha),
+		HTMLURL:     github.String("https://github.com/octocat/project/commit/" + sha),
+		CommentsURL: github.String("https://api.github.com/repos/octocat/project/commits/" + sha + "/comments"),
+		Author:      &github.User{Login: github.String("octocat"), AvatarURL: github.String("https://avatars.githubusercontent.com/u/583231")},
+		Committer:   &github.User{Login: github.String("web-flow")},
+		Parents:     []*github.Commit{{SHA: github.String(strings.Repeat("0", 40))}},
+		Commit: &github.Commit{
+			Message:   github.String(fmt.Sprintf("fix flaky test %d\n\n%s", i, strings.Repeat("Details of the change. ", 10))),
+			Author:    who,
+			Committer: who,
+			Tree:      &github.Tree{SHA: github.String(sha)},
+		},
+	}
+}
+
+func TestCommitSampler(t *testing.T) {
+	tests := []struct {
+		name        string
+		added       int
+		limit       int
+		wantKept    int
+		wantSampled bool
+	}{
+		{"under the cap", 5, 10, 5, false},
+		{"at the cap", 10, 10, 10, false},
+		{"over the cap", 1000, 10, 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newCommitSampler("octocat", tt.limit)
+			for i := range tt.added {
+				s.add(NormalizedCommit{SHA: fmt.Sprint(i)})
+			}
+			if len(s.commits) != tt.wantKept || s.sampled() != tt.wantSampled || s.seen != tt.added {
+				t.Errorf("kept %d of %d, sampled %v; want %d, %v", len(s.commits), s.seen, s.sampled(), tt.wantKept, tt.wantSampled)
+			}
+		})
+	}
+}
+
+func TestCommitSamplerIsStablePerUser(t *testing.T) {
+	sample := func(username string) []string {
+		s := newCommitSampler(username, 20)
+		for i := range 500 {
+			s.add(NormalizedCommit{SHA: fmt.Sprint(i)})
+		}
+		shas := make([]string, len(s.commits))
+		for i, c := range s.commits {
+			shas[i] = c.SHA
+		}
+		return shas
+	}
+	if a, b := sample("octocat"), sample("OctoCat"); !slices.Equal(a, b) {
+		t.Errorf("repeat roasts sampled differently:\n%q\n%q", a, b)
+	}
+	if a, b := sample("octocat"), sample("hubot"); slices.Equal(a, b) {
+		t.Error("different users got the same sample")
+	}
+}
+
+// BenchmarkCommitRetention compares the heap left holding 10,000 fetched
+// commits when every full API payload is kept against normalizing each into
+// the capped sampler as it arrives.
+func BenchmarkCommitRetention(b *testing.B) {
+	const commits = 10_000
+	retained := func(b *testing.B, keep func() any) {
+		var before, after runtime.MemStats
+		var held any
+		for b.Loop() {
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			held = keep()
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+		}
+		b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "retained-B")
+		runtime.KeepAlive(held)
+	}
+
+	b.Run("full payloads", func(b *testing.B) {
+		retained(b, func() any {
+			var all []*github.RepositoryCommit
+			for i := range commits {
+				all = append(all, syntheticCommit(i))
+			}
+			return all
+		})
+	})
+	b.Run("sampled", func(b *testing.B) {
+		retained(b, func() any {
+			s := newCommitSampler("octocat", defaultMaxCommits)
+			for i := range commits {
+				s.add(normalizeCommit("project", syntheticCommit(i)))
+			}
+			return s.commits
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"sort"
+	"unicode"
+)
+
+// maxReportedScripts is how many scripts detectCommitLanguages returns.
+const maxReportedScripts = 3
+
+// commitScript returns the Unicode script of the first non-ASCII letter in
+// msg, or "Latin" when the message is plain ASCII. Runes shared between
+// scripts (punctuation, emoji) are skipped.
+func commitScript(msg string) string {
+	for _, r := range msg {
+		if r <= unicode.MaxASCII {
+			continue
+		}
+		for name, table := range unicode.Scripts {
+			if name == "Common" || name == "Inherited" {
+				continue
+			}
+			if unicode.Is(table, r) {
+				return name
+			}
+		}
+	}
+	return "Latin"
+}
+
+// scriptCounts counts commits per detected script.
+func scriptCounts(commits []NormalizedCommit) map[string]int {
+	counts := make(map[string]int)
+	for _, commit := range commits {
+		counts[commitScript(commit.Message)]++
+	}
+	return counts
+}
+
+// detectCommitLanguages returns up to maxReportedScripts scripts used in
+// commit messages, most common first.
+func detectCommitLanguages(commits []NormalizedCommit) []string {
+	return topScripts(scriptCounts(commits))
+}
+
+// topScripts returns up to maxReportedScripts scripts from counts, most
+// common first.
+func topScripts(counts map[string]int) []string {
+	scripts := make([]string, 0, len(counts))
+	for script := range counts {
+		scripts = append(scripts, script)
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		if counts[scripts[i]] != counts[scripts[j]] {
+			return counts[scripts[i]] > counts[scripts[j]]
+		}
+		return scripts[i] < scripts[j]
+	})
+
+	if len(scripts) > maxReportedScripts {
+		scripts = scripts[:maxReportedScripts]
+	}
+	return scripts
+}
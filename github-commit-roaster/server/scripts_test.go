@@ -0,0 +1,150 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// findRule returns the roast rule with id.
+func findRule(t *testing.T, id string) roastRule {
+	t.Helper()
+	for _, rule := range roastRules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	t.Fatalf("no rule %q", id)
+	return roastRule{}
+}
+
+// messages wraps commit messages in otherwise empty commits.
+func messages(msgs ...string) []NormalizedCommit {
+	commits := make([]NormalizedCommit, len(msgs))
+	for i, msg := range msgs {
+		commits[i] = NormalizedCommit{Repo: "repo", Message: msg}
+	}
+	return commits
+}
+
+func TestCommitScript(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"fix the build", "Latin"},
+		{"", "Latin"},
+		{"café au lait", "Latin"},
+		{"修复登录错误", "Han"},
+		{"fix: 修复登录错误", "Han"},
+		{"バグを修正", "Katakana"},
+		{"ログインのバグ", "Katakana"},
+		{"исправить сборку", "Cyrillic"},
+		{"إصلاح الخطأ", "Arabic"},
+		{"버그 수정", "Hangul"},
+		{"ship it 🚀", "Latin"},
+		{"wip — “final” version", "Latin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := commitScript(tt.msg); got != tt.want {
+				t.Errorf("commitScript(%q) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopScripts(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[string]int
+		want   []string
+	}{
+		{"empty", map[string]int{}, []string{}},
+		{"by count", map[string]int{"Latin": 2, "Han": 5, "Cyrillic": 1}, []string{"Han", "Latin", "Cyrillic"}},
+		{"capped", map[string]int{"Latin": 4, "Han": 3, "Cyrillic": 2, "Arabic": 1}, []string{"Latin", "Han", "Cyrillic"}},
+		{"ties alphabetical", map[string]int{"Latin": 1, "Han": 1, "Arabic": 1, "Greek": 1}, []string{"Arabic", "Greek", "Han"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topScripts(tt.counts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("topScripts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCommitLanguages(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []NormalizedCommit
+		want    []string
+	}{
+		{"no commits", nil, []string{}},
+		{"ascii only", messages("fix bug", "add tests", "update readme"), []string{"Latin"}},
+		{
+			"CJK heavy",
+			messages("修复登录错误", "添加测试", "更新文档", "ログインのバグ", "fix typo"),
+			[]string{"Han", "Katakana", "Latin"},
+		},
+		{
+			"ascii with a little CJK",
+			messages("fix bug", "add tests", "update readme", "修复登录错误"),
+			[]string{"Latin", "Han"},
+		},
+		{
+			"four scripts, top three",
+			messages("修复登录错误", "添加测试", "fix bug", "fix tests", "исправить сборку", "إصلاح الخطأ", "إضافة اختبارات", "更新文档"),
+			[]string{"Han", "Arabic", "Latin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCommitLanguages(tt.commits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectCommitLanguages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCommitsScripts(t *testing.T) {
+	tests := []struct {
+		name      string
+		commits   []NormalizedCommit
+		primary   string
+		count     int
+		triggered bool
+	}{
+		{
+			"ascii only",
+			messages("fix bug", "add tests", "update readme"),
+			"Latin", 1, false,
+		},
+		{
+			"cjk heavy",
+			messages("修复登录错误", "添加测试", "更新文档", "fix typo"),
+			"Han", 2, false,
+		},
+		{
+			"three scripts",
+			messages("修复登录错误", "添加测试", "исправить сборку", "fix typo"),
+			"Han", 3, true,
+		},
+		{
+			"four scripts",
+			messages("버그 수정", "修复", "исправить", "fix", "fix again"),
+			"Latin", 4, true,
+		},
+	}
+	rule := findRule(t, "mixed_scripts")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := analyzeCommits(tt.commits, loadRoastConfig())
+			if stats.PrimaryScript != tt.primary || stats.ScriptCount != tt.count {
+				t.Errorf("primary %q and %d scripts, want %q and %d", stats.PrimaryScript, stats.ScriptCount, tt.primary, tt.count)
+			}
+			if got := rule.Triggered(stats); got != tt.triggered {
+				t.Errorf("mixed_scripts triggered = %v, want %v", got, tt.triggered)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serverStats aggregates the roasts served since startup for GET /stats.
+type serverStats struct {
+	startedAt time.Time
+
+	mu          sync.Mutex
+	roasts      int
+	severitySum int
+	triggered   map[string]int // rule ID -> times fired
+
+	// Cache warming: completed prefetches, prefetched entries that were
+	// later read, and background refreshes of hot entries
+	prefetches   atomic.Int64
+	prefetchHits atomic.Int64
+	refreshes    atomic.Int64
+}
+
+func newServerStats() *serverStats {
+	return &serverStats{startedAt: time.Now().UTC(), triggered: make(map[string]int)}
+}
+
+// recordRoast counts one served roast.
+func (s *serverStats) recordRoast(stats CommitStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roasts++
+	s.severitySum += stats.Severity
+	for _, id := range triggeredRules(stats) {
+		s.triggered[id]++
+	}
+}
+
+// handleStats serves GET /stats.
+func (s *serverStats) handleStats(c *gin.Context) {
+	s.mu.Lock()
+	roasts := s.roasts
+	average := 0.0
+	if roasts > 0 {
+		average = float64(s.severitySum) / float64(roasts)
+	}
+	top, topCount := "", 0
+	for id, count := range s.triggered {
+		if count > topCount || (count == topCount && id < top) {
+			top, topCount = id, count
+		}
+	}
+	s.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"started_at":             s.startedAt.Format(time.RFC3339),
+		"total_roasts":           roasts,
+		"average_severity":       average,
+		"most_common_roast":      top,
+		"most_common_roast_hits": topCount,
+		"github_api_calls":       githubCalls.Load(),
+		"prefetch": gin.H{
+			"completed":      s.prefetches.Load(),
+			"converted_hits": s.prefetchHits.Load(),
+			"refreshes":      s.refreshes.Load(),
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type statsResponse struct {
+	TotalRoasts         int     `json:"total_roasts"`
+	AverageSeverity     float64 `json:"average_severity"`
+	MostCommonRoast     string  `json:"most_common_roast"`
+	MostCommonRoastHits int     `json:"most_common_roast_hits"`
+	GitHubAPICalls      int64   `json:"github_api_calls"`
+}
+
+func getStats(t *testing.T, s *serverStats) statsResponse {
+	t.Helper()
+	w := doRequest(s.handleStats, http.MethodGet, "/stats", "/stats", "")
+	var got statsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding /stats: %v", err)
+	}
+	return got
+}
+
+func TestServerStatsCounters(t *testing.T) {
+	s := newServerStats()
+	if got := getStats(t, s); got.TotalRoasts != 0 || got.AverageSeverity != 0 || got.MostCommonRoast != "" {
+		t.Errorf("fresh stats = %+v", got)
+	}
+
+	s.recordRoast(CommitStats{TotalCommits: 10, FixCommits: 8, Severity: 40})
+	s.recordRoast(CommitStats{TotalCommits: 10, FixCommits: 8, LateNightCommits: 8, Severity: 80})
+	s.recordRoast(CommitStats{TotalCommits: 10, Severity: 0})
+
+	got := getStats(t, s)
+	if got.TotalRoasts != 3 || got.AverageSeverity != 40 {
+		t.Errorf("total %d, average %v; want 3 and 40", got.TotalRoasts, got.AverageSeverity)
+	}
+	if s.triggered["fixes"] != 2 || s.triggered["late_night"] != 1 {
+		t.Errorf("fixes fired %d times and late_night %d, want 2 and 1", s.triggered["fixes"], s.triggered["late_night"])
+	}
+	for id, hits := range s.triggered {
+		if hits > got.MostCommonRoastHits || (hits == got.MostCommonRoastHits && id < got.MostCommonRoast) {
+			t.Errorf("most common roast %q (%d hits), but %q has %d", got.MostCommonRoast, got.MostCommonRoastHits, id, hits)
+		}
+	}
+}
+
+func TestServerStatsCountRoastsServed(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	s := newTestServer(t, gh)
+	before := githubCalls.Load()
+
+	for _, days := range []string{"7", "30", "90"} {
+		w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat&days="+days, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body)
+		}
+	}
+	got := getStats(t, s.stats)
+	if got.TotalRoasts != 3 {
+		t.Errorf("total_roasts = %d, want 3", got.TotalRoasts)
+	}
+	if got.GitHubAPICalls-before < int64(gh.callCount("/repos/octocat/project/commits")) || gh.callCount("/repos/octocat/project/commits") == 0 {
+		t.Errorf("github_api_calls grew by %d", got.GitHubAPICalls-before)
+	}
+}
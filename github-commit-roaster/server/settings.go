@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// defaultConfigFile is read when CONFIG_FILE isn't set, if it exists.
+const defaultConfigFile = "config.yaml"
+
+// Where a setting's value came from, for the startup debug log.
+const (
+	sourceEnv     = "env"
+	sourceFile    = "file"
+	sourceDefault = "default"
+)
+
+// settings layers environment variables over an optional config file.
+// The file uses the env var names in lowercase kebab-case, so GITHUB_TOKEN
+// is github-token; anything in neither falls back to the coded default.
+var settings = struct {
+	file *viper.Viper // nil without a config file
+
+	mu      sync.Mutex
+	sources map[string]string // env var name -> source of the value used
+}{sources: make(map[string]string)}
+
+// loadConfigFile reads path, else CONFIG_FILE, else ./config.yaml when it
+// exists, and validates it; a file with unknown or malformed settings is an
+// error rather than something to half-apply.
+func loadConfigFile(path string) error {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		if _, err := os.Stat(defaultConfigFile); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		path = defaultConfigFile
+	}
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		return err
+	}
+	if err := validateConfigFile(file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	settings.file = file
+	return nil
+}
+
+// Kinds of setting values, checked when the config file is loaded.
+const (
+	kindString = iota
+	kindInt
+	kindFloat
+	kindDuration
+)
+
+// knownSettings lists every setting the config file may hold, by env var
+// name, with the kind of value it takes.
+var knownSettings = map[string]int{
+	"PORT":                        kindString,
+	"LANGUAGE_ROASTS_FILE":        kindString,
+	"GITHUB_TOKEN":                kindString,
+	"GITHUB_USER_AGENT":           kindString,
+	"GITHUB_API_URL":              kindString,
+	"GITHUB_MAX_RETRY_WAIT":       kindDuration,
+	"GITHUB_API_TIMEOUT":          kindDuration,
+	"GITHUB_APP_ID":               kindInt,
+	"GITHUB_APP_INSTALLATION_ID":  kindInt,
+	"GITHUB_APP_PRIVATE_KEY_PATH": kindString,
+	"GITHUB_OAUTH_CLIENT_ID":      kindString,
+	"GITHUB_OAUTH_CLIENT_SECRET":  kindString,
+	"GITHUB_OAUTH_REDIRECT_URL":   kindString,
+	"OAUTH_SUCCESS_REDIRECT":      kindString,
+	"SESSION_SECRET":              kindString,
+	"SQLITE_PATH":                 kindString,
+	"REDIS_URL":                   kindString,
+	"ROAST_CACHE_TTL":             kindDuration,
+	"CACHE_TTL_SECONDS":           kindInt,
+	"ANON_DAILY_QUOTA":            kindInt,
+	"ANON_RATE_PER_MINUTE":        kindInt,
+	"DEFAULT_KEY_DAILY_QUOTA":     kindInt,
+	"DEFAULT_KEY_RATE_PER_MINUTE": kindInt,
+	"ADMIN_TOKEN":                 kindString,
+	"PREFETCH_CONCURRENCY":        kindInt,
+	"PREFETCH_HOT_HITS":           kindInt,
+	"FEATURED_USERS":              kindString,
+	"FEATURED_HOUR":               kindInt,
+	"GITHUB_WEBHOOK_SECRET":       kindString,
+	"WEBHOOK_COMMENT":             kindString,
+	"HTTP_READ_TIMEOUT":           kindDuration,
+	"HTTP_READ_HEADER_TIMEOUT":    kindDuration,
+	"HTTP_WRITE_TIMEOUT":          kindDuration,
+	"HTTP_IDLE_TIMEOUT":           kindDuration,
+	"MAX_COMMITS":                 kindInt,
+	"MAX_ROAST_LINES":             kindInt,
+	"SHOUT_UPPERCASE_RATIO":       kindFloat,
+	"STALE_REPO_DAYS":             kindInt,
+	"DEAD_REPO_DAYS":              kindInt,
+	"LATE_NIGHT_START":            kindInt,
+	"LATE_NIGHT_END":              kindInt,
+	"WORK_HOURS_START":            kindInt,
+	"WORK_HOURS_END":              kindInt,
+	"BOT_PATTERNS":                kindString,
+	"GITIGNORE_PATTERNS":          kindString,
+	"STOPWORDS":                   kindString,
+	"ROAST_WEIGHTS":               kindString,
+	"LANG_PROFANITY":              kindString,
+	"PROFANITY_DIR":               kindString,
+	"NO_COLOR":                    kindString,
+}
+
+// validateConfigFile reports every key that isn't a known setting and
+// every value that doesn't parse as its setting's kind.
+func validateConfigFile(file *viper.Viper) error {
+	names := make(map[string]string, len(knownSettings))
+	for name := range knownSettings {
+		names[configKey(name)] = name
+	}
+	keys := file.AllKeys()
+	sort.Strings(keys)
+	var errs []error
+	for _, key := range keys {
+		name, ok := names[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown setting %q", key))
+			continue
+		}
+		value := file.GetString(key)
+		var err error
+		switch knownSettings[name] {
+		case kindInt:
+			_, err = strconv.Atoi(value)
+		case kindFloat:
+			_, err = strconv.ParseFloat(value, 64)
+		case kindDuration:
+			_, err = time.ParseDuration(value)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q", key, value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// configKey is the config file key for an env var name.
+func configKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// getenv returns the setting name from the environment, then the config
+// file, or "" so the caller's default applies. Lists in the file are
+// joined with commas, like their env var form.
+func getenv(name string) string {
+	value, source := os.Getenv(name), sourceEnv
+	if value == "" {
+		value, source = fileValue(name), sourceFile
+	}
+	if value == "" {
+		source = sourceDefault
+	}
+	settings.mu.Lock()
+	settings.sources[name] = source
+	settings.mu.Unlock()
+	return value
+}
+
+func fileValue(name string) string {
+	if settings.file == nil || !settings.file.IsSet(configKey(name)) {
+		return ""
+	}
+	if list, ok := settings.file.Get(configKey(name)).([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return settings.file.GetString(configKey(name))
+}
+
+// logConfigSources logs where every setting read so far came from when
+// LOG_LEVEL=debug.
+func logConfigSources() {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	settings.mu.Lock()
+	defer settings.mu.Unlock()
+	names := make([]string, 0, len(settings.sources))
+	for name := range settings.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		slog.Debug("config", "setting", name, "key", configKey(name), "source", settings.sources[name])
+	}
+}
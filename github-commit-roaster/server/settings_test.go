@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useConfigFile loads contents as the config file for the rest of the test.
+func useConfigFile(t *testing.T, contents string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { settings.file = nil })
+	if err := loadConfigFile(path); err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+}
+
+// sourceOf is where the last read of the setting name came from.
+func sourceOf(name string) string {
+	settings.mu.Lock()
+	defer settings.mu.Unlock()
+	return settings.sources[name]
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		env    string
+		want   time.Duration
+		source string
+	}{
+		{"default", "", "", 10 * time.Minute, sourceDefault},
+		{"file", "cache-ttl-seconds: 300\n", "", 300 * time.Second, sourceFile},
+		{"env beats file", "cache-ttl-seconds: 300\n", "60", 60 * time.Second, sourceEnv},
+		{"env alone", "", "60", 60 * time.Second, sourceEnv},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfigFile(t, tt.file)
+			t.Setenv("CACHE_TTL_SECONDS", tt.env)
+			if got := loadConfig().CacheTTL; got != tt.want {
+				t.Errorf("CacheTTL = %s, want %s", got, tt.want)
+			}
+			if got := sourceOf("CACHE_TTL_SECONDS"); got != tt.source {
+				t.Errorf("CACHE_TTL_SECONDS came from %s, want %s", got, tt.source)
+			}
+		})
+	}
+}
+
+func TestConfigFileValues(t *testing.T) {
+	useConfigFile(t, "port: \"9090\"\nbot-patterns:\n  - renovate\n  - snyk\nhttp-write-timeout: 90s\n")
+	cfg := loadConfig()
+	if cfg.Port != "9090" {
+		t.Errorf("Port = %q, want 9090", cfg.Port)
+	}
+	if want := []string{"renovate", "snyk"}; !slices.Equal(cfg.Roast.BotPatterns, want) {
+		t.Errorf("BotPatterns = %q, want %q", cfg.Roast.BotPatterns, want)
+	}
+	if cfg.HTTPWriteTimeout != 90*time.Second {
+		t.Errorf("HTTPWriteTimeout = %s, want 90s", cfg.HTTPWriteTimeout)
+	}
+}
+
+func TestLoadConfigFileValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		errs     []string // every problem is reported, not just the first
+	}{
+		{"valid", "port: \"9090\"\nmax-commits: 50\nshout-uppercase-ratio: 0.5\ngithub-api-timeout: 5s\n", nil},
+		{"unknown setting", "prot: \"9090\"\n", []string{`unknown setting "prot"`}},
+		{"bad int", "max-commits: lots\n", []string{`invalid max-commits "lots"`}},
+		{"bad float", "shout-uppercase-ratio: loud\n", []string{`invalid shout-uppercase-ratio "loud"`}},
+		{"bad duration", "github-api-timeout: 5\n", []string{`invalid github-api-timeout "5"`}},
+		{"several problems", "max-commits: lots\ncolour: red\n", []string{`unknown setting "colour"`, `invalid max-commits "lots"`}},
+		{"malformed YAML", "port: [9090\n", []string{"yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { settings.file = nil })
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := loadConfigFile(path)
+			if len(tt.errs) == 0 {
+				if err != nil || settings.file == nil {
+					t.Fatalf("loadConfigFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("loadConfigFile() accepted a bad file")
+			}
+			for _, want := range tt.errs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q doesn't mention %q", err, want)
+				}
+			}
+			if settings.file != nil {
+				t.Error("a bad file was half-applied")
+			}
+		})
+	}
+}
+
+func TestLoadConfigFilePath(t *testing.T) {
+	t.Cleanup(func() { settings.file = nil })
+	dir := t.TempDir()
+	fromEnv := filepath.Join(dir, "env.yaml")
+	flagged := filepath.Join(dir, "flag.yaml")
+	for path, port := range map[string]string{fromEnv: "7000", flagged: "8000"} {
+		if err := os.WriteFile(path, []byte("port: \""+port+"\"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("CONFIG_FILE", fromEnv)
+	t.Setenv("PORT", "")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"CONFIG_FILE without --config", "", "7000"},
+		{"--config beats CONFIG_FILE", flagged, "8000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := loadConfigFile(tt.path); err != nil {
+				t.Fatal(err)
+			}
+			if got := getenv("PORT"); got != tt.want {
+				t.Errorf("PORT = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "missing.yaml"))
+	if err := loadConfigFile(""); err == nil {
+		t.Error("a missing CONFIG_FILE was ignored")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// defaultShoutRatio is the share of upper-case letters that makes a
+	// subject line shouting.
+	defaultShoutRatio = 0.7
+	// minShoutLength is the shortest subject that can count as shouting.
+	minShoutLength = 6
+	// Words this short are often acronyms (API, HTTP, JSON), so they don't
+	// make a subject shouting on their own.
+	maxAcronymLength = 4
+)
+
+// ShoutingStats counts commit subjects written in ALL CAPS.
+type ShoutingStats struct {
+	ShoutingCommits int      `json:"shouting_commits"`
+	ShoutingRatio   float64  `json:"shouting_ratio"`
+	Examples        []string `json:"examples"`
+}
+
+// isShouting reports whether a subject line is written in capitals. Besides
+// the upper-case share, at least one capitalized word must be longer than
+// an acronym, so "API FIX" is left alone but "FIX THE BUILD AGAIN" isn't.
+func isShouting(subject string, ratio float64) bool {
+	if len([]rune(strings.TrimSpace(subject))) < minShoutLength {
+		return false
+	}
+	upper, letters := 0, 0
+	for _, r := range subject {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters == 0 || float64(upper)/float64(letters) <= ratio {
+		return false
+	}
+	for _, word := range strings.FieldsFunc(subject, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(word)) > maxAcronymLength && strings.ToUpper(word) == word {
+			return true
+		}
+	}
+	return false
+}
+
+// detectShouting counts commits whose subject line is shouting.
+func detectShouting(commits []NormalizedCommit, ratio float64) ShoutingStats {
+	stats := ShoutingStats{Examples: []string{}}
+	for _, commit := range commits {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		if !isShouting(subject, ratio) {
+			continue
+		}
+		stats.ShoutingCommits++
+		if len(stats.Examples) < 3 {
+			stats.Examples = append(stats.Examples, subject)
+		}
+	}
+	stats.ShoutingRatio = share(stats.ShoutingCommits, len(commits))
+	return stats
+}
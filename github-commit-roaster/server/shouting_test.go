@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsShouting(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    bool
+	}{
+		{"FIX THE BUILD AGAIN", true},
+		{"WHY DOES NOTHING WORK", true},
+		{"WTF!!!!!!", false}, // too few letters to be more than an acronym
+		{"API FIX", false},
+		{"HTTP API JSON FIX", false},
+		{"Fix API auth", false},
+		{"fix the build", false},
+		{"UPDATE", true},
+		{"FIXED", false}, // five characters is too short
+		{"FIX", false},   // too short
+		{"REFACTOR the parser", false},
+		{"MERGE BRANCH main", true},
+		{"ÉCHEC DU DÉPLOIEMENT", true},
+		{"1234567", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isShouting(tt.subject, defaultShoutRatio); got != tt.want {
+			t.Errorf("isShouting(%q) = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestDetectShouting(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		ratio    float64
+		want     ShoutingStats
+	}{
+		{"no commits", nil, defaultShoutRatio, ShoutingStats{Examples: []string{}}},
+		{
+			"subjects only",
+			[]string{"FIX THE BUILD\n\nit was broken", "add parser\n\nTHIS BODY IS LOUD", "API FIX", "WHY DOES NOTHING WORK"},
+			defaultShoutRatio,
+			ShoutingStats{ShoutingCommits: 2, ShoutingRatio: 0.5, Examples: []string{"FIX THE BUILD", "WHY DOES NOTHING WORK"}},
+		},
+		{
+			"a stricter ratio",
+			[]string{"FIX THE BUILD for real", "FIX THE BUILD"},
+			0.9,
+			ShoutingStats{ShoutingCommits: 1, ShoutingRatio: 0.5, Examples: []string{"FIX THE BUILD"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectShouting(messages(tt.messages...), tt.ratio); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectShouting() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shutdownTimeout is how long in-flight requests get to finish on SIGTERM.
+const shutdownTimeout = 30 * time.Second
+
+// requestTracker counts requests so shutdown can report how draining went.
+type requestTracker struct {
+	inFlight  atomic.Int64
+	completed atomic.Int64
+}
+
+func (t *requestTracker) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t.inFlight.Add(1)
+		defer func() {
+			t.inFlight.Add(-1)
+			t.completed.Add(1)
+		}()
+		c.Next()
+	}
+}
+
+// backgroundJobs tracks the server's background loops so shutdown can wait
+// for them before closing what they write to.
+type backgroundJobs struct {
+	wg sync.WaitGroup
+}
+
+// start runs job in its own goroutine.
+func (b *backgroundJobs) start(job func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		job()
+	}()
+}
+
+// wait returns once every started job has returned.
+func (b *backgroundJobs) wait() {
+	b.wg.Wait()
+}
+
+// serve runs server until ctx is done, then stops accepting connections and
+// waits up to shutdownTimeout for in-flight requests to finish. stop
+// unregisters the signals behind ctx as draining starts, so a second
+// SIGTERM or SIGINT kills the process instead of waiting out the drain.
+func serve(ctx context.Context, stop context.CancelFunc, server *http.Server, requests *requestTracker) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	draining, before := requests.inFlight.Load(), requests.completed.Load()
+	slog.Info("Shutting down", "draining", draining)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(shutdownCtx)
+	slog.Info("Shutdown complete",
+		"completed", requests.completed.Load()-before, "dropped", requests.inFlight.Load())
+	if errors.Is(err, context.DeadlineExceeded) {
+		server.Close()
+	}
+	return err
+}
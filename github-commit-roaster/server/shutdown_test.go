@@ -0,0 +1,119 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServeDrainsOnSIGTERM(t *testing.T) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+
+	// Grab a free port for serve to listen on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	requests := &requestTracker{}
+	started := make(chan struct{})
+	r := gin.New()
+	r.Use(requests.middleware())
+	r.GET("/slow", func(c *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+
+	served := make(chan error, 1)
+	// serve lets go of the signal as draining starts, for a second one to kill
+	var released atomic.Bool
+	release := func() {
+		released.Store(true)
+		stop()
+	}
+	go func() { served <- serve(ctx, release, &http.Server{Addr: addr, Handler: r}, requests) }()
+	eventually(t, "the server to listen", func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	})
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{body: string(body), err: err}
+	}()
+	<-started
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("serve() = %v, want a clean shutdown", err)
+		}
+		if !released.Load() {
+			t.Error("serve() kept the SIGTERM handler registered while draining")
+		}
+		// The in-flight request finished before serve let go
+		if n := requests.completed.Load(); n != 1 || requests.inFlight.Load() != 0 {
+			t.Errorf("on exit: %d completed, %d in flight", n, requests.inFlight.Load())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve() didn't return after SIGTERM")
+	}
+	res := <-responses
+	if res.err != nil || res.body != "done" {
+		t.Fatalf("in-flight request got %q, %v", res.body, res.err)
+	}
+	if _, err := http.Get("http://" + addr + "/slow"); err == nil {
+		t.Error("server still accepting requests after shutdown")
+	}
+}
+
+func TestBackgroundJobsWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var jobs backgroundJobs
+	var finished atomic.Int32
+	for range 3 {
+		jobs.start(func() {
+			<-ctx.Done()
+			// A job still writing when its context ends
+			time.Sleep(20 * time.Millisecond)
+			finished.Add(1)
+		})
+	}
+	cancel()
+	jobs.wait()
+	if n := finished.Load(); n != 3 {
+		t.Errorf("wait() returned with %d of 3 jobs finished", n)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// fetchGroup coalesces concurrent identical analyses, so a username that
+// suddenly gets popular costs one set of GitHub calls rather than one per
+// request.
+type fetchGroup struct {
+	group singleflight.Group
+
+	mu      sync.Mutex
+	callers map[string]int // key -> requests waiting on the in-flight fetch
+}
+
+func newFetchGroup() *fetchGroup {
+	return &fetchGroup{callers: make(map[string]int)}
+}
+
+// flightResult is what the leader of a flight hands to everyone waiting.
+type flightResult struct {
+	analysis  *analysis
+	groupSize int
+}
+
+// fetchKey identifies analyses that would come out identical: the user
+// plus every option that changes what is fetched or computed.
+func fetchKey(username string, opts RoastOptions) string {
+	since, until := opts.Since.Format(time.RFC3339), opts.Until.Format(time.RFC3339)
+	loc := ""
+	if opts.Location != nil {
+		loc = opts.Location.String()
+	}
+	return fmt.Sprintf("github:%s:%d:%s:%s:%t:%t:%t:%t:%t:%t:%t:%s:%v",
+		strings.ToLower(username), opts.Days, since, until,
+		opts.Languages, opts.Deep, opts.DeepScan, opts.FollowThrough, opts.Timeline, opts.Words, opts.SpellCheck,
+		loc, map[string]int(opts.Weights))
+}
+
+// do runs fetch once per key at a time. It returns a private copy of the
+// analysis, whether it was shared with other requests and how many
+// requests shared it.
+func (f *fetchGroup) do(key string, fetch func() (*analysis, error)) (*analysis, bool, int, error) {
+	f.mu.Lock()
+	f.callers[key]++
+	f.mu.Unlock()
+
+	value, err, shared := f.group.Do(key, func() (interface{}, error) {
+		result, err := fetch()
+		f.mu.Lock()
+		size := f.callers[key]
+		delete(f.callers, key)
+		f.mu.Unlock()
+		return flightResult{analysis: result, groupSize: size}, err
+	})
+	if err != nil {
+		return nil, shared, 0, err
+	}
+	flight := value.(flightResult)
+	result := *flight.analysis
+	result.stats.CoalescedRequest = shared
+	return &result, shared, flight.groupSize, nil
+}
+
+// sharedFetch runs fetchAnalysis through the fetch group. The fetch is
+// detached from the request's cancellation since other requests may be
+// waiting on it, but still gets the GitHub timeout.
+func (s *server) sharedFetch(ctx context.Context, client *ghclient.Client, authMode, username string, opts RoastOptions) (*analysis, error) {
+	result, _, size, err := s.flights.do(fetchKey(username, opts), func() (*analysis, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.githubTimeout)
+		defer cancel()
+		return s.fetchAnalysis(fetchCtx, client, authMode, username, opts, false)
+	})
+	if result != nil {
+		result.groupSize = size
+	}
+	return result, err
+}
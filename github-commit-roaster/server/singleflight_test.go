@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// waiting is how many requests are queued on in-flight fetches.
+func (f *fetchGroup) waiting() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, callers := range f.callers {
+		n += callers
+	}
+	return n
+}
+
+func TestRoastCoalescesConcurrentRequests(t *testing.T) {
+	const requests = 20
+	gh := newFakeGitHub("torvalds", fakeCommit("Linus", "fix scheduler", 2))
+	gate := make(chan struct{})
+	release := sync.OnceFunc(func() { close(gate) })
+	t.Cleanup(release)
+	s := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-gate
+		gh.ServeHTTP(w, r)
+	}))
+	r := gin.New()
+	r.GET("/roast", s.handleRoast)
+
+	responses := make([]*httptest.ResponseRecorder, requests)
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/roast?username=torvalds&debug=true", nil))
+			responses[i] = w
+		}()
+	}
+	// Hold GitHub back until every request has joined the flight
+	eventually(t, "all requests to join the flight", func() bool { return s.flights.waiting() == requests })
+	release()
+	wg.Wait()
+
+	for _, path := range []string{"/users/torvalds", "/users/torvalds/repos", "/repos/torvalds/project/commits"} {
+		if n := gh.callCount(path); n != 1 {
+			t.Errorf("%s called %d times, want 1", path, n)
+		}
+	}
+	for i, w := range responses {
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d: %s", i, w.Code, w.Body)
+		}
+		var body RoastResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		// The leader shared its fetch too
+		if !body.Stats.CoalescedRequest {
+			t.Errorf("request %d: coalesced_request = false", i)
+		}
+		if body.Debug == nil || body.Debug.SingleflightGroupSize != requests {
+			t.Errorf("request %d: debug = %+v, want a group of %d", i, body.Debug, requests)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/roast?username=torvalds&days=7&debug=true", nil))
+	var alone RoastResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &alone); err != nil {
+		t.Fatal(err)
+	}
+	if alone.Stats.CoalescedRequest || alone.Debug == nil || alone.Debug.SingleflightGroupSize != 1 {
+		t.Errorf("lone request: coalesced_request = %v, debug = %+v", alone.Stats.CoalescedRequest, alone.Debug)
+	}
+}
+
+func TestFetchKey(t *testing.T) {
+	base := RoastOptions{Days: 30}
+	tests := []struct {
+		name string
+		a, b RoastOptions
+		same bool
+	}{
+		{"identical", base, base, true},
+		{"intensity only changes wording", base, RoastOptions{Days: 30, Intensity: 5}, true},
+		{"different window", base, RoastOptions{Days: 7}, false},
+		{"deep", base, RoastOptions{Days: 30, Deep: true}, false},
+		{"range", base, RoastOptions{Since: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := fetchKey("Octocat", tt.a) == fetchKey("octocat", tt.b); same != tt.same {
+				t.Errorf("same key = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
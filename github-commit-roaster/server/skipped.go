@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Reasons a repo was left out of the analysis.
+const (
+	skipUnavailableLegal = "unavailable_legal" // 451, e.g. a DMCA takedown
+	skipDisabled         = "disabled"          // 403, disabled by GitHub (billing, ToS)
+)
+
+// SkippedRepo is a repo whose commits couldn't be read.
+type SkippedRepo struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// unavailableReason classifies errors from repos GitHub has blocked. It
+// returns "" for any other error.
+func unavailableReason(err error) string {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return ""
+	}
+	switch errResp.Response.StatusCode {
+	case http.StatusUnavailableForLegalReasons:
+		return skipUnavailableLegal
+	case http.StatusForbidden:
+		msg := strings.ToLower(errResp.Message)
+		if strings.Contains(msg, "disabled") || strings.Contains(msg, "access blocked") {
+			return skipDisabled
+		}
+	}
+	return ""
+}
+
+// hasSkipReason reports whether any skipped repo was skipped for reason.
+func hasSkipReason(skipped []SkippedRepo, reason string) bool {
+	for _, repo := range skipped {
+		if repo.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
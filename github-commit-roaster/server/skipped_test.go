@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// captureLog returns what fn logs through the default logger.
+func captureLog(t *testing.T, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(newLogger(&buf, slog.LevelDebug))
+	defer slog.SetDefault(defaultLogger)
+	fn()
+	return buf.String()
+}
+
+func githubErrorResponse(status int, message string) error {
+	return &github.ErrorResponse{Response: &http.Response{StatusCode: status}, Message: message}
+}
+
+func TestUnavailableReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"DMCA", githubErrorResponse(http.StatusUnavailableForLegalReasons, "Repository access blocked"), skipUnavailableLegal},
+		{"disabled", githubErrorResponse(http.StatusForbidden, "Repository access blocked"), skipDisabled},
+		{"disabled for billing", githubErrorResponse(http.StatusForbidden, "This repository has been disabled."), skipDisabled},
+		{"other 403", githubErrorResponse(http.StatusForbidden, "Resource not accessible by integration"), ""},
+		{"not found", githubErrorResponse(http.StatusNotFound, "Not Found"), ""},
+		{"network", errors.New("connection reset"), ""},
+		{"no response", &github.ErrorResponse{Message: "blocked"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unavailableReason(tt.err); got != tt.want {
+				t.Errorf("unavailableReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubErrorLegal(t *testing.T) {
+	status, body := githubError(githubErrorResponse(http.StatusUnavailableForLegalReasons, "Repository access blocked"))
+	if status != http.StatusUnavailableForLegalReasons || body["details"] == nil {
+		t.Errorf("githubError() = %d, %v", status, body)
+	}
+}
+
+func TestRoastSkipsBlockedRepos(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	gh.repos = append(gh.repos,
+		&github.Repository{Name: github.String("dmca")},
+		&github.Repository{Name: github.String("billing")},
+		&github.Repository{Name: github.String("suspended"), Disabled: github.Bool(true)},
+	)
+	gh.blocked = map[string]int{"dmca": http.StatusUnavailableForLegalReasons, "billing": http.StatusForbidden}
+	s := newTestServer(t, gh)
+
+	var w *httptest.ResponseRecorder
+	warnings := captureLog(t, func() {
+		w = doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat", "")
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	for _, want := range []string{
+		`level=WARN msg="Skipping repo blocked by GitHub" version=dev repo=octocat/dmca reason=` + skipUnavailableLegal,
+		`level=WARN msg="Skipping repo blocked by GitHub" version=dev repo=octocat/billing reason=` + skipDisabled,
+	} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("no %q in the log:\n%s", want, warnings)
+		}
+	}
+	var body RoastResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := []SkippedRepo{
+		{Name: "dmca", Reason: skipUnavailableLegal},
+		{Name: "billing", Reason: skipDisabled},
+		{Name: "suspended", Reason: skipDisabled},
+	}
+	if !reflect.DeepEqual(body.Stats.SkippedRepos, want) {
+		t.Errorf("skipped_repos = %+v, want %+v", body.Stats.SkippedRepos, want)
+	}
+	if gh.callCount("/repos/octocat/suspended/commits") != 0 {
+		t.Error("fetched commits of a repo GitHub reported disabled")
+	}
+	if body.Stats.TotalCommits != 1 {
+		t.Errorf("analyzed %d commits, want the 1 from the readable repo", body.Stats.TotalCommits)
+	}
+	if !findRule(t, "dmca").Triggered(body.Stats) {
+		t.Error("no roast line for the DMCA'd repo")
+	}
+}
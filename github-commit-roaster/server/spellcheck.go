@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"embed"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// maxReportedTypos is how many of the most repeated typos are reported.
+	maxReportedTypos = 3
+	// maxNonASCIIShare is the share of non-ASCII letters above which a
+	// subject is taken for another language and not checked.
+	maxNonASCIIShare = 0.1
+)
+
+// spellingFiles are the bundled misspelling table and the allowlist of
+// programming terms, so spell-checking never needs the network.
+//
+//go:embed spelling/*.txt
+var spellingFiles embed.FS
+
+// spellingTables are parsed from spellingFiles on first use.
+var spellingTables = sync.OnceValues(func() (map[string]string, map[string]bool) {
+	corrections := make(map[string]string)
+	for _, line := range embeddedLines("spelling/misspellings.txt") {
+		if typo, correction, ok := strings.Cut(line, "->"); ok {
+			corrections[typo] = correction
+		}
+	}
+	allowed := make(map[string]bool)
+	for _, line := range embeddedLines("spelling/allowlist.txt") {
+		allowed[line] = true
+	}
+	return corrections, allowed
+})
+
+// embeddedLines returns the trimmed, non-comment lines of an embedded file.
+func embeddedLines(name string) []string {
+	f, err := spellingFiles.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// SpellingStats reports known misspellings in commit subject lines. Only
+// computed with ?spellcheck=true.
+type SpellingStats struct {
+	CheckedSubjects    int     `json:"checked_subjects"`
+	SkippedSubjects    int     `json:"skipped_subjects"` // taken for another language
+	MisspelledSubjects int     `json:"misspelled_subjects"`
+	MisspellingRate    float64 `json:"misspelling_rate"` // of checked subjects
+	TopTypos           []Typo  `json:"top_typos"`
+}
+
+// Typo is a misspelling, how often it was made and what was meant.
+type Typo struct {
+	Word       string `json:"word"`
+	Suggestion string `json:"suggestion"`
+	Count      int    `json:"count"`
+}
+
+// mostlyASCII is the language heuristic: a subject whose letters are
+// almost all ASCII is checked as English; anything else, in another
+// script or heavy with accents, is skipped rather than flagged.
+func mostlyASCII(subject string) bool {
+	letters, nonASCII := 0, 0
+	for _, r := range subject {
+		if unicode.IsLetter(r) {
+			letters++
+			if r > unicode.MaxASCII {
+				nonASCII++
+			}
+		}
+	}
+	return letters > 0 && float64(nonASCII)/float64(letters) <= maxNonASCIIShare
+}
+
+// detectTypos checks every commit subject against the misspelling table.
+func detectTypos(commits []NormalizedCommit) SpellingStats {
+	corrections, allowed := spellingTables()
+	stats := SpellingStats{TopTypos: []Typo{}}
+	counts := make(map[string]int)
+	for _, commit := range commits {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		if !mostlyASCII(subject) {
+			stats.SkippedSubjects++
+			continue
+		}
+		stats.CheckedSubjects++
+		misspelled := false
+		for _, word := range messageWords(subject) {
+			if _, ok := corrections[word]; ok && !allowed[word] {
+				counts[word]++
+				misspelled = true
+			}
+		}
+		if misspelled {
+			stats.MisspelledSubjects++
+		}
+	}
+	stats.MisspellingRate = share(stats.MisspelledSubjects, stats.CheckedSubjects)
+
+	for word, count := range counts {
+		stats.TopTypos = append(stats.TopTypos, Typo{Word: word, Suggestion: corrections[word], Count: count})
+	}
+	sort.Slice(stats.TopTypos, func(i, j int) bool {
+		if stats.TopTypos[i].Count != stats.TopTypos[j].Count {
+			return stats.TopTypos[i].Count > stats.TopTypos[j].Count
+		}
+		return stats.TopTypos[i].Word < stats.TopTypos[j].Word
+	})
+	if len(stats.TopTypos) > maxReportedTypos {
+		stats.TopTypos = stats.TopTypos[:maxReportedTypos]
+	}
+	return stats
+}
+
+// quotedTypos lists the top typos for a roast line: 'recieve', 'seperate'.
+func (s SpellingStats) quotedTypos() string {
+	quoted := make([]string, len(s.TopTypos))
+	for i, typo := range s.TopTypos {
+		quoted[i] = "'" + typo.Word + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
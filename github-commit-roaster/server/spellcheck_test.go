@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpellingTables(t *testing.T) {
+	corrections, allowed := spellingTables()
+	if corrections["recieve"] != "receive" || corrections["teh"] != "the" {
+		t.Errorf("misspelling table not loaded: %d entries", len(corrections))
+	}
+	for _, word := range []string{"refactor", "async", "middleware"} {
+		if !allowed[word] {
+			t.Errorf("%q isn't allowed", word)
+		}
+	}
+	for word, correction := range corrections {
+		if word == "" || correction == "" || word == correction || strings.ContainsAny(word, " \t#") {
+			t.Errorf("bad table entry %q -> %q", word, correction)
+		}
+	}
+}
+
+func TestMostlyASCII(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    bool
+	}{
+		{"fix the parser", true},
+		{"update café menu handling", true}, // one accent in plenty of letters
+		{"Füge Größenprüfung hinzu", false},
+		{"修复登录问题", false},
+		{"исправил сборку", false},
+		{"1234 !!", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := mostlyASCII(tt.subject); got != tt.want {
+			t.Errorf("mostlyASCII(%q) = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestDetectTypos(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     SpellingStats
+	}{
+		{"no commits", nil, SpellingStats{TopTypos: []Typo{}}},
+		{
+			"clean subjects and allowed terms",
+			[]string{"refactor async middleware", "fix the parser"},
+			SpellingStats{CheckedSubjects: 2, TopTypos: []Typo{}},
+		},
+		{
+			"subjects only",
+			[]string{"fix receive\n\nrecieve was wrong", "Recieve teh data", "seperate teh config, recieve"},
+			SpellingStats{
+				CheckedSubjects: 3, MisspelledSubjects: 2, MisspellingRate: 2.0 / 3,
+				TopTypos: []Typo{{"recieve", "receive", 2}, {"teh", "the", 2}, {"seperate", "separate", 1}},
+			},
+		},
+		{
+			"top three by count then word",
+			[]string{"teh", "teh", "lenght", "fucntion", "definately"},
+			SpellingStats{
+				CheckedSubjects: 5, MisspelledSubjects: 5, MisspellingRate: 1,
+				TopTypos: []Typo{{"teh", "the", 2}, {"definately", "definitely", 1}, {"fucntion", "function", 1}},
+			},
+		},
+		{
+			"other languages skipped",
+			[]string{"исправил сборку", "Füge Größenprüfung hinzu", "recieve data"},
+			SpellingStats{
+				CheckedSubjects: 1, SkippedSubjects: 2, MisspelledSubjects: 1, MisspellingRate: 1,
+				TopTypos: []Typo{{"recieve", "receive", 1}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectTypos(messages(tt.messages...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectTypos() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTyposRule(t *testing.T) {
+	rule := findRule(t, "typos")
+	typos := []Typo{{"recieve", "receive", 3}, {"seperate", "separate", 2}, {"definately", "definitely", 1}}
+	tests := []struct {
+		name     string
+		spelling *SpellingStats
+		want     bool
+	}{
+		{"spellcheck off", nil, false},
+		{"too few subjects", &SpellingStats{CheckedSubjects: 4, MisspellingRate: 1, TopTypos: typos}, false},
+		{"at the threshold", &SpellingStats{CheckedSubjects: 10, MisspellingRate: 0.2, TopTypos: typos}, false},
+		{"over the threshold", &SpellingStats{CheckedSubjects: 10, MisspellingRate: 0.3, TopTypos: typos}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, Spelling: tt.spelling}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Fatalf("triggered = %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+			want := "'recieve', 'seperate', 'definately' — your commits need autocorrect more than code review."
+			if line := rule.line(stats, defaultIntensity); line != want {
+				t.Errorf("line = %q, want %q", line, want)
+			}
+		})
+	}
+}
+
+func TestRoastSpellcheckParam(t *testing.T) {
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"/roast?username=octocat", false},
+		{"/roast?username=octocat&spellcheck=true", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "recieve teh data", 2)))
+			w := doRequest(s.handleRoast, http.MethodGet, "/roast", tt.target, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var body struct {
+				Stats struct {
+					Spelling *SpellingStats `json:"spelling"`
+				} `json:"stats"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if got := body.Stats.Spelling != nil; got != tt.want {
+				t.Fatalf("spelling reported = %v, want %v", got, tt.want)
+			}
+			if tt.want && body.Stats.Spelling.MisspelledSubjects != 1 {
+				t.Errorf("spelling = %+v", body.Stats.Spelling)
+			}
+		})
+	}
+}
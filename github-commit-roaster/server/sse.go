@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleRoastSSE serves GET /roast/sse, the roast as a text/event-stream
+// for browsers: one "line" event per roast line, then a "stats" event with
+// the stats object as JSON. It takes the GET /roast query parameters and
+// shares its cache. Errors before the first event are answered as JSON
+// with the usual status codes; a client that goes away stops the stream.
+func (s *server) handleRoastSSE(c *gin.Context) {
+	username := c.Query("username")
+	if err := validateGitHubUsername(username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := roastOptions(c, s.cfg)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, ownRoast := s.login.tokenFor(c, username)
+	cacheKey := roastCacheKey(username, c.Request.URL.Query())
+	var response RoastResponse
+	cached, hit := cachedRoast{}, false
+	if !ownRoast {
+		cached, hit = s.cache.Get(c.Request.Context(), cacheKey)
+	}
+	if hit {
+		s.prefetch.hit(cacheKey)
+		c.Header("X-Cache", "HIT")
+		response = cached.hitResponse()
+	} else {
+		result, ok := s.analyze(c, username, opts)
+		if !ok {
+			return
+		}
+		s.record(username, result.stats, opts)
+		response = s.roastResponse(username, result, opts, false)
+		if !ownRoast {
+			s.cache.Set(c.Request.Context(), cacheKey, newCachedRoast(response), s.cacheTTL)
+			s.prefetch.stored(cacheKey, username)
+		}
+		c.Header("X-Cache", "MISS")
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // keep nginx from holding events back
+	c.Status(http.StatusOK)
+
+	ctx := c.Request.Context()
+	for _, line := range strings.Split(response.Roast, "\n\n") {
+		if ctx.Err() != nil {
+			return
+		}
+		c.SSEvent("line", line)
+		c.Writer.Flush()
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	c.SSEvent("stats", response.Stats)
+	c.Writer.Flush()
+}
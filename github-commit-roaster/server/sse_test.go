@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseEvent is one event read off a text/event-stream.
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvents reads events until the stream ends.
+func readEvents(t *testing.T, body io.Reader) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var event sseEvent
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event != (sseEvent{}) {
+				events = append(events, event)
+			}
+			event = sseEvent{}
+		case strings.HasPrefix(line, "event:"):
+			event.name = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			if event.data != "" {
+				event.data += "\n"
+			}
+			event.data += strings.TrimPrefix(line, "data:")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestRoastSSE(t *testing.T) {
+	gh := newFakeGitHub("octocat",
+		fakeCommit("Octo", "fix bug", 1), fakeCommit("Octo", "fix typo", 2), fakeCommit("Octo", "fix it", 3), fakeCommit("Octo", "update", 4))
+	s := newTestServer(t, gh)
+	r := gin.New()
+	r.GET("/roast", s.handleRoast)
+	r.GET("/roast/sse", s.handleRoastSSE)
+	api := httptest.NewServer(r)
+	defer api.Close()
+
+	var streams [][]sseEvent
+	for _, cache := range []string{"MISS", "HIT"} {
+		resp, err := http.Get(api.URL + "/roast/sse?username=octocat")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if got := resp.Header.Get("X-Cache"); got != cache {
+			t.Errorf("X-Cache = %q, want %q", got, cache)
+		}
+		streams = append(streams, readEvents(t, resp.Body))
+		resp.Body.Close()
+	}
+
+	// The stream is the cached roast's lines, then its stats
+	resp, err := http.Get(api.URL + "/roast?username=octocat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roast RoastResponse
+	json.NewDecoder(resp.Body).Decode(&roast)
+	resp.Body.Close()
+	wantLines := strings.Split(roast.Roast, "\n\n")
+
+	for _, events := range streams {
+		if len(events) != len(wantLines)+1 {
+			t.Fatalf("%d events, want %d lines and stats: %+v", len(events), len(wantLines), events)
+		}
+		for i, line := range wantLines {
+			if events[i].name != "line" || events[i].data != line {
+				t.Errorf("event %d = %+v, want line %q", i, events[i], line)
+			}
+		}
+		last := events[len(events)-1]
+		var stats CommitStats
+		if err := json.Unmarshal([]byte(last.data), &stats); last.name != "stats" || err != nil {
+			t.Fatalf("last event = %+v: %v", last, err)
+		}
+		if stats.TotalCommits != 4 || stats.FixCommits != 3 {
+			t.Errorf("stats = %d commits, %d fixes", stats.TotalCommits, stats.FixCommits)
+		}
+	}
+	if calls := gh.callCount("/repos/octocat/project/commits"); calls != 1 {
+		t.Errorf("commits fetched %d times, want once", calls)
+	}
+}
+
+func TestRoastSSEErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"no username", "/roast/sse", http.StatusBadRequest},
+		{"invalid username", "/roast/sse?username=-octocat", http.StatusBadRequest},
+		{"no such user", "/roast/sse?username=ghost", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, newFakeGitHub("octocat"))
+			w := doRequest(s.handleRoastSSE, http.MethodGet, "/roast/sse", tt.target, "")
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); strings.HasPrefix(ct, "text/event-stream") {
+				t.Errorf("error sent as an event stream")
+			}
+		})
+	}
+}
+
+func TestRoastSSEClientGone(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix bug", 1)))
+	s.cache.Set(t.Context(), roastCacheKey("octocat", nil), newCachedRoast(RoastResponse{Roast: "one\n\ntwo"}), s.cacheTTL)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	r := gin.New()
+	r.GET("/roast/sse", s.handleRoastSSE)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequestWithContext(ctx, http.MethodGet, "/roast/sse?username=octocat", nil))
+	if w.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("X-Cache = %q, want the cached roast", w.Header().Get("X-Cache"))
+	}
+	if strings.Contains(w.Body.String(), "event:") {
+		t.Errorf("events sent to a client that went away:\n%s", w.Body)
+	}
+}
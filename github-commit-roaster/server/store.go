@@ -0,0 +1,251 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store persists server state that has to survive restarts. It is optional:
+// without SQLITE_PATH everything is kept in memory.
+type Store struct {
+	db *sql.DB
+}
+
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS api_keys (
+	key             TEXT PRIMARY KEY,
+	name            TEXT NOT NULL,
+	daily_quota     INTEGER NOT NULL,
+	rate_per_minute INTEGER NOT NULL,
+	created_at      TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS api_usage (
+	key   TEXT NOT NULL,
+	day   TEXT NOT NULL,
+	count INTEGER NOT NULL,
+	PRIMARY KEY (key, day)
+);
+CREATE TABLE IF NOT EXISTS roast_history (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	username   TEXT NOT NULL,
+	stats      TEXT NOT NULL,
+	days       INTEGER,
+	weights    TEXT,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS roast_history_user ON roast_history (username, created_at);
+CREATE TABLE IF NOT EXISTS job_runs (
+	name     TEXT PRIMARY KEY,
+	last_run TIMESTAMP NOT NULL,
+	result   TEXT NOT NULL
+);
+`
+
+// openStore opens (and if needed creates) the SQLite database at path.
+func openStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(storeSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrateStore(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// storeMigrations add columns that databases created by older versions
+// lack. Rows from before a migration keep NULL in the new columns.
+var storeMigrations = []struct{ table, column, definition string }{
+	{"roast_history", "days", "INTEGER"},
+	{"roast_history", "weights", "TEXT"},
+}
+
+func migrateStore(db *sql.DB) error {
+	for _, m := range storeMigrations {
+		var exists bool
+		err := db.QueryRow(
+			`SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?`, m.table, m.column,
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, m.table, m.column, m.definition)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) SaveAPIKey(k APIKey) error {
+	_, err := s.db.Exec(
+		`INSERT INTO api_keys (key, name, daily_quota, rate_per_minute, created_at) VALUES (?, ?, ?, ?, ?)`,
+		k.Key, k.Name, k.DailyQuota, k.RatePerMinute, k.CreatedAt,
+	)
+	return err
+}
+
+func (s *Store) DeleteAPIKey(key string) error {
+	_, err := s.db.Exec(`DELETE FROM api_keys WHERE key = ?`, key)
+	return err
+}
+
+func (s *Store) LoadAPIKeys() ([]APIKey, error) {
+	rows, err := s.db.Query(`SELECT key, name, daily_quota, rate_per_minute, created_at FROM api_keys`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var k APIKey
+		if err := rows.Scan(&k.Key, &k.Name, &k.DailyQuota, &k.RatePerMinute, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// IncrementUsage adds n to the counter for key on day and returns the new
+// count.
+func (s *Store) IncrementUsage(key string, day time.Time, n int) (int, error) {
+	var count int
+	err := s.db.QueryRow(
+		`INSERT INTO api_usage (key, day, count) VALUES (?, ?, ?)
+		 ON CONFLICT (key, day) DO UPDATE SET count = count + excluded.count
+		 RETURNING count`,
+		key, day.Format(time.DateOnly), n,
+	).Scan(&count)
+	return count, err
+}
+
+// Usage returns how many requests key made on day.
+func (s *Store) Usage(key string, day time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRow(
+		`SELECT count FROM api_usage WHERE key = ? AND day = ?`,
+		key, day.Format(time.DateOnly),
+	).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return count, err
+}
+
+// AppendHistory stores an analysis in the roast history.
+func (s *Store) AppendHistory(rec HistoryRecord) error {
+	stats, err := json.Marshal(rec.Stats)
+	if err != nil {
+		return err
+	}
+	weights, err := json.Marshal(rec.Weights)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(
+		`INSERT INTO roast_history (username, stats, days, weights, created_at) VALUES (?, ?, ?, ?, ?)`,
+		rec.Username, string(stats), rec.Days, string(weights), rec.CreatedAt,
+	)
+	return err
+}
+
+// LatestHistory returns the most recent stored analysis of username over
+// the same days and with the same weights. The weights are matched on their
+// JSON encoding, which sorts the keys.
+func (s *Store) LatestHistory(username string, days int, weights Weights) (HistoryRecord, bool, error) {
+	encoded, err := json.Marshal(weights)
+	if err != nil {
+		return HistoryRecord{}, false, err
+	}
+	rec := HistoryRecord{Username: username, Days: days, Weights: weights}
+	var stats string
+	err = s.db.QueryRow(
+		`SELECT stats, created_at FROM roast_history
+		 WHERE username = ? AND days = ? AND weights = ?
+		 ORDER BY created_at DESC, id DESC LIMIT 1`,
+		username, days, string(encoded),
+	).Scan(&stats, &rec.CreatedAt)
+	if err == sql.ErrNoRows {
+		return HistoryRecord{}, false, nil
+	}
+	if err != nil {
+		return HistoryRecord{}, false, err
+	}
+	if err := json.Unmarshal([]byte(stats), &rec.Stats); err != nil {
+		return HistoryRecord{}, false, err
+	}
+	return rec, true, nil
+}
+
+// RecentHistory returns up to n of username's latest stored analyses,
+// oldest first.
+func (s *Store) RecentHistory(username string, n int) ([]HistoryRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT stats, created_at FROM roast_history WHERE username = ? ORDER BY created_at DESC, id DESC LIMIT ?`,
+		username, n,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var recs []HistoryRecord
+	for rows.Next() {
+		rec := HistoryRecord{Username: username}
+		var stats string
+		if err := rows.Scan(&stats, &rec.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(stats), &rec.Stats); err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	slices.Reverse(recs)
+	return recs, nil
+}
+
+// SaveJobRun records that the named background job ran at, along with its
+// JSON-encoded result.
+func (s *Store) SaveJobRun(name string, at time.Time, result []byte) error {
+	_, err := s.db.Exec(
+		`INSERT INTO job_runs (name, last_run, result) VALUES (?, ?, ?)
+		 ON CONFLICT (name) DO UPDATE SET last_run = excluded.last_run, result = excluded.result`,
+		name, at, string(result),
+	)
+	return err
+}
+
+// LastJobRun returns when the named job last ran and what it produced.
+func (s *Store) LastJobRun(name string) (time.Time, []byte, bool, error) {
+	var at time.Time
+	var result string
+	err := s.db.QueryRow(`SELECT last_run, result FROM job_runs WHERE name = ?`, name).Scan(&at, &result)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil, false, nil
+	}
+	if err != nil {
+		return time.Time{}, nil, false, err
+	}
+	return at, []byte(result), true, nil
+}
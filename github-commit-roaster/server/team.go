@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxTeamMembers = 20
+	// maxTeamCalls is the GitHub call budget shared by a whole team.
+	maxTeamCalls = 400
+	// leaderboardSize is how many members are named per sin.
+	leaderboardSize = 3
+)
+
+// TeamDetails is added to a team roast: who was in it, who couldn't be
+// analyzed and who contributed most to each sin.
+type TeamDetails struct {
+	Members     []string                      `json:"members"`
+	Excluded    []ExcludedMember              `json:"excluded"`
+	Leaderboard map[string][]LeaderboardEntry `json:"leaderboard"`
+}
+
+// ExcludedMember is a team member whose account couldn't be analyzed.
+type ExcludedMember struct {
+	Username string `json:"username"`
+	Status   int    `json:"status"`
+	Reason   string `json:"reason"`
+}
+
+// LeaderboardEntry is one member's count for a sin.
+type LeaderboardEntry struct {
+	Username string `json:"username"`
+	Count    int    `json:"count"`
+}
+
+// teamSins are the leaderboard categories, named after the pattern scores.
+var teamSins = []struct {
+	id    string
+	count func(CommitStats) int
+}{
+	{"late_night", func(s CommitStats) int { return s.LateNightCommits }},
+	{"fixes", func(s CommitStats) int { return s.FixCommits }},
+	{"merges", func(s CommitStats) int { return s.MergeCommits }},
+	{"generic_messages", func(s CommitStats) int { return s.GenericMessages }},
+	{"swearing", func(s CommitStats) int { return s.SwearWords }},
+	{"automation", func(s CommitStats) int { return s.Automation.BotCount }},
+}
+
+// teamCacheKey identifies a team by its sorted members, whatever the team
+// is called, plus the options that change the roast.
+func teamCacheKey(members []string, opts RoastOptions) string {
+	sorted := make([]string, len(members))
+	for i, member := range members {
+		sorted[i] = strings.ToLower(member)
+	}
+	sort.Strings(sorted)
+	return fmt.Sprintf("team:%s:%d", strings.Join(sorted, ","), opts.Days)
+}
+
+// teamVerdict is the team roast's opening line, about its worst pattern.
+func teamVerdict(stats CommitStats) string {
+	total := stats.TotalCommits
+	switch dominantPattern(stats) {
+	case "late_night":
+		return fmt.Sprintf("As a unit, %.0f%% of your commits land late at night.", share(stats.LateNightCommits, total)*100)
+	case "fixes":
+		return fmt.Sprintf("As a unit, you produce %.0f%% fix commits.", share(stats.FixCommits, total)*100)
+	case "merges":
+		return fmt.Sprintf("As a unit, %.0f%% of your commits are merges.", share(stats.MergeCommits, total)*100)
+	case "generic_messages":
+		return fmt.Sprintf("As a unit, %.0f%% of your commit messages say nothing at all.", share(stats.GenericMessages, total)*100)
+	case "swearing":
+		return fmt.Sprintf("As a unit, you've sworn %d times in commit messages.", stats.SwearWords)
+	case "automation":
+		return fmt.Sprintf("As a unit, %.0f%% of your commits come from bots.", stats.Automation.AutomationRatio*100)
+	default:
+		return "As a unit, your commits are suspiciously clean."
+	}
+}
+
+// teamLeaderboard names the members contributing most to each sin.
+func teamLeaderboard(members []string, stats []CommitStats) map[string][]LeaderboardEntry {
+	board := make(map[string][]LeaderboardEntry, len(teamSins))
+	for _, sin := range teamSins {
+		entries := []LeaderboardEntry{}
+		for i, member := range members {
+			if n := sin.count(stats[i]); n > 0 {
+				entries = append(entries, LeaderboardEntry{Username: member, Count: n})
+			}
+		}
+		sort.SliceStable(entries, func(a, b int) bool { return entries[a].Count > entries[b].Count })
+		if len(entries) > leaderboardSize {
+			entries = entries[:leaderboardSize]
+		}
+		board[sin.id] = entries
+	}
+	return board
+}
+
+// handleRoastTeam serves POST /roast/team: the members' commits analyzed
+// as one, with a team verdict and a per-sin leaderboard.
+func (s *server) handleRoastTeam(c *gin.Context) {
+	var req struct {
+		Name    string   `json:"name"`
+		Members []string `json:"members"`
+		Days    int      `json:"days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name and members are required"})
+		return
+	}
+	members := uniqueUsernames(req.Members)
+	if len(members) == 0 || len(members) > maxTeamMembers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a team needs 1 to %d members", maxTeamMembers)})
+		return
+	}
+	if req.Days < 0 || req.Days > maxWindowDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be a number from 1 to %d", maxWindowDays)})
+		return
+	}
+	if !chargeMembers(c, len(members)) {
+		return
+	}
+	opts, _ := s.bodyRoastOptions(req.Days)
+
+	cacheKey := teamCacheKey(members, opts)
+	if cached, ok := s.cache.Get(c.Request.Context(), cacheKey); ok {
+		response := cached.hitResponse()
+		response.Username = req.Name
+		c.Header("X-Cache", "HIT")
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	ctx := withCallBudget(c.Request.Context(), maxTeamCalls)
+	results := make([]*analysis, len(members))
+	errs := make([]error, len(members))
+	// Invalid usernames are excluded without a GitHub call
+	invalid := make([]error, len(members))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, member := range members {
+		if invalid[i] = validateGitHubUsername(member); invalid[i] != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			client, authMode := s.clients.newClient(ctx)
+			results[i], errs[i] = s.sharedFetch(ctx, client, authMode, member, opts)
+		}()
+	}
+	wg.Wait()
+
+	details := &TeamDetails{Members: []string{}, Excluded: []ExcludedMember{}}
+	var commits []NormalizedCommit
+	var memberStats []CommitStats
+	repos := 0
+	for i, member := range members {
+		if invalid[i] != nil {
+			details.Excluded = append(details.Excluded, ExcludedMember{Username: member, Status: http.StatusBadRequest, Reason: invalid[i].Error()})
+			continue
+		}
+		if errs[i] != nil {
+			status, body := s.analysisError(errs[i])
+			details.Excluded = append(details.Excluded, ExcludedMember{Username: member, Status: status, Reason: fmt.Sprint(body["error"])})
+			continue
+		}
+		details.Members = append(details.Members, member)
+		memberStats = append(memberStats, results[i].stats)
+		commits = append(commits, results[i].commits...)
+		repos += results[i].stats.ReposAnalyzed
+	}
+	if len(details.Members) == 0 {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "no team member could be analyzed", "excluded": details.Excluded})
+		return
+	}
+	details.Leaderboard = teamLeaderboard(details.Members, memberStats)
+
+	stats := analyzeCommits(commits, s.cfg)
+	stats.ReposAnalyzed = repos
+	since, until := opts.window(time.Now())
+	stats.Since, stats.Until = since.UTC(), until.UTC()
+	stats.Severity = weightedSeverity(stats, opts.Weights)
+	stats.TriggeredRules = rankedRules(stats, opts.Weights)
+
+	response := RoastResponse{
+		Username:     req.Name,
+		Roast:        teamVerdict(stats) + "\n\n" + generateRoast(stats, opts),
+		Verdict:      roastVerdict(stats, opts.Weights, opts.Lang),
+		Stats:        stats,
+		Weights:      opts.Weights,
+		Achievements: earnedAchievements(stats),
+		Team:         details,
+	}
+	// Teams with excluded members may resolve fully next time
+	if len(details.Excluded) == 0 {
+		s.cache.Set(c.Request.Context(), cacheKey, newCachedRoast(response), s.cacheTTL)
+	}
+	c.Header("X-Cache", "MISS")
+	c.JSON(http.StatusOK, response)
+}
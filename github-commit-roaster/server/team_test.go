@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTeamLeaderboard(t *testing.T) {
+	members := []string{"ana", "bo", "cy", "di"}
+	stats := []CommitStats{
+		{FixCommits: 2, LateNightCommits: 1},
+		{FixCommits: 5},
+		{FixCommits: 2, SwearWords: 3},
+		{FixCommits: 1},
+	}
+	board := teamLeaderboard(members, stats)
+	tests := []struct {
+		sin  string
+		want []LeaderboardEntry
+	}{
+		// Capped at three, ties in member order
+		{"fixes", []LeaderboardEntry{{"bo", 5}, {"ana", 2}, {"cy", 2}}},
+		{"late_night", []LeaderboardEntry{{"ana", 1}}},
+		{"swearing", []LeaderboardEntry{{"cy", 3}}},
+		{"merges", []LeaderboardEntry{}},
+	}
+	for _, tt := range tests {
+		if got := board[tt.sin]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %+v, want %+v", tt.sin, got, tt.want)
+		}
+	}
+	if len(board) != len(teamSins) {
+		t.Errorf("%d sins on the board, want %d", len(board), len(teamSins))
+	}
+}
+
+func TestTeamCacheKey(t *testing.T) {
+	opts := RoastOptions{Days: 30}
+	key := teamCacheKey([]string{"Octocat", "hubot"}, opts)
+	if other := teamCacheKey([]string{"HUBOT", "octocat"}, opts); other != key {
+		t.Errorf("member order or case changed the key: %q vs %q", key, other)
+	}
+	if other := teamCacheKey([]string{"octocat", "hubot"}, RoastOptions{Days: 7}); other == key {
+		t.Error("a different window shares the key")
+	}
+	if other := teamCacheKey([]string{"octocat"}, opts); other == key {
+		t.Error("a different team shares the key")
+	}
+}
+
+func TestTeamVerdict(t *testing.T) {
+	tests := []struct {
+		stats CommitStats
+		want  string
+	}{
+		{CommitStats{TotalCommits: 100, FixCommits: 73}, "As a unit, you produce 73% fix commits."},
+		{CommitStats{TotalCommits: 10, LateNightCommits: 8}, "As a unit, 80% of your commits land late at night."},
+		{CommitStats{TotalCommits: 10, SwearWords: 4}, "As a unit, you've sworn 4 times in commit messages."},
+		{CommitStats{TotalCommits: 10}, "As a unit, your commits are suspiciously clean."},
+	}
+	for _, tt := range tests {
+		if got := teamVerdict(tt.stats); got != tt.want {
+			t.Errorf("teamVerdict(%+v) = %q, want %q", tt.stats, got, tt.want)
+		}
+	}
+}
+
+func TestRoastTeam(t *testing.T) {
+	s := newTestServer(t, fakeGitHubs{
+		"octocat": newFakeGitHub("octocat", fakeCommit("Octo", "fix login", 2), fakeCommit("Octo", "fix logout", 3), fakeCommit("Octo", "fix it", 4)),
+		"hubot":   newFakeGitHub("hubot", fakeCommit("Hubot", "fix chat", 5), fakeCommit("Hubot", "add chat ops", 6)),
+	})
+	team := func(body string) (*RoastResponse, int, string) {
+		w := doRequest(s.handleRoastTeam, http.MethodPost, "/roast/team", "/roast/team", body)
+		var resp RoastResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return &resp, w.Code, w.Header().Get("X-Cache")
+	}
+
+	resp, status, cache := team(`{"name":"Platform","members":["octocat","hubot","ghost","Octocat","../orgs/x"]}`)
+	if status != http.StatusOK || cache != "MISS" {
+		t.Fatalf("status %d, X-Cache %q", status, cache)
+	}
+	if resp.Username != "Platform" || resp.Stats.TotalCommits != 5 || !strings.HasPrefix(resp.Roast, "As a unit, you produce 80% fix commits.") {
+		t.Errorf("team roast = %q for %d commits", resp.Roast, resp.Stats.TotalCommits)
+	}
+	if resp.Team == nil {
+		t.Fatal("no team details")
+	}
+	if want := []string{"octocat", "hubot"}; !reflect.DeepEqual(resp.Team.Members, want) {
+		t.Errorf("members = %q, want %q", resp.Team.Members, want)
+	}
+	invalid := ExcludedMember{"../orgs/x", http.StatusBadRequest, validateGitHubUsername("../orgs/x").Error()}
+	if len(resp.Team.Excluded) != 2 || resp.Team.Excluded[0].Username != "ghost" || resp.Team.Excluded[0].Status != http.StatusNotFound || resp.Team.Excluded[1] != invalid {
+		t.Errorf("excluded = %+v, want ghost with 404 and ../orgs/x with 400", resp.Team.Excluded)
+	}
+	if want := []LeaderboardEntry{{"octocat", 3}, {"hubot", 1}}; !reflect.DeepEqual(resp.Team.Leaderboard["fixes"], want) {
+		t.Errorf("fixes leaderboard = %+v, want %+v", resp.Team.Leaderboard["fixes"], want)
+	}
+
+	// Only fully resolved teams are cached, under their members whatever the name
+	if _, _, cache := team(`{"name":"Platform","members":["octocat","hubot","ghost"]}`); cache != "MISS" {
+		t.Errorf("team with an excluded member: X-Cache = %q, want MISS", cache)
+	}
+	team(`{"name":"Platform","members":["octocat","hubot"]}`)
+	resp, _, cache = team(`{"name":"Renamed","members":["Hubot","octocat"]}`)
+	if cache != "HIT" || resp.Username != "Renamed" {
+		t.Errorf("X-Cache = %q, name %q; want a HIT under the new name", cache, resp.Username)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"no name", `{"members":["octocat"]}`, http.StatusBadRequest},
+		{"no members", `{"name":"Platform","members":[]}`, http.StatusBadRequest},
+		{"days out of range", `{"name":"Platform","members":["octocat"],"days":400}`, http.StatusBadRequest},
+		{"nobody resolves", `{"name":"Ghosts","members":["ghost","phantom"]}`, http.StatusBadGateway},
+		{"nobody valid", `{"name":"Paths","members":["../orgs/x","-dash"]}`, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, status, _ := team(tt.body); status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+//go:build integration
+
+// Package integration runs the roaster binary against Redis in a container
+// and GitHub responses recorded in test/fixtures. It needs Docker:
+//
+//	go test -tags integration ./test/integration
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// The roaster under test: its base URL and the Redis it caches in.
+var (
+	roasterURL string
+	redisURL   string
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	os.Exit(run(m))
+}
+
+// run starts Redis, the fixture GitHub and the roaster, runs the tests and
+// tears all three down again, returning the exit code.
+func run(m *testing.M) int {
+	ctx := context.Background()
+
+	container, err := tcredis.Run(ctx, "redis:7-alpine")
+	defer func() {
+		if err := testcontainers.TerminateContainer(container); err != nil {
+			fmt.Fprintf(os.Stderr, "terminating Redis: %v\n", err)
+		}
+	}()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "starting Redis: %v\n", err)
+		return 1
+	}
+	if redisURL, err = container.ConnectionString(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Redis address: %v\n", err)
+		return 1
+	}
+
+	api := httptest.NewServer(fixtureGitHub(filepath.Join("..", "fixtures")))
+	defer api.Close()
+
+	stop, err := startRoaster(api.URL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "starting the roaster: %v\n", err)
+		return 1
+	}
+	defer stop()
+	return m.Run()
+}
+
+// fixtureGitHub serves GET requests from the JSON file at the request path
+// under dir, so /users/octocat is dir/users/octocat.json. Query strings are
+// ignored; anything without a fixture gets GitHub's 404.
+func fixtureGitHub(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path.Clean(r.URL.Path))+".json"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "no fixture for %s %s\n", r.Method, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Write(body)
+	})
+}
+
+// startRoaster builds the server and runs it against githubURL and Redis,
+// returning once /health answers. stop shuts it down the way a deploy would.
+func startRoaster(githubURL string) (stop func(), err error) {
+	dir, err := os.MkdirTemp("", "roaster-integration")
+	if err != nil {
+		return nil, err
+	}
+	build := exec.Command("go", "build", "-o", filepath.Join(dir, "roaster"), ".")
+	build.Dir = filepath.Join("..", "..")
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	// An empty config file keeps a developer's ./config.yaml out of the run
+	config := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(config, nil, 0o644); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	server := exec.Command(filepath.Join(dir, "roaster"), "-config", config)
+	server.Dir = filepath.Join("..", "..")
+	server.Env = append(os.Environ(),
+		"PORT="+port,
+		"REDIS_URL="+redisURL,
+		"GITHUB_API_URL="+githubURL,
+		"GITHUB_TOKEN=",
+		"SQLITE_PATH=",
+		"GIN_MODE=release",
+	)
+	server.Stdout, server.Stderr = os.Stdout, os.Stderr
+	if err := server.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	stop = func() {
+		server.Process.Signal(syscall.SIGTERM)
+		server.Wait()
+		os.RemoveAll(dir)
+	}
+
+	roasterURL = "http://127.0.0.1:" + port
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		if resp, err := http.Get(roasterURL + "/health"); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return stop, nil
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	stop()
+	return nil, fmt.Errorf("no answer on %s/health", roasterURL)
+}
+
+// freePort is a TCP port nothing is listening on.
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	return port, err
+}
+
+// get fetches path from the roaster.
+func get(t *testing.T, path string) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := http.Get(roasterURL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, body
+}
+
+// golden compares got with testdata/name, or rewrites it with -update.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	file := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs, rerun with -update if the change is intended:\n%s", name, got)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	if resp, body := get(t, "/health"); resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestRoastIsCachedInRedis(t *testing.T) {
+	var roast struct {
+		Username string `json:"username"`
+		Roast    string `json:"roast"`
+		Stats    struct {
+			TotalCommits int  `json:"total_commits"`
+			CacheHit     bool `json:"cache_hit"`
+		} `json:"stats"`
+	}
+
+	resp, body := get(t, "/roast?username=fixture-user")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+	if cache := resp.Header.Get("X-Cache"); cache != "MISS" {
+		t.Errorf("first request: X-Cache = %q, want MISS", cache)
+	}
+	if err := json.Unmarshal(body, &roast); err != nil {
+		t.Fatal(err)
+	}
+	if roast.Stats.CacheHit {
+		t.Error("first request: cache_hit = true, want false")
+	}
+	if roast.Username != "fixture-user" || roast.Stats.TotalCommits != 13 {
+		t.Errorf("roasted %q over %d commits, want fixture-user over 13", roast.Username, roast.Stats.TotalCommits)
+	}
+	golden(t, "fixture-user.roast.golden", roast.Roast+"\n")
+
+	resp, cached := get(t, "/roast?username=fixture-user")
+	if cache := resp.Header.Get("X-Cache"); cache != "HIT" {
+		t.Errorf("second request: X-Cache = %q, want HIT", cache)
+	}
+	var hit struct {
+		Roast string `json:"roast"`
+		Stats struct {
+			CacheHit bool `json:"cache_hit"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(cached, &hit); err != nil {
+		t.Fatal(err)
+	}
+	if !hit.Stats.CacheHit {
+		t.Error("second request: cache_hit = false, want true")
+	}
+	if hit.Roast != roast.Roast {
+		t.Errorf("cached roast differs from the first:\n%s\n%s", hit.Roast, roast.Roast)
+	}
+
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(opts)
+	defer client.Close()
+	keys, err := client.Keys(t.Context(), "roast:fixture-user:*").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("Redis keys = %q, want one roast entry", keys)
+	}
+	if ttl := client.TTL(t.Context(), keys[0]).Val(); ttl <= 0 {
+		t.Errorf("cached roast TTL = %s, want it to expire", ttl)
+	}
+	if entry := client.Get(t.Context(), keys[0]).Val(); !strings.Contains(entry, "fixture-user") {
+		t.Errorf("cached entry doesn't hold the roast: %.200s", entry)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxTestCoverageRepos is how many repos get their file tree listed for
+// test files, one call each.
+const maxTestCoverageRepos = 3
+
+// untestedRatio is the share of repos with tests below which a profile
+// counts as untested.
+const untestedRatio = 0.3
+
+// TestCoverageStats counts repos with test files in their tree. It says
+// nothing about how much those tests cover. Only set in deep mode.
+type TestCoverageStats struct {
+	ReposWithTests    int     `json:"repos_with_tests"`
+	ReposWithoutTests int     `json:"repos_without_tests"`
+	TestFilesFound    int     `json:"test_files_found"`
+	CoverageRatio     float64 `json:"coverage_ratio"` // share of repos with tests
+}
+
+// isTestFile reports whether a path follows the test file naming of Go,
+// JavaScript and TypeScript, Python or RSpec.
+func isTestFile(filePath string) bool {
+	name := path.Base(filePath)
+	switch {
+	case strings.HasSuffix(name, "_test.go"):
+		return true
+	case strings.HasSuffix(name, ".py"):
+		return strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py")
+	case strings.HasSuffix(name, "_spec.rb"):
+		return true
+	}
+	for _, ext := range []string{".js", ".jsx", ".ts", ".tsx"} {
+		if strings.HasSuffix(name, ".test"+ext) || strings.HasSuffix(name, ".spec"+ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchTestCoverage lists the default branch tree of up to
+// maxTestCoverageRepos non-empty repos and counts their test files. Repos
+// whose tree can't be listed are left out.
+func fetchTestCoverage(ctx context.Context, client *ghclient.Client, owner string, repos []*github.Repository) TestCoverageStats {
+	var stats TestCoverageStats
+	listed := 0
+	for _, repo := range repos {
+		if listed == maxTestCoverageRepos {
+			break
+		}
+		if repo.GetSize() == 0 || repo.GetDefaultBranch() == "" {
+			continue
+		}
+		listed++
+		spanCtx, span := startGitHubSpan(ctx, "Git.GetTree", attribute.String("github.repo", repo.GetName()))
+		tree, _, err := client.Git.GetTree(spanCtx, owner, repo.GetName(), repo.GetDefaultBranch(), true)
+		endSpan(span, err)
+		if err != nil {
+			continue
+		}
+		found := 0
+		for _, entry := range tree.Entries {
+			if entry.GetType() == "blob" && isTestFile(entry.GetPath()) {
+				found++
+			}
+		}
+		stats.TestFilesFound += found
+		if found > 0 {
+			stats.ReposWithTests++
+		} else {
+			stats.ReposWithoutTests++
+		}
+	}
+	stats.CoverageRatio = share(stats.ReposWithTests, stats.ReposWithTests+stats.ReposWithoutTests)
+	return stats
+}
+
+// untested reports whether fewer than untestedRatio of the listed repos have
+// test files. It is false when no tree could be listed.
+func (t *TestCoverageStats) untested() bool {
+	return t != nil && t.ReposWithTests+t.ReposWithoutTests > 0 && t.CoverageRatio < untestedRatio
+}
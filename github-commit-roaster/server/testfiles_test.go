@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	ghclient "github-commit-roaster/internal/github"
+	"github.com/google/go-github/v50/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		// Go
+		{"handlers_test.go", true},
+		{"internal/github/client_test.go", true},
+		{"handlers.go", false},
+		{"test.go", false},
+		{"testdata/fixture.go", false},
+		{"handlers_test.go.orig", false},
+		// JavaScript and TypeScript
+		{"src/app.test.js", true},
+		{"src/app.spec.ts", true},
+		{"Button.test.tsx", true},
+		{"app.js", false},
+		{"test.js", false},
+		{"contest.js", false},
+		{"app.test.json", false},
+		// Python
+		{"test_models.py", true},
+		{"tests/models_test.py", true},
+		{"models.py", false},
+		{"attest_models.py", false},
+		{"test_models.pyc", false},
+		// Ruby
+		{"spec/user_spec.rb", true},
+		{"user.rb", false},
+		{"spec/spec_helper.rb", false},
+		{"user_spec.rb.bak", false},
+		// Other
+		{"", false},
+		{"tests/", false},
+		{"Test_models.py", false},
+	}
+	for _, tt := range tests {
+		if got := isTestFile(tt.path); got != tt.want {
+			t.Errorf("isTestFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// tree is a recursive tree listing of blobs, with dirs as tree entries.
+func tree(paths ...string) *github.Tree {
+	t := &github.Tree{}
+	for _, p := range paths {
+		kind := "blob"
+		if p[len(p)-1] == '/' {
+			kind = "tree"
+		}
+		t.Entries = append(t.Entries, &github.TreeEntry{Path: github.String(p), Type: github.String(kind)})
+	}
+	return t
+}
+
+func TestFetchTestCoverage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	git := ghclient.NewMockGitService(ctrl)
+	client := &ghclient.Client{Git: git}
+	git.EXPECT().GetTree(gomock.Any(), "octocat", "api", "main", true).Return(tree("main.go", "main_test.go", "store_test.go"), nil, nil)
+	git.EXPECT().GetTree(gomock.Any(), "octocat", "gone", "main", true).Return(nil, nil, errors.New("409"))
+	git.EXPECT().GetTree(gomock.Any(), "octocat", "site", "trunk", true).Return(tree("index.js", "test_dir.js/", "app.test.js/"), nil, nil)
+
+	repo := func(name, branch string, size int) *github.Repository {
+		return &github.Repository{Name: github.String(name), DefaultBranch: github.String(branch), Size: github.Int(size)}
+	}
+	list := []*github.Repository{
+		repo("api", "main", 10),
+		repo("empty", "main", 0),
+		repo("gone", "main", 10),
+		repo("site", "trunk", 10),
+		// "gone" used up the third listing
+		repo("bot", "main", 10),
+	}
+	got := fetchTestCoverage(t.Context(), client, "octocat", list)
+	want := TestCoverageStats{ReposWithTests: 1, ReposWithoutTests: 1, TestFilesFound: 2, CoverageRatio: 0.5}
+	if got != want {
+		t.Errorf("fetchTestCoverage() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUntestedRule(t *testing.T) {
+	rule := findRule(t, "untested")
+	tests := []struct {
+		name     string
+		coverage *TestCoverageStats
+		want     bool
+	}{
+		{"not deep", nil, false},
+		{"no tree listed", &TestCoverageStats{}, false},
+		{"none tested", &TestCoverageStats{ReposWithoutTests: 3, CoverageRatio: 0}, true},
+		{"a third tested", &TestCoverageStats{ReposWithTests: 1, ReposWithoutTests: 2, CoverageRatio: 0.33}, false},
+		{"a quarter tested", &TestCoverageStats{ReposWithTests: 1, ReposWithoutTests: 3, CoverageRatio: 0.25}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, TestCoverage: tt.coverage}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Fatalf("triggered = %v, want %v", got, tt.want)
+			}
+			if want := "Fewer than a third of your repos have detectable test files. The other 70% live on hope and prayers."; tt.want && rule.line(stats, defaultIntensity) != want {
+				t.Errorf("line = %q", rule.line(stats, defaultIntensity))
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// commitSpec is a commit in the compact form test fixtures are written in.
+// Zero fields are left out of the built commit, except for the defaults
+// noted below.
+type commitSpec struct {
+	SHA     string // derived from the other fields when empty
+	Message string
+	Time    time.Time // both the author and the committer date
+	Author  string
+	Email   string // Author + "@example.com" when empty
+	// Login is the GitHub account the commit is attributed to
+	Login string
+	// Committer and CommitterEmail default to the author's
+	Committer      string
+	CommitterEmail string
+	Verified       bool
+	Parents        int
+}
+
+// buildCommit fills in the nested pointer fields of the commit GitHub would
+// return for spec. The same spec always builds the same commit.
+func buildCommit(spec commitSpec) *github.RepositoryCommit {
+	if spec.SHA == "" {
+		spec.SHA = fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%+v", spec))))
+	}
+	if spec.Email == "" && spec.Author != "" {
+		spec.Email = spec.Author + "@example.com"
+	}
+	if spec.Committer == "" {
+		spec.Committer, spec.CommitterEmail = spec.Author, spec.Email
+	}
+
+	commit := &github.RepositoryCommit{
+		SHA:    github.String(spec.SHA),
+		Commit: &github.Commit{Message: github.String(spec.Message)},
+	}
+	var when *github.Timestamp
+	if !spec.Time.IsZero() {
+		when = &github.Timestamp{Time: spec.Time}
+	}
+	if spec.Author != "" {
+		commit.Commit.Author = &github.CommitAuthor{Name: github.String(spec.Author), Email: github.String(spec.Email), Date: when}
+	}
+	if spec.Committer != "" {
+		commit.Commit.Committer = &github.CommitAuthor{Name: github.String(spec.Committer), Email: github.String(spec.CommitterEmail), Date: when}
+	}
+	if spec.Login != "" {
+		commit.Author = &github.User{Login: github.String(spec.Login)}
+	}
+	if spec.Verified {
+		commit.Commit.Verification = &github.SignatureVerification{Verified: github.Bool(true)}
+	}
+	for i := range spec.Parents {
+		commit.Parents = append(commit.Parents, &github.Commit{SHA: github.String(fmt.Sprintf("%s^%d", spec.SHA, i+1))})
+	}
+	return commit
+}
+
+// buildCommits builds a commit for each spec.
+func buildCommits(specs ...commitSpec) []*github.RepositoryCommit {
+	commits := make([]*github.RepositoryCommit, len(specs))
+	for i, spec := range specs {
+		commits[i] = buildCommit(spec)
+	}
+	return commits
+}
+
+// normalizedSpecs builds the commits and normalizes them as commits of
+// repo, the form the analyzers take.
+func normalizedSpecs(repo string, specs ...commitSpec) []NormalizedCommit {
+	commits := make([]NormalizedCommit, len(specs))
+	for i, spec := range specs {
+		commits[i] = normalizeCommit(repo, buildCommit(spec))
+	}
+	return commits
+}
@@ -0,0 +1,41 @@
+package main
+
+import "time"
+
+// TimelineBucket is one day of commit activity.
+type TimelineBucket struct {
+	Date    string `json:"date"`
+	Commits int    `json:"commits"`
+}
+
+// buildTimeline buckets commits by calendar day in loc, with one bucket per
+// day from since to until (inclusive) and empty days zero-filled. Commits are
+// deduplicated by SHA so forks of the same history count once. Days are
+// stepped with AddDate rather than 24h so DST changes don't skip or repeat
+// a day.
+func buildTimeline(commits []NormalizedCommit, since, until time.Time, loc *time.Location) []TimelineBucket {
+	counts := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		if commit.SHA != "" {
+			if seen[commit.SHA] {
+				continue
+			}
+			seen[commit.SHA] = true
+		}
+		counts[commit.Date.In(loc).Format(time.DateOnly)]++
+	}
+
+	start := since.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	last := until.In(loc).Format(time.DateOnly)
+	var timeline []TimelineBucket
+	for {
+		date := day.Format(time.DateOnly)
+		timeline = append(timeline, TimelineBucket{Date: date, Commits: counts[date]})
+		if date >= last {
+			return timeline
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
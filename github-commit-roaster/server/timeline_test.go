@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata" // the buckets are pinned to real zone rules
+)
+
+func TestBuildTimeline(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(value string) time.Time {
+		when, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return when
+	}
+	commit := func(sha, value string) NormalizedCommit {
+		return NormalizedCommit{SHA: sha, Date: at(value)}
+	}
+
+	tests := []struct {
+		name         string
+		commits      []NormalizedCommit
+		since, until string
+		loc          *time.Location
+		want         []TimelineBucket
+	}{
+		{
+			"zero-filled across a month boundary",
+			[]NormalizedCommit{commit("a", "2024-01-30T10:00:00Z"), commit("b", "2024-02-01T23:59:59Z"), commit("c", "2024-02-01T00:00:00Z")},
+			"2024-01-30T08:00:00Z", "2024-02-02T08:00:00Z", time.UTC,
+			[]TimelineBucket{{"2024-01-30", 1}, {"2024-01-31", 0}, {"2024-02-01", 2}, {"2024-02-02", 0}},
+		},
+		{
+			// 03:00 UTC on the 1st is still leap day evening in New York
+			"time zone shifts the day",
+			[]NormalizedCommit{commit("a", "2024-03-01T03:00:00Z"), commit("b", "2024-03-01T05:00:00Z")},
+			"2024-02-29T12:00:00Z", "2024-03-01T12:00:00Z", newYork,
+			[]TimelineBucket{{"2024-02-29", 1}, {"2024-03-01", 1}},
+		},
+		{
+			// Clocks jump from 02:00 to 03:00 on March 10, a 23-hour day
+			"spring forward",
+			[]NormalizedCommit{commit("a", "2024-03-10T06:30:00Z"), commit("b", "2024-03-11T03:59:00Z"), commit("c", "2024-03-11T04:00:00Z")},
+			"2024-03-09T12:00:00Z", "2024-03-11T12:00:00Z", newYork,
+			[]TimelineBucket{{"2024-03-09", 0}, {"2024-03-10", 2}, {"2024-03-11", 1}},
+		},
+		{
+			// Clocks fall back from 02:00 to 01:00 on November 3, a 25-hour day
+			"fall back",
+			[]NormalizedCommit{commit("a", "2024-11-03T04:00:00Z"), commit("b", "2024-11-04T04:59:00Z"), commit("c", "2024-11-04T05:00:00Z")},
+			"2024-11-02T12:00:00Z", "2024-11-04T12:00:00Z", newYork,
+			[]TimelineBucket{{"2024-11-02", 0}, {"2024-11-03", 2}, {"2024-11-04", 1}},
+		},
+		{
+			"forked history counts once",
+			[]NormalizedCommit{commit("a", "2024-01-30T10:00:00Z"), commit("a", "2024-01-30T10:00:00Z"), commit("", "2024-01-30T11:00:00Z"), commit("", "2024-01-30T11:00:00Z")},
+			"2024-01-30T00:00:00Z", "2024-01-30T23:00:00Z", time.UTC,
+			[]TimelineBucket{{"2024-01-30", 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildTimeline(tt.commits, at(tt.since), at(tt.until), tt.loc)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildTimeline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoastTimelineIsOptIn(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2)))
+	tests := []struct {
+		query string
+		days  int // buckets expected; 0 for none
+	}{
+		{"", 0},
+		{"&timeline=true&days=7", 8},
+	}
+	for _, tt := range tests {
+		w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat"+tt.query, "")
+		var body RoastResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Stats.Timeline) != tt.days {
+			t.Errorf("%q: %d timeline buckets, want %d", tt.query, len(body.Stats.Timeline), tt.days)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import "time"
+
+// Timing breaks down where a roast request spent its time, in milliseconds.
+// It is only returned with ?debug_timing=true (or =detailed for the
+// per-repo commit fetches).
+type Timing struct {
+	UserLookupMs   float64            `json:"user_lookup_ms"`
+	ListReposMs    float64            `json:"list_repos_ms"`
+	FetchCommitsMs float64            `json:"fetch_commits_ms"`
+	PerRepoMs      map[string]float64 `json:"per_repo_ms,omitempty"`
+	AnalyzeMs      float64            `json:"analyze_ms"`
+	EnrichMs       float64            `json:"enrich_ms"` // search, deep mode and deep scan calls
+	RenderMs       float64            `json:"render_ms"`
+	TotalMs        float64            `json:"total_ms"`
+}
+
+// sinceMs returns the time since start in milliseconds. time.Since uses the
+// monotonic clock, so wall clock jumps can't make it negative.
+func sinceMs(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestRoastTiming(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantTiming  bool
+		wantPerRepo bool
+	}{
+		{"off by default", "", false, false},
+		{"summary", "&debug_timing=true", true, false},
+		{"detailed", "&debug_timing=detailed", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2)))
+			w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat"+tt.query, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var body struct {
+				Timing map[string]json.RawMessage `json:"timing"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if (body.Timing != nil) != tt.wantTiming {
+				t.Fatalf("timing = %s, want present %v", body.Timing, tt.wantTiming)
+			}
+			if !tt.wantTiming {
+				return
+			}
+
+			for _, field := range []string{"user_lookup_ms", "list_repos_ms", "fetch_commits_ms", "analyze_ms", "enrich_ms", "render_ms", "total_ms"} {
+				var ms float64
+				if err := json.Unmarshal(body.Timing[field], &ms); err != nil {
+					t.Errorf("%s missing: %v", field, err)
+					continue
+				}
+				if ms < 0 {
+					t.Errorf("%s = %v, want non-negative", field, ms)
+				}
+			}
+			var perRepo map[string]float64
+			json.Unmarshal(body.Timing["per_repo_ms"], &perRepo)
+			if _, ok := perRepo["project"]; ok != tt.wantPerRepo {
+				t.Errorf("per_repo_ms = %v, want per-repo timings %v", perRepo, tt.wantPerRepo)
+			}
+			for repo, ms := range perRepo {
+				if ms < 0 {
+					t.Errorf("per_repo_ms[%s] = %v", repo, ms)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github-commit-roaster"
+
+// tracer is a no-op until setupTracing installs a real provider.
+var tracer = otel.Tracer(tracerName)
+
+// tracingEnabled reports whether the standard OTEL_ variables ask for OTLP
+// trace export. Tracing is off unless an endpoint or exporter is configured.
+func tracingEnabled() bool {
+	if os.Getenv("OTEL_SDK_DISABLED") == "true" {
+		return false
+	}
+	return os.Getenv("OTEL_TRACES_EXPORTER") == "otlp" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// setupTracing installs the W3C trace context propagator and, when enabled,
+// an OTLP/HTTP exporter configured entirely by OTEL_ env vars. The returned
+// function flushes pending spans.
+func setupTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !tracingEnabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES still win over this default
+	res, err := resource.Merge(
+		resource.NewSchemaless(attribute.String("service.name", tracerName)),
+		resource.Default(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// tracingMiddleware starts a server span per request, continuing any trace
+// passed in via the traceparent header.
+func tracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+	}
+}
+
+// startGitHubSpan starts a client span around a single GitHub API call.
+func startGitHubSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "github."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedCache wraps a Cache in a span per call, so cache latency shows up
+// in request traces next to the GitHub calls it saves.
+type tracedCache struct {
+	next Cache
+}
+
+func (t tracedCache) Get(ctx context.Context, key string) (cachedRoast, bool) {
+	ctx, span := tracer.Start(ctx, "cache.Get", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+	value, ok := t.next.Get(ctx, key)
+	span.SetAttributes(attribute.Bool("cache.hit", ok))
+	return value, ok
+}
+
+func (t tracedCache) Set(ctx context.Context, key string, value cachedRoast, ttl time.Duration) {
+	ctx, span := tracer.Start(ctx, "cache.Set", trace.WithAttributes(attribute.String("cache.key", key)))
+	defer span.End()
+	t.next.Set(ctx, key, value, ttl)
+}
+
+// pageAttr reports the page being fetched; go-github uses 0 for the first page.
+func pageAttr(page int) attribute.KeyValue {
+	if page == 0 {
+		page = 1
+	}
+	return attribute.Int("github.page", page)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans points tracer at an in-memory exporter for the rest of the
+// test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	saved, savedPropagator := tracer, otel.GetTextMapPropagator()
+	tracer = provider.Tracer(tracerName)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		tracer = saved
+		otel.SetTextMapPropagator(savedPropagator)
+		provider.Shutdown(t.Context())
+	})
+	return exporter
+}
+
+func TestRoastSpanHierarchy(t *testing.T) {
+	exporter := recordSpans(t)
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix typo", 2)))
+	s.cache = tracedCache{next: newMemoryCache()}
+
+	r := gin.New()
+	r.Use(tracingMiddleware())
+	r.GET("/roast", s.handleRoast)
+	req := httptest.NewRequest(http.MethodGet, "/roast?username=octocat", nil)
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		if span.SpanContext.TraceID().String() != traceID {
+			t.Errorf("span %q is in trace %s, want the incoming %s", span.Name, span.SpanContext.TraceID(), traceID)
+		}
+		if _, seen := byName[span.Name]; !seen {
+			byName[span.Name] = span
+		}
+	}
+
+	root, ok := byName["GET /roast"]
+	if !ok {
+		t.Fatalf("no server span among %v", spanNames(spans))
+	}
+	if root.Parent.SpanID().String() != parentID || !root.Parent.IsRemote() {
+		t.Errorf("server span parent = %s, want the remote %s", root.Parent.SpanID(), parentID)
+	}
+	if root.SpanKind != trace.SpanKindServer {
+		t.Errorf("server span kind = %v", root.SpanKind)
+	}
+
+	tests := []struct {
+		span, parent string
+	}{
+		{"cache.Get", "GET /roast"},
+		{"fetch", "GET /roast"},
+		{"analyze", "GET /roast"},
+		{"enrich", "GET /roast"},
+		{"cache.Set", "GET /roast"},
+		{"github.Users.Get", "fetch"},
+		{"github.Repositories.List", "fetch"},
+		{"github.Repositories.ListCommits", "fetch"},
+		{"github.Search.Commits", "enrich"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.span, func(t *testing.T) {
+			span, ok := byName[tt.span]
+			if !ok {
+				t.Fatalf("span missing from %v", spanNames(spans))
+			}
+			if want := byName[tt.parent].SpanContext.SpanID(); span.Parent.SpanID() != want {
+				t.Errorf("parent = %s, want %s (%s)", span.Parent.SpanID(), want, tt.parent)
+			}
+		})
+	}
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name
+	}
+	return names
+}
+
+func TestTracingEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"nothing set", nil, false},
+		{"endpoint", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318"}, true},
+		{"traces endpoint", map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://collector:4318/v1/traces"}, true},
+		{"otlp exporter", map[string]string{"OTEL_TRACES_EXPORTER": "otlp"}, true},
+		{"other exporter", map[string]string{"OTEL_TRACES_EXPORTER": "console"}, false},
+		{"sdk disabled", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_SDK_DISABLED": "true"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"OTEL_SDK_DISABLED", "OTEL_TRACES_EXPORTER", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := tracingEnabled(); got != tt.want {
+				t.Errorf("tracingEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
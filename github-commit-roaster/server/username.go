@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// maxUsernameLength is the longest username GitHub allows.
+const maxUsernameLength = 39
+
+// usernamePattern is GitHub's username alphabet: letters, digits and
+// hyphens, not starting or ending with a hyphen. Consecutive hyphens are
+// allowed because some older accounts have them.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+
+// validateGitHubUsername rejects names GitHub can't have, so they're
+// answered with 400 rather than spending an API call on a sure 404.
+func validateGitHubUsername(username string) error {
+	switch {
+	case username == "":
+		return errors.New("username is required")
+	case len(username) > maxUsernameLength:
+		return fmt.Errorf("username can be at most %d characters", maxUsernameLength)
+	case !usernamePattern.MatchString(username):
+		return errors.New("username may only contain letters, digits and hyphens, and can't start or end with a hyphen")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// usernameSeeds are usernames with a known answer: true for names GitHub
+// allows.
+var usernameSeeds = map[string]bool{
+	"octocat":                true,
+	"Octocat":                true,
+	"torvalds":               true,
+	"a":                      true,
+	"0":                      true,
+	"A1":                     true,
+	"octo-cat":               true,
+	"x-y-z":                  true,
+	"legacy--name":           true,
+	strings.Repeat("a", 39):  true,
+	"":                       false,
+	"-octocat":               false,
+	"octocat-":               false,
+	"octo cat":               false,
+	"octo_cat":               false,
+	"octo.cat":               false,
+	strings.Repeat("a", 40):  false,
+	"../etc/passwd":          false,
+	"octocat?username=hubot": false,
+	"ünïcode":                false,
+}
+
+func FuzzValidateGitHubUsername(f *testing.F) {
+	for username := range usernameSeeds {
+		f.Add(username)
+	}
+	f.Fuzz(func(t *testing.T, username string) {
+		err := validateGitHubUsername(username)
+		if want, known := usernameSeeds[username]; known && (err == nil) != want {
+			t.Fatalf("validateGitHubUsername(%q) = %v, want valid %v", username, err, want)
+		}
+		if err != nil {
+			return
+		}
+		if len(username) > maxUsernameLength || strings.HasPrefix(username, "-") || strings.HasSuffix(username, "-") {
+			t.Fatalf("validateGitHubUsername(%q) accepted an impossible name", username)
+		}
+	})
+}
+
+func TestRoastRejectsInvalidUsername(t *testing.T) {
+	gh := newFakeGitHub("octocat")
+	s := newTestServer(t, gh)
+	for _, target := range []string{"/roast", "/roast?username=-octocat", "/roast?username=octo_cat"} {
+		if w := doRequest(s.handleRoast, http.MethodGet, "/roast", target, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", target, w.Code)
+		}
+	}
+	if calls := gh.callCount("/users/-octocat") + gh.callCount("/users/octo_cat"); calls != 0 {
+		t.Errorf("invalid usernames cost %d GitHub calls", calls)
+	}
+}
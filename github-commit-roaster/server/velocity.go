@@ -0,0 +1,68 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// A typical active developer pushes this many commits a year.
+const (
+	typicalYearlyCommitsLow  = 100
+	typicalYearlyCommitsHigh = 500
+)
+
+const (
+	// oldAccountDays is the account age past which a slow pace is no
+	// longer new-user shyness.
+	oldAccountDays = 5 * 365
+	// slowYearlyPace is a yearly pace low enough to roast an old account.
+	slowYearlyPace = 50
+)
+
+// Values of CommitVelocityContext.GlobalAverageComparison.
+const (
+	velocityAboveAverage = "above_average"
+	velocityAverage      = "average"
+	velocityBelowAverage = "below_average"
+)
+
+// CommitVelocityContext puts the window's commit count next to the age of
+// the account: 100 commits mean more from a 3-month-old account than from
+// a 10-year-old one.
+type CommitVelocityContext struct {
+	AccountAgeDays int `json:"account_age_days"`
+	// YearlyPace is the window's commits extrapolated to a year
+	YearlyPace int `json:"yearly_pace"`
+	// LifetimeCommitsEstimate is the account's age at the window's pace
+	LifetimeCommitsEstimate int `json:"lifetime_commits_estimate"`
+	// GlobalAverageComparison places YearlyPace against the typical 100 to
+	// 500 commits a year: "above_average", "average" or "below_average"
+	GlobalAverageComparison string `json:"global_average_comparison"`
+}
+
+// commitVelocity extrapolates commits, counted between since and until, to
+// a yearly pace and to the lifetime of an account created at created.
+func commitVelocity(created, since, until time.Time, commits int, now time.Time) CommitVelocityContext {
+	velocity := CommitVelocityContext{}
+	if !created.IsZero() && created.Before(now) {
+		velocity.AccountAgeDays = int(now.Sub(created).Hours() / 24)
+	}
+	windowDays := math.Max(until.Sub(since).Hours()/24, 1)
+	perDay := float64(commits) / windowDays
+	velocity.YearlyPace = int(math.Round(perDay * 365))
+	velocity.LifetimeCommitsEstimate = int(math.Round(perDay * float64(velocity.AccountAgeDays)))
+	switch {
+	case velocity.YearlyPace > typicalYearlyCommitsHigh:
+		velocity.GlobalAverageComparison = velocityAboveAverage
+	case velocity.YearlyPace >= typicalYearlyCommitsLow:
+		velocity.GlobalAverageComparison = velocityAverage
+	default:
+		velocity.GlobalAverageComparison = velocityBelowAverage
+	}
+	return velocity
+}
+
+// slowVeteran reports whether an old account is committing at a crawl.
+func (v CommitVelocityContext) slowVeteran() bool {
+	return v.AccountAgeDays >= oldAccountDays && v.YearlyPace < slowYearlyPace
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCommitVelocity(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	month := now.AddDate(0, 0, -30)
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+	tests := []struct {
+		name    string
+		created time.Time
+		since   time.Time
+		commits int
+		want    CommitVelocityContext
+	}{
+		{
+			"new account, busy month",
+			days(90), month, 100,
+			CommitVelocityContext{AccountAgeDays: 90, YearlyPace: 1217, LifetimeCommitsEstimate: 300, GlobalAverageComparison: velocityAboveAverage},
+		},
+		{
+			"ten-year-old account, same month",
+			days(3650), month, 100,
+			CommitVelocityContext{AccountAgeDays: 3650, YearlyPace: 1217, LifetimeCommitsEstimate: 12167, GlobalAverageComparison: velocityAboveAverage},
+		},
+		{
+			"typical pace",
+			days(1000), month, 20,
+			CommitVelocityContext{AccountAgeDays: 1000, YearlyPace: 243, LifetimeCommitsEstimate: 667, GlobalAverageComparison: velocityAverage},
+		},
+		{
+			"lower edge of typical",
+			days(1000), days(365), 100,
+			CommitVelocityContext{AccountAgeDays: 1000, YearlyPace: 100, LifetimeCommitsEstimate: 274, GlobalAverageComparison: velocityAverage},
+		},
+		{
+			"upper edge of typical",
+			days(1000), days(365), 500,
+			CommitVelocityContext{AccountAgeDays: 1000, YearlyPace: 500, LifetimeCommitsEstimate: 1370, GlobalAverageComparison: velocityAverage},
+		},
+		{
+			"slow",
+			days(2000), month, 2,
+			CommitVelocityContext{AccountAgeDays: 2000, YearlyPace: 24, LifetimeCommitsEstimate: 133, GlobalAverageComparison: velocityBelowAverage},
+		},
+		{
+			"unknown creation date",
+			time.Time{}, month, 30,
+			CommitVelocityContext{YearlyPace: 365, GlobalAverageComparison: velocityAverage},
+		},
+		{
+			"created in the future",
+			now.Add(time.Hour), month, 0,
+			CommitVelocityContext{GlobalAverageComparison: velocityBelowAverage},
+		},
+		{
+			"window under a day",
+			days(1000), now.Add(-time.Hour), 1,
+			CommitVelocityContext{AccountAgeDays: 1000, YearlyPace: 365, LifetimeCommitsEstimate: 1000, GlobalAverageComparison: velocityAverage},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitVelocity(tt.created, tt.since, now, tt.commits, now); got != tt.want {
+				t.Errorf("commitVelocity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlowPaceRule(t *testing.T) {
+	rule := findRule(t, "slow_pace")
+	tests := []struct {
+		name     string
+		velocity CommitVelocityContext
+		want     bool
+	}{
+		{"old and slow", CommitVelocityContext{AccountAgeDays: oldAccountDays, YearlyPace: 24}, true},
+		{"new and slow", CommitVelocityContext{AccountAgeDays: oldAccountDays - 1, YearlyPace: 24}, false},
+		{"old and steady", CommitVelocityContext{AccountAgeDays: 4000, YearlyPace: slowYearlyPace}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 2, VelocityContext: tt.velocity}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Fatalf("triggered = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				want := "At your current pace you'll push 24 commits this year. My Roomba does more meaningful work."
+				if line := rule.line(stats, defaultIntensity); line != want {
+					t.Errorf("line = %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestRoastVelocityContext(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix", 1), fakeCommit("Octo", "fix", 2)))
+	w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Stats struct {
+			VelocityContext CommitVelocityContext `json:"velocity_context"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	// The fake account was created in 2015; two commits in 30 days is 24 a year
+	v := body.Stats.VelocityContext
+	if v.AccountAgeDays < 9*365 || v.YearlyPace != 24 || v.GlobalAverageComparison != velocityBelowAverage {
+		t.Errorf("velocity_context = %+v", v)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+// maxVerdictLength bounds a verdict, in characters, so it fits badges, page
+// titles and notification previews.
+const maxVerdictLength = 80
+
+// Verdicts for roasts no rule fires on.
+var (
+	noCommitsVerdicts = map[string]string{
+		defaultCatalogLang: "No commits lately. Are you even a developer?",
+		"es":               "Sin commits últimamente. ¿Seguro que programas?",
+	}
+	cleanVerdicts = map[string]string{
+		defaultCatalogLang: "Suspiciously clean. Are you even trying?",
+		"es":               "Sospechosamente limpio. ¿Lo estás intentando siquiera?",
+	}
+)
+
+// verdictTemplates are the one-line verdicts per rule, by language. They
+// sum up the worst finding in at most maxVerdictLength characters, without
+// numbers, so every one can be checked against the bound as written.
+var verdictTemplates = map[string]map[string]string{
+	defaultCatalogLang: {
+		"late_night":               "Commits at night, sleeps never.",
+		"nine_to_five":             "Codes strictly nine to five, not a minute more.",
+		"off_hours":                "Commits everywhere except the day job.",
+		"sleepless_percentile":     "More sleep-deprived than nearly everyone roasted today.",
+		"weekends_only":            "A weekend warrior with a weekday alibi.",
+		"nonstop":                  "Commits like the keyboard is on fire.",
+		"swearing":                 "Commit log rated R for language.",
+		"merges":                   "Merges more than codes.",
+		"branch_merges":            "A git graph made of spaghetti.",
+		"web_editor":               "Codes in a browser textarea.",
+		"designer":                 "More CSS than JavaScript. Designer in disguise.",
+		"untested":                 "Tests? Most repos run on hope and prayers.",
+		"no_gitignore":             "No .gitignore, .DS_Store everywhere.",
+		"shell_heavy":              "More shell scripts than application code.",
+		"squash_merges":            "Squashes every trace of how the sausage was made.",
+		"fixes":                    "Mostly fixing their own bugs.",
+		"fixups":                   "Forgot to squash the fixups.",
+		"generic_messages":         "Every commit message says \"update\".",
+		"empty_messages":           "Commit messages left blank.",
+		"veteran_low_activity":     "A veteran in semi-retirement.",
+		"slow_pace":                "Commits at the pace of continental drift.",
+		"solo":                     "Codes alone, reviewed by no one.",
+		"collaborator":             "Never codes without a chaperone.",
+		"no_commit_body":           "Commit messages with no body, all headline.",
+		"no_issue_links":           "Commits and issues live separate lives.",
+		"over_linked":              "Opens a ticket to fix a typo.",
+		"bare_issue_refs":          "Commit messages that are just \"#123\".",
+		"unsigned":                 "Not a single signed commit.",
+		"always_signed":            "Signs every commit, trusts no one.",
+		"dmca":                     "Got DMCA'd. Finally, code somebody cared about.",
+		"serial_starter":           "Starts projects, finishes none.",
+		"abandoned_projects":       "Abandons projects after a week.",
+		"famous_abandoned":         "Famous repo, absent maintainer.",
+		"nobody_cares":             "Lots of commits, not a single star.",
+		"initial_commit_graveyard": "A graveyard of initial commits.",
+		"mixed_scripts":            "Commit messages in a babel of scripts.",
+		"cross_repo_duplicates":    "Copy-pastes commit messages across repos.",
+		"automation":               "Mostly bots doing the work.",
+		"borrowed_glory":           "Commits authored by someone else.",
+		"code_profanity":           "The code comments swear more than the commits.",
+		"doc_only":                 "Commits mostly docs, barely code.",
+		"shouting":                 "COMMIT MESSAGES IN ALL CAPS.",
+		"debt_markers":             "TODOs everywhere, done nowhere.",
+		"no_follow_through":        "Opens issues, closes none.",
+		"vague_vocabulary":         "Commit vocabulary: \"stuff\" and \"things\".",
+		"noreply_only":             "Hides behind a noreply address.",
+		"typos":                    "Commit messages full of typos.",
+		"giant_commits":            "Commits the size of a novel.",
+		"one_liners":               "One-line commits, over and over.",
+		"many_names":               "Commits under more aliases than a spy.",
+	},
+	"es": {
+		"late_night":               "Hace commits de noche y no duerme nunca.",
+		"nine_to_five":             "Programa de nueve a cinco, ni un minuto más.",
+		"off_hours":                "Hace commits en todas partes menos en el trabajo.",
+		"sleepless_percentile":     "Duerme menos que casi todos los analizados hoy.",
+		"weekends_only":            "Guerrero de fin de semana con coartada entre semana.",
+		"nonstop":                  "Hace commits como si el teclado estuviera en llamas.",
+		"swearing":                 "Historial de commits no apto para menores.",
+		"merges":                   "Hace más merges que código.",
+		"branch_merges":            "Un grafo de git hecho de espaguetis.",
+		"web_editor":               "Programa en un formulario del navegador.",
+		"designer":                 "Más CSS que JavaScript. Diseñador encubierto.",
+		"untested":                 "¿Tests? Casi todos sus repos viven de fe y oraciones.",
+		"no_gitignore":             "Sin .gitignore, con .DS_Store por todas partes.",
+		"shell_heavy":              "Más scripts de shell que código de aplicación.",
+		"squash_merges":            "Aplasta con squash cualquier rastro del proceso.",
+		"fixes":                    "Casi siempre arreglando sus propios bugs.",
+		"fixups":                   "Se le olvidó hacer squash de los fixups.",
+		"generic_messages":         "Todos sus mensajes dicen \"update\".",
+		"empty_messages":           "Mensajes de commit en blanco.",
+		"veteran_low_activity":     "Un veterano en semijubilación.",
+		"slow_pace":                "Hace commits a la velocidad de la deriva continental.",
+		"solo":                     "Programa solo y nadie lo revisa.",
+		"collaborator":             "Nunca programa sin carabina.",
+		"no_commit_body":           "Mensajes de commit sin cuerpo, todo titular.",
+		"no_issue_links":           "Sus commits y sus issues viven vidas separadas.",
+		"over_linked":              "Abre un ticket para corregir una errata.",
+		"bare_issue_refs":          "Mensajes de commit que solo dicen \"#123\".",
+		"unsigned":                 "Ni un solo commit firmado.",
+		"always_signed":            "Firma cada commit, no se fía de nadie.",
+		"dmca":                     "Recibió un DMCA. Por fin, código que le importa a alguien.",
+		"serial_starter":           "Empieza proyectos y no termina ninguno.",
+		"abandoned_projects":       "Abandona los proyectos a la semana.",
+		"famous_abandoned":         "Repositorio famoso, mantenedor ausente.",
+		"nobody_cares":             "Muchos commits y ni una estrella.",
+		"initial_commit_graveyard": "Un cementerio de commits iniciales.",
+		"mixed_scripts":            "Mensajes de commit en una babel de alfabetos.",
+		"cross_repo_duplicates":    "Copia y pega mensajes de commit entre repos.",
+		"automation":               "Los bots hacen casi todo el trabajo.",
+		"borrowed_glory":           "Commits escritos por otra persona.",
+		"code_profanity":           "Los comentarios del código dicen más palabrotas que los commits.",
+		"doc_only":                 "Casi todo documentación, casi nada de código.",
+		"shouting":                 "MENSAJES DE COMMIT EN MAYÚSCULAS.",
+		"debt_markers":             "TODOs por todas partes, hechos en ninguna.",
+		"no_follow_through":        "Abre issues y no cierra ninguno.",
+		"vague_vocabulary":         "Vocabulario de commits: \"cosas\" y \"cambios\".",
+		"noreply_only":             "Se esconde tras una dirección noreply.",
+		"typos":                    "Mensajes de commit llenos de erratas.",
+		"giant_commits":            "Commits del tamaño de una novela.",
+		"one_liners":               "Commits de una línea, una y otra vez.",
+		"many_names":               "Hace commits con más alias que un espía.",
+	},
+}
+
+// verdictLang is lang when there are verdicts in it, else English.
+func verdictLang(lang string) string {
+	if _, ok := verdictTemplates[lang]; ok {
+		return lang
+	}
+	return defaultCatalogLang
+}
+
+// roastVerdict sums up the roast in one line: the verdict of the most
+// damning rule that fires, the same one that tops TriggeredRules. Rules
+// without a verdict in lang fall back to English.
+func roastVerdict(stats CommitStats, weights Weights, lang string) string {
+	lang = verdictLang(lang)
+	if stats.TotalCommits == 0 {
+		return noCommitsVerdicts[lang]
+	}
+	for _, id := range rankedRules(stats, weights) {
+		if verdict, ok := verdictTemplates[lang][id]; ok {
+			return verdict
+		}
+		if verdict, ok := verdictTemplates[defaultCatalogLang][id]; ok {
+			return verdict
+		}
+	}
+	return cleanVerdicts[lang]
+}
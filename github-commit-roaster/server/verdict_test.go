@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestVerdictLength(t *testing.T) {
+	check := func(lang, id, verdict string) {
+		t.Helper()
+		if verdict == "" {
+			t.Errorf("%s/%s: empty verdict", lang, id)
+		}
+		if n := utf8.RuneCountInString(verdict); n > maxVerdictLength {
+			t.Errorf("%s/%s: %d characters, over %d: %q", lang, id, n, maxVerdictLength, verdict)
+		}
+	}
+	for lang, templates := range verdictTemplates {
+		for id, verdict := range templates {
+			check(lang, id, verdict)
+		}
+	}
+	for lang := range verdictTemplates {
+		check(lang, "no commits", noCommitsVerdicts[lang])
+		check(lang, "clean", cleanVerdicts[lang])
+	}
+}
+
+func TestVerdictTemplatesCoverRules(t *testing.T) {
+	known := make(map[string]bool)
+	for _, rule := range roastRules {
+		known[rule.ID] = true
+		if _, ok := verdictTemplates[defaultCatalogLang][rule.ID]; !ok {
+			t.Errorf("rule %s has no English verdict", rule.ID)
+		}
+	}
+	for lang, templates := range verdictTemplates {
+		for id := range templates {
+			if !known[id] {
+				t.Errorf("%s verdict for unknown rule %s", lang, id)
+			}
+		}
+	}
+}
+
+func TestRoastVerdict(t *testing.T) {
+	// Swearing outweighs fixes and late nights, as in TestRoastLinesMaxLines;
+	// a habit of work hours, issue links and half-signed commits keeps the
+	// rules that fire on their absence quiet
+	clean := CommitStats{TotalCommits: 10, WorkHoursShare: 0.5, IssueReferences: IssueReferenceStats{LinkedCommits: 2}, Verification: VerificationStats{VerifiedCount: 5, UnverifiedCount: 5}}
+	sinner := clean
+	sinner.SwearWords, sinner.FixCommits, sinner.LateNightCommits = 5, 8, 6
+	tests := []struct {
+		name  string
+		stats CommitStats
+		lang  string
+		want  string
+	}{
+		{"worst rule", sinner, defaultCatalogLang, verdictTemplates[defaultCatalogLang]["swearing"]},
+		{"worst rule in Spanish", sinner, "es", verdictTemplates["es"]["swearing"]},
+		{"unknown language", sinner, "xx", verdictTemplates[defaultCatalogLang]["swearing"]},
+		{"no commits", CommitStats{}, defaultCatalogLang, noCommitsVerdicts[defaultCatalogLang]},
+		{"no commits in Spanish", CommitStats{}, "es", noCommitsVerdicts["es"]},
+		{"nothing fires", clean, "es", cleanVerdicts["es"]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.want == "" {
+				t.Fatal("no expected verdict; the template is missing")
+			}
+			for range 3 {
+				if got := roastVerdict(tt.stats, defaultWeights(), tt.lang); got != tt.want {
+					t.Fatalf("roastVerdict() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+// VerificationStats counts GPG/SSH-signed commits GitHub could verify.
+type VerificationStats struct {
+	VerifiedCount   int     `json:"verified_count"`
+	UnverifiedCount int     `json:"unverified_count"`
+	VerifiedRatio   float64 `json:"verified_ratio"`
+}
+
+// detectVerification counts verified commits. The verification status comes
+// with the commit list, so this costs no extra API calls.
+func detectVerification(commits []NormalizedCommit) VerificationStats {
+	var stats VerificationStats
+	for _, commit := range commits {
+		if commit.Verified {
+			stats.VerifiedCount++
+		} else {
+			stats.UnverifiedCount++
+		}
+	}
+	stats.VerifiedRatio = share(stats.VerifiedCount, len(commits))
+	return stats
+}
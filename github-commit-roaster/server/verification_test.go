@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// signedCommit is a commit whose signature GitHub did or didn't verify.
+func signedCommit(verified bool) *github.RepositoryCommit {
+	return &github.RepositoryCommit{Commit: &github.Commit{
+		Message:      github.String("change"),
+		Verification: &github.SignatureVerification{Verified: github.Bool(verified)},
+	}}
+}
+
+func TestDetectVerification(t *testing.T) {
+	unsignedCommit := &github.RepositoryCommit{Commit: &github.Commit{Message: github.String("change")}}
+	tests := []struct {
+		name    string
+		commits []*github.RepositoryCommit
+		want    VerificationStats
+	}{
+		{"no commits", nil, VerificationStats{}},
+		{
+			"mixed",
+			[]*github.RepositoryCommit{signedCommit(true), signedCommit(false), signedCommit(true), unsignedCommit},
+			VerificationStats{VerifiedCount: 2, UnverifiedCount: 2, VerifiedRatio: 0.5},
+		},
+		{"all verified", []*github.RepositoryCommit{signedCommit(true), signedCommit(true)}, VerificationStats{VerifiedCount: 2, VerifiedRatio: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits := make([]NormalizedCommit, len(tt.commits))
+			for i, c := range tt.commits {
+				commits[i] = normalizeCommit("project", c)
+			}
+			if got := detectVerification(commits); got != tt.want {
+				t.Errorf("detectVerification() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerificationRules(t *testing.T) {
+	tests := []struct {
+		name             string
+		total            int
+		verified         int
+		unsigned, signed bool
+	}{
+		{"too few to tell", 4, 0, false, false},
+		{"never signed", 5, 0, true, false},
+		{"sometimes signed", 5, 3, false, false},
+		{"always signed", 5, 5, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: tt.total, Verification: VerificationStats{
+				VerifiedCount: tt.verified, UnverifiedCount: tt.total - tt.verified,
+			}}
+			if got := findRule(t, "unsigned").Triggered(stats); got != tt.unsigned {
+				t.Errorf("unsigned triggered = %v, want %v", got, tt.unsigned)
+			}
+			if got := findRule(t, "always_signed").Triggered(stats); got != tt.signed {
+				t.Errorf("always_signed triggered = %v, want %v", got, tt.signed)
+			}
+		})
+	}
+}
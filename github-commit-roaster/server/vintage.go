@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Developer vintages, by the year of the first public commit.
+const (
+	vintageVeteran     = "veteran"     // before 2015
+	vintageEstablished = "established" // 2015 to 2020
+	vintageNewcomer    = "newcomer"    // 2021 onwards
+)
+
+// firstCommitTTL is how long a user's first commit date is remembered. It
+// only moves when older history is pushed or made public.
+const firstCommitTTL = 24 * time.Hour
+
+// searchCooldown is how long to stop searching after a search rate limit
+// that didn't say when it resets.
+const searchCooldown = time.Minute
+
+// firstCommitCache remembers first commit dates per user. The commit search
+// API allows only 10 to 30 requests a minute, far fewer than roasts, batch
+// and team members can ask for, so each user is searched once a day and
+// not at all while the search limit is exhausted.
+type firstCommitCache struct {
+	now func() time.Time
+
+	mu           sync.Mutex
+	entries      map[string]firstCommitEntry
+	blockedUntil time.Time // the search rate limit resets then
+}
+
+type firstCommitEntry struct {
+	first     *time.Time // nil when the user has no public commits
+	expiresAt time.Time
+}
+
+func newFirstCommitCache() *firstCommitCache {
+	return &firstCommitCache{now: time.Now, entries: make(map[string]firstCommitEntry)}
+}
+
+// firstCommitDate returns username's earliest public commit, searching
+// only when it isn't cached and the search limit allows. Failed searches
+// return nil and are retried on a later roast.
+func (f *firstCommitCache) firstCommitDate(ctx context.Context, client *ghclient.Client, username string) *time.Time {
+	key := strings.ToLower(username)
+	now := f.now()
+	f.mu.Lock()
+	entry, cached := f.entries[key]
+	blocked := now.Before(f.blockedUntil)
+	f.mu.Unlock()
+	if cached && now.Before(entry.expiresAt) {
+		return entry.first
+	}
+	if blocked {
+		return nil
+	}
+
+	first, err := searchFirstCommit(ctx, client, username)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err != nil {
+		if until, limited := searchLimitedUntil(err, now); limited {
+			f.blockedUntil = until
+		}
+		return nil
+	}
+	// Drop expired entries as we go so the map doesn't grow forever
+	for k, entry := range f.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(f.entries, k)
+		}
+	}
+	f.entries[key] = firstCommitEntry{first: first, expiresAt: now.Add(firstCommitTTL)}
+	return first
+}
+
+// searchLimitedUntil reports whether err is the search API's rate limit,
+// and when to search again.
+func searchLimitedUntil(err error, now time.Time) (time.Time, bool) {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		if reset := rateErr.Rate.Reset.Time; reset.After(now) {
+			return reset, true
+		}
+		return now.Add(searchCooldown), true
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if wait := abuseErr.GetRetryAfter(); wait > 0 {
+			return now.Add(wait), true
+		}
+		return now.Add(searchCooldown), true
+	}
+	return time.Time{}, false
+}
+
+// searchFirstCommit finds username's earliest public commit with the
+// commit search API; nil without an error means there are none.
+func searchFirstCommit(ctx context.Context, client *ghclient.Client, username string) (*time.Time, error) {
+	opts := &github.SearchOptions{
+		Sort:        "author-date",
+		Order:       "asc",
+		ListOptions: github.ListOptions{PerPage: 1},
+	}
+	spanCtx, span := startGitHubSpan(ctx, "Search.Commits", attribute.String("github.user", username))
+	result, _, err := client.Search.Commits(spanCtx, "author:"+username, opts)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Commits) == 0 {
+		return nil, nil
+	}
+
+	author := result.Commits[0].GetCommit().GetAuthor()
+	if author == nil || author.Date == nil {
+		return nil, nil
+	}
+	first := author.GetDate().Time.UTC()
+	return &first, nil
+}
+
+// developerVintage classifies a developer by their first commit date.
+func developerVintage(first *time.Time) string {
+	switch {
+	case first == nil:
+		return ""
+	case first.Year() < 2015:
+		return vintageVeteran
+	case first.Year() <= 2020:
+		return vintageEstablished
+	}
+	return vintageNewcomer
+}
+
+// vintageIntensity nudges the roast intensity by experience: veterans should
+// know better, newcomers get some slack.
+func vintageIntensity(intensity int, vintage string) int {
+	switch vintage {
+	case vintageVeteran:
+		return min(intensity+1, maxIntensity)
+	case vintageNewcomer:
+		return max(intensity-1, minIntensity)
+	}
+	return intensity
+}
+
+// yearsSince returns the whole years between t and now.
+func yearsSince(t time.Time, now time.Time) int {
+	years := now.Year() - t.Year()
+	if now.YearDay() < t.YearDay() {
+		years--
+	}
+	return years
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ghclient "github-commit-roaster/internal/github"
+
+	"github.com/google/go-github/v50/github"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeveloperVintage(t *testing.T) {
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	tests := []struct {
+		first *time.Time
+		want  string
+	}{
+		{nil, ""},
+		{date(2008, 4, 10), vintageVeteran},
+		{date(2014, 12, 31), vintageVeteran},
+		{date(2015, 1, 1), vintageEstablished},
+		{date(2020, 12, 31), vintageEstablished},
+		{date(2021, 1, 1), vintageNewcomer},
+	}
+	for _, tt := range tests {
+		if got := developerVintage(tt.first); got != tt.want {
+			t.Errorf("developerVintage(%v) = %q, want %q", tt.first, got, tt.want)
+		}
+	}
+}
+
+func TestVintageIntensity(t *testing.T) {
+	tests := []struct {
+		intensity int
+		vintage   string
+		want      int
+	}{
+		{3, vintageVeteran, 4},
+		{5, vintageVeteran, 5},
+		{3, vintageEstablished, 3},
+		{3, vintageNewcomer, 2},
+		{1, vintageNewcomer, 1},
+		{3, "", 3},
+	}
+	for _, tt := range tests {
+		if got := vintageIntensity(tt.intensity, tt.vintage); got != tt.want {
+			t.Errorf("vintageIntensity(%d, %q) = %d, want %d", tt.intensity, tt.vintage, got, tt.want)
+		}
+	}
+}
+
+// firstCommitResult is a search result whose only commit was authored at
+// date.
+func firstCommitResult(date time.Time) *github.CommitsSearchResult {
+	return &github.CommitsSearchResult{Commits: []*github.CommitResult{{
+		Commit: &github.Commit{Author: &github.CommitAuthor{Date: &github.Timestamp{Time: date}}},
+	}}}
+}
+
+func TestFirstCommitCache(t *testing.T) {
+	first := time.Date(2012, 3, 4, 5, 6, 7, 0, time.UTC)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	limited := &github.RateLimitError{
+		Rate:     github.Rate{Reset: github.Timestamp{Time: now.Add(10 * time.Minute)}},
+		Response: &http.Response{Request: httptest.NewRequest(http.MethodGet, "/search/commits", nil)},
+	}
+
+	tests := []struct {
+		name string
+		// results answer successive searches, nil with the date; the cache
+		// is asked at each of the offsets from now
+		results []error
+		offsets []time.Duration
+		want    []bool // whether each lookup returned the date
+	}{
+		{"cached for a day", []error{nil, nil}, []time.Duration{0, time.Hour, firstCommitTTL}, []bool{true, true, true}},
+		{"failures retry", []error{errors.New("boom"), nil}, []time.Duration{0, time.Second}, []bool{false, true}},
+		{"rate limit backs off", []error{limited, nil}, []time.Duration{0, time.Minute, 10 * time.Minute}, []bool{false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			search := ghclient.NewMockSearchService(ctrl)
+			var calls []any
+			for _, err := range tt.results {
+				if err != nil {
+					calls = append(calls, search.EXPECT().Commits(gomock.Any(), "author:Octocat", gomock.Any()).Return(nil, nil, err))
+				} else {
+					calls = append(calls, search.EXPECT().Commits(gomock.Any(), "author:Octocat", gomock.Any()).Return(firstCommitResult(first), nil, nil))
+				}
+			}
+			gomock.InOrder(calls...)
+
+			cache := newFirstCommitCache()
+			client := &ghclient.Client{Search: search}
+			for i, offset := range tt.offsets {
+				cache.now = func() time.Time { return now.Add(offset) }
+				got := cache.firstCommitDate(t.Context(), client, "Octocat")
+				if (got != nil) != tt.want[i] || (got != nil && !got.Equal(first)) {
+					t.Errorf("lookup %d = %v, want date %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFirstCommitCacheNoCommits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	search := ghclient.NewMockSearchService(ctrl)
+	search.EXPECT().Commits(gomock.Any(), "author:ghost", gomock.Any()).Return(&github.CommitsSearchResult{}, nil, nil).Times(1)
+
+	cache := newFirstCommitCache()
+	client := &ghclient.Client{Search: search}
+	for range 2 {
+		if got := cache.firstCommitDate(t.Context(), client, "ghost"); got != nil {
+			t.Errorf("firstCommitDate() = %v, want nil", got)
+		}
+	}
+}
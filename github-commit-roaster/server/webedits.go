@@ -0,0 +1,39 @@
+package main
+
+import "strings"
+
+// webCommitterName and webCommitterEmail are the committer GitHub puts on
+// commits it creates itself, and signs with its own key.
+const (
+	webCommitterName  = "GitHub"
+	webCommitterEmail = "noreply@github.com"
+)
+
+// WebEditStats counts commits typed into GitHub's web editor.
+type WebEditStats struct {
+	WebCommits   int     `json:"web_commits"`
+	WebEditRatio float64 `json:"web_edit_ratio"`
+}
+
+// isWebEdit reports whether commit was made in the browser. GitHub is also
+// the committer of everything its merge button makes, where the author is
+// whoever clicked it or opened the pull request; pull request, branch and
+// squash merges are left out so only file edits count.
+func isWebEdit(commit NormalizedCommit) bool {
+	return commit.Verified &&
+		commit.CommitterName == webCommitterName &&
+		strings.EqualFold(commit.CommitterEmail, webCommitterEmail) &&
+		classifyMerge(commit) == notAMerge
+}
+
+// detectWebEdits counts the commits made in GitHub's web editor.
+func detectWebEdits(commits []NormalizedCommit) WebEditStats {
+	var stats WebEditStats
+	for _, commit := range commits {
+		if isWebEdit(commit) {
+			stats.WebCommits++
+		}
+	}
+	stats.WebEditRatio = share(stats.WebCommits, len(commits))
+	return stats
+}
@@ -0,0 +1,95 @@
+package main
+
+import "testing"
+
+// webEdit is a file edited in GitHub's web editor.
+var webEdit = commitSpec{
+	Message: "Update README.md", Author: "Octo",
+	Committer: "GitHub", CommitterEmail: "noreply@github.com", Verified: true, Parents: 1,
+}
+
+// withSpec returns a copy of spec changed by edit.
+func withSpec(spec commitSpec, edit func(*commitSpec)) commitSpec {
+	edit(&spec)
+	return spec
+}
+
+func TestIsWebEdit(t *testing.T) {
+	mergeButton := withSpec(webEdit, func(c *commitSpec) {
+		c.Message, c.Parents = "Merge pull request #5 from mona/feature\n\nAdd search", 2
+	})
+	tests := []struct {
+		name string
+		spec commitSpec
+		want bool
+	}{
+		{"edited in the browser", webEdit, true},
+		{"committer email in another case", withSpec(webEdit, func(c *commitSpec) { c.CommitterEmail = "NoReply@GitHub.com" }), true},
+		{"pushed from a terminal", commitSpec{Message: "fix parser", Author: "Octo", Verified: true, Parents: 1}, false},
+		{"claims to be GitHub, unsigned", withSpec(webEdit, func(c *commitSpec) { c.Verified = false }), false},
+		{"merge button on someone else's pull request", mergeButton, false},
+		{"merge button on own pull request", withSpec(mergeButton, func(c *commitSpec) { c.Message = "Merge pull request #6 from octo/fix" }), false},
+		{"branch updated from the pull request page", withSpec(mergeButton, func(c *commitSpec) { c.Message = "Merge branch 'main' into feature" }), false},
+		{"squash merge", withSpec(webEdit, func(c *commitSpec) { c.Message, c.Author = "Add search (#12)", "Mona" }), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWebEdit(normalizeCommit("project", buildCommit(tt.spec))); got != tt.want {
+				t.Errorf("isWebEdit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectWebEdits(t *testing.T) {
+	local := commitSpec{Message: "fix parser", Author: "Octo", Parents: 1}
+	merge := withSpec(webEdit, func(c *commitSpec) { c.Message, c.Parents = "Merge pull request #5 from mona/feature", 2 })
+	tests := []struct {
+		name  string
+		specs []commitSpec
+		want  WebEditStats
+	}{
+		{"no commits", nil, WebEditStats{}},
+		{"all local", []commitSpec{local, local}, WebEditStats{}},
+		{"merges don't count", []commitSpec{webEdit, merge, merge, local}, WebEditStats{WebCommits: 1, WebEditRatio: 0.25}},
+		{
+			"mostly the browser",
+			[]commitSpec{webEdit, webEdit, webEdit, webEdit, webEdit, webEdit, webEdit, local, local, merge},
+			WebEditStats{WebCommits: 7, WebEditRatio: 0.7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectWebEdits(normalizedSpecs("project", tt.specs...)); got != tt.want {
+				t.Errorf("detectWebEdits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebEditorRule(t *testing.T) {
+	rule := findRule(t, "web_editor")
+	tests := []struct {
+		name  string
+		edits WebEditStats
+		want  bool
+	}{
+		{"mostly the browser", WebEditStats{WebCommits: 7, WebEditRatio: 0.7}, true},
+		{"too few to tell", WebEditStats{WebCommits: 2, WebEditRatio: 1}, false},
+		{"under half", WebEditStats{WebCommits: 4, WebEditRatio: 0.4}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, WebEdits: tt.edits}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Fatalf("triggered = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				want := "70% of your commits were typed into a web form — have you met the terminal?"
+				if line := rule.line(stats, defaultIntensity); line != want {
+					t.Errorf("line = %q", line)
+				}
+			}
+		})
+	}
+}
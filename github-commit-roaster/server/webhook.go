@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v50/github"
+)
+
+// validSignature checks an X-Hub-Signature-256 header ("sha256=<hex>")
+// against the HMAC of payload.
+func validSignature(payload []byte, header string, secret []byte) bool {
+	sigHex, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	sig, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// normalizeHeadCommit converts a commit from a push event payload.
+func normalizeHeadCommit(repo string, commit *github.HeadCommit) NormalizedCommit {
+	return NormalizedCommit{
+		Repo:        repo,
+		SHA:         commit.GetID(),
+		Message:     commit.GetMessage(),
+		Date:        commit.GetTimestamp().Time,
+		AuthorName:  commit.GetAuthor().GetName(),
+		AuthorEmail: commit.GetAuthor().GetEmail(),
+	}
+}
+
+// webhookHandler roasts the commits of GitHub push events. With comment set,
+// the roast is also posted as a commit comment on the pushed head commit.
+func webhookHandler(clients *clientFactory, cfg RoastConfig, secret string, comment bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "webhook is not configured"})
+			return
+		}
+
+		payload, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "could not read payload"})
+			return
+		}
+		if !validSignature(payload, c.GetHeader("X-Hub-Signature-256"), []byte(secret)) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+			return
+		}
+
+		// Only push events get roasted; everything else (ping included) is acknowledged
+		if github.WebHookType(c.Request) != "push" {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		event, err := github.ParseWebHook("push", payload)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid push payload"})
+			return
+		}
+		push := event.(*github.PushEvent)
+
+		repoName := push.GetRepo().GetName()
+		var commits []NormalizedCommit
+		for _, commit := range push.Commits {
+			commits = append(commits, normalizeHeadCommit(repoName, commit))
+		}
+
+		stats := analyzeCommits(commits, cfg)
+		stats.ReposAnalyzed = 1
+		roast := generateRoast(stats, RoastOptions{Intensity: defaultIntensity})
+
+		commented := false
+		if comment && push.GetHeadCommit().GetID() != "" {
+			ctx := c.Request.Context()
+			client, _ := clients.newClient(ctx)
+			owner := push.GetRepo().GetOwner().GetLogin()
+			body := fmt.Sprintf("🔥 **Commit roast**\n\n%s", roast)
+			_, _, err := client.Repositories.CreateComment(ctx, owner, repoName, push.GetHeadCommit().GetID(), &github.RepositoryComment{Body: &body})
+			if err != nil {
+				slog.Warn("Could not post roast comment", "repo", owner+"/"+repoName, "error", err)
+			} else {
+				commented = true
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"repository": push.GetRepo().GetFullName(),
+			"roast":      roast,
+			"stats":      stats,
+			"commented":  commented,
+		})
+	}
+}
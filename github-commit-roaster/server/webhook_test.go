@@ -0,0 +1,168 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testWebhookSecret = "s3cret"
+
+func sign(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidSignature(t *testing.T) {
+	payload := `{"zen":"Keep it logically awesome."}`
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"valid", sign(payload, testWebhookSecret), true},
+		{"wrong secret", sign(payload, "other"), false},
+		{"other payload", sign(payload+" ", testWebhookSecret), false},
+		{"missing prefix", strings.TrimPrefix(sign(payload, testWebhookSecret), "sha256="), false},
+		{"sha1 header", "sha1=" + strings.TrimPrefix(sign(payload, testWebhookSecret), "sha256="), false},
+		{"not hex", "sha256=zzzz", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validSignature([]byte(payload), tt.header, []byte(testWebhookSecret)); got != tt.want {
+				t.Errorf("validSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+const testPushPayload = `{
+	"ref": "refs/heads/main",
+	"repository": {"name": "project", "full_name": "octocat/project", "owner": {"login": "octocat"}},
+	"head_commit": {"id": "abc123", "message": "fix it again"},
+	"commits": [
+		{"id": "abc121", "message": "fix", "timestamp": "2024-06-01T23:30:00Z", "author": {"name": "Octo", "email": "octo@example.com"}},
+		{"id": "abc122", "message": "fix typo", "timestamp": "2024-06-01T23:40:00Z", "author": {"name": "Octo", "email": "octo@example.com"}},
+		{"id": "abc123", "message": "fix it again", "timestamp": "2024-06-01T23:50:00Z", "author": {"name": "Octo", "email": "octo@example.com"}}
+	]
+}`
+
+func postWebhook(handler gin.HandlerFunc, event, payload, signature string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.POST("/webhook/github", handler)
+	req := httptest.NewRequest(http.MethodPost, "/webhook/github", strings.NewReader(payload))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", signature)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		event     string
+		payload   string
+		signature string
+		status    int
+	}{
+		{"not configured", "", "push", testPushPayload, sign(testPushPayload, testWebhookSecret), http.StatusServiceUnavailable},
+		{"bad signature", testWebhookSecret, "push", testPushPayload, sign(testPushPayload, "other"), http.StatusUnauthorized},
+		{"ping", testWebhookSecret, "ping", `{"zen":"hi"}`, sign(`{"zen":"hi"}`, testWebhookSecret), http.StatusNoContent},
+		{"malformed push", testWebhookSecret, "push", `{"commits":`, sign(`{"commits":`, testWebhookSecret), http.StatusBadRequest},
+		{"push", testWebhookSecret, "push", testPushPayload, sign(testPushPayload, testWebhookSecret), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := webhookHandler(newClientFactory("", "", nil), loadRoastConfig(), tt.secret, false)
+			w := postWebhook(handler, tt.event, tt.payload, tt.signature)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestWebhookRoastsPush(t *testing.T) {
+	var commentPath, commentBody string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		commentPath, commentBody = r.Method+" "+r.URL.Path, string(body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer api.Close()
+	clients := newClientFactory("ghp_secret", "", nil)
+	clients.baseURL, _ = url.Parse(api.URL + "/")
+
+	handler := webhookHandler(clients, loadRoastConfig(), testWebhookSecret, true)
+	w := postWebhook(handler, "push", testPushPayload, sign(testPushPayload, testWebhookSecret))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	var body struct {
+		Repository string      `json:"repository"`
+		Roast      string      `json:"roast"`
+		Stats      CommitStats `json:"stats"`
+		Commented  bool        `json:"commented"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Repository != "octocat/project" || body.Roast == "" || !body.Commented {
+		t.Errorf("response = %+v", body)
+	}
+	if body.Stats.TotalCommits != 3 || body.Stats.FixCommits != 3 || body.Stats.LateNightCommits != 3 {
+		t.Errorf("stats counted %d commits, %d fixes, %d late-night; want 3 of each",
+			body.Stats.TotalCommits, body.Stats.FixCommits, body.Stats.LateNightCommits)
+	}
+	if commentPath != "POST /repos/octocat/project/commits/abc123/comments" {
+		t.Errorf("comment posted to %q", commentPath)
+	}
+	if !strings.Contains(commentBody, "Commit roast") {
+		t.Errorf("comment body = %s", commentBody)
+	}
+}
+
+func TestWebhookBodyLimit(t *testing.T) {
+	r := gin.New()
+	r.Use(limitPostBodies())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.POST("/webhook/github", ok)
+	r.POST("/roast", ok)
+
+	tests := []struct {
+		name   string
+		route  string
+		size   int
+		status int
+	}{
+		{"big push", "/webhook/github", 10 << 20, http.StatusOK},
+		{"GitHub's largest payload", "/webhook/github", maxWebhookBodyBytes, http.StatusOK},
+		{"over GitHub's largest payload", "/webhook/github", maxWebhookBodyBytes + 1, http.StatusRequestEntityTooLarge},
+		{"other routes keep the general limit", "/roast", maxPostBodyBytes + 1, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.route, strings.NewReader(strings.Repeat("x", tt.size)))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
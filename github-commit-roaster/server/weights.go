@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxWeight = 5
+
+// weightMetrics maps the metric names callers use in ?weights= to the
+// pattern scores they weigh.
+var weightMetrics = map[string]string{
+	"latenight":  "late_night",
+	"fixes":      "fixes",
+	"merges":     "merges",
+	"messages":   "generic_messages",
+	"swearing":   "swearing",
+	"automation": "automation",
+}
+
+// reportCategories groups metrics into the report card's subjects. Each
+// metric is a reportScores key; comments are by grade, best first.
+var reportCategories = []reportCategory{
+	{
+		Name:    "Message Quality",
+		Metrics: []string{"generic_messages", "empty_messages", "swearing"},
+		Comments: [len(gradeScale)]string{
+			"Clear, descriptive messages. Your reviewers thank you.",
+			"Mostly readable messages, with the odd \"update\" slipping through.",
+			"Your messages explain what changed about half the time.",
+			"Your commit log reads like a ransom note.",
+			"\"fix\", \"update\", \"asdf\". Future you will not be amused.",
+		},
+	},
+	{
+		Name:    "Work-Life Balance",
+		Metrics: []string{"late_night", "weekend"},
+		Comments: [len(gradeScale)]string{
+			"You commit during daylight hours like a well-adjusted human.",
+			"The occasional late night, nothing a coffee can't fix.",
+			"Your laptop sees more of your evenings than your friends do.",
+			"Sleep is a rumour and weekends are a myth.",
+			"Go outside. Please. The code will still be broken tomorrow.",
+		},
+	},
+	{
+		Name:    "Git Hygiene",
+		Metrics: []string{"merges", "fixes", "fixups", "issue_links"},
+		Comments: [len(gradeScale)]string{
+			"A tidy, linear history. Rebasing suits you.",
+			"A few merge bubbles and fixups, but nothing alarming.",
+			"Your history has more patches than a quilt.",
+			"Every feature ships with a fix for the fix.",
+			"Your git graph looks like a subway map drawn during an earthquake.",
+		},
+	},
+	{
+		Name:    "Consistency",
+		Metrics: []string{"longest_gap", "burstiness"},
+		Comments: [len(gradeScale)]string{
+			"Steady, regular commits. Suspiciously disciplined.",
+			"Mostly steady, with a quiet week here and there.",
+			"You code in bursts, then vanish for a while.",
+			"Feast or famine: marathons followed by radio silence.",
+			"Your contribution graph is mostly whitespace with the occasional explosion.",
+		},
+	},
+}
+
+// reportScores extends patternScores with the metrics only the report card
+// grades. Like pattern scores, 1.0 is the level that would earn a roast.
+func reportScores(stats CommitStats) map[string]float64 {
+	scores := patternScores(stats)
+	if scores == nil {
+		return nil
+	}
+	total := float64(stats.TotalCommits)
+	scores["empty_messages"] = float64(stats.EmptyMessages) / total / 0.1
+	scores["fixups"] = float64(stats.FixupCommits) / total / 0.2
+	scores["weekend"] = float64(stats.WeekendCommits) / total / 0.5
+	scores["longest_gap"] = float64(stats.LongestGapDays) / 14
+	scores["burstiness"] = stats.CommitsPerActiveDay / 10
+	scores["issue_links"] = 1 - stats.IssueReferences.LinkRatio
+	return scores
+}
+
+// Weights scales how much each metric counts towards the severity score.
+// A nil Weights counts every metric once.
+type Weights map[string]int
+
+// defaultWeights counts every metric once.
+func defaultWeights() Weights {
+	w := make(Weights, len(weightMetrics))
+	for metric := range weightMetrics {
+		w[metric] = 1
+	}
+	return w
+}
+
+func (w Weights) weight(metric string) int {
+	if w == nil {
+		return 1
+	}
+	return w[metric]
+}
+
+// parseWeights applies a "messages:3,latenight:0" list on top of base.
+func parseWeights(value string, base Weights) (Weights, error) {
+	w := make(Weights, len(weightMetrics))
+	for metric := range weightMetrics {
+		w[metric] = base.weight(metric)
+	}
+	for _, item := range splitList(value) {
+		metric, raw, ok := strings.Cut(item, ":")
+		metric = strings.ToLower(strings.TrimSpace(metric))
+		if _, known := weightMetrics[metric]; !ok || !known {
+			return nil, fmt.Errorf("unknown weight %q, expected one of %s", item, strings.Join(weightNames(), ", "))
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || n < 0 || n > maxWeight {
+			return nil, fmt.Errorf("weight for %s must be a number from 0 to %d", metric, maxWeight)
+		}
+		w[metric] = n
+	}
+	return w, nil
+}
+
+func weightNames() []string {
+	names := make([]string, 0, len(weightMetrics))
+	for metric := range weightMetrics {
+		names = append(names, metric)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// weightedSeverity is severityScore with each metric scaled by its weight.
+// All-zero weights score 0.
+func weightedSeverity(stats CommitStats, weights Weights) int {
+	scores := patternScores(stats)
+	if len(scores) == 0 {
+		return 0
+	}
+	sum, total := 0.0, 0
+	for metric, pattern := range weightMetrics {
+		w := weights.weight(metric)
+		sum += float64(w) * math.Min(scores[pattern], 2) / 2
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(sum / float64(total) * 100))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseWeights(t *testing.T) {
+	base := defaultWeights()
+	with := func(overrides Weights) Weights {
+		w := maps.Clone(base)
+		maps.Copy(w, overrides)
+		return w
+	}
+	tests := []struct {
+		value   string
+		want    Weights
+		wantErr bool
+	}{
+		{"", base, false},
+		{"messages:3,latenight:0,fixes:2", with(Weights{"messages": 3, "latenight": 0, "fixes": 2}), false},
+		{" Messages : 5 ", with(Weights{"messages": 5}), false},
+		{"tabs:4", nil, true},
+		{"messages", nil, true},
+		{"messages:6", nil, true},
+		{"messages:-1", nil, true},
+		{"messages:lots", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseWeights(tt.value, base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseWeights() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("parseWeights() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeightedSeverity(t *testing.T) {
+	// Fixes sit at twice their threshold, late nights at none
+	stats := CommitStats{TotalCommits: 10, FixCommits: 10}
+	tests := []struct {
+		name    string
+		weights Weights
+		want    int
+	}{
+		{"even weights", defaultWeights(), 17},
+		{"fixes only", Weights{"fixes": 1}, 100},
+		{"fixes tripled", Weights{"fixes": 3, "latenight": 1}, 75},
+		{"fixes ignored", Weights{"fixes": 0, "latenight": 5}, 0},
+		{"all zero", Weights{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weightedSeverity(stats, tt.weights); got != tt.want {
+				t.Errorf("weightedSeverity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if got, want := weightedSeverity(stats, nil), severityScore(stats); got != want {
+		t.Errorf("nil weights score %d, severityScore %d", got, want)
+	}
+}
+
+func TestZeroWeightSilencesRules(t *testing.T) {
+	stats := CommitStats{TotalCommits: 10, FixCommits: 8, LateNightCommits: 8}
+	has := func(weights Weights, rule string) bool {
+		for _, line := range ruleLines(stats, weights, defaultIntensity, defaultPersona) {
+			if line.rule == rule {
+				return true
+			}
+		}
+		return false
+	}
+	silenced := Weights{"latenight": 0, "fixes": 1}
+	if !has(defaultWeights(), "late_night") || has(silenced, "late_night") {
+		t.Error("late_night not silenced by a zero weight")
+	}
+	if !has(silenced, "fixes") {
+		t.Error("fixes silenced along with late_night")
+	}
+}
+
+func TestRoastReportsWeights(t *testing.T) {
+	s := newTestServer(t, newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2)))
+	w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat&weights=messages:3,latenight:0", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Weights Weights `json:"weights"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Weights["messages"] != 3 || body.Weights["latenight"] != 0 || body.Weights["fixes"] != 1 {
+		t.Errorf("effective weights = %v", body.Weights)
+	}
+
+	w = doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat&weights=tabs:4", "")
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unknown weight") {
+		t.Errorf("bad weights: status %d, %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultWindowDays = 30
+	maxWindowDays     = 365
+)
+
+// parseWindow reads the analysis window: either a trailing ?days= window or
+// an explicit ?since=&until= range in RFC3339. A zero until means "now".
+func parseWindow(c *gin.Context) (days int, since, until time.Time, err error) {
+	daysValue, sinceValue, untilValue := c.Query("days"), c.Query("since"), c.Query("until")
+	if daysValue != "" && (sinceValue != "" || untilValue != "") {
+		return 0, since, until, errors.New("days can't be combined with since or until")
+	}
+
+	if daysValue != "" {
+		days, err = strconv.Atoi(daysValue)
+		if err != nil || days < 1 || days > maxWindowDays {
+			return 0, since, until, fmt.Errorf("days must be a number from 1 to %d", maxWindowDays)
+		}
+		return days, since, until, nil
+	}
+	if untilValue != "" && sinceValue == "" {
+		return 0, since, until, errors.New("until requires since")
+	}
+	if sinceValue == "" {
+		return defaultWindowDays, since, until, nil
+	}
+
+	if since, err = time.Parse(time.RFC3339, sinceValue); err != nil {
+		return 0, since, until, errors.New("since must be an RFC3339 timestamp")
+	}
+	if untilValue != "" {
+		if until, err = time.Parse(time.RFC3339, untilValue); err != nil {
+			return 0, since, until, errors.New("until must be an RFC3339 timestamp")
+		}
+		if !since.Before(until) {
+			return 0, since, until, errors.New("since must be before until")
+		}
+	}
+	return 0, since, until, nil
+}
+
+// window returns the time range to analyze as of now.
+func (o RoastOptions) window(now time.Time) (time.Time, time.Time) {
+	if !o.Since.IsZero() {
+		if o.Until.IsZero() {
+			return o.Since, now
+		}
+		return o.Since, o.Until
+	}
+	days := o.Days
+	if days <= 0 {
+		days = defaultWindowDays
+	}
+	return now.AddDate(0, 0, -days), now
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseWindow(t *testing.T) {
+	june := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		query   string
+		days    int
+		since   time.Time
+		until   time.Time
+		wantErr string
+	}{
+		{"default", "", defaultWindowDays, time.Time{}, time.Time{}, ""},
+		{"days", "days=7", 7, time.Time{}, time.Time{}, ""},
+		{"range", "since=2024-06-01T00:00:00Z&until=2024-07-01T00:00:00Z", 0, june, july, ""},
+		{"open range", "since=2024-06-01T00:00:00Z", 0, june, time.Time{}, ""},
+		{"offset", "since=2024-06-01T02:00:00%2B02:00&until=2024-07-01T00:00:00Z", 0, june, july, ""},
+		{"days and since", "days=7&since=2024-06-01T00:00:00Z", 0, time.Time{}, time.Time{}, "days can't be combined with since or until"},
+		{"days and until", "days=7&until=2024-07-01T00:00:00Z", 0, time.Time{}, time.Time{}, "days can't be combined with since or until"},
+		{"until alone", "until=2024-07-01T00:00:00Z", 0, time.Time{}, time.Time{}, "until requires since"},
+		{"bad since", "since=2024-06-01", 0, time.Time{}, time.Time{}, "since must be an RFC3339 timestamp"},
+		{"bad until", "since=2024-06-01T00:00:00Z&until=yesterday", 0, time.Time{}, time.Time{}, "until must be an RFC3339 timestamp"},
+		{"reversed", "since=2024-07-01T00:00:00Z&until=2024-06-01T00:00:00Z", 0, time.Time{}, time.Time{}, "since must be before until"},
+		{"empty range", "since=2024-06-01T00:00:00Z&until=2024-06-01T00:00:00Z", 0, time.Time{}, time.Time{}, "since must be before until"},
+		{"days too large", "days=366", 0, time.Time{}, time.Time{}, "days must be a number from 1 to 365"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/roast?"+tt.query, nil)
+			days, since, until, err := parseWindow(c)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseWindow() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseWindow() error = %v", err)
+			}
+			if days != tt.days || !since.Equal(tt.since) || !until.Equal(tt.until) {
+				t.Errorf("parseWindow() = %d, %v, %v, want %d, %v, %v", days, since, until, tt.days, tt.since, tt.until)
+			}
+		})
+	}
+}
+
+func TestRoastDateRange(t *testing.T) {
+	gh := newFakeGitHub("octocat", fakeCommit("Octo", "fix", 2))
+	s := newTestServer(t, gh)
+
+	w := doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat&since=2024-06-01T00:00:00Z&until=2024-07-01T00:00:00Z", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	query := gh.lastQuery("/repos/octocat/project/commits")
+	if query.Get("since") != "2024-06-01T00:00:00Z" || query.Get("until") != "2024-07-01T00:00:00Z" {
+		t.Errorf("commits listed with %v, want the requested range", query)
+	}
+
+	w = doRequest(s.handleRoast, http.MethodGet, "/roast", "/roast?username=octocat&days=7&since=2024-06-01T00:00:00Z", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("days with since: status = %d, want 400", w.Code)
+	}
+}
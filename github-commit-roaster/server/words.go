@@ -0,0 +1,134 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// topWordsCount is how many of the most used words are reported.
+	topWordsCount = 10
+	// minWordLength drops single letters left over from tokenizing.
+	minWordLength = 2
+	// minShaLength is the shortest run of hex letters taken for a SHA.
+	minShaLength = 7
+)
+
+// WordStats is the word frequency of commit messages, without stopwords.
+// Only computed with ?words=true.
+type WordStats struct {
+	Top           []WordCount `json:"top"`
+	TotalWords    int         `json:"total_words"`
+	DistinctWords int         `json:"distinct_words"`
+	// VagueWord is the most used word when it says nothing, like "stuff"
+	VagueWord string `json:"vague_word,omitempty"`
+}
+
+// WordCount is how often one word was used.
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// defaultStopwords are English filler and git vocabulary that would top
+// everyone's list. STOPWORDS adds to them.
+var defaultStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "by", "for", "from", "in",
+	"into", "is", "it", "its", "of", "on", "or", "so", "that", "the", "this",
+	"to", "was", "with", "we", "i", "not", "no", "but", "all", "now", "when",
+	"up", "out", "if", "then", "than", "also", "some", "more", "can", "use",
+	"merge", "merged", "branch", "pull", "request", "commit", "commits",
+	"master", "main", "origin", "remote", "tracking", "github", "com",
+	"signed", "off", "co", "authored",
+}
+
+// vagueWords say nothing about what changed. One of them topping the list
+// is a vocabulary crime.
+var vagueWords = map[string]bool{
+	"stuff": true, "things": true, "thing": true, "misc": true, "wip": true,
+	"changes": true, "change": true, "update": true, "updates": true,
+	"updated": true, "tweaks": true, "tweak": true, "minor": true,
+	"various": true, "something": true, "asdf": true, "work": true,
+}
+
+// stopwordSet is the default stopwords plus extra, lowercased.
+func stopwordSet(extra []string) map[string]bool {
+	set := make(map[string]bool, len(defaultStopwords)+len(extra))
+	for _, word := range append(append([]string{}, defaultStopwords...), extra...) {
+		set[strings.ToLower(word)] = true
+	}
+	return set
+}
+
+// vocabularyWords is messageWords without the tokens that aren't really
+// words: single letters, anything with a digit (issue numbers, versions,
+// most SHAs) and all-hex runs long enough to be a SHA.
+func vocabularyWords(message string) []string {
+	var words []string
+	for _, word := range messageWords(message) {
+		word = strings.Trim(word, "'")
+		if len([]rune(word)) < minWordLength || strings.IndexFunc(word, unicode.IsDigit) >= 0 || looksLikeSHA(word) {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// looksLikeSHA reports whether word is an abbreviated commit hash made of
+// hex letters only, like "deadbeef".
+func looksLikeSHA(word string) bool {
+	if len(word) < minShaLength {
+		return false
+	}
+	for _, r := range word {
+		if !strings.ContainsRune("abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// countWords is the word frequency over every commit message.
+func countWords(commits []NormalizedCommit, stopwords map[string]bool) WordStats {
+	counts := make(map[string]int)
+	stats := WordStats{Top: []WordCount{}}
+	for _, commit := range commits {
+		for _, word := range vocabularyWords(commit.Message) {
+			if stopwords[word] {
+				continue
+			}
+			counts[word]++
+			stats.TotalWords++
+		}
+	}
+	stats.DistinctWords = len(counts)
+
+	for word, count := range counts {
+		stats.Top = append(stats.Top, WordCount{Word: word, Count: count})
+	}
+	sort.Slice(stats.Top, func(i, j int) bool {
+		if stats.Top[i].Count != stats.Top[j].Count {
+			return stats.Top[i].Count > stats.Top[j].Count
+		}
+		return stats.Top[i].Word < stats.Top[j].Word
+	})
+	if len(stats.Top) > topWordsCount {
+		stats.Top = stats.Top[:topWordsCount]
+	}
+	if len(stats.Top) > 0 && vagueWords[stats.Top[0].Word] {
+		stats.VagueWord = stats.Top[0].Word
+	}
+	return stats
+}
+
+// hasVagueTopWord reports whether any of the top words is vague.
+func (w WordStats) hasVagueTopWord() bool {
+	for _, word := range w.Top {
+		if vagueWords[word.Word] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVocabularyWords(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want []string
+	}{
+		{"Fix the login page", []string{"fix", "the", "login", "page"}},
+		{"ÜBER-Größe für Straße", []string{"über", "größe", "für", "straße"}},
+		{"ПОЧИНИЛ сборку", []string{"починил", "сборку"}},
+		{"Revert deadbeef and cafebabe1", []string{"revert", "and"}},
+		{"facade decade", []string{"facade", "decade"}}, // too short for a SHA
+		{"fixes #123, see v1.2.3", []string{"fixes", "see"}},
+		{"don't 'quote' me", []string{"don't", "quote", "me"}},
+		{"a b c", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := vocabularyWords(tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("vocabularyWords(%q) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		extra    []string
+		top      []WordCount
+		total    int
+		vague    string
+	}{
+		{
+			"stopwords and git terms left out",
+			[]string{"Merge branch 'main' into the login", "fix login", "Fix the Login"},
+			nil,
+			[]WordCount{{"login", 3}, {"fix", 2}},
+			5, "",
+		},
+		{
+			"ties are alphabetical",
+			[]string{"zebra apple", "mango"},
+			nil,
+			[]WordCount{{"apple", 1}, {"mango", 1}, {"zebra", 1}},
+			3, "",
+		},
+		{
+			"vague word on top",
+			[]string{"stuff", "more stuff", "stuff and things", "login"},
+			nil,
+			[]WordCount{{"stuff", 3}, {"login", 1}, {"things", 1}},
+			5, "stuff",
+		},
+		{
+			"extra stopwords",
+			[]string{"fix login", "fix signup"},
+			[]string{"FIX"},
+			[]WordCount{{"login", 1}, {"signup", 1}},
+			2, "",
+		},
+		{
+			"capped at ten",
+			[]string{"alpha bravo charlie delta echo foxtrot golf hotel india juliet kilo lima"},
+			nil,
+			[]WordCount{{"alpha", 1}, {"bravo", 1}, {"charlie", 1}, {"delta", 1}, {"echo", 1}, {"foxtrot", 1}, {"golf", 1}, {"hotel", 1}, {"india", 1}, {"juliet", 1}},
+			12, "",
+		},
+		{"nothing to count", nil, nil, []WordCount{}, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := countWords(messages(tt.messages...), stopwordSet(tt.extra))
+			if !reflect.DeepEqual(got.Top, tt.top) || got.TotalWords != tt.total || got.VagueWord != tt.vague {
+				t.Errorf("countWords() = %+v, want top %+v, %d words, vague %q", got, tt.top, tt.total, tt.vague)
+			}
+		})
+	}
+}
+
+func TestStopwordsConfig(t *testing.T) {
+	t.Setenv("STOPWORDS", "Login, signup")
+	cfg := loadRoastConfig()
+	for _, word := range []string{"login", "signup", "the", "merge"} {
+		if !cfg.Stopwords[word] {
+			t.Errorf("%q isn't a stopword", word)
+		}
+	}
+}
+
+func TestVagueVocabularyRule(t *testing.T) {
+	rule := findRule(t, "vague_vocabulary")
+	tests := []struct {
+		name  string
+		words *WordStats
+		want  bool
+	}{
+		{"words not asked for", nil, false},
+		{"precise", &WordStats{Top: []WordCount{{"login", 9}}}, false},
+		{"vague but rare", &WordStats{Top: []WordCount{{"stuff", 4}}, VagueWord: "stuff"}, false},
+		{"vague and frequent", &WordStats{Top: []WordCount{{"stuff", 5}}, VagueWord: "stuff"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CommitStats{TotalCommits: 10, Words: tt.words}
+			if got := rule.Triggered(stats); got != tt.want {
+				t.Errorf("triggered = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				if line := rule.line(stats, defaultIntensity); !strings.Contains(line, "'stuff'") {
+					t.Errorf("line = %q", line)
+				}
+			}
+		})
+	}
+}